@@ -46,6 +46,9 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// APIKeyIDKey holds the ID of the API key a request was authenticated with
+const APIKeyIDKey contextKey = "apiKeyID"
+
 func GenerateToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -245,8 +248,14 @@ func GenerateAPIKey(prefix string) (string, string, string, string, error) {
 
 // ValidateAPIKey validates an API key and returns the associated user ID and permissions
 func ValidateAPIKey(keyStr string) (string, []string, error) {
+	_, userID, permissions, err := ValidateAPIKeyWithID(keyStr)
+	return userID, permissions, err
+}
+
+// ValidateAPIKeyWithID validates an API key and returns the key ID, the associated user ID and permissions
+func ValidateAPIKeyWithID(keyStr string) (string, string, []string, error) {
 	if keyStr == "" {
-		return "", nil, errors.New("API key is empty")
+		return "", "", nil, errors.New("API key is empty")
 	}
 
 	keyHashSHA256 := hashAPIKey(keyStr)
@@ -266,7 +275,7 @@ func ValidateAPIKey(keyStr string) (string, []string, error) {
 		First(&key).Error
 	if err == nil {
 		if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
-			return "", nil, errors.New("API key has expired")
+			return "", "", nil, errors.New("API key has expired")
 		}
 
 		var permissions []string
@@ -296,10 +305,10 @@ func ValidateAPIKey(keyStr string) (string, []string, error) {
 			}
 		}()
 
-		return key.UserID, permissions, nil
+		return key.ID, key.UserID, permissions, nil
 	}
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return "", nil, fmt.Errorf("database query failed: %w", err)
+		return "", "", nil, fmt.Errorf("database query failed: %w", err)
 	}
 
 	// Extract prefix from the key to find candidates
@@ -328,7 +337,7 @@ func ValidateAPIKey(keyStr string) (string, []string, error) {
 		Where("key_hash_sha256 IS NULL").
 		Find(&keys).Error
 	if err != nil {
-		return "", nil, fmt.Errorf("database query failed: %w", err)
+		return "", "", nil, fmt.Errorf("database query failed: %w", err)
 	}
 
 	// Filter to only keys where the provided key starts with the stored prefix
@@ -377,12 +386,12 @@ func ValidateAPIKey(keyStr string) (string, []string, error) {
 	}
 
 	if result == nil {
-		return "", nil, errors.New("invalid API key")
+		return "", "", nil, errors.New("invalid API key")
 	}
 
 	// Check if the key has expired
 	if result.ExpiresAt != nil && time.Now().After(*result.ExpiresAt) {
-		return "", nil, errors.New("API key has expired")
+		return "", "", nil, errors.New("API key has expired")
 	}
 
 	// Parse permissions from JSON
@@ -421,5 +430,36 @@ func ValidateAPIKey(keyStr string) (string, []string, error) {
 		}
 	}()
 
-	return result.UserID, permissions, nil
+	return result.ID, result.UserID, permissions, nil
+}
+
+// ClientIP returns the IP address of the client that sent the request.
+//
+// Security Note: This function trusts X-Forwarded-For and X-Real-IP headers.
+// In production environments behind a reverse proxy, ensure the proxy is configured
+// to set these headers correctly and that direct client access to the backend is blocked.
+func ClientIP(r *http.Request) string {
+	// Check X-Forwarded-For header first (common in proxied environments)
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded != "" {
+		// X-Forwarded-For can contain multiple IPs, take the first one
+		ips := strings.Split(forwarded, ",")
+		if len(ips) > 0 {
+			return strings.TrimSpace(ips[0])
+		}
+	}
+
+	// Check X-Real-IP header
+	realIP := r.Header.Get("X-Real-IP")
+	if realIP != "" {
+		return strings.TrimSpace(realIP)
+	}
+
+	// Fall back to RemoteAddr
+	// RemoteAddr is in format "IP:port", extract just the IP
+	ip := r.RemoteAddr
+	if idx := strings.LastIndex(ip, ":"); idx != -1 {
+		ip = ip[:idx]
+	}
+	return ip
 }
@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	key     []byte
+	keyLock sync.Mutex
+)
+
+// Init derives the key used to encrypt sensitive data at rest (e.g. bank details)
+// from DATA_ENCRYPTION_KEY. The key is independent of JWT_SECRET, so rotating the
+// signing secret does not make encrypted data unreadable.
+func Init() error {
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	secret := os.Getenv("DATA_ENCRYPTION_KEY")
+	if secret == "" {
+		return fmt.Errorf("DATA_ENCRYPTION_KEY environment variable is required")
+	}
+	sum := sha256.Sum256([]byte(secret))
+	key = sum[:]
+	return nil
+}
+
+// getKey returns the encryption key, initializing it from the environment on first use
+func getKey() ([]byte, error) {
+	keyLock.Lock()
+	k := key
+	keyLock.Unlock()
+	if k != nil {
+		return k, nil
+	}
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM and returns it base64 encoded
+// Format: base64(nonce + ciphertext)
+func Encrypt(plaintext string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(encoded string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// SigningKey derives a key for signing data of the given purpose, e.g. download links. Each purpose
+// gets its own key, separate from the encryption key and from JWT_SECRET.
+func SigningKey(purpose string) ([]byte, error) {
+	k, err := getKey()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, k)
+	mac.Write([]byte("signing:" + purpose))
+	return mac.Sum(nil), nil
+}
+
+// newGCM creates the AES-GCM cipher for the configured key
+func newGCM() (cipher.AEAD, error) {
+	k, err := getKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
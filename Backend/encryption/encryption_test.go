@@ -0,0 +1,85 @@
+package encryption
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	t.Run("Init with DATA_ENCRYPTION_KEY", func(t *testing.T) {
+		os.Setenv("DATA_ENCRYPTION_KEY", "test-encryption-key")
+		defer os.Unsetenv("DATA_ENCRYPTION_KEY")
+
+		assert.NoError(t, Init())
+		assert.Len(t, key, 32)
+	})
+
+	t.Run("Init does not fall back to JWT_SECRET", func(t *testing.T) {
+		os.Unsetenv("DATA_ENCRYPTION_KEY")
+		os.Setenv("JWT_SECRET", "test-jwt-secret")
+		defer os.Unsetenv("JWT_SECRET")
+
+		err := Init()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "DATA_ENCRYPTION_KEY")
+	})
+
+	t.Run("Init without secrets", func(t *testing.T) {
+		os.Unsetenv("DATA_ENCRYPTION_KEY")
+		os.Unsetenv("JWT_SECRET")
+
+		err := Init()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "DATA_ENCRYPTION_KEY")
+	})
+}
+
+func TestSigningKey(t *testing.T) {
+	os.Setenv("DATA_ENCRYPTION_KEY", "test-encryption-key")
+	defer os.Unsetenv("DATA_ENCRYPTION_KEY")
+	require.NoError(t, Init())
+
+	exports, err := SigningKey("data-export")
+	require.NoError(t, err)
+	assert.Len(t, exports, 32)
+	assert.NotEqual(t, key, exports)
+
+	again, err := SigningKey("data-export")
+	require.NoError(t, err)
+	assert.Equal(t, exports, again)
+
+	other, err := SigningKey("other")
+	require.NoError(t, err)
+	assert.NotEqual(t, exports, other)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	os.Setenv("DATA_ENCRYPTION_KEY", "test-encryption-key")
+	defer os.Unsetenv("DATA_ENCRYPTION_KEY")
+	require.NoError(t, Init())
+
+	ciphertext, err := Encrypt("DE89370400440532013000")
+	require.NoError(t, err)
+	assert.NotContains(t, ciphertext, "DE89370400440532013000")
+
+	// Each encryption uses a fresh nonce
+	other, err := Encrypt("DE89370400440532013000")
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, other)
+
+	plaintext, err := Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "DE89370400440532013000", plaintext)
+
+	_, err = Decrypt("not-valid")
+	assert.Error(t, err)
+
+	// Data encrypted with another key cannot be decrypted
+	os.Setenv("DATA_ENCRYPTION_KEY", "another-key")
+	require.NoError(t, Init())
+	_, err = Decrypt(ciphertext)
+	assert.Error(t, err)
+}
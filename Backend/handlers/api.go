@@ -13,6 +13,7 @@ func Handler_v1() http.Handler {
 
 	registerAuthRoutes(mux)
 	registerKeycloakAuthRoutes(mux)
+	registerDataExportRoutes(mux)
 
 	return LoggingMiddleware(CorsMiddleware(mux))
 }
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/models"
+)
+
+func registerDataExportRoutes(mux *http.ServeMux) {
+	mux.Handle("/api/v1/data-exports/", RateLimitMiddleware(apiLimiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleDownloadDataExport(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+}
+
+// endpoint: GET /api/v1/data-exports/{exportId}?expires=...&signature=...
+// The signed link is the authorization, so the archive can be downloaded directly by the browser.
+func handleDownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	exportID := strings.TrimPrefix(r.URL.Path, "/api/v1/data-exports/")
+	if exportID == "" || strings.Contains(exportID, "/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	content, err := models.GetSignedDataExport(exportID, r.URL.Query().Get("expires"), r.URL.Query().Get("signature"))
+	if err != nil {
+		if errors.Is(err, models.ErrDataExportInvalidLink) {
+			http.Error(w, "Download link is invalid or expired", http.StatusForbidden)
+			return
+		}
+		log.Printf("Failed to load data export %s: %v", exportID, err)
+		http.Error(w, "Failed to load data export", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"data-export-%s.zip\"", time.Now().Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
@@ -262,29 +262,7 @@ func handleKeycloakLogout(w http.ResponseWriter, r *http.Request) {
 // - Use RemoteAddr only (comment out X-Forwarded-For logic)
 // - Consider making IP validation optional via configuration
 func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first (common in proxied environments)
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		ips := strings.Split(forwarded, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return strings.TrimSpace(realIP)
-	}
-
-	// Fall back to RemoteAddr
-	// RemoteAddr is in format "IP:port", extract just the IP
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
-	}
-	return ip
+	return auth.ClientIP(r)
 }
 
 // generateRandomState generates a random state parameter
@@ -212,15 +212,16 @@ func APIKeyAuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Validate the API key
-		userID, _, err := auth.ValidateAPIKey(apiKey)
+		apiKeyID, userID, _, err := auth.ValidateAPIKeyWithID(apiKey)
 		if err != nil {
 			log.Printf("API key authentication failed: %v", err)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// Set user ID in context
+		// Set user ID and API key ID in context
 		ctx := context.WithValue(r.Context(), auth.UserIDKey, userID)
+		ctx = context.WithValue(ctx, auth.APIKeyIDKey, apiKeyID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -245,15 +246,16 @@ func CompositeAuthMiddleware(next http.Handler) http.Handler {
 
 		if apiKey != "" {
 			// Validate the API key
-			userID, _, err := auth.ValidateAPIKey(apiKey)
+			apiKeyID, userID, _, err := auth.ValidateAPIKeyWithID(apiKey)
 			if err != nil {
 				log.Printf("Authentication failed: %v", err)
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			// Set user ID in context
+			// Set user ID and API key ID in context
 			ctx := context.WithValue(r.Context(), auth.UserIDKey, userID)
+			ctx = context.WithValue(ctx, auth.APIKeyIDKey, apiKeyID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
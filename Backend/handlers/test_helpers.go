@@ -23,8 +23,9 @@ func GetDB() *gorm.DB {
 
 // SetupTestDB initializes a file-based SQLite database for testing
 func SetupTestDB(t *testing.T) {
-	// Set test environment variable
+	// Set test environment variables
 	os.Setenv("GO_ENV", "test")
+	os.Setenv("DATA_ENCRYPTION_KEY", "test-encryption-key")
 
 	var err error
 	// Use a temp file-based SQLite database for more consistent test behavior
@@ -97,6 +98,8 @@ func SetupTestDB(t *testing.T) {
 			created_by TEXT,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_by TEXT,
+			status TEXT DEFAULT 'active',
+			status_since DATETIME,
 			UNIQUE(club_id, user_id)
 		)
 	`)
@@ -127,13 +130,20 @@ func SetupTestDB(t *testing.T) {
 			user_id TEXT NOT NULL,
 			club_id TEXT NOT NULL,
 			team_id TEXT,
+			season_id TEXT,
 			reason TEXT,
 			amount REAL,
 			paid BOOLEAN DEFAULT FALSE,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			created_by TEXT,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_by TEXT
+			updated_by TEXT,
+			fine_rule_id TEXT,
+			event_id TEXT,
+			pending_review BOOLEAN DEFAULT FALSE,
+			dispute_status TEXT DEFAULT 'open',
+			dispute_reason TEXT,
+			original_amount REAL
 		)
 	`)
 	testDB.Exec(`
@@ -158,14 +168,27 @@ func SetupTestDB(t *testing.T) {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			created_by TEXT,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_by TEXT
+			updated_by TEXT,
+			role TEXT,
+			description TEXT,
+			min_headcount INTEGER DEFAULT 1,
+			max_headcount INTEGER DEFAULT 0,
+			withdrawal_cutoff_hours INTEGER DEFAULT 24,
+			swap_requires_approval BOOLEAN DEFAULT FALSE,
+			shift_template_id TEXT,
+			venue_id TEXT
 		)
 	`)
 	testDB.Exec(`
 		CREATE TABLE IF NOT EXISTS shift_members (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			id TEXT PRIMARY KEY,
 			shift_id TEXT NOT NULL,
-			user_id TEXT NOT NULL
+			user_id TEXT NOT NULL,
+			attended BOOLEAN,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
 		)
 	`)
 	testDB.Exec(`
@@ -173,6 +196,7 @@ func SetupTestDB(t *testing.T) {
 			id TEXT PRIMARY KEY,
 			club_id TEXT NOT NULL,
 			team_id TEXT,
+			season_id TEXT,
 			name TEXT NOT NULL,
 			description TEXT,
 			location TEXT,
@@ -186,7 +210,8 @@ func SetupTestDB(t *testing.T) {
 			recurrence_pattern TEXT,
 			recurrence_interval INTEGER DEFAULT 1,
 			recurrence_end DATETIME,
-			parent_event_id TEXT
+			parent_event_id TEXT,
+			venue_id TEXT
 		)
 	`)
 	testDB.Exec(`
@@ -196,7 +221,10 @@ func SetupTestDB(t *testing.T) {
 			user_id TEXT NOT NULL,
 			response TEXT NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			attended BOOLEAN,
+			previous_response TEXT,
+			response_changed_at DATETIME
 		)
 	`)
 	testDB.Exec(`
@@ -211,6 +239,601 @@ func SetupTestDB(t *testing.T) {
 			updated_by TEXT
 		)
 	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS comments (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			news_id TEXT,
+			event_id TEXT,
+			parent_id TEXT,
+			content TEXT NOT NULL,
+			edited BOOLEAN DEFAULT FALSE,
+			edited_at DATETIME,
+			deleted BOOLEAN DEFAULT FALSE,
+			deleted_at DATETIME,
+			deleted_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS comment_reactions (
+			id TEXT PRIMARY KEY,
+			comment_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			emoji TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(comment_id, user_id, emoji)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS polls (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			team_id TEXT,
+			question TEXT NOT NULL,
+			description TEXT,
+			multiple_choice BOOLEAN DEFAULT FALSE,
+			anonymous BOOLEAN DEFAULT FALSE,
+			hide_results_until_closed BOOLEAN DEFAULT FALSE,
+			deadline DATETIME,
+			closed BOOLEAN DEFAULT FALSE,
+			closed_at DATETIME,
+			closed_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS poll_options (
+			id TEXT PRIMARY KEY,
+			poll_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			position INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS poll_votes (
+			id TEXT PRIMARY KEY,
+			poll_id TEXT NOT NULL,
+			option_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(option_id, user_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduling_polls (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			team_id TEXT,
+			title TEXT NOT NULL,
+			description TEXT,
+			location TEXT,
+			deadline DATETIME,
+			closed BOOLEAN DEFAULT FALSE,
+			event_id TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduling_slots (
+			id TEXT PRIMARY KEY,
+			scheduling_poll_id TEXT NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduling_responses (
+			id TEXT PRIMARY KEY,
+			scheduling_poll_id TEXT NOT NULL,
+			slot_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			answer TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(slot_id, user_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS conversations (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			team_id TEXT,
+			title TEXT,
+			last_message_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_participants (
+			id TEXT PRIMARY KEY,
+			conversation_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_read_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(conversation_id, user_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS messages (
+			id TEXT PRIMARY KEY,
+			conversation_id TEXT NOT NULL,
+			club_id TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fee_plans (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			amount REAL NOT NULL,
+			period TEXT NOT NULL,
+			due_days INTEGER DEFAULT 14,
+			active BOOLEAN DEFAULT TRUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fee_discounts (
+			id TEXT PRIMARY KEY,
+			fee_plan_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			min_age INTEGER,
+			max_age INTEGER,
+			role TEXT,
+			percent REAL NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fee_assignments (
+			id TEXT PRIMARY KEY,
+			fee_plan_id TEXT NOT NULL,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			start_date DATETIME NOT NULL,
+			end_date DATETIME,
+			next_billing_date DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS invoices (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			fee_plan_id TEXT NOT NULL,
+			fee_assignment_id TEXT NOT NULL,
+			reference TEXT NOT NULL UNIQUE,
+			period_start DATETIME,
+			period_end DATETIME,
+			amount REAL,
+			discount_amount REAL,
+			due_date DATETIME,
+			paid BOOLEAN DEFAULT FALSE,
+			paid_at DATETIME,
+			reminder_count INTEGER DEFAULT 0,
+			last_reminder_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS sepa_mandates (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			iban_encrypted TEXT NOT NULL,
+			iban_masked TEXT,
+			bic TEXT,
+			mandate_reference TEXT NOT NULL,
+			signature_date DATETIME,
+			active BOOLEAN DEFAULT TRUE,
+			revoked_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS sepa_creditors (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			iban_encrypted TEXT NOT NULL,
+			iban_masked TEXT,
+			bic TEXT,
+			creditor_identifier TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS sepa_debits (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			batch_id TEXT NOT NULL,
+			end_to_end_id TEXT NOT NULL UNIQUE,
+			user_id TEXT NOT NULL,
+			mandate_id TEXT NOT NULL,
+			fine_id TEXT,
+			invoice_id TEXT,
+			amount REAL,
+			collection_date DATETIME,
+			status TEXT NOT NULL,
+			paid_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fine_rules (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			team_id TEXT,
+			name TEXT NOT NULL,
+			trigger TEXT NOT NULL,
+			amount REAL,
+			reason TEXT,
+			late_change_hours INTEGER DEFAULT 24,
+			evaluation_delay_hours INTEGER DEFAULT 24,
+			active BOOLEAN DEFAULT TRUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fine_rule_evaluations (
+			id TEXT PRIMARY KEY,
+			fine_rule_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			fines_created INTEGER DEFAULT 0,
+			evaluated_at DATETIME,
+			UNIQUE(fine_rule_id, event_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS fine_dispute_entries (
+			id TEXT PRIMARY KEY,
+			fine_id TEXT NOT NULL,
+			club_id TEXT NOT NULL,
+			from_status TEXT,
+			to_status TEXT,
+			comment TEXT,
+			amount_before REAL,
+			amount_after REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS shift_templates (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS shift_template_slots (
+			id TEXT PRIMARY KEY,
+			template_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			headcount INTEGER DEFAULT 1,
+			start_offset_minutes INTEGER DEFAULT 0,
+			duration_minutes INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS shift_swap_requests (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			shift_id TEXT NOT NULL,
+			shift_member_id TEXT NOT NULL,
+			requested_by TEXT NOT NULL,
+			note TEXT,
+			status TEXT NOT NULL DEFAULT 'open',
+			claimed_by TEXT,
+			trade_shift_member_id TEXT,
+			resolved_by TEXT,
+			resolved_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS volunteer_quotas (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL UNIQUE,
+			required_hours REAL DEFAULT 0,
+			period_start_month INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS member_absences (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			reason TEXT NOT NULL DEFAULT 'other',
+			note TEXT,
+			visibility TEXT NOT NULL DEFAULT 'team',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS venues (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			address TEXT,
+			capacity INTEGER DEFAULT 0,
+			allow_overlap BOOLEAN DEFAULT FALSE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS club_roles (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS club_role_permissions (
+			id TEXT PRIMARY KEY,
+			role_id TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(role_id, permission)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS club_role_assignments (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			UNIQUE(role_id, user_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_logs (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			actor_id TEXT,
+			api_key_id TEXT,
+			ip_address TEXT,
+			entity_type TEXT NOT NULL,
+			entity_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			action TEXT,
+			changes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS data_exports (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			size INTEGER DEFAULT 0,
+			error TEXT,
+			completed_at DATETIME,
+			expires_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS data_export_archives (
+			export_id TEXT PRIMARY KEY,
+			content BLOB NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS account_deletions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			reason TEXT,
+			scheduled_for DATETIME NOT NULL,
+			cancelled_at DATETIME,
+			completed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS ownership_transfers (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			from_user_id TEXT NOT NULL,
+			to_user_id TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			responded_at DATETIME
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS club_successions (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL UNIQUE,
+			successor_id TEXT NOT NULL,
+			inactivity_days INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS user_presences (
+			user_id TEXT PRIMARY KEY,
+			last_seen_at DATETIME NOT NULL
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS guardianships (
+			id TEXT PRIMARY KEY,
+			guardian_id TEXT NOT NULL,
+			dependent_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			UNIQUE (guardian_id, dependent_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS member_field_definitions (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			options TEXT,
+			visibility TEXT NOT NULL DEFAULT 'admins',
+			required BOOLEAN DEFAULT FALSE,
+			sort_order INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT,
+			UNIQUE (club_id, name)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS member_field_values (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			member_id TEXT NOT NULL,
+			field_id TEXT NOT NULL,
+			value TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT,
+			UNIQUE (member_id, field_id)
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS seasons (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			start_date DATETIME NOT NULL,
+			end_date DATETIME NOT NULL,
+			archived BOOLEAN DEFAULT FALSE,
+			archived_at DATETIME,
+			archived_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS membership_status_changes (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			role TEXT,
+			effective_from DATETIME NOT NULL,
+			effective_until DATETIME,
+			reason TEXT,
+			changed_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS bank_statement_imports (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			format TEXT NOT NULL,
+			file_name TEXT,
+			transaction_count INTEGER DEFAULT 0,
+			matched_count INTEGER DEFAULT 0,
+			suggested_count INTEGER DEFAULT 0,
+			duplicate_count INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT
+		)
+	`)
+	testDB.Exec(`
+		CREATE TABLE IF NOT EXISTS bank_transactions (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			import_id TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			booking_date DATETIME,
+			amount REAL,
+			currency TEXT,
+			payer_name TEXT,
+			payer_iban TEXT,
+			reference TEXT,
+			end_to_end_id TEXT,
+			status TEXT NOT NULL,
+			confidence INTEGER DEFAULT 0,
+			match_reason TEXT,
+			fine_id TEXT,
+			invoice_id TEXT,
+			settled_at DATETIME,
+			settled_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
 	testDB.Exec(`
 		CREATE TABLE IF NOT EXISTS club_settings (
 			id TEXT PRIMARY KEY,
@@ -241,7 +864,8 @@ func SetupTestDB(t *testing.T) {
 			event_id TEXT,
 			fine_id TEXT,
 			invite_id TEXT,
-			join_request_id TEXT
+			join_request_id TEXT,
+			dependent_id TEXT
 		)
 	`)
 	testDB.Exec(`
@@ -276,6 +900,12 @@ func SetupTestDB(t *testing.T) {
 			role_changed_email BOOLEAN DEFAULT TRUE,
 			join_request_in_app BOOLEAN DEFAULT TRUE,
 			join_request_email BOOLEAN DEFAULT TRUE,
+			comment_added_in_app BOOLEAN DEFAULT TRUE,
+			comment_added_email BOOLEAN DEFAULT FALSE,
+			message_received_in_app BOOLEAN DEFAULT TRUE,
+			message_received_email BOOLEAN DEFAULT FALSE,
+			fee_due_in_app BOOLEAN DEFAULT TRUE,
+			fee_due_email BOOLEAN DEFAULT TRUE,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
@@ -302,6 +932,7 @@ func SetupTestDB(t *testing.T) {
 		CREATE TABLE IF NOT EXISTS teams (
 			id TEXT PRIMARY KEY,
 			club_id TEXT NOT NULL,
+			season_id TEXT,
 			name TEXT NOT NULL,
 			description TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
@@ -364,17 +995,16 @@ func SetupTestDB(t *testing.T) {
 	testDB.Exec(`
 		CREATE TABLE IF NOT EXISTS api_keys (
 			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
 			name TEXT NOT NULL,
 			key_hash TEXT NOT NULL UNIQUE,
 			key_hash_sha256 TEXT UNIQUE,
-			club_id TEXT NOT NULL,
-			created_by TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			expires_at DATETIME,
+			key_prefix TEXT NOT NULL,
+			permissions TEXT,
 			last_used_at DATETIME,
-			revoked BOOLEAN DEFAULT FALSE,
-			revoked_at DATETIME,
-			revoked_by TEXT
+			expires_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
 	`)
 }
@@ -395,6 +1025,50 @@ func TeardownTestDB(t *testing.T) {
 		testDB.Exec("DELETE FROM user_privacy_settings")
 		testDB.Exec("DELETE FROM member_privacy_settings")
 		testDB.Exec("DELETE FROM notifications")
+		testDB.Exec("DELETE FROM seasons")
+		testDB.Exec("DELETE FROM membership_status_changes")
+		testDB.Exec("DELETE FROM member_field_values")
+		testDB.Exec("DELETE FROM member_field_definitions")
+		testDB.Exec("DELETE FROM guardianships")
+		testDB.Exec("DELETE FROM user_presences")
+		testDB.Exec("DELETE FROM club_successions")
+		testDB.Exec("DELETE FROM ownership_transfers")
+		testDB.Exec("DELETE FROM account_deletions")
+		testDB.Exec("DELETE FROM data_export_archives")
+		testDB.Exec("DELETE FROM data_exports")
+		testDB.Exec("DELETE FROM audit_logs")
+		testDB.Exec("DELETE FROM club_role_assignments")
+		testDB.Exec("DELETE FROM club_role_permissions")
+		testDB.Exec("DELETE FROM club_roles")
+		testDB.Exec("DELETE FROM venues")
+		testDB.Exec("DELETE FROM member_absences")
+		testDB.Exec("DELETE FROM volunteer_quotas")
+		testDB.Exec("DELETE FROM shift_swap_requests")
+		testDB.Exec("DELETE FROM shift_template_slots")
+		testDB.Exec("DELETE FROM shift_templates")
+		testDB.Exec("DELETE FROM fine_dispute_entries")
+		testDB.Exec("DELETE FROM fine_rule_evaluations")
+		testDB.Exec("DELETE FROM fine_rules")
+		testDB.Exec("DELETE FROM bank_transactions")
+		testDB.Exec("DELETE FROM bank_statement_imports")
+		testDB.Exec("DELETE FROM sepa_debits")
+		testDB.Exec("DELETE FROM sepa_creditors")
+		testDB.Exec("DELETE FROM sepa_mandates")
+		testDB.Exec("DELETE FROM invoices")
+		testDB.Exec("DELETE FROM fee_assignments")
+		testDB.Exec("DELETE FROM fee_discounts")
+		testDB.Exec("DELETE FROM fee_plans")
+		testDB.Exec("DELETE FROM messages")
+		testDB.Exec("DELETE FROM conversation_participants")
+		testDB.Exec("DELETE FROM conversations")
+		testDB.Exec("DELETE FROM scheduling_responses")
+		testDB.Exec("DELETE FROM scheduling_slots")
+		testDB.Exec("DELETE FROM scheduling_polls")
+		testDB.Exec("DELETE FROM poll_votes")
+		testDB.Exec("DELETE FROM poll_options")
+		testDB.Exec("DELETE FROM polls")
+		testDB.Exec("DELETE FROM comment_reactions")
+		testDB.Exec("DELETE FROM comments")
 		testDB.Exec("DELETE FROM event_rsvps")
 		testDB.Exec("DELETE FROM shift_members")
 		testDB.Exec("DELETE FROM shifts")
@@ -13,6 +13,7 @@ import (
 	"github.com/NLstn/civo/azure"
 	"github.com/NLstn/civo/csrf"
 	"github.com/NLstn/civo/database"
+	"github.com/NLstn/civo/encryption"
 	"github.com/NLstn/civo/handlers"
 	"github.com/NLstn/civo/models"
 	"github.com/NLstn/civo/odata"
@@ -49,6 +50,50 @@ func main() {
 		&models.Event{},
 		&models.EventRSVP{},
 		&models.News{},
+		&models.Comment{},
+		&models.CommentReaction{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.SchedulingPoll{},
+		&models.SchedulingSlot{},
+		&models.SchedulingResponse{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
+		&models.FeePlan{},
+		&models.FeeDiscount{},
+		&models.FeeAssignment{},
+		&models.Invoice{},
+		&models.SepaMandate{},
+		&models.SepaCreditor{},
+		&models.SepaDebit{},
+		&models.BankStatementImport{},
+		&models.BankTransaction{},
+		&models.FineRule{},
+		&models.FineRuleEvaluation{},
+		&models.FineDisputeEntry{},
+		&models.ShiftTemplate{},
+		&models.ShiftTemplateSlot{},
+		&models.ShiftSwapRequest{},
+		&models.VolunteerQuota{},
+		&models.MemberAbsence{},
+		&models.Venue{},
+		&models.ClubRole{},
+		&models.ClubRolePermission{},
+		&models.ClubRoleAssignment{},
+		&models.AuditLog{},
+		&models.DataExport{},
+		&models.DataExportArchive{},
+		&models.AccountDeletion{},
+		&models.OwnershipTransfer{},
+		&models.ClubSuccession{},
+		&models.UserPresence{},
+		&models.Guardianship{},
+		&models.MemberFieldDefinition{},
+		&models.MemberFieldValue{},
+		&models.Season{},
+		&models.MembershipStatusChange{},
 		&models.ClubSettings{},
 		&models.Notification{},
 		&models.UserNotificationPreferences{},
@@ -78,6 +123,11 @@ func main() {
 		log.Fatal("Could not initialize auth:", err)
 	}
 
+	err = encryption.Init()
+	if err != nil {
+		log.Fatal("Could not initialize encryption:", err)
+	}
+
 	err = auth.InitKeycloak()
 	if err != nil {
 		log.Printf("Warning: Could not initialize Keycloak: %v", err)
@@ -119,6 +169,99 @@ func main() {
 		log.Fatal("Could not register API key cleanup job:", err)
 	}
 
+	// Register membership fee billing jobs
+	err = jobScheduler.RegisterJobWithSchedule(
+		"generate_fee_invoices",
+		models.GenerateFeeInvoices,
+		scheduler.JobConfig{
+			Name:            "fee_invoice_generation",
+			Description:     "Generates membership fee invoices for assignments whose billing period has started",
+			IntervalMinutes: 60,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register fee invoice job:", err)
+	}
+
+	err = jobScheduler.RegisterJobWithSchedule(
+		"send_overdue_fee_reminders",
+		models.SendOverdueFeeReminders,
+		scheduler.JobConfig{
+			Name:            "fee_overdue_reminders",
+			Description:     "Reminds members about unpaid membership fee invoices past their due date",
+			IntervalMinutes: 1440,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register fee reminder job:", err)
+	}
+
+	// Register automatic fine rule evaluation job
+	err = jobScheduler.RegisterJobWithSchedule(
+		"evaluate_fine_rules",
+		models.EvaluateFineRules,
+		scheduler.JobConfig{
+			Name:            "fine_rule_evaluation",
+			Description:     "Creates fines pending review for events that ended according to the club's fine rules",
+			IntervalMinutes: 60,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register fine rule job:", err)
+	}
+
+	err = jobScheduler.RegisterJobWithSchedule(
+		"process_data_exports",
+		models.ProcessDataExports,
+		scheduler.JobConfig{
+			Name:            "data_export_processing",
+			Description:     "Builds requested personal data exports and removes expired archives",
+			IntervalMinutes: 5,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register data export job:", err)
+	}
+
+	err = jobScheduler.RegisterJobWithSchedule(
+		"purge_deleted_accounts",
+		models.PurgeDeletedAccounts,
+		scheduler.JobConfig{
+			Name:            "account_deletion_purge",
+			Description:     "Anonymizes accounts whose deletion grace period has passed",
+			IntervalMinutes: 60,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register account deletion job:", err)
+	}
+
+	err = jobScheduler.RegisterJobWithSchedule(
+		"purge_deleted_clubs",
+		models.PurgeDeletedClubs,
+		scheduler.JobConfig{
+			Name:            "deleted_club_purge",
+			Description:     "Notifies owners of deleted clubs and permanently deletes clubs after the retention period",
+			IntervalMinutes: 60,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register club purge job:", err)
+	}
+
+	err = jobScheduler.RegisterJobWithSchedule(
+		"promote_club_successors",
+		models.PromoteClubSuccessors,
+		scheduler.JobConfig{
+			Name:            "club_successor_promotion",
+			Description:     "Promotes designated successors of clubs whose owners have all been inactive",
+			IntervalMinutes: 1440,
+		},
+	)
+	if err != nil {
+		log.Fatal("Could not register club successor job:", err)
+	}
+
 	// Start the scheduler
 	jobScheduler.Start()
 
@@ -0,0 +1,319 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Absence reason categories
+const (
+	AbsenceReasonHoliday = "holiday"
+	AbsenceReasonIllness = "illness"
+	AbsenceReasonInjury  = "injury"
+	AbsenceReasonWork    = "work"
+	AbsenceReasonOther   = "other"
+)
+
+// Absence visibility levels
+const (
+	AbsenceVisibilityPrivate = "private" // The member and club admins
+	AbsenceVisibilityTeam    = "team"    // Additionally admins of the member's teams
+	AbsenceVisibilityClub    = "club"    // All club members
+)
+
+var ErrMemberAbsent = errors.New("member is absent during this time")
+
+// absenceVisibleScope limits absences to those the user may see: their own, all of clubs they
+// administer, those shared with their teams' admins and those shared with the whole club
+const absenceVisibleScope = `user_id = ?
+	OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role IN ('admin', 'owner'))
+	OR (visibility = 'club' AND club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former'))
+	OR (visibility = 'team' AND EXISTS (
+		SELECT 1 FROM team_members tm
+		JOIN team_members coach ON coach.team_id = tm.team_id AND coach.user_id = ? AND coach.role = 'admin'
+		JOIN teams ON teams.id = tm.team_id AND teams.club_id = member_absences.club_id
+		WHERE tm.user_id = member_absences.user_id))`
+
+// MemberAbsence is a period in which a member is unavailable for events and shifts
+type MemberAbsence struct {
+	ID         string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID     string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	UserID     string    `json:"UserID" gorm:"type:uuid;not null;index" odata:"required"`
+	StartDate  time.Time `json:"StartDate" gorm:"type:date;not null" odata:"required"`
+	EndDate    time.Time `json:"EndDate" gorm:"type:date;not null" odata:"required"` // Inclusive
+	Reason     string    `json:"Reason" gorm:"not null;default:other"`               // holiday, illness, injury, work, other
+	Note       *string   `json:"Note,omitempty" odata:"nullable"`
+	Visibility string    `json:"Visibility" gorm:"not null;default:team"` // private, team, club
+	CreatedAt  time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy  string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt  time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy  string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new absences
+func (ma *MemberAbsence) BeforeCreate(tx *gorm.DB) error {
+	if ma.ID == "" {
+		ma.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsValidAbsenceReason checks if a reason category is supported
+func IsValidAbsenceReason(reason string) bool {
+	switch reason {
+	case AbsenceReasonHoliday, AbsenceReasonIllness, AbsenceReasonInjury, AbsenceReasonWork, AbsenceReasonOther:
+		return true
+	}
+	return false
+}
+
+// validate checks the absence and fills in defaults
+func (ma *MemberAbsence) validate() error {
+	if ma.StartDate.IsZero() || ma.EndDate.IsZero() {
+		return fmt.Errorf("start and end date are required")
+	}
+	if ma.EndDate.Before(ma.StartDate) {
+		return fmt.Errorf("end date must not be before start date")
+	}
+	if ma.Reason == "" {
+		ma.Reason = AbsenceReasonOther
+	}
+	if !IsValidAbsenceReason(ma.Reason) {
+		return fmt.Errorf("invalid reason: must be one of '%s', '%s', '%s', '%s' or '%s'", AbsenceReasonHoliday, AbsenceReasonIllness, AbsenceReasonInjury, AbsenceReasonWork, AbsenceReasonOther)
+	}
+	if ma.Visibility == "" {
+		ma.Visibility = AbsenceVisibilityTeam
+	}
+	switch ma.Visibility {
+	case AbsenceVisibilityPrivate, AbsenceVisibilityTeam, AbsenceVisibilityClub:
+	default:
+		return fmt.Errorf("invalid visibility: must be '%s', '%s' or '%s'", AbsenceVisibilityPrivate, AbsenceVisibilityTeam, AbsenceVisibilityClub)
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", ma.ClubID, ma.UserID).First(&member).Error; err != nil {
+		return fmt.Errorf("user is not a member of the club")
+	}
+	return nil
+}
+
+// Covers checks whether the absence overlaps the given time range. The end date is inclusive.
+func (ma *MemberAbsence) Covers(start, end time.Time) bool {
+	from := time.Date(ma.StartDate.Year(), ma.StartDate.Month(), ma.StartDate.Day(), 0, 0, 0, 0, start.Location())
+	until := time.Date(ma.EndDate.Year(), ma.EndDate.Month(), ma.EndDate.Day(), 0, 0, 0, 0, start.Location()).AddDate(0, 0, 1)
+	return from.Before(end) && until.After(start)
+}
+
+// IsAbsent checks whether the user has an absence in the club overlapping the given time range
+func IsAbsent(clubID, userID string, start, end time.Time) (bool, error) {
+	var absences []MemberAbsence
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, userID).Find(&absences).Error; err != nil {
+		return false, err
+	}
+	for _, absence := range absences {
+		if absence.Covers(start, end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeclineEvents answers "no" for all upcoming events of the club (and the member's teams)
+// that start during the absence, replacing earlier answers. Returns the number of declined events.
+func (ma *MemberAbsence) DeclineEvents(tx *gorm.DB) (int, error) {
+	from := time.Date(ma.StartDate.Year(), ma.StartDate.Month(), ma.StartDate.Day(), 0, 0, 0, 0, time.Local)
+	until := time.Date(ma.EndDate.Year(), ma.EndDate.Month(), ma.EndDate.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	if !until.After(from) {
+		return 0, nil
+	}
+
+	var events []Event
+	err := tx.Where("club_id = ? AND start_time >= ? AND start_time < ?", ma.ClubID, from, until).
+		Where("team_id IS NULL OR team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)", ma.UserID).
+		Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+
+	user := User{ID: ma.UserID}
+	for _, event := range events {
+		if err := user.createOrUpdateRSVP(tx, event.ID, "no"); err != nil {
+			return 0, fmt.Errorf("failed to decline event %s: %w", event.ID, err)
+		}
+	}
+	return len(events), nil
+}
+
+// declineAbsentMembers answers "no" for the members whose absence overlaps the event, replacing
+// earlier answers. For team events only the team's members are declined.
+func (e *Event) declineAbsentMembers(tx *gorm.DB) error {
+	query := tx.Where("club_id = ?", e.ClubID)
+	if e.TeamID != nil && *e.TeamID != "" {
+		query = query.Where("user_id IN (SELECT user_id FROM team_members WHERE team_id = ?)", *e.TeamID)
+	}
+	var absences []MemberAbsence
+	if err := query.Find(&absences).Error; err != nil {
+		return err
+	}
+
+	for _, absence := range absences {
+		if !absence.Covers(e.StartTime, e.EndTime) {
+			continue
+		}
+		user := User{ID: absence.UserID}
+		if err := user.createOrUpdateRSVP(tx, e.ID, "no"); err != nil {
+			return fmt.Errorf("failed to decline event for absent member: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetUpcomingAbsences returns the current and future absences of the team's members that the user may see
+func (t *Team) GetUpcomingAbsences(viewerID string) ([]MemberAbsence, error) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	var absences []MemberAbsence
+	err := database.Db.Preload("User").
+		Where("club_id = ? AND end_date >= ?", t.ClubID, today).
+		Where("user_id IN (SELECT user_id FROM team_members WHERE team_id = ?)", t.ID).
+		Where(absenceVisibleScope, viewerID, viewerID, viewerID, viewerID).
+		Order("start_date ASC").
+		Find(&absences).Error
+	return absences, err
+}
+
+// canManage checks whether the user may manage the absence: the member themselves or club admins
+func (ma *MemberAbsence) canManage(userID string) bool {
+	return ma.UserID == userID || HasPermission(ma.ClubID, userID, PermissionMembersManage)
+}
+
+// ODataBeforeReadCollection filters absences by their visibility
+func (ma MemberAbsence) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(absenceVisibleScope, userID, userID, userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific absence
+func (ma MemberAbsence) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return ma.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate allows members to record their own absences and admins to record any member's
+func (ma *MemberAbsence) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if ma.UserID == "" {
+		ma.UserID = userID
+	}
+	if !ma.canManage(userID) {
+		return fmt.Errorf("unauthorized: only the member or club admins can record absences")
+	}
+
+	if err := ma.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ma.CreatedAt = now
+	ma.CreatedBy = userID
+	ma.UpdatedAt = now
+	ma.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate declines the events during the absence
+func (ma *MemberAbsence) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	_, err := ma.DeclineEvents(tx)
+	return err
+}
+
+// ODataBeforeUpdate validates absence update permissions
+func (ma *MemberAbsence) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !ma.canManage(userID) {
+		return fmt.Errorf("unauthorized: only the member or club admins can update absences")
+	}
+
+	updated, err := updatedEntity(ctx, ma)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving absences to another club or member
+	if updated.ClubID != ma.ClubID || updated.UserID != ma.UserID {
+		return fmt.Errorf("forbidden: club and member cannot be changed for an existing absence")
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	ma.UpdatedAt = time.Now()
+	ma.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate declines the events of an extended absence. RSVPs are not reset when it is shortened.
+func (ma *MemberAbsence) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+
+	var updated MemberAbsence
+	if err := tx.Where("id = ?", ma.ID).First(&updated).Error; err != nil {
+		return err
+	}
+	_, err := updated.DeclineEvents(tx)
+	return err
+}
+
+// ODataBeforeDelete validates absence deletion permissions
+func (ma *MemberAbsence) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !ma.canManage(userID) {
+		return fmt.Errorf("unauthorized: only the member or club admins can delete absences")
+	}
+
+	return nil
+}
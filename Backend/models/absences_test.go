@@ -0,0 +1,205 @@
+package models_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberAbsences(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "absence-owner@example.com")
+	coach, _ := handlers.CreateTestUser(t, "absence-coach@example.com")
+	anna, _ := handlers.CreateTestUser(t, "absence-anna@example.com")
+	ben, benToken := handlers.CreateTestUser(t, "absence-ben@example.com")
+	club := handlers.CreateTestClub(t, owner, "Absence Club")
+	handlers.CreateTestMember(t, coach, club, "member")
+	handlers.CreateTestMember(t, anna, club, "member")
+	handlers.CreateTestMember(t, ben, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET teams_enabled = true, shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	team, err := club.CreateTeam("First Team", "", owner.ID)
+	require.NoError(t, err)
+	otherTeam, err := club.CreateTeam("Second Team", "", owner.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.TeamMember{ID: uuid.New().String(), TeamID: team.ID, UserID: coach.ID, Role: "admin"}).Error)
+	require.NoError(t, db.Create(&models.TeamMember{ID: uuid.New().String(), TeamID: team.ID, UserID: anna.ID, Role: "member"}).Error)
+
+	now := time.Now()
+	day := func(offset int) time.Time {
+		d := now.AddDate(0, 0, offset)
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	newEvent := func(name string, teamID *string, offset int) models.Event {
+		start := day(offset).Add(18 * time.Hour)
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, TeamID: teamID, Name: name, StartTime: start, EndTime: start.Add(2 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&event).Error)
+		return event
+	}
+	clubEvent := newEvent("General assembly", nil, 3)
+	teamEvent := newEvent("Training", &team.ID, 4)
+	otherTeamEvent := newEvent("Other training", &otherTeam.ID, 3)
+	laterEvent := newEvent("Match", &team.ID, 10)
+
+	answer := func(user models.User, event models.Event, response string) {
+		rsvp := models.EventRSVP{ID: uuid.New().String(), EventID: event.ID, UserID: user.ID, Response: response}
+		require.NoError(t, db.Create(&rsvp).Error)
+	}
+
+	absence := models.MemberAbsence{ClubID: club.ID, StartDate: day(2), EndDate: day(4), Reason: models.AbsenceReasonHoliday}
+
+	t.Run("absences are validated and limited to the member and admins", func(t *testing.T) {
+		ctx, req := authedRequest(ben.ID)
+		forAnna := models.MemberAbsence{ClubID: club.ID, UserID: anna.ID, StartDate: day(2), EndDate: day(4)}
+		assert.Error(t, forAnna.ODataBeforeCreate(ctx, req))
+
+		ctx, req = authedRequest(anna.ID)
+		invalid := models.MemberAbsence{ClubID: club.ID, StartDate: day(4), EndDate: day(2)}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+		invalid = models.MemberAbsence{ClubID: club.ID, StartDate: day(2), EndDate: day(4), Reason: "bored"}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+
+		require.NoError(t, absence.ODataBeforeCreate(ctx, req))
+		assert.Equal(t, anna.ID, absence.UserID)
+		assert.Equal(t, models.AbsenceVisibilityTeam, absence.Visibility)
+		require.NoError(t, db.Create(&absence).Error)
+		require.NoError(t, absence.ODataAfterCreate(ctx, req))
+	})
+
+	t.Run("events during the absence are declined", func(t *testing.T) {
+		var rsvps []models.EventRSVP
+		require.NoError(t, db.Where("user_id = ?", anna.ID).Find(&rsvps).Error)
+		declined := map[string]string{}
+		for _, rsvp := range rsvps {
+			declined[rsvp.EventID] = rsvp.Response
+		}
+		assert.Equal(t, "no", declined[clubEvent.ID])
+		assert.Equal(t, "no", declined[teamEvent.ID])
+		assert.NotContains(t, declined, otherTeamEvent.ID, "not in that team")
+		assert.NotContains(t, declined, laterEvent.ID, "after the absence")
+	})
+
+	t.Run("events created during the absence are declined", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		assemblyFollowUp := newEvent("Assembly follow-up", nil, 2)
+		require.NoError(t, assemblyFollowUp.ODataAfterCreate(ctx, req))
+		otherTraining := newEvent("Extra training", &otherTeam.ID, 2)
+		require.NoError(t, otherTraining.ODataAfterCreate(ctx, req))
+
+		rsvp, err := anna.GetUserRSVP(assemblyFollowUp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "no", rsvp.Response)
+		_, err = anna.GetUserRSVP(otherTraining.ID)
+		assert.Error(t, err, "not in that team")
+		_, err = ben.GetUserRSVP(assemblyFollowUp.ID)
+		assert.Error(t, err, "not absent")
+	})
+
+	t.Run("absences recorded through the API replace earlier answers", func(t *testing.T) {
+		answer(ben, clubEvent, "yes")
+
+		w := odataRequest(t, benToken, http.MethodPost, "/MemberAbsences", map[string]interface{}{
+			"ClubID": club.ID, "UserID": ben.ID, "StartDate": day(3), "EndDate": day(3), "Reason": models.AbsenceReasonWork,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var created models.MemberAbsence
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+		rsvp, err := ben.GetUserRSVP(clubEvent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "no", rsvp.Response)
+		require.NotNil(t, rsvp.PreviousResponse)
+		assert.Equal(t, "yes", *rsvp.PreviousResponse)
+
+		t.Run("patched values are validated", func(t *testing.T) {
+			path := "/MemberAbsences(" + created.ID + ")"
+			for _, patch := range []map[string]interface{}{
+				{"UserID": anna.ID},
+				{"ClubID": uuid.New().String()},
+				{"Reason": "bored"},
+				{"Visibility": "everyone"},
+			} {
+				w := odataRequest(t, benToken, http.MethodPatch, path, patch)
+				assert.Equal(t, http.StatusForbidden, w.Code, patch)
+			}
+
+			replacement := created
+			replacement.StartDate, replacement.EndDate = day(5), day(3)
+			w := odataRequest(t, benToken, http.MethodPut, path, replacement)
+			assert.Equal(t, http.StatusForbidden, w.Code, "end date before start date")
+
+			var stored models.MemberAbsence
+			require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+			assert.Equal(t, ben.ID, stored.UserID)
+			assert.Equal(t, models.AbsenceReasonWork, stored.Reason)
+			assert.Equal(t, day(3).Format("2006-01-02"), stored.EndDate.Format("2006-01-02"))
+		})
+
+		t.Run("extending the absence declines the added events", func(t *testing.T) {
+			answer(ben, laterEvent, "yes")
+			generalEvent := newEvent("Board meeting", nil, 10)
+			answer(ben, generalEvent, "yes")
+
+			replacement := created
+			replacement.EndDate = day(10)
+			w := odataRequest(t, benToken, http.MethodPut, "/MemberAbsences("+created.ID+")", replacement)
+			require.Less(t, w.Code, 300, w.Body.String())
+
+			rsvp, err := ben.GetUserRSVP(generalEvent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "no", rsvp.Response)
+			rsvp, err = ben.GetUserRSVP(laterEvent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "yes", rsvp.Response, "not in that team")
+		})
+
+		require.NoError(t, db.Delete(&models.MemberAbsence{}, "id = ?", created.ID).Error)
+	})
+
+	t.Run("absent members cannot take shifts", func(t *testing.T) {
+		shift := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: clubEvent.ID, StartTime: clubEvent.StartTime, EndTime: clubEvent.EndTime, MinHeadcount: 1, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shift).Error)
+
+		_, err := shift.SignUp(anna.ID)
+		assert.ErrorIs(t, err, models.ErrMemberAbsent)
+
+		assigned, err := club.AutoAssignShifts(7, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, assigned, 1)
+		assert.NotEqual(t, anna.ID, assigned[0].UserID)
+	})
+
+	t.Run("team overview shows absences by visibility", func(t *testing.T) {
+		absences, err := team.GetUpcomingAbsences(coach.ID)
+		require.NoError(t, err)
+		require.Len(t, absences, 1)
+		assert.Equal(t, anna.ID, absences[0].UserID)
+
+		absences, err = team.GetUpcomingAbsences(ben.ID)
+		require.NoError(t, err)
+		assert.Empty(t, absences, "team visibility hides it from other members")
+
+		require.NoError(t, db.Model(&absence).Update("visibility", models.AbsenceVisibilityClub).Error)
+		absences, err = team.GetUpcomingAbsences(ben.ID)
+		require.NoError(t, err)
+		assert.Len(t, absences, 1)
+
+		require.NoError(t, db.Model(&absence).Update("visibility", models.AbsenceVisibilityPrivate).Error)
+		absences, err = team.GetUpcomingAbsences(coach.ID)
+		require.NoError(t, err)
+		assert.Empty(t, absences)
+		absences, err = team.GetUpcomingAbsences(owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, absences, 1, "club admins see private absences")
+	})
+}
@@ -0,0 +1,352 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Account deletion states
+const (
+	AccountDeletionStatusPending   = "pending"
+	AccountDeletionStatusCancelled = "cancelled"
+	AccountDeletionStatusCompleted = "completed"
+)
+
+// AccountDeletionGracePeriod is how long a deletion can be cancelled before the account is purged
+const AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+var (
+	ErrAccountDeletionPending   = errors.New("account deletion is already scheduled")
+	ErrNoAccountDeletion        = errors.New("no account deletion is scheduled")
+	ErrAccountDeletionSoleOwner = errors.New("you are the last owner of a club. Transfer ownership or delete the club first")
+)
+
+// AccountDeletion is a user's request to delete their account. The account stays usable until
+// ScheduledFor, so the request can be cancelled; then the purge_deleted_accounts job anonymizes it.
+type AccountDeletion struct {
+	ID           string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	UserID       string     `json:"UserID" gorm:"type:uuid;not null;index" odata:"auto"`
+	Status       string     `json:"Status" gorm:"not null;default:pending" odata:"auto"` // pending, cancelled, completed
+	Reason       *string    `json:"Reason,omitempty" gorm:"type:text" odata:"nullable"`
+	ScheduledFor time.Time  `json:"ScheduledFor" gorm:"not null;index" odata:"auto"`
+	CancelledAt  *time.Time `json:"CancelledAt,omitempty" odata:"auto,nullable"`
+	CompletedAt  *time.Time `json:"CompletedAt,omitempty" odata:"auto,nullable"`
+	CreatedAt    time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+}
+
+// BeforeCreate generates UUID for new account deletions
+func (ad *AccountDeletion) BeforeCreate(tx *gorm.DB) error {
+	if ad.ID == "" {
+		ad.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SoleOwnedClubs returns the active clubs in which the user is the only owner. Their account
+// cannot be deleted while there are any, as the clubs would be left without an owner.
+func SoleOwnedClubs(userID string) ([]Club, error) {
+	var clubs []Club
+	err := database.Db.Where("deleted = ? AND id IN (SELECT club_id FROM members WHERE user_id = ? AND role = 'owner' AND status <> 'former')", false, userID).
+		Find(&clubs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var soleOwned []Club
+	for i := range clubs {
+		count, err := clubs[i].CountOwners()
+		if err != nil {
+			return nil, err
+		}
+		if count <= 1 {
+			soleOwned = append(soleOwned, clubs[i])
+		}
+	}
+	return soleOwned, nil
+}
+
+// soleOwnerError names the clubs that block the deletion
+func soleOwnerError(clubs []Club) error {
+	names := make([]string, len(clubs))
+	for i, club := range clubs {
+		names[i] = club.Name
+	}
+	return fmt.Errorf("%w (%s)", ErrAccountDeletionSoleOwner, strings.Join(names, ", "))
+}
+
+// GetPendingAccountDeletion returns the user's scheduled deletion, if any
+func GetPendingAccountDeletion(userID string) (*AccountDeletion, error) {
+	var deletion AccountDeletion
+	err := database.Db.Where("user_id = ? AND status = ?", userID, AccountDeletionStatusPending).First(&deletion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoAccountDeletion
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// RequestAccountDeletion schedules the user's account for deletion after the grace period.
+// Users who are the last owner of a club must transfer ownership first.
+func RequestAccountDeletion(userID string, reason *string) (*AccountDeletion, error) {
+	if _, err := GetPendingAccountDeletion(userID); err == nil {
+		return nil, ErrAccountDeletionPending
+	} else if !errors.Is(err, ErrNoAccountDeletion) {
+		return nil, err
+	}
+
+	clubs, err := SoleOwnedClubs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check club ownership: %w", err)
+	}
+	// Clubs with a designated successor are handed over when the account is purged
+	if clubs, err = clubsWithoutSuccessor(clubs); err != nil {
+		return nil, fmt.Errorf("failed to check club successors: %w", err)
+	}
+	if len(clubs) > 0 {
+		return nil, soleOwnerError(clubs)
+	}
+
+	now := time.Now()
+	deletion := AccountDeletion{
+		UserID:       userID,
+		Status:       AccountDeletionStatusPending,
+		Reason:       reason,
+		ScheduledFor: now.Add(AccountDeletionGracePeriod),
+		CreatedAt:    now,
+	}
+	if err := database.Db.Create(&deletion).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	_ = CreateNotification(userID, "account_deletion_scheduled", "Account deletion scheduled",
+		fmt.Sprintf("Your account will be deleted on %s. You can cancel the deletion until then.", deletion.ScheduledFor.Format("02.01.2006")), nil, nil, nil)
+
+	return &deletion, nil
+}
+
+// CancelAccountDeletion cancels the user's scheduled deletion
+func CancelAccountDeletion(userID string) (*AccountDeletion, error) {
+	deletion, err := GetPendingAccountDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deletion.Status = AccountDeletionStatusCancelled
+	deletion.CancelledAt = &now
+	if err := database.Db.Save(deletion).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	return deletion, nil
+}
+
+// anonymizeUser removes the user's personal data. The user row itself is kept with placeholder
+// values, so fines, payments, activities and other club history keep a valid reference.
+func anonymizeUser(tx *gorm.DB, userID string) error {
+	now := time.Now()
+
+	// Memberships end: the members become former and stay in the clubs' membership history
+	var memberships []Member
+	if err := tx.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return fmt.Errorf("failed to load memberships: %w", err)
+	}
+	for i := range memberships {
+		if err := archiveMembership(tx, &memberships[i], "account deleted", nil); err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&Member{}).Error; err != nil {
+		return fmt.Errorf("failed to archive memberships: %w", err)
+	}
+
+	// Personal records without value for the clubs are removed
+	personal := []struct {
+		model interface{}
+		query string
+	}{
+		{&RefreshToken{}, "user_id = ?"},
+		{&APIKey{}, "user_id = ?"},
+		{&Notification{}, "user_id = ?"},
+		{&UserNotificationPreferences{}, "user_id = ?"},
+		{&UserPrivacySettings{}, "user_id = ?"},
+		{&MemberPrivacySettings{}, "member_id IN (SELECT id FROM members WHERE user_id = ?)"},
+		{&MemberFieldValue{}, "member_id IN (SELECT id FROM members WHERE user_id = ?)"},
+		{&DataExportArchive{}, "export_id IN (SELECT id FROM data_exports WHERE user_id = ?)"},
+		{&DataExport{}, "user_id = ?"},
+		{&MemberAbsence{}, "user_id = ?"},
+		{&ClubRoleAssignment{}, "user_id = ?"},
+		{&ClubSuccession{}, "successor_id = ?"},
+		{&UserPresence{}, "user_id = ?"},
+		{&Guardianship{}, "guardian_id = ?"},
+		{&Guardianship{}, "dependent_id = ?"},
+		{&TeamMember{}, "user_id = ?"},
+	}
+	for _, p := range personal {
+		if err := tx.Where(p.query, userID).Delete(p.model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T: %w", p.model, err)
+		}
+	}
+
+	// Commitments to upcoming events and shifts are dropped; past ones stay for attendance history
+	if err := tx.Model(&ShiftSwapRequest{}).
+		Where("(requested_by = ? OR claimed_by = ?) AND status IN ?", userID, userID,
+			[]string{ShiftSwapStatusOpen, ShiftSwapStatusTradeProposed, ShiftSwapStatusPendingApproval}).
+		Updates(map[string]interface{}{"status": ShiftSwapStatusCancelled, "resolved_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to cancel shift swaps: %w", err)
+	}
+	if err := tx.Model(&OwnershipTransfer{}).
+		Where("(from_user_id = ? OR to_user_id = ?) AND status = ?", userID, userID, OwnershipTransferStatusPending).
+		Updates(map[string]interface{}{"status": OwnershipTransferStatusCancelled, "responded_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to cancel ownership transfers: %w", err)
+	}
+	if err := tx.Where("user_id = ? AND event_id IN (SELECT id FROM events WHERE start_time > ?)", userID, now).
+		Delete(&EventRSVP{}).Error; err != nil {
+		return fmt.Errorf("failed to delete upcoming RSVPs: %w", err)
+	}
+	if err := tx.Where("user_id = ? AND shift_id IN (SELECT id FROM shifts WHERE start_time > ?)", userID, now).
+		Delete(&ShiftMember{}).Error; err != nil {
+		return fmt.Errorf("failed to delete upcoming shift assignments: %w", err)
+	}
+
+	if err := tx.Model(&SepaMandate{}).Where("user_id = ? AND active = ?", userID, true).
+		Updates(map[string]interface{}{"active": false, "revoked_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to revoke SEPA mandates: %w", err)
+	}
+
+	// Audit log entries stay as the clubs' record of who changed what. Their diffs refer to the user
+	// only by ID, which now points to the anonymized row, but the IP addresses are personal data.
+	// UpdateColumn skips the hooks that otherwise keep the audit log append-only.
+	if err := tx.Model(&AuditLog{}).Where("actor_id = ?", userID).UpdateColumn("ip_address", "").Error; err != nil {
+		return fmt.Errorf("failed to anonymize audit log: %w", err)
+	}
+
+	return tx.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"first_name":      "Deleted",
+		"last_name":       "User",
+		"email":           fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+		"keycloak_id":     nil,
+		"birth_date":      nil,
+		"setup_completed": false,
+		"updated_at":      now,
+	}).Error
+}
+
+// handOverToSuccessors promotes the designated successors of clubs the deleted user is the last
+// owner of. It returns the clubs that are still left without another owner.
+func handOverToSuccessors(clubs []Club) []Club {
+	var remaining []Club
+	for _, club := range clubs {
+		succession, err := GetClubSuccession(club.ID)
+		if err == nil && succession != nil {
+			err = promoteSuccessor(succession, SuccessionReasonAccountDeleted)
+			if err == nil {
+				continue
+			}
+		}
+		if err != nil {
+			log.Printf("failed to promote successor of club %s: %v", club.ID, err)
+		}
+		remaining = append(remaining, club)
+	}
+	return remaining
+}
+
+// PurgeDeletedAccounts anonymizes the accounts whose deletion grace period has passed. Clubs the
+// user is the last owner of go to their designated successor; without one the account is kept
+// until ownership is transferred. Dependents without own account that have no other guardian
+// are anonymized along with the user.
+// This should be called periodically by the scheduler.
+func PurgeDeletedAccounts() error {
+	var due []AccountDeletion
+	if err := database.Db.Where("status = ? AND scheduled_for <= ?", AccountDeletionStatusPending, time.Now()).
+		Find(&due).Error; err != nil {
+		return err
+	}
+
+	for i := range due {
+		deletion := &due[i]
+
+		clubs, err := SoleOwnedClubs(deletion.UserID)
+		if err != nil {
+			log.Printf("failed to check club ownership of user %s: %v", deletion.UserID, err)
+			continue
+		}
+		clubs = handOverToSuccessors(clubs)
+		if len(clubs) > 0 {
+			log.Printf("account deletion of user %s postponed: %v", deletion.UserID, soleOwnerError(clubs))
+			continue
+		}
+
+		err = database.Db.Transaction(func(tx *gorm.DB) error {
+			// Dependents without own account have nobody left to manage them
+			dependents, err := soleGuardianDependents(tx, deletion.UserID)
+			if err != nil {
+				return err
+			}
+			for _, dependentID := range dependents {
+				if err := anonymizeUser(tx, dependentID); err != nil {
+					return err
+				}
+			}
+			if err := anonymizeUser(tx, deletion.UserID); err != nil {
+				return err
+			}
+			now := time.Now()
+			return tx.Model(deletion).Updates(map[string]interface{}{
+				"status":       AccountDeletionStatusCompleted,
+				"completed_at": now,
+				"reason":       nil,
+			}).Error
+		})
+		if err != nil {
+			log.Printf("failed to purge account of user %s: %v", deletion.UserID, err)
+		}
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection filters account deletions to the user's own
+func (ad AccountDeletion) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific account deletion
+func (ad AccountDeletion) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return ad.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating deletions directly; use the RequestAccountDeletion action
+func (ad *AccountDeletion) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the RequestAccountDeletion action to delete your account")
+}
+
+// ODataBeforeUpdate prevents changing deletions; use the CancelAccountDeletion action
+func (ad *AccountDeletion) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the CancelAccountDeletion action to cancel the deletion")
+}
+
+// ODataBeforeDelete keeps the record of account deletions
+func (ad *AccountDeletion) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: account deletions cannot be deleted")
+}
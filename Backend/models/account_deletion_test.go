@@ -0,0 +1,119 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountDeletion(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "deletion-owner@example.com")
+	user, _ := handlers.CreateTestUser(t, "deletion-user@example.com")
+	club := handlers.CreateTestClub(t, owner, "Deletion Club")
+	handlers.CreateTestMember(t, user, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	fine := models.Fine{ClubID: club.ID, UserID: user.ID, Reason: "Late", Amount: 5}
+	require.NoError(t, db.Create(&fine).Error)
+	require.NoError(t, user.StoreRefreshToken("deletion-refresh-token", "test", "127.0.0.1"))
+	userEntry := models.AuditLog{ClubID: club.ID, ActorID: user.ID, IPAddress: "203.0.113.7", EntityType: "Fine", EntityID: fine.ID, Operation: models.AuditOperationUpdate, Changes: "{}"}
+	ownerEntry := models.AuditLog{ClubID: club.ID, ActorID: owner.ID, IPAddress: "203.0.113.8", EntityType: "Fine", EntityID: fine.ID, Operation: models.AuditOperationCreate, Changes: "{}"}
+	require.NoError(t, db.Create(&userEntry).Error)
+	require.NoError(t, db.Create(&ownerEntry).Error)
+
+	t.Run("last owner cannot request deletion", func(t *testing.T) {
+		_, err := models.RequestAccountDeletion(owner.ID, nil)
+		assert.ErrorIs(t, err, models.ErrAccountDeletionSoleOwner)
+	})
+
+	t.Run("direct delete of user is rejected", func(t *testing.T) {
+		ctx, req := authedRequest(user.ID)
+		assert.Error(t, user.ODataBeforeDelete(ctx, req))
+	})
+
+	t.Run("deletion can be cancelled during grace period", func(t *testing.T) {
+		deletion, err := models.RequestAccountDeletion(user.ID, nil)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(models.AccountDeletionGracePeriod), deletion.ScheduledFor, time.Minute)
+
+		_, err = models.RequestAccountDeletion(user.ID, nil)
+		assert.ErrorIs(t, err, models.ErrAccountDeletionPending)
+
+		cancelled, err := models.CancelAccountDeletion(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountDeletionStatusCancelled, cancelled.Status)
+
+		_, err = models.CancelAccountDeletion(user.ID)
+		assert.ErrorIs(t, err, models.ErrNoAccountDeletion)
+	})
+
+	t.Run("accounts are not purged before grace period ends", func(t *testing.T) {
+		_, err := models.RequestAccountDeletion(user.ID, nil)
+		require.NoError(t, err)
+		require.NoError(t, models.PurgeDeletedAccounts())
+
+		var reloaded models.User
+		require.NoError(t, db.Where("id = ?", user.ID).First(&reloaded).Error)
+		assert.Equal(t, user.Email, reloaded.Email)
+	})
+
+	t.Run("purge anonymizes account and keeps club finances", func(t *testing.T) {
+		require.NoError(t, db.Model(&models.AccountDeletion{}).Where("user_id = ? AND status = ?", user.ID, models.AccountDeletionStatusPending).
+			Update("scheduled_for", time.Now().Add(-time.Minute)).Error)
+		require.NoError(t, models.PurgeDeletedAccounts())
+
+		var reloaded models.User
+		require.NoError(t, db.Where("id = ?", user.ID).First(&reloaded).Error)
+		assert.NotEqual(t, user.Email, reloaded.Email)
+		assert.Equal(t, "Deleted", reloaded.FirstName)
+		assert.Nil(t, reloaded.KeycloakID)
+
+		var tokens, members, fines int64
+		db.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Count(&tokens)
+		db.Model(&models.Member{}).Where("user_id = ?", user.ID).Count(&members)
+		db.Model(&models.Fine{}).Where("id = ? AND user_id = ?", fine.ID, user.ID).Count(&fines)
+		assert.Zero(t, tokens)
+		assert.Zero(t, members)
+		assert.Equal(t, int64(1), fines)
+
+		var entries []models.AuditLog
+		require.NoError(t, db.Where("id IN ?", []string{userEntry.ID, ownerEntry.ID}).Find(&entries).Error)
+		require.Len(t, entries, 2, "audit entries are kept")
+		for _, entry := range entries {
+			if entry.ActorID == user.ID {
+				assert.Empty(t, entry.IPAddress)
+			} else {
+				assert.Equal(t, "203.0.113.8", entry.IPAddress)
+			}
+		}
+
+		_, err := models.GetPendingAccountDeletion(user.ID)
+		assert.ErrorIs(t, err, models.ErrNoAccountDeletion)
+	})
+
+	t.Run("purge is postponed while user is last owner", func(t *testing.T) {
+		coOwner, _ := handlers.CreateTestUser(t, "deletion-coowner@example.com")
+		handlers.CreateTestMember(t, coOwner, club, "owner")
+
+		_, err := models.RequestAccountDeletion(coOwner.ID, nil)
+		require.NoError(t, err)
+		// The other owner leaves in the meantime
+		require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, owner.ID).Delete(&models.Member{}).Error)
+		require.NoError(t, db.Model(&models.AccountDeletion{}).Where("user_id = ?", coOwner.ID).
+			Update("scheduled_for", time.Now().Add(-time.Minute)).Error)
+
+		require.NoError(t, models.PurgeDeletedAccounts())
+
+		deletion, err := models.GetPendingAccountDeletion(coOwner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.AccountDeletionStatusPending, deletion.Status)
+	})
+}
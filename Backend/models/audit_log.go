@@ -0,0 +1,277 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Audit log operations
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+	AuditOperationAction = "action" // Custom OData action, named in AuditLog.Action
+)
+
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be changed or deleted")
+
+// auditIgnoredFields are bookkeeping fields left out of update diffs
+var auditIgnoredFields = map[string]bool{"UpdatedAt": true, "UpdatedBy": true}
+
+// AuditLog is an append-only record of a change to club data. Unlike Activity, which feeds the
+// timeline, it captures every write with its actor, origin and a before/after diff.
+type AuditLog struct {
+	ID         string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID     string    `json:"ClubID" gorm:"type:uuid;not null;index"`
+	ActorID    string    `json:"ActorID" gorm:"type:uuid;index;default:null"`          // Empty for changes made by scheduled jobs
+	APIKeyID   *string   `json:"APIKeyID,omitempty" gorm:"type:uuid" odata:"nullable"` // Set if the request was authenticated with an API key
+	IPAddress  string    `json:"IPAddress"`
+	EntityType string    `json:"EntityType" gorm:"not null;index"`
+	EntityID   string    `json:"EntityID" gorm:"not null;index"`
+	Operation  string    `json:"Operation" gorm:"not null"` // create, update, delete, action
+	Action     *string   `json:"Action,omitempty" odata:"nullable"`
+	Changes    string    `json:"Changes" gorm:"type:text"` // JSON object of changed fields with their old and new values
+	CreatedAt  time.Time `json:"CreatedAt" gorm:"index"`
+
+	// Navigation properties for OData expansions
+	Actor *User `gorm:"foreignKey:ActorID" json:"Actor,omitempty" odata:"nav"`
+}
+
+// AuditChange is the old and new value of a changed field
+type AuditChange struct {
+	Old interface{} `json:"Old,omitempty"`
+	New interface{} `json:"New,omitempty"`
+}
+
+// BeforeCreate generates UUID for new audit log entries
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeUpdate keeps the audit log append-only
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete keeps the audit log append-only
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+type auditStateKey struct{}
+
+// WithAuditState prepares a request context to carry entity states from ODataBeforeUpdate,
+// where the entity is still unchanged, to ODataAfterUpdate, where the diff is written.
+func WithAuditState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditStateKey{}, &sync.Map{})
+}
+
+// captureAuditState remembers the state of an entity before it is updated
+func captureAuditState(ctx context.Context, entityType, entityID string, entity interface{}) {
+	if states, ok := ctx.Value(auditStateKey{}).(*sync.Map); ok {
+		states.Store(entityType+"/"+entityID, auditFields(entity))
+	}
+}
+
+// auditFields flattens an entity to its scalar JSON fields, leaving out navigation properties
+func auditFields(entity interface{}) map[string]interface{} {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	for name, value := range fields {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+// auditDiff returns the fields that differ between two entity states. A nil state stands for
+// an entity that did not exist before or does not exist anymore.
+func auditDiff(before, after map[string]interface{}) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	for name, value := range after {
+		old, existed := before[name]
+		if before != nil && (auditIgnoredFields[name] || (existed && reflect.DeepEqual(old, value))) {
+			continue
+		}
+		changes[name] = AuditChange{Old: old, New: value}
+	}
+	for name, old := range before {
+		if _, ok := after[name]; !ok {
+			changes[name] = AuditChange{Old: old}
+		}
+	}
+	return changes
+}
+
+// writeAuditLog stores an entry with the actor, API key and IP of the request. It joins the
+// OData transaction if there is one, so the entry is rolled back together with the change.
+func writeAuditLog(ctx context.Context, r *http.Request, entry *AuditLog) error {
+	entry.ActorID, _ = ctx.Value(auth.UserIDKey).(string)
+	if apiKeyID, ok := ctx.Value(auth.APIKeyIDKey).(string); ok && apiKeyID != "" {
+		entry.APIKeyID = &apiKeyID
+	}
+	if r != nil {
+		entry.IPAddress = auth.ClientIP(r)
+	}
+	entry.CreatedAt = time.Now()
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditEntityChange records the creation, update or deletion of an entity. Updates are diffed
+// against the state captured in ODataBeforeUpdate; without it all current values are logged.
+func auditEntityChange(ctx context.Context, r *http.Request, clubID, entityType, entityID, operation string, entity interface{}) error {
+	var before, after map[string]interface{}
+	switch operation {
+	case AuditOperationCreate:
+		after = auditFields(entity)
+	case AuditOperationUpdate:
+		after = auditFields(entity)
+		if states, ok := ctx.Value(auditStateKey{}).(*sync.Map); ok {
+			if state, ok := states.LoadAndDelete(entityType + "/" + entityID); ok {
+				before = state.(map[string]interface{})
+			}
+		}
+	case AuditOperationDelete:
+		before = auditFields(entity)
+	}
+
+	changes := auditDiff(before, after)
+	if operation == AuditOperationUpdate && len(changes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	return writeAuditLog(ctx, r, &AuditLog{
+		ClubID:     clubID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  operation,
+		Changes:    string(data),
+	})
+}
+
+// RecordAuditAction records a custom action on an entity, such as approving a fine or changing a
+// member's role. Details are stored as the entry's changes.
+func RecordAuditAction(ctx context.Context, r *http.Request, clubID, entityType, entityID, action string, details map[string]AuditChange) error {
+	if details == nil {
+		details = map[string]AuditChange{}
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	return writeAuditLog(ctx, r, &AuditLog{
+		ClubID:     clubID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  AuditOperationAction,
+		Action:     &action,
+		Changes:    string(data),
+	})
+}
+
+// ExportAuditLog returns the club's audit log in the time range as CSV, oldest entries first.
+// An empty entity type exports entries of all entities.
+func ExportAuditLog(clubID string, from, to time.Time, entityType string) ([]byte, error) {
+	query := database.Db.Where("club_id = ? AND created_at >= ? AND created_at < ?", clubID, from, to)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	var entries []AuditLog
+	if err := query.Order("created_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"Time", "ActorID", "APIKeyID", "IPAddress", "EntityType", "EntityID", "Operation", "Action", "Changes"}); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		apiKeyID, action := "", ""
+		if entry.APIKeyID != nil {
+			apiKeyID = *entry.APIKeyID
+		}
+		if entry.Action != nil {
+			action = *entry.Action
+		}
+		record := []string{entry.CreatedAt.UTC().Format(time.RFC3339), entry.ActorID, apiKeyID, entry.IPAddress,
+			entry.EntityType, entry.EntityID, entry.Operation, action, entry.Changes}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// ODataBeforeReadCollection limits the audit log to owners of the club
+func (a AuditLog) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role = 'owner')", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific audit log entry
+func (a AuditLog) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return a.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents writing audit log entries through the API
+func (a *AuditLog) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: audit log entries are written by the system")
+}
+
+// ODataBeforeUpdate keeps the audit log read-only
+func (a *AuditLog) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: %w", ErrAuditLogImmutable)
+}
+
+// ODataBeforeDelete keeps the audit log read-only
+func (a *AuditLog) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: %w", ErrAuditLogImmutable)
+}
@@ -0,0 +1,125 @@
+package models_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func auditRequest(userID, apiKeyID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v2/Fines", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	if apiKeyID != "" {
+		ctx = context.WithValue(ctx, auth.APIKeyIDKey, apiKeyID)
+	}
+	ctx = models.WithAuditState(ctx)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestAuditLog(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "audit-owner@example.com")
+	admin, _ := handlers.CreateTestUser(t, "audit-admin@example.com")
+	member, _ := handlers.CreateTestUser(t, "audit-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Audit Club")
+	handlers.CreateTestMember(t, admin, club, "admin")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	apiKeyID := uuid.New().String()
+	ctx, req := auditRequest(admin.ID, apiKeyID)
+	fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: member.ID, Reason: "Late", Amount: 5}
+
+	entries := func(operation string) []models.AuditLog {
+		var logs []models.AuditLog
+		require.NoError(t, db.Where("entity_id = ? AND operation = ?", fine.ID, operation).Find(&logs).Error)
+		return logs
+	}
+
+	t.Run("creates record actor, API key and IP", func(t *testing.T) {
+		require.NoError(t, fine.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&fine).Error)
+		require.NoError(t, fine.ODataAfterCreate(ctx, req))
+
+		logs := entries(models.AuditOperationCreate)
+		require.Len(t, logs, 1)
+		assert.Equal(t, club.ID, logs[0].ClubID)
+		assert.Equal(t, admin.ID, logs[0].ActorID)
+		require.NotNil(t, logs[0].APIKeyID)
+		assert.Equal(t, apiKeyID, *logs[0].APIKeyID)
+		assert.Equal(t, "203.0.113.7", logs[0].IPAddress)
+		assert.Equal(t, "Fine", logs[0].EntityType)
+		assert.Contains(t, logs[0].Changes, `"Reason":{"New":"Late"}`)
+	})
+
+	t.Run("updates store a diff of the changed fields", func(t *testing.T) {
+		// Like the OData handler: the hook sees the stored state, then the patch is applied
+		require.NoError(t, fine.ODataBeforeUpdate(ctx, req))
+		require.NoError(t, db.Model(&fine).Updates(map[string]interface{}{"amount": 7.5, "updated_at": time.Now()}).Error)
+		require.NoError(t, fine.ODataAfterUpdate(ctx, req))
+
+		logs := entries(models.AuditOperationUpdate)
+		require.Len(t, logs, 1)
+		var changes map[string]models.AuditChange
+		require.NoError(t, json.Unmarshal([]byte(logs[0].Changes), &changes))
+		assert.Equal(t, map[string]models.AuditChange{"Amount": {Old: 5.0, New: 7.5}}, changes)
+	})
+
+	t.Run("deletes and actions are recorded", func(t *testing.T) {
+		require.NoError(t, models.RecordAuditAction(ctx, req, club.ID, "Fine", fine.ID, "Approve", nil))
+		require.NoError(t, fine.ODataAfterDelete(ctx, req))
+
+		actions := entries(models.AuditOperationAction)
+		require.Len(t, actions, 1)
+		require.NotNil(t, actions[0].Action)
+		assert.Equal(t, "Approve", *actions[0].Action)
+		deletes := entries(models.AuditOperationDelete)
+		require.Len(t, deletes, 1)
+		assert.Contains(t, deletes[0].Changes, `"Amount":{"Old":7.5}`)
+	})
+
+	t.Run("entries are immutable", func(t *testing.T) {
+		entry := entries(models.AuditOperationCreate)[0]
+		assert.ErrorIs(t, db.Model(&entry).Update("actor_id", owner.ID).Error, models.ErrAuditLogImmutable)
+		assert.ErrorIs(t, db.Delete(&entry).Error, models.ErrAuditLogImmutable)
+		assert.Error(t, entry.ODataBeforeUpdate(ctx, req))
+		assert.Error(t, entry.ODataBeforeDelete(ctx, req))
+		assert.Error(t, (&models.AuditLog{ClubID: club.ID}).ODataBeforeCreate(ctx, req))
+		assert.Len(t, entries(models.AuditOperationCreate), 1)
+	})
+
+	t.Run("only owners can read and export", func(t *testing.T) {
+		count := func(userID string) int64 {
+			readCtx, readReq := authedRequest(userID)
+			scopes, err := models.AuditLog{}.ODataBeforeReadCollection(readCtx, readReq, nil)
+			require.NoError(t, err)
+			var n int64
+			require.NoError(t, db.Model(&models.AuditLog{}).Scopes(scopes...).Count(&n).Error)
+			return n
+		}
+		assert.Equal(t, int64(4), count(owner.ID))
+		assert.Zero(t, count(admin.ID))
+		assert.False(t, models.HasPermission(club.ID, admin.ID, models.PermissionAuditRead))
+
+		document, err := models.ExportAuditLog(club.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "Fine")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(document)), "\n")
+		require.Len(t, lines, 5)
+		assert.True(t, strings.HasPrefix(lines[0], "Time,ActorID,APIKeyID,IPAddress"))
+	})
+}
@@ -0,0 +1,938 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supported bank statement formats
+const (
+	BankStatementFormatCAMT  = "camt053"
+	BankStatementFormatMT940 = "mt940"
+	BankStatementFormatCSV   = "csv"
+)
+
+// Bank transaction statuses
+const (
+	BankTransactionStatusMatched   = "matched"   // Settled automatically
+	BankTransactionStatusSuggested = "suggested" // Uncertain match awaiting confirmation by a treasurer
+	BankTransactionStatusUnmatched = "unmatched" // No open fine or invoice found
+	BankTransactionStatusConfirmed = "confirmed" // Settled after manual confirmation
+	BankTransactionStatusIgnored   = "ignored"   // Not related to fines or dues
+)
+
+// Match confidence levels
+const (
+	matchConfidenceReference     = 100 // Reference code and amount match
+	matchConfidenceReferenceOnly = 70  // Reference code matches, amount differs
+	matchConfidenceNameAmount    = 60  // Payer name and amount match
+	matchConfidenceAmount        = 30  // Only the amount matches a single open item
+)
+
+var ErrBankTransactionSettled = errors.New("bank transaction has already been settled")
+
+// BankStatementImport is an uploaded bank statement
+type BankStatementImport struct {
+	ID               string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID           string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	Format           string    `json:"Format" gorm:"not null" odata:"auto"`
+	FileName         *string   `json:"FileName,omitempty" odata:"auto,nullable"`
+	TransactionCount int       `json:"TransactionCount" odata:"auto"`
+	MatchedCount     int       `json:"MatchedCount" odata:"auto"`
+	SuggestedCount   int       `json:"SuggestedCount" odata:"auto"`
+	DuplicateCount   int       `json:"DuplicateCount" odata:"auto"`
+	CreatedAt        time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy        string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	Transactions []BankTransaction `gorm:"foreignKey:ImportID" json:"Transactions,omitempty" odata:"nav"`
+}
+
+// BankTransaction is an incoming payment read from a bank statement
+type BankTransaction struct {
+	ID          string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	ImportID    string     `json:"ImportID" gorm:"type:uuid;not null;index" odata:"auto"`
+	Fingerprint string     `json:"-" gorm:"not null;index"` // Detects transactions imported twice
+	BookingDate time.Time  `json:"BookingDate" odata:"auto"`
+	Amount      float64    `json:"Amount" odata:"auto"`
+	Currency    string     `json:"Currency" odata:"auto"`
+	PayerName   string     `json:"PayerName" odata:"auto"`
+	PayerIBAN   string     `json:"PayerIBAN" gorm:"column:payer_iban" odata:"auto"`
+	Reference   string     `json:"Reference" odata:"auto"`
+	EndToEndID  string     `json:"EndToEndID" gorm:"column:end_to_end_id" odata:"auto"`
+	Status      string     `json:"Status" gorm:"not null;index" odata:"auto"`
+	Confidence  int        `json:"Confidence" odata:"auto"`
+	MatchReason string     `json:"MatchReason" odata:"auto"`
+	FineID      *string    `json:"FineID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	InvoiceID   *string    `json:"InvoiceID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	SettledAt   *time.Time `json:"SettledAt,omitempty" odata:"auto,nullable"`
+	SettledBy   *string    `json:"SettledBy,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	CreatedAt   time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	Fine    *Fine    `gorm:"foreignKey:FineID" json:"Fine,omitempty" odata:"nav"`
+	Invoice *Invoice `gorm:"foreignKey:InvoiceID" json:"Invoice,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new statement imports
+func (bi *BankStatementImport) BeforeCreate(tx *gorm.DB) error {
+	if bi.ID == "" {
+		bi.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new bank transactions
+func (bt *BankTransaction) BeforeCreate(tx *gorm.DB) error {
+	if bt.ID == "" {
+		bt.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// FineReference returns the payment reference members use when transferring a fine
+func FineReference(fineID string) string {
+	return "FINE-" + strings.ToUpper(strings.ReplaceAll(fineID, "-", ""))[:8]
+}
+
+// statementTransaction is an incoming payment parsed from a statement file
+type statementTransaction struct {
+	bookingDate time.Time
+	amount      float64
+	currency    string
+	payerName   string
+	payerIBAN   string
+	reference   string
+	endToEndID  string
+}
+
+// DetectBankStatementFormat guesses the format of a statement file
+func DetectBankStatementFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return BankStatementFormatCAMT
+	case bytes.Contains(trimmed, []byte(":61:")):
+		return BankStatementFormatMT940
+	default:
+		return BankStatementFormatCSV
+	}
+}
+
+// parseBankStatement reads the incoming payments of a statement file
+func parseBankStatement(format string, data []byte) ([]statementTransaction, error) {
+	switch format {
+	case BankStatementFormatCAMT:
+		return parseCamtStatement(data)
+	case BankStatementFormatMT940:
+		return parseMT940Statement(data)
+	case BankStatementFormatCSV:
+		return parseCSVStatement(data)
+	default:
+		return nil, fmt.Errorf("unsupported statement format: %s", format)
+	}
+}
+
+// parseStatementAmount parses amounts like "1234.56", "1.234,56" or "+12,00 EUR"
+func parseStatementAmount(value string) (float64, error) {
+	value = strings.NewReplacer("EUR", "", "€", "", " ", "", "\u00a0", "", "+", "").Replace(strings.TrimSpace(value))
+	negative := strings.HasSuffix(value, "-")
+	value = strings.TrimSuffix(value, "-")
+
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	switch {
+	case lastComma > lastDot:
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	case lastDot > lastComma && lastComma >= 0:
+		value = strings.ReplaceAll(value, ",", "")
+	case strings.Count(value, ".") > 1:
+		value = strings.ReplaceAll(value, ".", "")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	if negative {
+		amount = -amount
+	}
+	return roundCents(amount), nil
+}
+
+// parseStatementDate parses the date formats commonly found in bank exports
+func parseStatementDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "02.01.06", "01/02/2006", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+// parseCamtStatement reads the booked credits of a camt.053 statement
+func parseCamtStatement(data []byte) ([]statementTransaction, error) {
+	var document camtDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 document: %w", err)
+	}
+
+	var result []statementTransaction
+	for _, statement := range document.Statements {
+		for _, entry := range statement.Entries {
+			status := strings.TrimSpace(entry.Status)
+			if entry.CdtDbtInd != "CRDT" || (status != "" && status != "BOOK") {
+				continue
+			}
+
+			bookingDate, _ := parseStatementDate(entry.BookingDt)
+			if entry.BookingDt == "" && len(entry.BookingDtTm) >= 10 {
+				bookingDate, _ = parseStatementDate(entry.BookingDtTm[:10])
+			}
+
+			var transactions []camtTransaction
+			for _, details := range entry.Details {
+				transactions = append(transactions, details.Transactions...)
+			}
+			if len(transactions) == 0 {
+				transactions = []camtTransaction{{}}
+			}
+
+			for _, transaction := range transactions {
+				amount, ok := parseCamtAmount(transaction.Amount)
+				if !ok {
+					amount, ok = parseCamtAmount(transaction.TxAmount)
+				}
+				if !ok && len(transactions) == 1 {
+					amount, ok = parseCamtAmount(&entry.Amount)
+				}
+				if !ok {
+					continue
+				}
+
+				reference := strings.TrimSpace(strings.Join(append(transaction.Unstructured, transaction.CreditorRef), " "))
+				if reference == "" {
+					reference = strings.TrimSpace(entry.AdditionalInfo)
+				}
+
+				result = append(result, statementTransaction{
+					bookingDate: bookingDate,
+					amount:      amount,
+					currency:    entry.Amount.Currency,
+					payerName:   strings.TrimSpace(transaction.DebtorName),
+					payerIBAN:   NormalizeIBAN(transaction.DebtorIBAN),
+					reference:   reference,
+					endToEndID:  strings.TrimSpace(transaction.EndToEndID),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+var mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+var mt940StatementLinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)[A-Z]?(\d+,\d{0,2})`)
+var mt940EndToEndPattern = regexp.MustCompile(`EREF\+(.+?)(?:[A-Z]{4}\+|$)`)
+
+// parseMT940Statement reads the credits of an MT940 statement. Structured :86: fields
+// as used by German banks (?20-?29 remittance, ?31 IBAN, ?32/?33 name) are supported.
+func parseMT940Statement(data []byte) ([]statementTransaction, error) {
+	type field struct {
+		tag   string
+		lines []string
+	}
+
+	var fields []field
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if match := mt940TagPattern.FindStringSubmatch(line); match != nil {
+			fields = append(fields, field{tag: match[1], lines: []string{match[2]}})
+		} else if len(fields) > 0 && strings.TrimSpace(line) != "-" && strings.TrimSpace(line) != "" {
+			fields[len(fields)-1].lines = append(fields[len(fields)-1].lines, line)
+		}
+	}
+
+	var result []statementTransaction
+	var current *statementTransaction
+	currency := ""
+	flush := func() {
+		if current != nil {
+			result = append(result, *current)
+			current = nil
+		}
+	}
+
+	for _, f := range fields {
+		switch f.tag {
+		case "60F", "60M":
+			if len(f.lines[0]) >= 10 {
+				currency = f.lines[0][7:10]
+			}
+		case "61":
+			flush()
+			match := mt940StatementLinePattern.FindStringSubmatch(f.lines[0])
+			if match == nil {
+				return nil, fmt.Errorf("invalid MT940 statement line: %s", f.lines[0])
+			}
+			// Only credits and reversed debits are incoming payments
+			if match[3] != "C" && match[3] != "RD" {
+				continue
+			}
+			bookingDate, err := time.Parse("060102", match[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid MT940 date: %s", match[1])
+			}
+			amount, err := parseStatementAmount(match[4])
+			if err != nil {
+				return nil, err
+			}
+			current = &statementTransaction{bookingDate: bookingDate, amount: amount, currency: currency}
+		case "86":
+			if current == nil {
+				continue
+			}
+			text := strings.Join(f.lines, "")
+			if !strings.Contains(text, "?") {
+				current.reference = strings.TrimSpace(strings.Join(f.lines, " "))
+				continue
+			}
+
+			var remittance, name []string
+			for _, part := range strings.Split(text, "?")[1:] {
+				if len(part) < 2 {
+					continue
+				}
+				key, value := part[:2], part[2:]
+				switch {
+				case key >= "20" && key <= "29", key >= "60" && key <= "63":
+					remittance = append(remittance, value)
+				case key == "31":
+					current.payerIBAN = NormalizeIBAN(value)
+				case key == "32" || key == "33":
+					name = append(name, value)
+				}
+			}
+			current.reference = strings.TrimSpace(strings.Join(remittance, ""))
+			current.payerName = strings.TrimSpace(strings.Join(name, ""))
+			if match := mt940EndToEndPattern.FindStringSubmatch(current.reference); match != nil {
+				current.endToEndID = strings.TrimSpace(match[1])
+			}
+		}
+	}
+	flush()
+
+	return result, nil
+}
+
+// CSV header names (lowercase) in order of preference
+var csvColumnNames = map[string][]string{
+	"date":      {"buchungstag", "buchungsdatum", "booking date", "date", "datum", "valutadatum", "wertstellung"},
+	"amount":    {"betrag", "amount", "umsatz", "betrag (eur)"},
+	"name":      {"name zahlungsbeteiligter", "beguenstigter/zahlungspflichtiger", "begünstigter/zahlungspflichtiger", "auftraggeber", "zahlungspflichtiger", "payer", "counterparty", "name"},
+	"reference": {"verwendungszweck", "reference", "purpose", "description", "remittance information"},
+	"iban":      {"iban zahlungsbeteiligter", "kontonummer/iban", "iban", "account"},
+	"currency":  {"waehrung", "währung", "currency"},
+}
+
+// csvColumns maps the columns of a header row, or returns nil if it is no header
+func csvColumns(header []string) map[string]int {
+	normalized := make([]string, len(header))
+	for i, cell := range header {
+		normalized[i] = strings.ToLower(strings.TrimSpace(cell))
+	}
+
+	columns := map[string]int{}
+	for key, names := range csvColumnNames {
+		for _, name := range names {
+			found := -1
+			for i, cell := range normalized {
+				if cell == name {
+					found = i
+					break
+				}
+			}
+			if found < 0 {
+				for i, cell := range normalized {
+					if strings.Contains(cell, name) {
+						found = i
+						break
+					}
+				}
+			}
+			if found >= 0 {
+				columns[key] = found
+				break
+			}
+		}
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil
+	}
+	return columns
+}
+
+// parseCSVStatement reads the incoming payments of a bank CSV export. The delimiter and
+// columns are detected from the header row; preamble lines before the header are skipped.
+func parseCSVStatement(data []byte) ([]statementTransaction, error) {
+	content := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+
+	// Detect the delimiter from the first lines, as amounts may contain decimal commas
+	lines := strings.SplitN(content, "\n", 21)
+	sample := strings.Join(lines[:len(lines)-1], "\n")
+	if len(lines) == 1 {
+		sample = content
+	}
+	delimiter := ','
+	if strings.Count(sample, ";") > strings.Count(sample, ",") {
+		delimiter = ';'
+	} else if strings.Count(sample, "\t") > strings.Count(sample, ",") {
+		delimiter = '\t'
+	}
+
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+
+	var columns map[string]int
+	start := 0
+	for i := 0; i < len(records) && i < 20; i++ {
+		if columns = csvColumns(records[i]); columns != nil {
+			start = i + 1
+			break
+		}
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("CSV file needs a header row with date and amount columns")
+	}
+
+	cell := func(record []string, key string) string {
+		i, ok := columns[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var result []statementTransaction
+	for _, record := range records[start:] {
+		amount, err := parseStatementAmount(cell(record, "amount"))
+		if err != nil || amount <= 0 {
+			continue
+		}
+		bookingDate, err := parseStatementDate(cell(record, "date"))
+		if err != nil {
+			continue
+		}
+		result = append(result, statementTransaction{
+			bookingDate: bookingDate,
+			amount:      amount,
+			currency:    cell(record, "currency"),
+			payerName:   cell(record, "name"),
+			payerIBAN:   NormalizeIBAN(cell(record, "iban")),
+			reference:   cell(record, "reference"),
+		})
+	}
+
+	return result, nil
+}
+
+// openPaymentItem is an unpaid fine or invoice that a transfer can settle
+type openPaymentItem struct {
+	fineID    *string
+	invoiceID *string
+	amount    float64
+	reference string // Normalized reference code
+	firstName string
+	lastName  string
+}
+
+// normalizeReference keeps only letters and digits, so references survive line breaks and spacing
+func normalizeReference(text string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(text) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// nameTokens splits a name into lowercase words
+func nameTokens(name string) map[string]bool {
+	tokens := map[string]bool{}
+	for _, token := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.' || r == '-'
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
+
+// matchesPayer reports whether the payer name contains the first and last name of the member
+func (item openPaymentItem) matchesPayer(payerName string) bool {
+	if item.firstName == "" || item.lastName == "" || payerName == "" {
+		return false
+	}
+	tokens := nameTokens(payerName)
+	for token := range nameTokens(item.firstName + " " + item.lastName) {
+		if !tokens[token] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadOpenPaymentItems loads the unpaid fines and invoices of a club. Like direct debits, payments
+// are not matched to fines awaiting review or with an open or accepted dispute.
+func loadOpenPaymentItems(db *gorm.DB, clubID string) ([]openPaymentItem, error) {
+	var fines []Fine
+	if err := db.Preload("User").Where("club_id = ? AND paid = ? AND pending_review = ?", clubID, false, false).
+		Where("dispute_status IS NULL OR dispute_status NOT IN ?", []string{FineDisputeStatusDisputed, FineDisputeStatusWaived}).Find(&fines).Error; err != nil {
+		return nil, err
+	}
+	var invoices []Invoice
+	if err := db.Where("club_id = ? AND paid = ?", clubID, false).Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]openPaymentItem, 0, len(fines)+len(invoices))
+	for i := range fines {
+		item := openPaymentItem{fineID: &fines[i].ID, amount: roundCents(fines[i].Amount), reference: normalizeReference(FineReference(fines[i].ID))}
+		if fines[i].User != nil {
+			item.firstName, item.lastName = fines[i].User.FirstName, fines[i].User.LastName
+		}
+		items = append(items, item)
+	}
+
+	users := map[string]User{}
+	for i := range invoices {
+		user, ok := users[invoices[i].UserID]
+		if !ok {
+			db.Where("id = ?", invoices[i].UserID).First(&user)
+			users[invoices[i].UserID] = user
+		}
+		items = append(items, openPaymentItem{
+			invoiceID: &invoices[i].ID,
+			amount:    roundCents(invoices[i].Amount),
+			reference: normalizeReference(invoices[i].Reference),
+			firstName: user.FirstName,
+			lastName:  user.LastName,
+		})
+	}
+
+	return items, nil
+}
+
+// matchTransaction finds the open item a transfer most likely settles. Items already
+// claimed by earlier transactions of the same import are skipped.
+func matchTransaction(transaction statementTransaction, items []openPaymentItem, claimed map[int]bool) (int, int, string) {
+	reference := normalizeReference(transaction.reference)
+
+	if reference != "" {
+		for i, item := range items {
+			if claimed[i] || !strings.Contains(reference, item.reference) {
+				continue
+			}
+			if item.amount == transaction.amount {
+				return i, matchConfidenceReference, "reference and amount match"
+			}
+			return i, matchConfidenceReferenceOnly, "reference matches, amount differs"
+		}
+	}
+
+	var byAmount, byNameAndAmount []int
+	for i, item := range items {
+		if claimed[i] || item.amount != transaction.amount {
+			continue
+		}
+		byAmount = append(byAmount, i)
+		if item.matchesPayer(transaction.payerName) {
+			byNameAndAmount = append(byNameAndAmount, i)
+		}
+	}
+
+	switch {
+	case len(byNameAndAmount) > 0:
+		return byNameAndAmount[0], matchConfidenceNameAmount, "payer name and amount match"
+	case len(byAmount) == 1:
+		return byAmount[0], matchConfidenceAmount, "only the amount matches"
+	}
+
+	return -1, 0, ""
+}
+
+// transactionFingerprint identifies a transaction across imports of overlapping statements
+func transactionFingerprint(clubID string, transaction statementTransaction, occurrence int) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		clubID,
+		transaction.bookingDate.Format("2006-01-02"),
+		strconv.FormatFloat(transaction.amount, 'f', 2, 64),
+		transaction.payerName,
+		transaction.payerIBAN,
+		transaction.reference,
+		transaction.endToEndID,
+		strconv.Itoa(occurrence),
+	}, "|")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ImportBankStatement reads a CAMT.053, MT940 or CSV statement, records its incoming payments
+// and matches them to open fines and invoices. Matches by reference and amount (and collected
+// SEPA direct debits) are settled right away; uncertain matches are left for confirmation.
+func ImportBankStatement(clubID, format, fileName string, data []byte, importedBy string) (*BankStatementImport, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = DetectBankStatementFormat(data)
+	}
+
+	transactions, err := parseBankStatement(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("statement contains no incoming payments")
+	}
+
+	statementImport := BankStatementImport{
+		ClubID:    clubID,
+		Format:    format,
+		CreatedAt: time.Now(),
+		CreatedBy: importedBy,
+	}
+	if fileName = strings.TrimSpace(fileName); fileName != "" {
+		statementImport.FileName = &fileName
+	}
+
+	// Record the import, its transactions and the settled items together or not at all
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		items, err := loadOpenPaymentItems(tx, clubID)
+		if err != nil {
+			return fmt.Errorf("failed to load open items: %w", err)
+		}
+
+		if err := tx.Create(&statementImport).Error; err != nil {
+			return fmt.Errorf("failed to record import: %w", err)
+		}
+
+		claimed := map[int]bool{}
+		occurrences := map[string]int{}
+		for _, transaction := range transactions {
+			base := transactionFingerprint(clubID, transaction, 0)
+			fingerprint := transactionFingerprint(clubID, transaction, occurrences[base])
+			occurrences[base]++
+
+			var existing int64
+			tx.Model(&BankTransaction{}).Where("club_id = ? AND fingerprint = ?", clubID, fingerprint).Count(&existing)
+			if existing > 0 {
+				statementImport.DuplicateCount++
+				continue
+			}
+
+			record := BankTransaction{
+				ClubID:      clubID,
+				ImportID:    statementImport.ID,
+				Fingerprint: fingerprint,
+				BookingDate: transaction.bookingDate,
+				Amount:      transaction.amount,
+				Currency:    transaction.currency,
+				PayerName:   transaction.payerName,
+				PayerIBAN:   transaction.payerIBAN,
+				Reference:   transaction.reference,
+				EndToEndID:  transaction.endToEndID,
+				Status:      BankTransactionStatusUnmatched,
+				CreatedAt:   time.Now(),
+			}
+
+			if err := matchBankTransaction(tx, &record, transaction, items, claimed, importedBy); err != nil {
+				return err
+			}
+
+			switch record.Status {
+			case BankTransactionStatusMatched:
+				statementImport.MatchedCount++
+			case BankTransactionStatusSuggested:
+				statementImport.SuggestedCount++
+			}
+
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("failed to record transaction: %w", err)
+			}
+			statementImport.TransactionCount++
+		}
+
+		return tx.Model(&statementImport).Updates(map[string]interface{}{
+			"transaction_count": statementImport.TransactionCount,
+			"matched_count":     statementImport.MatchedCount,
+			"suggested_count":   statementImport.SuggestedCount,
+			"duplicate_count":   statementImport.DuplicateCount,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &statementImport, nil
+}
+
+// matchBankTransaction sets the match of a new transaction and settles certain matches
+func matchBankTransaction(tx *gorm.DB, record *BankTransaction, transaction statementTransaction, items []openPaymentItem, claimed map[int]bool, importedBy string) error {
+	// Collections of exported SEPA direct debits carry their end-to-end ID
+	if transaction.endToEndID != "" {
+		var debit SepaDebit
+		if err := tx.Where("club_id = ? AND end_to_end_id = ?", record.ClubID, transaction.endToEndID).First(&debit).Error; err == nil {
+			if _, _, err := settleSepaDebit(tx, record.ClubID, debit.EndToEndID, transaction.amount, true, importedBy); err != nil {
+				return err
+			}
+			var settled SepaDebit
+			tx.Where("id = ?", debit.ID).First(&settled)
+			if settled.Status == SepaDebitStatusPaid {
+				now := time.Now()
+				record.Status = BankTransactionStatusMatched
+				record.Confidence = matchConfidenceReference
+				record.MatchReason = "SEPA direct debit collected"
+				record.FineID = debit.FineID
+				record.InvoiceID = debit.InvoiceID
+				record.SettledAt = &now
+				record.SettledBy = &importedBy
+				for i, item := range items {
+					if (item.fineID != nil && debit.FineID != nil && *item.fineID == *debit.FineID) ||
+						(item.invoiceID != nil && debit.InvoiceID != nil && *item.invoiceID == *debit.InvoiceID) {
+						claimed[i] = true
+					}
+				}
+				return nil
+			}
+		}
+	}
+
+	index, confidence, reason := matchTransaction(transaction, items, claimed)
+	if index < 0 {
+		return nil
+	}
+
+	item := items[index]
+	claimed[index] = true
+	record.FineID = item.fineID
+	record.InvoiceID = item.invoiceID
+	record.Confidence = confidence
+	record.MatchReason = reason
+	record.Status = BankTransactionStatusSuggested
+
+	if confidence == matchConfidenceReference {
+		if err := settlePaymentItem(tx, item.fineID, item.invoiceID, transaction.bookingDate, importedBy); err != nil {
+			return err
+		}
+		now := time.Now()
+		record.Status = BankTransactionStatusMatched
+		record.SettledAt = &now
+		record.SettledBy = &importedBy
+	}
+
+	return nil
+}
+
+// settlePaymentItem marks a fine or invoice as paid
+func settlePaymentItem(tx *gorm.DB, fineID, invoiceID *string, paidAt time.Time, settledBy string) error {
+	now := time.Now()
+	if paidAt.IsZero() {
+		paidAt = now
+	}
+	if fineID != nil {
+		if err := tx.Model(&Fine{}).Where("id = ?", *fineID).
+			Updates(map[string]interface{}{"paid": true, "updated_at": now, "updated_by": settledBy}).Error; err != nil {
+			return fmt.Errorf("failed to mark fine as paid: %w", err)
+		}
+	}
+	if invoiceID != nil {
+		if err := tx.Model(&Invoice{}).Where("id = ? AND paid = ?", *invoiceID, false).
+			Updates(map[string]interface{}{"paid": true, "paid_at": paidAt, "updated_at": now, "updated_by": settledBy}).Error; err != nil {
+			return fmt.Errorf("failed to mark invoice as paid: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsSettled reports whether the transaction has been used to mark an item as paid
+func (bt *BankTransaction) IsSettled() bool {
+	return bt.Status == BankTransactionStatusMatched || bt.Status == BankTransactionStatusConfirmed
+}
+
+// Confirm marks the suggested item, or the given fine or invoice, as paid by this transaction
+func (bt *BankTransaction) Confirm(fineID, invoiceID *string, confirmedBy string) error {
+	if bt.IsSettled() {
+		return ErrBankTransactionSettled
+	}
+
+	if fineID == nil && invoiceID == nil {
+		fineID, invoiceID = bt.FineID, bt.InvoiceID
+	}
+	if (fineID == nil) == (invoiceID == nil) {
+		return fmt.Errorf("exactly one fine or invoice must be selected")
+	}
+
+	if fineID != nil {
+		var fine Fine
+		if err := database.Db.Where("id = ? AND club_id = ?", *fineID, bt.ClubID).First(&fine).Error; err != nil {
+			return fmt.Errorf("fine not found in this club")
+		}
+		if fine.Paid {
+			return fmt.Errorf("fine is already paid")
+		}
+	} else {
+		var invoice Invoice
+		if err := database.Db.Where("id = ? AND club_id = ?", *invoiceID, bt.ClubID).First(&invoice).Error; err != nil {
+			return fmt.Errorf("invoice not found in this club")
+		}
+		if invoice.Paid {
+			return ErrInvoiceAlreadyPaid
+		}
+	}
+
+	now := time.Now()
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := settlePaymentItem(tx, fineID, invoiceID, bt.BookingDate, confirmedBy); err != nil {
+			return err
+		}
+		return tx.Model(bt).Updates(map[string]interface{}{
+			"status":     BankTransactionStatusConfirmed,
+			"fine_id":    fineID,
+			"invoice_id": invoiceID,
+			"settled_at": now,
+			"settled_by": confirmedBy,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	bt.Status = BankTransactionStatusConfirmed
+	bt.FineID = fineID
+	bt.InvoiceID = invoiceID
+	bt.SettledAt = &now
+	bt.SettledBy = &confirmedBy
+	return nil
+}
+
+// Ignore marks a transaction as unrelated to fines and dues
+func (bt *BankTransaction) Ignore() error {
+	if bt.IsSettled() {
+		return ErrBankTransactionSettled
+	}
+
+	if err := database.Db.Model(bt).Updates(map[string]interface{}{"status": BankTransactionStatusIgnored}).Error; err != nil {
+		return err
+	}
+
+	bt.Status = BankTransactionStatusIgnored
+	return nil
+}
+
+// bankStatementAdminScope restricts statements to clubs in which the user manages fines.
+// Arguments: user ID, user ID, fines.manage permission.
+const bankStatementAdminScope = "club_id IN (" + permittedClubsQuery + ")"
+
+// ODataBeforeReadCollection filters statement imports to administered clubs
+func (bi BankStatementImport) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(bankStatementAdminScope, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific statement import
+func (bi BankStatementImport) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return bi.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation; use the ImportBankStatement action instead
+func (bi *BankStatementImport) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the ImportBankStatement action to upload statements")
+}
+
+// ODataBeforeUpdate prevents updates of statement imports
+func (bi *BankStatementImport) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: statement imports cannot be modified")
+}
+
+// ODataBeforeDelete allows club admins to delete an import with its transactions.
+// Items already marked as paid stay paid.
+func (bi *BankStatementImport) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(bi.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete statement imports")
+	}
+
+	return database.Db.Where("import_id = ?", bi.ID).Delete(&BankTransaction{}).Error
+}
+
+// ODataBeforeReadCollection filters bank transactions to administered clubs
+func (bt BankTransaction) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(bankStatementAdminScope, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific bank transaction
+func (bt BankTransaction) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return bt.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation; transactions are read from uploaded statements
+func (bt *BankTransaction) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the ImportBankStatement action to upload statements")
+}
+
+// ODataBeforeUpdate prevents direct updates; use the ConfirmMatch and Ignore actions instead
+func (bt *BankTransaction) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the ConfirmMatch or Ignore actions")
+}
+
+// ODataBeforeDelete prevents deleting single transactions; delete the import instead
+func (bt *BankTransaction) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: delete the statement import instead")
+}
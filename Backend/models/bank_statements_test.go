@@ -0,0 +1,176 @@
+package models_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportBankStatement(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "bank-owner@example.com")
+	anna, _ := handlers.CreateTestUser(t, "bank-anna@example.com")
+	ben, _ := handlers.CreateTestUser(t, "bank-ben@example.com")
+	club := handlers.CreateTestClub(t, owner, "Bank Club")
+	handlers.CreateTestMember(t, anna, club, "member")
+	handlers.CreateTestMember(t, ben, club, "member")
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", anna.ID).Updates(map[string]interface{}{"first_name": "Anna", "last_name": "Schmidt"}).Error)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", ben.ID).Updates(map[string]interface{}{"first_name": "Ben", "last_name": "Meyer"}).Error)
+
+	lateFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: anna.ID, Reason: "Late", Amount: 10, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	jerseyFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: anna.ID, Reason: "Jersey", Amount: 7.5, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&lateFine).Error)
+	require.NoError(t, db.Create(&jerseyFine).Error)
+
+	invoice := models.Invoice{ClubID: club.ID, UserID: ben.ID, FeePlanID: uuid.New().String(), FeeAssignmentID: uuid.New().String(), Amount: 25, DueDate: time.Now()}
+	require.NoError(t, db.Create(&invoice).Error)
+
+	csvStatement := fmt.Sprintf(`Kontoauszug Vereinskonto
+Buchungstag;Name Zahlungsbeteiligter;Verwendungszweck;Betrag;Waehrung
+01.10.2026;Anna Schmidt;Strafe %s;10,00;EUR
+02.10.2026;SCHMIDT, ANNA;Trikot vergessen;7,50;EUR
+03.10.2026;Someone Else;Spende;50,00;EUR
+04.10.2026;Hall Rental Ltd;Miete;-200,00;EUR
+`, models.FineReference(lateFine.ID))
+
+	var suggestion models.BankTransaction
+
+	t.Run("CSV statements are matched by reference, name and amount", func(t *testing.T) {
+		assert.Equal(t, models.BankStatementFormatCSV, models.DetectBankStatementFormat([]byte(csvStatement)))
+
+		statementImport, err := models.ImportBankStatement(club.ID, "", "umsaetze.csv", []byte(csvStatement), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, statementImport.TransactionCount, "outgoing payments are skipped")
+		assert.Equal(t, 1, statementImport.MatchedCount)
+		assert.Equal(t, 1, statementImport.SuggestedCount)
+
+		var fine models.Fine
+		require.NoError(t, db.Where("id = ?", lateFine.ID).First(&fine).Error)
+		assert.True(t, fine.Paid, "reference and amount match settles the fine")
+
+		require.NoError(t, db.Where("import_id = ? AND status = ?", statementImport.ID, models.BankTransactionStatusSuggested).First(&suggestion).Error)
+		require.NotNil(t, suggestion.FineID)
+		assert.Equal(t, jerseyFine.ID, *suggestion.FineID)
+		var pending models.Fine
+		require.NoError(t, db.Where("id = ?", jerseyFine.ID).First(&pending).Error)
+		assert.False(t, pending.Paid, "uncertain matches need confirmation")
+
+		var unmatched int64
+		db.Model(&models.BankTransaction{}).Where("import_id = ? AND status = ?", statementImport.ID, models.BankTransactionStatusUnmatched).Count(&unmatched)
+		assert.Equal(t, int64(1), unmatched)
+	})
+
+	t.Run("importing the same statement again skips known transactions", func(t *testing.T) {
+		statementImport, err := models.ImportBankStatement(club.ID, models.BankStatementFormatCSV, "", []byte(csvStatement), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, statementImport.TransactionCount)
+		assert.Equal(t, 3, statementImport.DuplicateCount)
+	})
+
+	t.Run("suggested matches are confirmed by a treasurer", func(t *testing.T) {
+		require.NoError(t, suggestion.Confirm(nil, nil, owner.ID))
+		assert.Equal(t, models.BankTransactionStatusConfirmed, suggestion.Status)
+
+		var fine models.Fine
+		require.NoError(t, db.Where("id = ?", jerseyFine.ID).First(&fine).Error)
+		assert.True(t, fine.Paid)
+
+		assert.ErrorIs(t, suggestion.Confirm(nil, nil, owner.ID), models.ErrBankTransactionSettled)
+		assert.ErrorIs(t, suggestion.Ignore(), models.ErrBankTransactionSettled)
+	})
+
+	t.Run("MT940 statements with structured remittance", func(t *testing.T) {
+		mt940 := fmt.Sprintf(":20:STARTUMS\r\n:25:12345678/0123456789\r\n:28C:00001/001\r\n:60F:C261001EUR1000,00\r\n"+
+			":61:2610051005CR25,00NTRFNONREF\r\n:86:166?00GUTSCHRIFT?20SVWZ+Beitrag %s\r\n?32MEYER BEN\r\n"+
+			":61:2610061006DR12,00NTRFNONREF\r\n:86:805?00LASTSCHRIFT?20Gebuehren\r\n:62F:C261006EUR1013,00\r\n-", invoice.Reference)
+		assert.Equal(t, models.BankStatementFormatMT940, models.DetectBankStatementFormat([]byte(mt940)))
+
+		statementImport, err := models.ImportBankStatement(club.ID, "", "", []byte(mt940), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, statementImport.TransactionCount)
+		assert.Equal(t, 1, statementImport.MatchedCount)
+
+		var transaction models.BankTransaction
+		require.NoError(t, db.Where("import_id = ?", statementImport.ID).First(&transaction).Error)
+		assert.Equal(t, "MEYER BEN", transaction.PayerName)
+		assert.Equal(t, "EUR", transaction.Currency)
+
+		var paid models.Invoice
+		require.NoError(t, db.Where("id = ?", invoice.ID).First(&paid).Error)
+		assert.True(t, paid.Paid)
+	})
+
+	t.Run("CAMT statements with a different amount are only suggested", func(t *testing.T) {
+		openFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: ben.ID, Reason: "Red card", Amount: 20, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&openFine).Error)
+
+		camt := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt><Stmt>
+    <Ntry>
+      <Amt Ccy="EUR">15.00</Amt>
+      <CdtDbtInd>CRDT</CdtDbtInd>
+      <Sts>BOOK</Sts>
+      <BookgDt><Dt>2026-10-07</Dt></BookgDt>
+      <NtryDtls><TxDtls>
+        <RltdPties><Dbtr><Nm>Ben Meyer</Nm></Dbtr></RltdPties>
+        <RmtInf><Ustrd>%s</Ustrd></RmtInf>
+      </TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`, models.FineReference(openFine.ID))
+
+		statementImport, err := models.ImportBankStatement(club.ID, "", "", []byte(camt), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, statementImport.SuggestedCount)
+
+		var transaction models.BankTransaction
+		require.NoError(t, db.Where("import_id = ?", statementImport.ID).First(&transaction).Error)
+		require.NotNil(t, transaction.FineID)
+		assert.Equal(t, openFine.ID, *transaction.FineID)
+		assert.Equal(t, "Ben Meyer", transaction.PayerName)
+
+		require.NoError(t, transaction.Ignore())
+		assert.Equal(t, models.BankTransactionStatusIgnored, transaction.Status)
+	})
+
+	t.Run("fines under review or in dispute are not matched", func(t *testing.T) {
+		reviewFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: ben.ID, Reason: "Reported", Amount: 5, PendingReview: true, CreatedBy: ben.ID, UpdatedBy: ben.ID}
+		disputedFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: ben.ID, Reason: "Disputed", Amount: 6, DisputeStatus: models.FineDisputeStatusDisputed, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&reviewFine).Error)
+		require.NoError(t, db.Create(&disputedFine).Error)
+
+		statement := fmt.Sprintf(`Buchungstag;Name Zahlungsbeteiligter;Verwendungszweck;Betrag;Waehrung
+08.10.2026;Someone Else;%s;5,00;EUR
+09.10.2026;Someone Else;%s;6,00;EUR
+`, models.FineReference(reviewFine.ID), models.FineReference(disputedFine.ID))
+
+		statementImport, err := models.ImportBankStatement(club.ID, models.BankStatementFormatCSV, "", []byte(statement), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, statementImport.TransactionCount)
+		assert.Equal(t, 0, statementImport.MatchedCount)
+
+		var matched int64
+		db.Model(&models.BankTransaction{}).Where("fine_id IN ?", []string{reviewFine.ID, disputedFine.ID}).Count(&matched)
+		assert.Zero(t, matched)
+		var paid int64
+		db.Model(&models.Fine{}).Where("id IN ? AND paid = ?", []string{reviewFine.ID, disputedFine.ID}, true).Count(&paid)
+		assert.Zero(t, paid)
+	})
+
+	t.Run("unsupported content is rejected", func(t *testing.T) {
+		_, err := models.ImportBankStatement(club.ID, "pdf", "", []byte("%PDF"), owner.ID)
+		assert.Error(t, err)
+		_, err = models.ImportBankStatement(club.ID, models.BankStatementFormatCSV, "", []byte("foo,bar\n1,2"), owner.ID)
+		assert.Error(t, err)
+	})
+}
@@ -92,7 +92,7 @@ func (c *Club) GetAdminsAndOwners() ([]User, error) {
 	var users []User
 	err := database.Db.Table("users").
 		Joins("JOIN members ON users.id = members.user_id").
-		Where("members.club_id = ? AND (members.role = ? OR members.role = ?)", c.ID, "admin", "owner").
+		Where("members.club_id = ? AND (members.role = ? OR members.role = ?) AND members.status <> 'former'", c.ID, "admin", "owner").
 		Find(&users).Error
 	return users, err
 }
@@ -158,6 +158,7 @@ func (c *Club) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 }
 
 // ODataAfterCreate OData hook - creates the creator as an owner member of the club and default settings
+// and records the new club in the audit log
 func (c *Club) ODataAfterCreate(ctx context.Context, r *http.Request) error {
 	// Get transaction from context
 	tx, ok := odata.TransactionFromContext(ctx)
@@ -187,7 +188,7 @@ func (c *Club) ODataAfterCreate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("failed to create default club settings: %w", err)
 	}
 
-	return nil
+	return auditEntityChange(ctx, r, c.ID, "Club", c.ID, AuditOperationCreate, c)
 }
 
 // ODataBeforeUpdate OData hook - sets UpdatedBy from authenticated user context
@@ -198,6 +199,24 @@ func (c *Club) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "Club", c.ID, c)
+
+	// Check if user holds the settings.edit permission in the club
+	if !HasPermission(c.ID, userID, PermissionSettingsEdit) {
+		return fmt.Errorf("unauthorized: only admins and owners can update clubs")
+	}
+
+	updated, err := updatedEntity(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a club through an update requires the owner-only club.delete permission
+	if updated.Deleted != c.Deleted && !HasPermission(c.ID, userID, PermissionClubDelete) {
+		return fmt.Errorf("unauthorized: only owners can delete clubs")
+	}
+
 	// Set updated by and updated at (these will always be updated)
 	now := time.Now()
 	c.UpdatedAt = now
@@ -206,7 +225,7 @@ func (c *Club) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
-// ODataAfterUpdate OData hook - handles soft delete timestamp setting
+// ODataAfterUpdate OData hook - handles soft delete timestamp setting and records the changes in the audit log
 func (c *Club) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
 	// If the club was just marked as deleted, set the soft delete fields
 	if c.Deleted && c.DeletedAt == nil {
@@ -236,39 +255,44 @@ func (c *Club) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
 		c.DeletedBy = &userID
 	}
 
-	return nil
+	return auditEntityChange(ctx, r, c.ID, "Club", c.ID, AuditOperationUpdate, c)
 }
 
 // ODataBeforeReadCollection OData read hook - filters clubs based on membership and discoverability
 // Users can see:
-// 1. Non-deleted clubs they are members of
+// 1. Non-deleted clubs they or their dependents are members of
 // 2. Non-deleted clubs where DiscoverableByNonMembers is enabled
-// Note: Deleted clubs are only visible to owners when includeDeleted=true (handled elsewhere)
+// Note: Deleted clubs are only visible to owners when includeDeleted=true
 func (c Club) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
 	if !ok || userID == "" {
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	scope := func(db *gorm.DB) *gorm.DB {
+	return []func(*gorm.DB) *gorm.DB{clubVisibilityScope(ctx, userID)}, nil
+}
+
+// clubVisibilityScope limits clubs to non-deleted clubs the user or a dependent is a member of or that are
+// discoverable, plus the user's own deleted clubs if includeDeleted was requested
+func clubVisibilityScope(ctx context.Context, userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
 		// Show only non-deleted clubs where:
-		// 1. User is a member
+		// 1. User or one of their dependents is a member
 		// OR
 		// 2. Club is discoverable by non-members
-		return db.Where(
-			"clubs.deleted = ? AND (clubs.id IN (SELECT club_id FROM members WHERE user_id = ?) OR clubs.id IN (SELECT club_id FROM club_settings WHERE discoverable_by_non_members = ?))",
-			false,
-			userID,
-			true,
-		)
+		visible := "clubs.deleted = ? AND (clubs.id IN (" + actingClubsQuery + ") OR clubs.id IN (SELECT club_id FROM club_settings WHERE discoverable_by_non_members = ?))"
+		if includeDeleted(ctx) {
+			// Owners can see their deleted clubs to restore them
+			return db.Where("("+visible+") OR (clubs.deleted = ? AND clubs.id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role = 'owner'))",
+				false, userID, userID, true, true, userID)
+		}
+		return db.Where(visible, false, userID, userID, true)
 	}
-
-	return []func(*gorm.DB) *gorm.DB{scope}, nil
 }
 
 // ODataBeforeReadEntity OData read hook - allows access to clubs based on membership and discoverability
 // Users can access:
-// 1. Non-deleted clubs they are members of
+// 1. Non-deleted clubs they or their dependents are members of
 // 2. Non-deleted clubs where DiscoverableByNonMembers is enabled
 // Note: Deleted clubs are only accessible to owners when requested with includeDeleted=true
 func (c Club) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
@@ -277,18 +301,5 @@ func (c Club) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts i
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	scope := func(db *gorm.DB) *gorm.DB {
-		// Allow access to non-deleted clubs only where:
-		// 1. User is a member
-		// OR
-		// 2. Club is discoverable by non-members
-		return db.Where(
-			"clubs.deleted = ? AND (clubs.id IN (SELECT club_id FROM members WHERE user_id = ?) OR clubs.id IN (SELECT club_id FROM club_settings WHERE discoverable_by_non_members = ?))",
-			false,
-			userID,
-			true,
-		)
-	}
-
-	return []func(*gorm.DB) *gorm.DB{scope}, nil
+	return []func(*gorm.DB) *gorm.DB{clubVisibilityScope(ctx, userID)}, nil
 }
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NLstn/civo/azure"
+	"github.com/NLstn/civo/database"
+	"gorm.io/gorm"
+)
+
+// ClubRetentionPeriod is how long a soft-deleted club can be restored before it is purged
+const ClubRetentionPeriod = 30 * 24 * time.Hour
+
+// ClubPurgeNoticePeriod is how long before the purge the owners are notified
+const ClubPurgeNoticePeriod = 7 * 24 * time.Hour
+
+var (
+	ErrClubNotDeleted       = errors.New("club is not deleted")
+	ErrClubRetentionExpired = errors.New("the retention period of the club has expired")
+)
+
+type includeDeletedKey struct{}
+
+// WithIncludeDeleted makes the club read hooks also return the soft-deleted clubs the user owns
+func WithIncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// includeDeleted reports whether soft-deleted clubs were requested
+func includeDeleted(ctx context.Context) bool {
+	value, ok := ctx.Value(includeDeletedKey{}).(bool)
+	return ok && value
+}
+
+// PurgeAt returns when a soft-deleted club is purged, or nil if the club is not deleted
+func (c *Club) PurgeAt() *time.Time {
+	if !c.Deleted || c.DeletedAt == nil {
+		return nil
+	}
+	purgeAt := c.DeletedAt.Add(ClubRetentionPeriod)
+	return &purgeAt
+}
+
+// Restore undoes the soft deletion of a club within the retention period. The club counts
+// towards its creator's club limit again.
+func (c *Club) Restore(restoredBy string) error {
+	purgeAt := c.PurgeAt()
+	if purgeAt == nil {
+		return ErrClubNotDeleted
+	}
+	if time.Now().After(*purgeAt) {
+		return ErrClubRetentionExpired
+	}
+
+	return database.Db.Transaction(func(tx *gorm.DB) error {
+		activeClubCount, err := CountActiveClubsCreatedByUser(c.CreatedBy, tx)
+		if err != nil {
+			return fmt.Errorf("failed to check club limit: %w", err)
+		}
+		if activeClubCount >= DefaultMaxActiveClubs {
+			return ErrClubLimitExceeded()
+		}
+
+		now := time.Now()
+		if err := tx.Model(&Club{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+			"deleted":    false,
+			"deleted_at": nil,
+			"deleted_by": nil,
+			"updated_at": now,
+			"updated_by": restoredBy,
+		}).Error; err != nil {
+			return err
+		}
+
+		c.Deleted = false
+		c.DeletedAt = nil
+		c.DeletedBy = nil
+		c.UpdatedAt = now
+		c.UpdatedBy = restoredBy
+		return nil
+	})
+}
+
+// clubDependents lists the tables holding a club's data, children before their parents.
+// Each query selects the rows of the club given as the only parameter.
+var clubDependents = []struct {
+	model interface{}
+	query string
+}{
+	{&TeamMember{}, "team_id IN (SELECT id FROM teams WHERE club_id = ?)"},
+	{&EventRSVP{}, "event_id IN (SELECT id FROM events WHERE club_id = ?)"},
+	{&ShiftMember{}, "shift_id IN (SELECT id FROM shifts WHERE club_id = ?)"},
+	{&CommentReaction{}, "comment_id IN (SELECT id FROM comments WHERE club_id = ?)"},
+	{&PollVote{}, "poll_id IN (SELECT id FROM polls WHERE club_id = ?)"},
+	{&PollOption{}, "poll_id IN (SELECT id FROM polls WHERE club_id = ?)"},
+	{&SchedulingResponse{}, "scheduling_poll_id IN (SELECT id FROM scheduling_polls WHERE club_id = ?)"},
+	{&SchedulingSlot{}, "scheduling_poll_id IN (SELECT id FROM scheduling_polls WHERE club_id = ?)"},
+	{&ConversationParticipant{}, "conversation_id IN (SELECT id FROM conversations WHERE club_id = ?)"},
+	{&FeeDiscount{}, "fee_plan_id IN (SELECT id FROM fee_plans WHERE club_id = ?)"},
+	{&FineRuleEvaluation{}, "fine_rule_id IN (SELECT id FROM fine_rules WHERE club_id = ?)"},
+	{&ShiftTemplateSlot{}, "template_id IN (SELECT id FROM shift_templates WHERE club_id = ?)"},
+	{&ClubRolePermission{}, "role_id IN (SELECT id FROM club_roles WHERE club_id = ?)"},
+	{&MemberPrivacySettings{}, "member_id IN (SELECT id FROM members WHERE club_id = ?)"},
+	{&MemberFieldValue{}, "club_id = ?"},
+	{&MemberFieldDefinition{}, "club_id = ?"},
+	{&OwnershipTransfer{}, "club_id = ?"},
+	{&ClubSuccession{}, "club_id = ?"},
+	{&ShiftSwapRequest{}, "club_id = ?"},
+	{&Shift{}, "club_id = ?"},
+	{&ShiftTemplate{}, "club_id = ?"},
+	{&VolunteerQuota{}, "club_id = ?"},
+	{&Comment{}, "club_id = ?"},
+	{&Poll{}, "club_id = ?"},
+	{&SchedulingPoll{}, "club_id = ?"},
+	{&Message{}, "club_id = ?"},
+	{&Conversation{}, "club_id = ?"},
+	{&BankTransaction{}, "club_id = ?"},
+	{&BankStatementImport{}, "club_id = ?"},
+	{&SepaDebit{}, "club_id = ?"},
+	{&SepaMandate{}, "club_id = ?"},
+	{&SepaCreditor{}, "club_id = ?"},
+	{&Invoice{}, "club_id = ?"},
+	{&FeeAssignment{}, "club_id = ?"},
+	{&FeePlan{}, "club_id = ?"},
+	{&FineDisputeEntry{}, "club_id = ?"},
+	{&Fine{}, "club_id = ?"},
+	{&FineRule{}, "club_id = ?"},
+	{&FineTemplate{}, "club_id = ?"},
+	{&Event{}, "club_id = ?"},
+	{&Venue{}, "club_id = ?"},
+	{&News{}, "club_id = ?"},
+	{&MemberAbsence{}, "club_id = ?"},
+	{&ClubRoleAssignment{}, "club_id = ?"},
+	{&ClubRole{}, "club_id = ?"},
+	{&Team{}, "club_id = ?"},
+	{&Season{}, "club_id = ?"},
+	{&MembershipStatusChange{}, "club_id = ?"},
+	{&JoinRequest{}, "club_id = ?"},
+	{&Invite{}, "club_id = ?"},
+	{&Activity{}, "club_id = ?"},
+	{&Notification{}, "club_id = ?"},
+	{&ClubSettings{}, "club_id = ?"},
+	{&Member{}, "club_id = ?"},
+}
+
+// HardDeleteClub permanently deletes a club with all its data and its logo. The audit log of
+// the club is kept as a record of the deletion.
+func HardDeleteClub(clubID string) error {
+	var club Club
+	if err := database.Db.Where("id = ?", clubID).First(&club).Error; err != nil {
+		return err
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		for _, dependent := range clubDependents {
+			// Unscoped, so former members are removed as well
+			if err := tx.Unscoped().Where(dependent.query, clubID).Delete(dependent.model).Error; err != nil {
+				return fmt.Errorf("failed to delete %T: %w", dependent.model, err)
+			}
+		}
+		return tx.Unscoped().Delete(&club).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if club.LogoURL != nil && *club.LogoURL != "" {
+		if err := azure.DeleteClubLogo(*club.LogoURL); err != nil {
+			log.Printf("failed to delete logo of purged club %s: %v", clubID, err)
+		}
+	}
+	return nil
+}
+
+// notifyClubPurge tells the owners of a deleted club when it will be purged, once per club
+func notifyClubPurge(club *Club, purgeAt time.Time) error {
+	var owners []Member
+	if err := database.Db.Where("club_id = ? AND role = ?", club.ID, "owner").Find(&owners).Error; err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		var sent int64
+		if err := database.Db.Model(&Notification{}).
+			Where("user_id = ? AND club_id = ? AND type = ?", owner.UserID, club.ID, "club_purge_scheduled").
+			Count(&sent).Error; err != nil {
+			return err
+		}
+		if sent > 0 {
+			continue
+		}
+
+		_ = CreateNotification(owner.UserID, "club_purge_scheduled", "Deleted club will be removed",
+			fmt.Sprintf("%s will be permanently deleted on %s. Restore the club before then to keep its data.", club.Name, purgeAt.Format("02.01.2006")),
+			&club.ID, nil, nil)
+	}
+	return nil
+}
+
+// PurgeDeletedClubs permanently deletes clubs whose retention period has expired and notifies
+// the owners of clubs that will be purged soon.
+// This should be called periodically by the scheduler.
+func PurgeDeletedClubs() error {
+	var clubs []Club
+	if err := database.Db.Where("deleted = ? AND deleted_at IS NOT NULL AND deleted_at <= ?", true,
+		time.Now().Add(ClubPurgeNoticePeriod-ClubRetentionPeriod)).Find(&clubs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range clubs {
+		club := &clubs[i]
+		purgeAt := *club.PurgeAt()
+
+		if now.Before(purgeAt) {
+			if err := notifyClubPurge(club, purgeAt); err != nil {
+				log.Printf("failed to notify owners of deleted club %s: %v", club.ID, err)
+			}
+			continue
+		}
+
+		var owners []Member
+		if err := database.Db.Where("club_id = ? AND role = ?", club.ID, "owner").Find(&owners).Error; err != nil {
+			log.Printf("failed to load owners of deleted club %s: %v", club.ID, err)
+			continue
+		}
+		if err := HardDeleteClub(club.ID); err != nil {
+			log.Printf("failed to purge deleted club %s: %v", club.ID, err)
+			continue
+		}
+		for _, owner := range owners {
+			_ = CreateNotification(owner.UserID, "club_purged", "Deleted club removed",
+				fmt.Sprintf("%s and all its data have been permanently deleted.", club.Name), nil, nil, nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClubRetention(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "retention-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "retention-member@example.com")
+
+	deleteClub := func(name string, deletedAgo time.Duration) models.Club {
+		club := handlers.CreateTestClub(t, owner, name)
+		handlers.CreateTestMember(t, member, club, "member")
+		deletedAt := time.Now().Add(-deletedAgo)
+		require.NoError(t, db.Model(&models.Club{}).Where("id = ?", club.ID).Updates(map[string]interface{}{
+			"deleted": true, "deleted_at": deletedAt, "deleted_by": owner.ID,
+		}).Error)
+		require.NoError(t, db.Where("id = ?", club.ID).First(&club).Error)
+		return club
+	}
+
+	t.Run("restore within retention period", func(t *testing.T) {
+		club := deleteClub("Restorable Club", 24*time.Hour)
+		require.NoError(t, club.Restore(owner.ID))
+
+		var reloaded models.Club
+		require.NoError(t, db.Where("id = ?", club.ID).First(&reloaded).Error)
+		assert.False(t, reloaded.Deleted)
+		assert.Nil(t, reloaded.DeletedAt)
+
+		assert.ErrorIs(t, reloaded.Restore(owner.ID), models.ErrClubNotDeleted)
+	})
+
+	t.Run("restore after retention period fails", func(t *testing.T) {
+		club := deleteClub("Expired Club", models.ClubRetentionPeriod+time.Hour)
+		assert.ErrorIs(t, club.Restore(owner.ID), models.ErrClubRetentionExpired)
+		require.NoError(t, models.HardDeleteClub(club.ID))
+	})
+
+	t.Run("owners are notified once before the purge", func(t *testing.T) {
+		club := deleteClub("Soon Purged Club", models.ClubRetentionPeriod-2*24*time.Hour)
+		require.NoError(t, models.PurgeDeletedClubs())
+		require.NoError(t, models.PurgeDeletedClubs())
+
+		var notices int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND club_id = ? AND type = ?", owner.ID, club.ID, "club_purge_scheduled").Count(&notices)
+		assert.Equal(t, int64(1), notices)
+
+		var clubs int64
+		db.Model(&models.Club{}).Where("id = ?", club.ID).Count(&clubs)
+		assert.Equal(t, int64(1), clubs)
+	})
+
+	t.Run("expired clubs are purged with their data", func(t *testing.T) {
+		club := deleteClub("Purged Club", models.ClubRetentionPeriod+time.Hour)
+		require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+		require.NoError(t, db.Create(&models.Fine{ClubID: club.ID, UserID: member.ID, Reason: "Late", Amount: 5}).Error)
+		require.NoError(t, db.Create(&models.Event{ClubID: club.ID, Name: "Training", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}).Error)
+
+		require.NoError(t, models.PurgeDeletedClubs())
+
+		counts := map[string]interface{}{"clubs": &models.Club{}, "members": &models.Member{}, "fines": &models.Fine{}, "events": &models.Event{}, "club_settings": &models.ClubSettings{}}
+		for name, model := range counts {
+			var count int64
+			column := "club_id"
+			if name == "clubs" {
+				column = "id"
+			}
+			db.Model(model).Where(column+" = ?", club.ID).Count(&count)
+			assert.Zero(t, count, name)
+		}
+
+		var purged int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", owner.ID, "club_purged").Count(&purged)
+		assert.Equal(t, int64(1), purged)
+	})
+}
@@ -112,7 +112,7 @@ func (s ClubSettings) ODataBeforeReadCollection(ctx context.Context, r *http.Req
 
 	scope := func(db *gorm.DB) *gorm.DB {
 		// Only show settings for clubs where user is a member
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -127,7 +127,7 @@ func (s ClubSettings) ODataBeforeReadEntity(ctx context.Context, r *http.Request
 
 	scope := func(db *gorm.DB) *gorm.DB {
 		// Only allow access to settings for clubs where user is a member
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -140,20 +140,11 @@ func (s *ClubSettings) ODataBeforeUpdate(ctx context.Context, r *http.Request) e
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// Get the club to check if user is admin
-	var club Club
-	if err := database.Db.First(&club, "id = ?", s.ClubID).Error; err != nil {
-		return fmt.Errorf("failed to find club: %w", err)
-	}
-
-	// Get user
-	var user User
-	if err := database.Db.First(&user, "id = ?", userID).Error; err != nil {
-		return fmt.Errorf("failed to find user: %w", err)
-	}
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "ClubSettings", s.ID, s)
 
-	// Only admins and owners can update settings
-	if !club.IsAdmin(user) {
+	// Only members holding the settings.edit permission can update settings
+	if !HasPermission(s.ClubID, userID, PermissionSettingsEdit) {
 		return fmt.Errorf("forbidden: only club admins can update settings")
 	}
 
@@ -164,3 +155,8 @@ func (s *ClubSettings) ODataBeforeUpdate(ctx context.Context, r *http.Request) e
 
 	return nil
 }
+
+// ODataAfterUpdate records the changes to the settings in the audit log
+func (s *ClubSettings) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, s.ClubID, "ClubSettings", s.ID, AuditOperationUpdate, s)
+}
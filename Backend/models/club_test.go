@@ -400,6 +400,23 @@ func TestSoftDeleteClub(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
 
+	t.Run("admins cannot soft delete a club via OData PATCH", func(t *testing.T) {
+		owner, _ := handlers.CreateTestUser(t, "softdeleteowner@example.com")
+		admin, adminToken := handlers.CreateTestUser(t, "softdeleteadmin@example.com")
+		club := handlers.CreateTestClub(t, owner, "Admin Cannot Delete")
+		handlers.CreateTestMember(t, admin, club, "admin")
+
+		rec := odataRequest(t, adminToken, http.MethodPatch, "/Clubs("+club.ID+")", map[string]interface{}{"Deleted": true})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		var dbClub models.Club
+		require.NoError(t, database.Db.Unscoped().Where("id = ?", club.ID).First(&dbClub).Error)
+		assert.False(t, dbClub.Deleted)
+
+		rec = odataRequest(t, adminToken, http.MethodPatch, "/Clubs("+club.ID+")", map[string]interface{}{"Description": "Still editable"})
+		assert.Less(t, rec.Code, 300, rec.Body.String())
+	})
+
 	t.Run("soft delete non-existent club via OData", func(t *testing.T) {
 		_, token := handlers.CreateTestUser(t, "nonexistdelete@example.com")
 
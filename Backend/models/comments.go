@@ -0,0 +1,482 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength is the maximum number of characters allowed in a comment
+const MaxCommentLength = 5000
+
+// MaxReactionEmojiLength is the maximum number of characters for a reaction emoji.
+// Emoji sequences (skin tones, ZWJ sequences) can consist of several runes.
+const MaxReactionEmojiLength = 16
+
+var ErrCommentDeleted = errors.New("comment has been deleted")
+
+// Comment is a member comment on a news post or an event. Replies reference
+// their parent comment through ParentID, which allows threaded discussions.
+type Comment struct {
+	ID        string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID    string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	NewsID    *string    `json:"NewsID,omitempty" gorm:"type:uuid;index" odata:"nullable"`
+	EventID   *string    `json:"EventID,omitempty" gorm:"type:uuid;index" odata:"nullable"`
+	ParentID  *string    `json:"ParentID,omitempty" gorm:"type:uuid;index" odata:"nullable"`
+	Content   string     `json:"Content" gorm:"type:text;not null" odata:"required"`
+	Edited    bool       `json:"Edited" gorm:"default:false" odata:"auto"`
+	EditedAt  *time.Time `json:"EditedAt,omitempty" odata:"auto,nullable"`
+	Deleted   bool       `json:"Deleted" gorm:"default:false" odata:"auto"`
+	DeletedAt *time.Time `json:"DeletedAt,omitempty" odata:"auto,nullable"`
+	DeletedBy *string    `json:"DeletedBy,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	CreatedAt time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	CreatedByUser *User             `gorm:"foreignKey:CreatedBy" json:"CreatedByUser,omitempty" odata:"nav"`
+	Replies       []Comment         `gorm:"foreignKey:ParentID" json:"Replies,omitempty" odata:"nav"`
+	Reactions     []CommentReaction `gorm:"foreignKey:CommentID" json:"Reactions,omitempty" odata:"nav"`
+}
+
+// CommentReaction is an emoji reaction of a user to a comment.
+// A user can react with several different emojis but only once per emoji.
+type CommentReaction struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	CommentID string    `json:"CommentID" gorm:"type:uuid;not null;uniqueIndex:idx_comment_reactions_unique" odata:"required"`
+	UserID    string    `json:"UserID" gorm:"type:uuid;not null;uniqueIndex:idx_comment_reactions_unique" odata:"auto"`
+	Emoji     string    `json:"Emoji" gorm:"not null;uniqueIndex:idx_comment_reactions_unique" odata:"required"`
+	CreatedAt time.Time `json:"CreatedAt" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new comments
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new comment reactions
+func (cr *CommentReaction) BeforeCreate(tx *gorm.DB) error {
+	if cr.ID == "" {
+		cr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Feature returns the club feature (news or events) the commented content belongs to
+func (c *Comment) Feature() string {
+	if c.EventID != nil && *c.EventID != "" {
+		return "events"
+	}
+	return "news"
+}
+
+// validateTarget ensures the comment references exactly one existing news post or event
+// of its club, and that a parent comment (for replies) belongs to the same thread.
+func (c *Comment) validateTarget() error {
+	hasNews := c.NewsID != nil && *c.NewsID != ""
+	hasEvent := c.EventID != nil && *c.EventID != ""
+	if hasNews == hasEvent {
+		return fmt.Errorf("a comment must reference either a news post or an event")
+	}
+
+	if hasNews {
+		var news News
+		if err := database.Db.Where("id = ? AND club_id = ?", *c.NewsID, c.ClubID).First(&news).Error; err != nil {
+			return fmt.Errorf("unauthorized: news post does not belong to the specified club")
+		}
+	} else {
+		var event Event
+		if err := database.Db.Where("id = ? AND club_id = ?", *c.EventID, c.ClubID).First(&event).Error; err != nil {
+			return fmt.Errorf("unauthorized: event does not belong to the specified club")
+		}
+	}
+
+	if c.ParentID != nil && *c.ParentID != "" {
+		var parent Comment
+		if err := database.Db.Where("id = ? AND club_id = ?", *c.ParentID, c.ClubID).First(&parent).Error; err != nil {
+			return fmt.Errorf("parent comment not found")
+		}
+		if parent.Deleted {
+			return fmt.Errorf("cannot reply to a deleted comment")
+		}
+		if !sameStringPtr(parent.NewsID, c.NewsID) || !sameStringPtr(parent.EventID, c.EventID) {
+			return fmt.Errorf("reply must reference the same news post or event as its parent comment")
+		}
+	}
+
+	return nil
+}
+
+// sameStringPtr compares two optional strings treating nil and empty as equal
+func sameStringPtr(a, b *string) bool {
+	var av, bv string
+	if a != nil {
+		av = *a
+	}
+	if b != nil {
+		bv = *b
+	}
+	return av == bv
+}
+
+// validateCommentContent trims and validates comment content
+func validateCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("comment content cannot be empty")
+	}
+	if utf8.RuneCountInString(content) > MaxCommentLength {
+		return "", fmt.Errorf("comment content exceeds %d characters", MaxCommentLength)
+	}
+	return content, nil
+}
+
+// SoftDelete marks the comment as deleted and removes its content.
+// The record is kept so that replies remain attached to the thread.
+func (c *Comment) SoftDelete(deletedBy string) error {
+	if c.Deleted {
+		return ErrCommentDeleted
+	}
+
+	now := time.Now()
+	if err := database.Db.Model(c).Updates(map[string]interface{}{
+		"deleted":    true,
+		"deleted_at": &now,
+		"deleted_by": &deletedBy,
+		"content":    "",
+		"updated_at": now,
+		"updated_by": deletedBy,
+	}).Error; err != nil {
+		return err
+	}
+
+	return database.Db.Where("comment_id = ?", c.ID).Delete(&CommentReaction{}).Error
+}
+
+// Edit replaces the content of the comment and marks it as edited
+func (c *Comment) Edit(content, editedBy string) error {
+	if c.Deleted {
+		return ErrCommentDeleted
+	}
+
+	content, err := validateCommentContent(content)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := database.Db.Model(c).Updates(map[string]interface{}{
+		"content":    content,
+		"edited":     true,
+		"edited_at":  &now,
+		"updated_at": now,
+		"updated_by": editedBy,
+	}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteComments removes all comments and their reactions attached to a news post or event.
+// column is either "news_id" or "event_id".
+func deleteComments(db *gorm.DB, column, id string) error {
+	commentIDs := db.Model(&Comment{}).Select("id").Where(column+" = ?", id)
+	if err := db.Where("comment_id IN (?)", commentIDs).Delete(&CommentReaction{}).Error; err != nil {
+		return err
+	}
+	return db.Where(column+" = ?", id).Delete(&Comment{}).Error
+}
+
+// CanModerate reports whether the user may remove the comment: its author or a member who manages
+// the commented content, i.e. holds events.manage for event comments and news.publish for news comments
+func (c *Comment) CanModerate(userID string) bool {
+	if c.CreatedBy == userID {
+		return true
+	}
+
+	if c.EventID != nil && *c.EventID != "" {
+		return HasPermission(c.ClubID, userID, PermissionEventsManage)
+	}
+	return HasPermission(c.ClubID, userID, PermissionNewsPublish)
+}
+
+// SendCommentNotifications notifies the author of the commented news post or event
+// and, for replies, the author of the parent comment. The commenter is never notified.
+func (c *Comment) SendCommentNotifications() error {
+	var club Club
+	if err := database.Db.Where("id = ?", c.ClubID).First(&club).Error; err != nil {
+		return fmt.Errorf("failed to find club: %v", err)
+	}
+
+	commenterName := "Someone"
+	var commenter User
+	if err := database.Db.Where("id = ?", c.CreatedBy).First(&commenter).Error; err == nil {
+		commenterName = strings.TrimSpace(commenter.FirstName + " " + commenter.LastName)
+	}
+
+	var targetAuthor, targetTitle string
+	if c.EventID != nil && *c.EventID != "" {
+		var event Event
+		if err := database.Db.Where("id = ?", *c.EventID).First(&event).Error; err != nil {
+			return fmt.Errorf("failed to find event: %v", err)
+		}
+		targetAuthor, targetTitle = event.CreatedBy, event.Name
+	} else if c.NewsID != nil && *c.NewsID != "" {
+		var news News
+		if err := database.Db.Where("id = ?", *c.NewsID).First(&news).Error; err != nil {
+			return fmt.Errorf("failed to find news: %v", err)
+		}
+		targetAuthor, targetTitle = news.CreatedBy, news.Title
+	}
+
+	notified := map[string]bool{c.CreatedBy: true}
+
+	if c.ParentID != nil && *c.ParentID != "" {
+		var parent Comment
+		if err := database.Db.Where("id = ?", *c.ParentID).First(&parent).Error; err == nil && !notified[parent.CreatedBy] {
+			notified[parent.CreatedBy] = true
+			title := "New reply in " + club.Name
+			message := fmt.Sprintf("%s replied to your comment on %s.", commenterName, targetTitle)
+			if err := sendCommentAddedNotification(parent.CreatedBy, "comment_reply", title, message, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if targetAuthor != "" && !notified[targetAuthor] {
+		title := "New comment in " + club.Name
+		message := fmt.Sprintf("%s commented on %s.", commenterName, targetTitle)
+		if err := sendCommentAddedNotification(targetAuthor, "comment_added", title, message, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendCommentAddedNotification creates the in-app notification if the recipient enabled it
+func sendCommentAddedNotification(userID, notificationType, title, message string, c *Comment) error {
+	// Get user notification preferences
+	preferences, err := GetUserNotificationPreferences(userID)
+	if err != nil {
+		// If preferences don't exist, create default ones and continue
+		preferences, err = CreateDefaultUserNotificationPreferences(userID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification preferences: %v", err)
+		}
+	}
+
+	// Send in-app notification if enabled
+	if preferences.CommentAddedInApp {
+		if err := CreateNotification(userID, notificationType, title, message, &c.ClubID, c.EventID, nil); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	// Email sending will be handled by the caller if needed
+
+	return nil
+}
+
+// commentReadScope restricts comments to clubs the user belongs to and where the
+// feature of the commented content (news or events) is enabled
+func commentReadScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND (news_id IS NULL OR club_id IN (SELECT club_id FROM club_settings WHERE news_enabled = true)) AND (event_id IS NULL OR club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true))", userID)
+	}
+}
+
+// ODataBeforeReadCollection filters comments to only those in clubs the user belongs to
+func (c Comment) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{commentReadScope(userID)}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific comment
+func (c Comment) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{commentReadScope(userID)}, nil
+}
+
+// ODataBeforeCreate validates comment creation permissions
+func (c *Comment) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if the feature of the commented content is enabled for the club
+	if err := CheckFeatureEnabled(c.ClubID, c.Feature()); err != nil {
+		return err
+	}
+
+	// Any member of the club can comment
+	var existingMember Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", c.ClubID, userID).First(&existingMember).Error; err != nil {
+		return fmt.Errorf("unauthorized: only club members can comment")
+	}
+
+	// SECURITY: Verify the news post/event and parent comment belong to the specified club
+	if err := c.validateTarget(); err != nil {
+		return err
+	}
+
+	content, err := validateCommentContent(c.Content)
+	if err != nil {
+		return err
+	}
+	c.Content = content
+
+	// Set audit fields
+	now := time.Now()
+	c.Edited = false
+	c.EditedAt = nil
+	c.Deleted = false
+	c.DeletedAt = nil
+	c.DeletedBy = nil
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.CreatedBy = userID
+	c.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate notifies the author of the commented content and of the parent comment
+func (c *Comment) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	// Notification failures must not prevent the comment from being created
+	_ = c.SendCommentNotifications()
+	return nil
+}
+
+// ODataBeforeUpdate prevents direct updates of comments
+// Comments are changed through the Edit and SoftDelete actions, which enforce
+// that only the content can change and keep the edit/delete markers consistent.
+func (c *Comment) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return fmt.Errorf("forbidden: comments cannot be updated directly, use the Edit action instead")
+}
+
+// ODataBeforeDelete prevents hard deletion of comments
+// Comments are soft-deleted through the SoftDelete action so that threads stay intact.
+func (c *Comment) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return fmt.Errorf("forbidden: comments cannot be deleted permanently, use the SoftDelete action instead")
+}
+
+// ODataBeforeReadCollection filters reactions to comments the user can see
+func (cr CommentReaction) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("comment_id IN (SELECT id FROM comments WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former'))", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific reaction
+func (cr CommentReaction) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("comment_id IN (SELECT id FROM comments WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former'))", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeCreate validates reaction creation permissions
+func (cr *CommentReaction) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var comment Comment
+	if err := database.Db.Where("id = ?", cr.CommentID).First(&comment).Error; err != nil {
+		return fmt.Errorf("comment not found")
+	}
+	if comment.Deleted {
+		return ErrCommentDeleted
+	}
+
+	if err := CheckFeatureEnabled(comment.ClubID, comment.Feature()); err != nil {
+		return err
+	}
+
+	var existingMember Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", comment.ClubID, userID).First(&existingMember).Error; err != nil {
+		return fmt.Errorf("unauthorized: only club members can react to comments")
+	}
+
+	cr.Emoji = strings.TrimSpace(cr.Emoji)
+	if cr.Emoji == "" || utf8.RuneCountInString(cr.Emoji) > MaxReactionEmojiLength {
+		return fmt.Errorf("invalid emoji")
+	}
+
+	// Reactions are always created for the authenticated user
+	cr.UserID = userID
+	cr.CreatedAt = time.Now()
+
+	return nil
+}
+
+// ODataBeforeUpdate prevents reactions from being changed; remove and re-add instead
+func (cr *CommentReaction) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: reactions cannot be updated")
+}
+
+// ODataBeforeDelete validates reaction deletion permissions
+func (cr *CommentReaction) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Users can only remove their own reactions
+	if cr.UserID != userID {
+		return fmt.Errorf("unauthorized: can only remove your own reactions")
+	}
+
+	return nil
+}
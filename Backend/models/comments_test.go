@@ -0,0 +1,214 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commentRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/Comments", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func createTestComment(t *testing.T, userID string, comment *models.Comment) {
+	t.Helper()
+	ctx, req := commentRequest(userID)
+	require.NoError(t, comment.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(comment).Error)
+	require.NoError(t, comment.ODataAfterCreate(ctx, req))
+}
+
+func TestCommentsOnNews(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "comment-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "comment-member@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "comment-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Comment Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET news_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	news, err := club.CreateNews("Season kickoff", "See you there", owner.ID)
+	require.NoError(t, err)
+
+	t.Run("member comment notifies news author", func(t *testing.T) {
+		comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "  Looking forward!  "}
+		createTestComment(t, member.ID, comment)
+
+		assert.Equal(t, "Looking forward!", comment.Content)
+		assert.Equal(t, member.ID, comment.CreatedBy)
+
+		var notifications []models.Notification
+		require.NoError(t, db.Where("user_id = ? AND type = ?", owner.ID, "comment_added").Find(&notifications).Error)
+		assert.Len(t, notifications, 1)
+	})
+
+	t.Run("reply notifies parent comment author", func(t *testing.T) {
+		parent := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "Who brings the balls?"}
+		createTestComment(t, member.ID, parent)
+
+		reply := &models.Comment{ClubID: club.ID, NewsID: &news.ID, ParentID: &parent.ID, Content: "I do"}
+		createTestComment(t, owner.ID, reply)
+
+		var count int64
+		require.NoError(t, db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", member.ID, "comment_reply").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("disabled preference suppresses notification", func(t *testing.T) {
+		prefs, err := models.GetUserNotificationPreferences(owner.ID)
+		require.NoError(t, err)
+		prefs.CommentAddedInApp = false
+		require.NoError(t, prefs.Update())
+
+		var before int64
+		db.Model(&models.Notification{}).Where("user_id = ?", owner.ID).Count(&before)
+
+		comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "Another one"}
+		createTestComment(t, member.ID, comment)
+
+		var after int64
+		db.Model(&models.Notification{}).Where("user_id = ?", owner.ID).Count(&after)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("non-member cannot comment", func(t *testing.T) {
+		ctx, req := commentRequest(outsider.ID)
+		comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "Hi"}
+		assert.Error(t, comment.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("comment must reference exactly one target", func(t *testing.T) {
+		ctx, req := commentRequest(member.ID)
+		comment := &models.Comment{ClubID: club.ID, Content: "Nothing to comment on"}
+		assert.Error(t, comment.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("news from another club is rejected", func(t *testing.T) {
+		otherClub := handlers.CreateTestClub(t, outsider, "Other Club")
+		otherNews, err := otherClub.CreateNews("Other", "Other", outsider.ID)
+		require.NoError(t, err)
+
+		ctx, req := commentRequest(member.ID)
+		comment := &models.Comment{ClubID: club.ID, NewsID: &otherNews.ID, Content: "Sneaky"}
+		assert.Error(t, comment.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("disabled news feature blocks comments", func(t *testing.T) {
+		require.NoError(t, db.Exec("UPDATE club_settings SET news_enabled = false WHERE club_id = ?", club.ID).Error)
+		defer db.Exec("UPDATE club_settings SET news_enabled = true WHERE club_id = ?", club.ID)
+
+		ctx, req := commentRequest(member.ID)
+		comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "Hello"}
+		assert.Error(t, comment.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("direct updates and hard deletes are rejected", func(t *testing.T) {
+		comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "Immutable"}
+		createTestComment(t, member.ID, comment)
+
+		ctx, req := commentRequest(member.ID)
+		assert.Error(t, comment.ODataBeforeUpdate(ctx, req))
+		assert.Error(t, comment.ODataBeforeDelete(ctx, req))
+	})
+}
+
+func TestCommentEditAndSoftDelete(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "moderation-owner@example.com")
+	author, _ := handlers.CreateTestUser(t, "moderation-author@example.com")
+	other, _ := handlers.CreateTestUser(t, "moderation-other@example.com")
+	club := handlers.CreateTestClub(t, owner, "Moderation Club")
+	handlers.CreateTestMember(t, author, club, "member")
+	handlers.CreateTestMember(t, other, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET news_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	news, err := club.CreateNews("Rules", "Be nice", owner.ID)
+	require.NoError(t, err)
+
+	comment := &models.Comment{ClubID: club.ID, NewsID: &news.ID, Content: "First"}
+	createTestComment(t, author.ID, comment)
+
+	t.Run("edit marks comment as edited", func(t *testing.T) {
+		require.NoError(t, comment.Edit("First (edited)", author.ID))
+
+		var reloaded models.Comment
+		require.NoError(t, db.Where("id = ?", comment.ID).First(&reloaded).Error)
+		assert.Equal(t, "First (edited)", reloaded.Content)
+		assert.True(t, reloaded.Edited)
+		assert.NotNil(t, reloaded.EditedAt)
+	})
+
+	t.Run("empty content is rejected", func(t *testing.T) {
+		assert.Error(t, comment.Edit("   ", author.ID))
+	})
+
+	t.Run("moderation rights", func(t *testing.T) {
+		assert.True(t, comment.CanModerate(author.ID))
+		assert.True(t, comment.CanModerate(owner.ID))
+		assert.False(t, comment.CanModerate(other.ID))
+	})
+
+	t.Run("reactions are created for the current user", func(t *testing.T) {
+		ctx, req := commentRequest(other.ID)
+		reaction := &models.CommentReaction{CommentID: comment.ID, UserID: author.ID, Emoji: "👍"}
+		require.NoError(t, reaction.ODataBeforeCreate(ctx, req))
+		assert.Equal(t, other.ID, reaction.UserID)
+		require.NoError(t, db.Create(reaction).Error)
+
+		duplicate := &models.CommentReaction{CommentID: comment.ID, UserID: other.ID, Emoji: "👍"}
+		assert.Error(t, db.Create(duplicate).Error)
+
+		ctx, req = commentRequest(author.ID)
+		assert.Error(t, reaction.ODataBeforeDelete(ctx, req), "users cannot remove reactions of others")
+	})
+
+	t.Run("soft delete clears content and reactions", func(t *testing.T) {
+		require.NoError(t, comment.SoftDelete(owner.ID))
+
+		var reloaded models.Comment
+		require.NoError(t, db.Where("id = ?", comment.ID).First(&reloaded).Error)
+		assert.True(t, reloaded.Deleted)
+		assert.Empty(t, reloaded.Content)
+		require.NotNil(t, reloaded.DeletedBy)
+		assert.Equal(t, owner.ID, *reloaded.DeletedBy)
+
+		var reactions int64
+		db.Model(&models.CommentReaction{}).Where("comment_id = ?", comment.ID).Count(&reactions)
+		assert.Zero(t, reactions)
+
+		assert.ErrorIs(t, reloaded.Edit("Back again", author.ID), models.ErrCommentDeleted)
+		assert.ErrorIs(t, reloaded.SoftDelete(owner.ID), models.ErrCommentDeleted)
+	})
+
+	t.Run("deleted comments accept no replies or reactions", func(t *testing.T) {
+		ctx, req := commentRequest(other.ID)
+		reply := &models.Comment{ClubID: club.ID, NewsID: &news.ID, ParentID: &comment.ID, Content: "Reply"}
+		assert.Error(t, reply.ODataBeforeCreate(ctx, req))
+
+		reaction := &models.CommentReaction{CommentID: comment.ID, Emoji: "🎉"}
+		assert.Error(t, reaction.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("deleting news removes its comments", func(t *testing.T) {
+		require.NoError(t, club.DeleteNews(news.ID))
+
+		var count int64
+		db.Model(&models.Comment{}).Where("news_id = ?", news.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
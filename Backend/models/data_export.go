@@ -0,0 +1,357 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/NLstn/civo/encryption"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Data export states
+const (
+	DataExportStatusPending = "pending"
+	DataExportStatusReady   = "ready"
+	DataExportStatusFailed  = "failed"
+	DataExportStatusExpired = "expired"
+)
+
+// DataExportRetention is how long a finished export can be downloaded
+const DataExportRetention = 7 * 24 * time.Hour
+
+var (
+	ErrDataExportNotReady    = errors.New("data export is not ready for download")
+	ErrDataExportInvalidLink = errors.New("download link is invalid or expired")
+)
+
+// DataExport is a user's request for a copy of all data stored about them (GDPR right of access).
+// The archive is built by the process_data_exports job and kept in DataExportArchive.
+type DataExport struct {
+	ID          string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	UserID      string     `json:"UserID" gorm:"type:uuid;not null;index" odata:"auto"`
+	Status      string     `json:"Status" gorm:"not null;default:pending" odata:"auto"` // pending, ready, failed, expired
+	Size        int64      `json:"Size" odata:"auto"`                                   // Archive size in bytes
+	Error       *string    `json:"Error,omitempty" odata:"auto,nullable"`
+	CompletedAt *time.Time `json:"CompletedAt,omitempty" odata:"auto,nullable"`
+	ExpiresAt   *time.Time `json:"ExpiresAt,omitempty" odata:"auto,nullable"` // Download is possible until then
+	CreatedAt   time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+}
+
+// DataExportArchive holds the ZIP file of a data export, separate from the export so listing
+// exports does not load the archives
+type DataExportArchive struct {
+	ExportID  string `gorm:"type:uuid;primaryKey"`
+	Content   []byte `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// BeforeCreate generates UUID for new data exports
+func (de *DataExport) BeforeCreate(tx *gorm.DB) error {
+	if de.ID == "" {
+		de.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// RequestDataExport queues an export of the user's data. A pending export is reused instead
+// of queueing another one.
+func RequestDataExport(userID string) (*DataExport, error) {
+	var export DataExport
+	err := database.Db.Where("user_id = ? AND status = ?", userID, DataExportStatusPending).First(&export).Error
+	if err == nil {
+		return &export, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	export = DataExport{UserID: userID, Status: DataExportStatusPending, CreatedAt: time.Now()}
+	if err := database.Db.Create(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue data export: %w", err)
+	}
+	return &export, nil
+}
+
+// apiKeyExport is the metadata of an API key included in data exports, without its hashes
+type apiKeyExport struct {
+	ID         string     `json:"ID"`
+	Name       string     `json:"Name"`
+	KeyPrefix  string     `json:"KeyPrefix"`
+	LastUsedAt *time.Time `json:"LastUsedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"ExpiresAt,omitempty"`
+	CreatedAt  time.Time  `json:"CreatedAt"`
+}
+
+// BuildDataExportArchive collects everything stored about the user into a ZIP of JSON files
+func BuildDataExportArchive(userID string) ([]byte, error) {
+	var user User
+	if err := database.Db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	var memberships []Member
+	var teamMemberships []TeamMember
+	var rsvps []EventRSVP
+	var fines []Fine
+	var shifts []ShiftMember
+	var notifications []Notification
+	var sessions []UserSession
+	var apiKeys []apiKeyExport
+	var userPrivacy []UserPrivacySettings
+	var memberPrivacy []MemberPrivacySettings
+	var activities []Activity
+	var guardianships []Guardianship
+	var memberFields []MemberFieldValue
+	var membershipHistory []MembershipStatusChange
+
+	queries := []struct {
+		name  string
+		query func() error
+	}{
+		{"memberships", func() error { return database.Db.Unscoped().Where("user_id = ?", userID).Find(&memberships).Error }}, // Including former ones
+		{"team memberships", func() error { return database.Db.Where("user_id = ?", userID).Find(&teamMemberships).Error }},
+		{"RSVPs", func() error { return database.Db.Where("user_id = ?", userID).Find(&rsvps).Error }},
+		{"fines", func() error { return database.Db.Where("user_id = ?", userID).Find(&fines).Error }},
+		{"shifts", func() error { return database.Db.Preload("Shift").Where("user_id = ?", userID).Find(&shifts).Error }},
+		{"notifications", func() error { return database.Db.Where("user_id = ?", userID).Find(&notifications).Error }},
+		{"sessions", func() error { return database.Db.Where("user_id = ?", userID).Find(&sessions).Error }},
+		{"API keys", func() error {
+			return database.Db.Model(&APIKey{}).Where("user_id = ?", userID).Find(&apiKeys).Error
+		}},
+		{"privacy settings", func() error { return database.Db.Where("user_id = ?", userID).Find(&userPrivacy).Error }},
+		{"club privacy settings", func() error {
+			return database.Db.Where("member_id IN (SELECT id FROM members WHERE user_id = ?)", userID).Find(&memberPrivacy).Error
+		}},
+		{"activities", func() error {
+			return database.Db.Where("user_id = ? OR actor_id = ?", userID, userID).Find(&activities).Error
+		}},
+		{"guardianships", func() error {
+			return database.Db.Where("guardian_id = ? OR dependent_id = ?", userID, userID).Find(&guardianships).Error
+		}},
+		{"member fields", func() error {
+			return database.Db.Preload("Field").Where("member_id IN (SELECT id FROM members WHERE user_id = ?)", userID).Find(&memberFields).Error
+		}},
+		{"membership history", func() error {
+			return database.Db.Where("user_id = ?", userID).Order("effective_from ASC").Find(&membershipHistory).Error
+		}},
+	}
+	for _, q := range queries {
+		if err := q.query(); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", q.name, err)
+		}
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", user},
+		{"memberships.json", memberships},
+		{"team_memberships.json", teamMemberships},
+		{"rsvps.json", rsvps},
+		{"fines.json", fines},
+		{"shifts.json", shifts},
+		{"notifications.json", notifications},
+		{"sessions.json", sessions},
+		{"api_keys.json", apiKeys},
+		{"privacy_settings.json", map[string]interface{}{"User": userPrivacy, "Clubs": memberPrivacy}},
+		{"activities.json", activities},
+		{"guardianships.json", guardianships},
+		{"member_fields.json", memberFields},
+		{"membership_history.json", membershipHistory},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// process builds the archive of a pending export and notifies the user
+func (de *DataExport) process() error {
+	content, buildErr := BuildDataExportArchive(de.UserID)
+
+	now := time.Now()
+	de.CompletedAt = &now
+	if buildErr != nil {
+		message := buildErr.Error()
+		de.Status = DataExportStatusFailed
+		de.Error = &message
+		return database.Db.Save(de).Error
+	}
+
+	expiresAt := now.Add(DataExportRetention)
+	de.Status = DataExportStatusReady
+	de.Size = int64(len(content))
+	de.ExpiresAt = &expiresAt
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&DataExportArchive{ExportID: de.ID, Content: content, CreatedAt: now}).Error; err != nil {
+			return err
+		}
+		return tx.Save(de).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = CreateNotification(de.UserID, "data_export_ready", "Your data export is ready",
+		fmt.Sprintf("The export of your personal data can be downloaded until %s.", expiresAt.Format("02.01.2006 15:04")), nil, nil, nil)
+	return nil
+}
+
+// ProcessDataExports builds the archives of pending exports and removes the archives of expired ones.
+// This should be called periodically by the scheduler.
+func ProcessDataExports() error {
+	var pending []DataExport
+	if err := database.Db.Where("status = ?", DataExportStatusPending).Order("created_at ASC").Find(&pending).Error; err != nil {
+		return err
+	}
+	for i := range pending {
+		if err := pending[i].process(); err != nil {
+			log.Printf("failed to process data export %s: %v", pending[i].ID, err)
+		}
+	}
+
+	return database.Db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&DataExport{}).Select("id").Where("status = ? AND expires_at < ?", DataExportStatusReady, time.Now())
+		if err := tx.Where("export_id IN (?)", expired).Delete(&DataExportArchive{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&DataExport{}).Where("status = ? AND expires_at < ?", DataExportStatusReady, time.Now()).
+			Update("status", DataExportStatusExpired).Error
+	})
+}
+
+// downloadSignature signs an export ID and expiry time with the key dedicated to data export links
+func downloadSignature(exportID string, expires int64) (string, error) {
+	key, err := encryption.SigningKey("data-export")
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(exportID + "|" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// DownloadPath returns a signed download path for a ready export, valid until the export expires
+func (de *DataExport) DownloadPath() (string, error) {
+	if de.Status != DataExportStatusReady || de.ExpiresAt == nil {
+		return "", ErrDataExportNotReady
+	}
+	expires := de.ExpiresAt.Unix()
+	signature, err := downloadSignature(de.ID, expires)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature)
+	return fmt.Sprintf("/api/v1/data-exports/%s?%s", de.ID, query.Encode()), nil
+}
+
+// GetSignedDataExport verifies a download link and returns the export's archive
+func GetSignedDataExport(exportID, expiresParam, signature string) ([]byte, error) {
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return nil, ErrDataExportInvalidLink
+	}
+	expected, err := downloadSignature(exportID, expires)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrDataExportInvalidLink
+	}
+
+	var export DataExport
+	if err := database.Db.Where("id = ? AND status = ?", exportID, DataExportStatusReady).First(&export).Error; err != nil {
+		return nil, ErrDataExportInvalidLink
+	}
+	var archive DataExportArchive
+	if err := database.Db.Where("export_id = ?", exportID).First(&archive).Error; err != nil {
+		return nil, ErrDataExportInvalidLink
+	}
+	return archive.Content, nil
+}
+
+// ODataBeforeReadCollection filters data exports to the user's own
+func (de DataExport) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific data export
+func (de DataExport) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return de.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating exports directly; use the RequestDataExport action
+func (de *DataExport) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the RequestDataExport action to request a data export")
+}
+
+// ODataBeforeUpdate prevents changing exports
+func (de *DataExport) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: data exports cannot be changed")
+}
+
+// ODataBeforeDelete lets users delete their own exports together with the archive
+func (de *DataExport) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if de.UserID != userID {
+		return fmt.Errorf("unauthorized: can only delete your own data exports")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("export_id = ?", de.ID).Delete(&DataExportArchive{}).Error; err != nil {
+		return fmt.Errorf("failed to delete data export archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package models_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExport(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	user, _ := handlers.CreateTestUser(t, "export-user@example.com")
+	other, _ := handlers.CreateTestUser(t, "export-other@example.com")
+	club := handlers.CreateTestClub(t, user, "Export Club")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+	require.NoError(t, db.Create(&models.Fine{ClubID: club.ID, UserID: user.ID, Reason: "Late", Amount: 5}).Error)
+
+	var export *models.DataExport
+
+	t.Run("pending request is reused", func(t *testing.T) {
+		var err error
+		export, err = models.RequestDataExport(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DataExportStatusPending, export.Status)
+
+		again, err := models.RequestDataExport(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, export.ID, again.ID)
+	})
+
+	t.Run("download link is not available before the export is built", func(t *testing.T) {
+		_, err := export.DownloadPath()
+		assert.ErrorIs(t, err, models.ErrDataExportNotReady)
+	})
+
+	t.Run("job builds archive and notifies user", func(t *testing.T) {
+		require.NoError(t, models.ProcessDataExports())
+
+		require.NoError(t, db.Where("id = ?", export.ID).First(export).Error)
+		assert.Equal(t, models.DataExportStatusReady, export.Status)
+		require.NotNil(t, export.ExpiresAt)
+		assert.Positive(t, export.Size)
+
+		var notifications int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", user.ID, "data_export_ready").Count(&notifications)
+		assert.Equal(t, int64(1), notifications)
+	})
+
+	t.Run("signed link returns ZIP with user data", func(t *testing.T) {
+		path, err := export.DownloadPath()
+		require.NoError(t, err)
+		link, err := url.Parse(path)
+		require.NoError(t, err)
+
+		content, err := models.GetSignedDataExport(export.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+		require.NoError(t, err)
+
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		files := map[string]string{}
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			_, err = buf.ReadFrom(reader)
+			require.NoError(t, err)
+			reader.Close()
+			files[file.Name] = buf.String()
+		}
+		for _, name := range []string{"user.json", "memberships.json", "team_memberships.json", "rsvps.json", "fines.json",
+			"shifts.json", "notifications.json", "sessions.json", "api_keys.json", "privacy_settings.json", "activities.json", "guardianships.json",
+			"member_fields.json", "membership_history.json"} {
+			assert.Contains(t, files, name)
+		}
+		assert.Contains(t, files["user.json"], user.Email)
+		assert.Contains(t, files["fines.json"], "Late")
+		assert.Contains(t, files["memberships.json"], club.ID)
+	})
+
+	t.Run("tampered or expired links are rejected", func(t *testing.T) {
+		path, err := export.DownloadPath()
+		require.NoError(t, err)
+		link, _ := url.Parse(path)
+		expires := link.Query().Get("expires")
+
+		_, err = models.GetSignedDataExport(export.ID, expires, strings.Repeat("0", 64))
+		assert.ErrorIs(t, err, models.ErrDataExportInvalidLink)
+
+		// Links are not signed with the JWT secret
+		mac := hmac.New(sha256.New, auth.GetJWTSecret())
+		mac.Write([]byte(export.ID + "|" + expires))
+		_, err = models.GetSignedDataExport(export.ID, expires, hex.EncodeToString(mac.Sum(nil)))
+		assert.ErrorIs(t, err, models.ErrDataExportInvalidLink)
+
+		later, _ := strconv.ParseInt(expires, 10, 64)
+		_, err = models.GetSignedDataExport(export.ID, strconv.FormatInt(later+3600, 10), link.Query().Get("signature"))
+		assert.ErrorIs(t, err, models.ErrDataExportInvalidLink)
+
+		past := time.Now().Add(-time.Hour)
+		db.Model(&models.DataExport{}).Where("id = ?", export.ID).Update("expires_at", past)
+		require.NoError(t, db.Where("id = ?", export.ID).First(export).Error)
+		path, err = export.DownloadPath()
+		require.NoError(t, err)
+		link, _ = url.Parse(path)
+		_, err = models.GetSignedDataExport(export.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+		assert.ErrorIs(t, err, models.ErrDataExportInvalidLink)
+	})
+
+	t.Run("job removes expired archives", func(t *testing.T) {
+		require.NoError(t, models.ProcessDataExports())
+
+		require.NoError(t, db.Where("id = ?", export.ID).First(export).Error)
+		assert.Equal(t, models.DataExportStatusExpired, export.Status)
+		var archives int64
+		db.Model(&models.DataExportArchive{}).Where("export_id = ?", export.ID).Count(&archives)
+		assert.Zero(t, archives)
+	})
+
+	t.Run("exports are only visible to their owner", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/DataExports", nil)
+		ctx := context.WithValue(req.Context(), auth.UserIDKey, other.ID)
+		scopes, err := models.DataExport{}.ODataBeforeReadCollection(ctx, req.WithContext(ctx), nil)
+		require.NoError(t, err)
+
+		var visible []models.DataExport
+		require.NoError(t, db.Scopes(scopes...).Find(&visible).Error)
+		assert.Empty(t, visible)
+
+		assert.Error(t, export.ODataBeforeDelete(ctx, req.WithContext(ctx)))
+	})
+}
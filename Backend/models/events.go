@@ -8,13 +8,15 @@ import (
 
 	"github.com/NLstn/civo/auth"
 	"github.com/NLstn/civo/database"
+	"github.com/nlstn/go-odata"
 	"gorm.io/gorm"
 )
 
 type Event struct {
 	ID          string    `json:"ID" gorm:"type:uuid;default:gen_random_uuid();primaryKey" odata:"key"`
 	ClubID      string    `json:"ClubID" gorm:"type:uuid;not null" odata:"required"`
-	TeamID      *string   `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Optional team association
+	TeamID      *string   `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"`         // Optional team association
+	SeasonID    *string   `json:"SeasonID,omitempty" gorm:"type:uuid;index" odata:"nullable"` // Defaults to the season containing StartTime
 	Name        string    `json:"Name" gorm:"not null" odata:"required"`
 	Description *string   `json:"Description,omitempty" gorm:"type:text" odata:"nullable"`
 	Location    *string   `json:"Location,omitempty" gorm:"type:varchar(255)" odata:"nullable"`
@@ -30,10 +32,14 @@ type Event struct {
 	RecurrenceInterval int        `json:"RecurrenceInterval,omitempty" gorm:"column:recurrence_interval;default:1"`                       // Every N weeks/days/months
 	RecurrenceEnd      *time.Time `json:"RecurrenceEnd,omitempty" gorm:"column:recurrence_end" odata:"nullable"`                          // When recurrence stops
 	ParentEventID      *string    `json:"ParentEventID,omitempty" gorm:"column:parent_event_id;type:uuid" odata:"nullable"`               // Links recurring event instances
+	VenueID            *string    `json:"VenueID,omitempty" gorm:"type:uuid;index" odata:"nullable"`                                      // Facility the event takes place at
 
 	// Navigation properties
 	EventRSVPs []EventRSVP `gorm:"foreignKey:EventID" json:"EventRSVPs,omitempty" odata:"nav"`
 	Shifts     []Shift     `gorm:"foreignKey:EventID" json:"Shifts,omitempty" odata:"nav"`
+	Comments   []Comment   `gorm:"foreignKey:EventID" json:"Comments,omitempty" odata:"nav"`
+	Venue      *Venue      `gorm:"foreignKey:VenueID" json:"Venue,omitempty" odata:"nav"`
+	Season     *Season     `gorm:"foreignKey:SeasonID" json:"Season,omitempty" odata:"nav"`
 }
 
 type EventRSVP struct {
@@ -44,11 +50,24 @@ type EventRSVP struct {
 	CreatedAt time.Time `json:"CreatedAt" odata:"immutable"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 
+	// Tracking used by automatic fine rules
+	Attended          *bool      `json:"Attended,omitempty" odata:"auto,nullable"` // Recorded by admins after the event
+	PreviousResponse  *string    `json:"PreviousResponse,omitempty" odata:"auto,nullable"`
+	ResponseChangedAt *time.Time `json:"ResponseChangedAt,omitempty" odata:"auto,nullable"`
+
 	// Navigation properties
 	Event *Event `gorm:"foreignKey:EventID" json:"Event,omitempty" odata:"nav"`
 	User  *User  `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
 }
 
+// BeforeCreate adds the event to the season containing its start time
+func (e *Event) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.SeasonID == nil {
+		e.SeasonID, err = seasonAt(tx, e.ClubID, e.StartTime)
+	}
+	return
+}
+
 // CreateEvent creates a new event for the club
 func (c *Club) CreateEvent(name string, description string, location string, startTime, endTime time.Time, createdBy string) (*Event, error) {
 	event := Event{
@@ -199,6 +218,11 @@ func (c *Club) DeleteEvent(eventID string) error {
 		return err
 	}
 
+	// Remove the discussion attached to the event
+	if err := deleteComments(database.Db, "event_id", eventID); err != nil {
+		return err
+	}
+
 	// Then delete the event
 	return database.Db.Where("id = ? AND club_id = ?", eventID, c.ID).Delete(&Event{}).Error
 }
@@ -215,8 +239,13 @@ func (c *Club) GetEventByID(eventID string) (*Event, error) {
 
 // CreateOrUpdateRSVP creates or updates an RSVP for an event
 func (u *User) CreateOrUpdateRSVP(eventID string, response string) error {
+	return u.createOrUpdateRSVP(database.Db, eventID, response)
+}
+
+// createOrUpdateRSVP creates or updates an RSVP using the given connection or transaction
+func (u *User) createOrUpdateRSVP(tx *gorm.DB, eventID string, response string) error {
 	var rsvp EventRSVP
-	err := database.Db.Where("event_id = ? AND user_id = ?", eventID, u.ID).First(&rsvp).Error
+	err := tx.Where("event_id = ? AND user_id = ?", eventID, u.ID).First(&rsvp).Error
 
 	if err != nil {
 		// Create new RSVP
@@ -225,14 +254,25 @@ func (u *User) CreateOrUpdateRSVP(eventID string, response string) error {
 			UserID:   u.ID,
 			Response: response,
 		}
-		return database.Db.Create(&rsvp).Error
+		return tx.Create(&rsvp).Error
 	} else {
 		// Update existing RSVP
-		rsvp.Response = response
-		return database.Db.Save(&rsvp).Error
+		rsvp.SetResponse(response)
+		return tx.Save(&rsvp).Error
 	}
 }
 
+// SetResponse changes the response and remembers the previous one, so late changes can be detected
+func (er *EventRSVP) SetResponse(response string) {
+	if er.Response != "" && er.Response != response {
+		previous := er.Response
+		now := time.Now()
+		er.PreviousResponse = &previous
+		er.ResponseChangedAt = &now
+	}
+	er.Response = response
+}
+
 // GetUserRSVP returns a user's RSVP for an event
 func (u *User) GetUserRSVP(eventID string) (*EventRSVP, error) {
 	var rsvp EventRSVP
@@ -333,6 +373,11 @@ func (t *Team) DeleteEvent(eventID string) error {
 		return err
 	}
 
+	// Remove the discussion attached to the event
+	if err := deleteComments(database.Db, "event_id", eventID); err != nil {
+		return err
+	}
+
 	// Then delete the event
 	return database.Db.Where("id = ? AND team_id = ?", eventID, t.ID).Delete(&Event{}).Error
 }
@@ -354,9 +399,9 @@ func (e Event) ODataBeforeReadCollection(ctx context.Context, r *http.Request, o
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see events of clubs they belong to and where events feature is enabled
+	// User can only see events of clubs they or their dependents belong to and where events feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true)", userID)
+		return db.Where("club_id IN ("+actingClubsQuery+") AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true)", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -369,9 +414,9 @@ func (e Event) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see events of clubs they belong to and where events feature is enabled
+	// User can only see events of clubs they or their dependents belong to and where events feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true)", userID)
+		return db.Where("club_id IN ("+actingClubsQuery+") AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true)", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -384,6 +429,23 @@ func (e *Event) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	if err := e.validateNew(userID); err != nil {
+		return err
+	}
+
+	// Set CreatedBy and UpdatedBy
+	now := time.Now()
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	e.CreatedBy = userID
+	e.UpdatedBy = userID
+
+	return nil
+}
+
+// validateNew checks that the user may create the event and that it fits the club's teams,
+// seasons and venues. Events created outside the OData API go through it as well.
+func (e *Event) validateNew(userID string) error {
 	// Check if events feature is enabled for the club
 	if err := CheckFeatureEnabled(e.ClubID, "events"); err != nil {
 		return err
@@ -397,20 +459,30 @@ func (e *Event) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		}
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", e.ClubID, userID).First(&existingMember).Error; err != nil {
+	// SECURITY: If SeasonID is provided, verify it belongs to the specified ClubID and is still open
+	if err := validateOpenSeason(e.SeasonID, e.ClubID); err != nil {
+		return err
+	}
+
+	// Check if user holds the events.manage permission in the club
+	if !HasPermission(e.ClubID, userID, PermissionEventsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can create events")
 	}
 
-	// Set CreatedBy and UpdatedBy
-	now := time.Now()
-	e.CreatedAt = now
-	e.UpdatedAt = now
-	e.CreatedBy = userID
-	e.UpdatedBy = userID
+	// Reject double bookings of exclusive venues
+	return e.checkVenueConflict()
+}
 
-	return nil
+// ODataAfterCreate declines the event for absent members and records the new event in the audit log
+func (e *Event) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := e.declineAbsentMembers(tx); err != nil {
+		return err
+	}
+	return auditEntityChange(ctx, r, e.ClubID, "Event", e.ID, AuditOperationCreate, e)
 }
 
 // ODataBeforeUpdate validates event update permissions
@@ -420,6 +492,9 @@ func (e *Event) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "Event", e.ID, e)
+
 	// Load the existing event to enforce immutable fields
 	var existingEvent Event
 	if err := database.Db.First(&existingEvent, "id = ?", e.ID).Error; err != nil {
@@ -431,25 +506,41 @@ func (e *Event) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
+	updated, err := updatedEntity(ctx, e)
+	if err != nil {
+		return err
+	}
+
 	// SECURITY: Prevent changing the club of an existing event (ClubID is immutable)
-	if e.ClubID != existingEvent.ClubID {
+	if updated.ClubID != existingEvent.ClubID {
 		return fmt.Errorf("forbidden: club cannot be changed for an existing event")
 	}
 
 	// SECURITY: If TeamID is being updated, verify it belongs to the (unchanged) ClubID
-	if e.TeamID != nil && *e.TeamID != "" {
+	if updated.TeamID != nil && *updated.TeamID != "" {
 		var team Team
-		if err := database.Db.Where("id = ? AND club_id = ?", *e.TeamID, existingEvent.ClubID).First(&team).Error; err != nil {
+		if err := database.Db.Where("id = ? AND club_id = ?", *updated.TeamID, existingEvent.ClubID).First(&team).Error; err != nil {
 			return fmt.Errorf("unauthorized: team does not belong to the specified club")
 		}
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", existingEvent.ClubID, userID).First(&existingMember).Error; err != nil {
+	// SECURITY: If SeasonID is being updated, verify it belongs to the (unchanged) ClubID and is still open
+	if !sameStringPtr(updated.SeasonID, existingEvent.SeasonID) {
+		if err := validateOpenSeason(updated.SeasonID, existingEvent.ClubID); err != nil {
+			return err
+		}
+	}
+
+	// Check if user holds the events.manage permission in the club
+	if !HasPermission(existingEvent.ClubID, userID, PermissionEventsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update events")
 	}
 
+	// Reject double bookings of exclusive venues at the new time and venue
+	if err := updated.checkVenueConflict(); err != nil {
+		return err
+	}
+
 	// Set UpdatedBy
 	now := time.Now()
 	e.UpdatedAt = now
@@ -458,6 +549,11 @@ func (e *Event) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
+// ODataAfterUpdate records the changes to the event in the audit log
+func (e *Event) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, e.ClubID, "Event", e.ID, AuditOperationUpdate, e)
+}
+
 // ODataBeforeDelete validates event deletion permissions
 func (e *Event) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -470,15 +566,28 @@ func (e *Event) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", e.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the events.manage permission in the club
+	if !HasPermission(e.ClubID, userID, PermissionEventsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete events")
 	}
 
+	// Remove the discussion attached to the event
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := deleteComments(tx, "event_id", e.ID); err != nil {
+		return fmt.Errorf("failed to delete event comments: %w", err)
+	}
+
 	return nil
 }
 
+// ODataAfterDelete records the deleted event in the audit log
+func (e *Event) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, e.ClubID, "Event", e.ID, AuditOperationDelete, e)
+}
+
 // EventRSVP authorization hooks
 // ODataBeforeReadCollection filters RSVPs to only those for events the user can access
 func (er EventRSVP) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
@@ -487,9 +596,10 @@ func (er EventRSVP) ODataBeforeReadCollection(ctx context.Context, r *http.Reque
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see RSVPs for events in clubs they belong to and where events feature is enabled
+	// User can only see RSVPs for events in clubs they belong to and where events feature is enabled.
+	// Guardians also see the RSVPs of their dependents.
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true))", userID)
+		return db.Where("(event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')) OR user_id IN ("+dependentsQuery+")) AND event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true))", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -502,9 +612,10 @@ func (er EventRSVP) ODataBeforeReadEntity(ctx context.Context, r *http.Request,
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see RSVPs for events in clubs they belong to and where events feature is enabled
+	// User can only see RSVPs for events in clubs they belong to and where events feature is enabled.
+	// Guardians also see the RSVPs of their dependents.
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true))", userID)
+		return db.Where("(event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')) OR user_id IN ("+dependentsQuery+")) AND event_id IN (SELECT id FROM events WHERE club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true))", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -528,20 +639,20 @@ func (er *EventRSVP) ODataBeforeCreate(ctx context.Context, r *http.Request) err
 		return err
 	}
 
-	// Check if user is a member of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ?", event.ClubID, userID).First(&existingMember).Error; err != nil {
-		return fmt.Errorf("unauthorized: only club members can RSVP to events")
+	// Set UserID if not already set
+	if er.UserID == "" {
+		er.UserID = userID
 	}
 
-	// Users can only create RSVPs for themselves
-	if er.UserID != "" && er.UserID != userID {
+	// Users can only create RSVPs for themselves or their dependents
+	if !CanActFor(userID, er.UserID) {
 		return fmt.Errorf("unauthorized: cannot create RSVP for another user")
 	}
 
-	// Set UserID if not already set
-	if er.UserID == "" {
-		er.UserID = userID
+	// Check if the responding user is a member of the club
+	var existingMember Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", event.ClubID, er.UserID).First(&existingMember).Error; err != nil {
+		return fmt.Errorf("unauthorized: only club members can RSVP to events")
 	}
 
 	// Set CreatedAt and UpdatedAt
@@ -570,8 +681,8 @@ func (er *EventRSVP) ODataBeforeUpdate(ctx context.Context, r *http.Request) err
 		return err
 	}
 
-	// Users can only update their own RSVPs
-	if er.UserID != userID {
+	// Users can only update their own RSVPs or those of their dependents
+	if !CanActFor(userID, er.UserID) {
 		return fmt.Errorf("unauthorized: can only update your own RSVPs")
 	}
 
@@ -599,16 +710,15 @@ func (er *EventRSVP) ODataBeforeDelete(ctx context.Context, r *http.Request) err
 		return err
 	}
 
-	// Users can only delete their own RSVPs
-	if er.UserID != userID {
-		// Check if user is club admin/owner
+	// Users can only delete their own RSVPs or those of their dependents
+	if !CanActFor(userID, er.UserID) {
+		// Members managing events may delete any RSVP
 		var event Event
 		if err := database.Db.Where("id = ?", er.EventID).First(&event).Error; err != nil {
 			return fmt.Errorf("event not found")
 		}
 
-		var existingMember Member
-		if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", event.ClubID, userID).First(&existingMember).Error; err != nil {
+		if !HasPermission(event.ClubID, userID, PermissionEventsManage) {
 			return fmt.Errorf("unauthorized: can only delete your own RSVPs")
 		}
 	}
@@ -0,0 +1,920 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Billing periods of fee plans
+const (
+	FeePeriodMonthly   = "monthly"
+	FeePeriodQuarterly = "quarterly"
+	FeePeriodYearly    = "yearly"
+)
+
+// Discount types of fee plans
+const (
+	FeeDiscountTypeAge  = "age"
+	FeeDiscountTypeRole = "role"
+)
+
+// OverdueReminderInterval is the minimum time between two reminders for the same invoice
+const OverdueReminderInterval = 7 * 24 * time.Hour
+
+// maxInvoicesPerRun limits how many missed periods are billed for one assignment in a single run
+const maxInvoicesPerRun = 24
+
+var ErrInvoiceAlreadyPaid = errors.New("invoice is already paid")
+
+// FeePlan defines recurring membership dues of a club
+type FeePlan struct {
+	ID          string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Name        string    `json:"Name" gorm:"not null" odata:"required"`
+	Description *string   `json:"Description,omitempty" gorm:"type:text" odata:"nullable"`
+	Amount      float64   `json:"Amount" gorm:"not null" odata:"required"`
+	Period      string    `json:"Period" gorm:"not null" odata:"required"` // "monthly", "quarterly", "yearly"
+	DueDays     int       `json:"DueDays" gorm:"default:14"`               // Days between invoice date and due date
+	Active      bool      `json:"Active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy   string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt   time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy   string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Discounts   []FeeDiscount   `gorm:"foreignKey:FeePlanID" json:"Discounts,omitempty" odata:"nav"`
+	Assignments []FeeAssignment `gorm:"foreignKey:FeePlanID" json:"Assignments,omitempty" odata:"nav"`
+}
+
+// FeeDiscount reduces the amount of a fee plan for members of an age range or role.
+// If several discounts apply, the largest one is used.
+type FeeDiscount struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	FeePlanID string    `json:"FeePlanID" gorm:"type:uuid;not null;index" odata:"required"`
+	Type      string    `json:"Type" gorm:"not null" odata:"required"` // "age" or "role"
+	MinAge    *int      `json:"MinAge,omitempty" odata:"nullable"`     // Inclusive, for age discounts
+	MaxAge    *int      `json:"MaxAge,omitempty" odata:"nullable"`     // Inclusive, for age discounts
+	Role      *string   `json:"Role,omitempty" odata:"nullable"`       // For role discounts
+	Percent   float64   `json:"Percent" gorm:"not null" odata:"required"`
+	CreatedAt time.Time `json:"CreatedAt" odata:"auto,immutable"`
+}
+
+// FeeAssignment assigns a fee plan to a member
+type FeeAssignment struct {
+	ID              string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	FeePlanID       string     `json:"FeePlanID" gorm:"type:uuid;not null;index" odata:"required"`
+	ClubID          string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	UserID          string     `json:"UserID" gorm:"type:uuid;not null;index" odata:"required"`
+	StartDate       time.Time  `json:"StartDate" gorm:"not null"`
+	EndDate         *time.Time `json:"EndDate,omitempty" odata:"nullable"`
+	NextBillingDate time.Time  `json:"NextBillingDate" gorm:"not null" odata:"auto"`
+	CreatedAt       time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy       string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt       time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy       string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	FeePlan *FeePlan `gorm:"foreignKey:FeePlanID" json:"FeePlan,omitempty" odata:"nav"`
+	User    *User    `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// Invoice is a bill for one period of a fee assignment. Invoices are generated by the
+// fee billing job and settled through the RecordPayment action.
+type Invoice struct {
+	ID              string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID          string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	UserID          string     `json:"UserID" gorm:"type:uuid;not null;index" odata:"auto"`
+	FeePlanID       string     `json:"FeePlanID" gorm:"type:uuid;not null" odata:"auto"`
+	FeeAssignmentID string     `json:"FeeAssignmentID" gorm:"type:uuid;not null" odata:"auto"`
+	Reference       string     `json:"Reference" gorm:"not null;uniqueIndex" odata:"auto"` // Payment reference for bank transfers
+	PeriodStart     time.Time  `json:"PeriodStart" odata:"auto"`
+	PeriodEnd       time.Time  `json:"PeriodEnd" odata:"auto"`
+	Amount          float64    `json:"Amount" odata:"auto"`         // Amount to pay after discounts
+	DiscountAmount  float64    `json:"DiscountAmount" odata:"auto"` // Amount deducted by discounts
+	DueDate         time.Time  `json:"DueDate" odata:"auto"`
+	Paid            bool       `json:"Paid" gorm:"default:false" odata:"auto"`
+	PaidAt          *time.Time `json:"PaidAt,omitempty" odata:"auto,nullable"`
+	ReminderCount   int        `json:"ReminderCount" gorm:"default:0" odata:"auto"`
+	LastReminderAt  *time.Time `json:"LastReminderAt,omitempty" odata:"auto,nullable"`
+	CreatedAt       time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	UpdatedAt       time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy       string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	User    *User    `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+	FeePlan *FeePlan `gorm:"foreignKey:FeePlanID" json:"FeePlan,omitempty" odata:"nav"`
+}
+
+// FeeStatement summarizes the invoices of a member in a club
+type FeeStatement struct {
+	ClubID      string    `json:"ClubID"`
+	UserID      string    `json:"UserID"`
+	TotalBilled float64   `json:"TotalBilled"`
+	TotalPaid   float64   `json:"TotalPaid"`
+	Outstanding float64   `json:"Outstanding"`
+	Overdue     float64   `json:"Overdue"`
+	Invoices    []Invoice `json:"Invoices"`
+}
+
+// BeforeCreate generates UUID for new fee plans
+func (fp *FeePlan) BeforeCreate(tx *gorm.DB) error {
+	if fp.ID == "" {
+		fp.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new fee discounts
+func (fd *FeeDiscount) BeforeCreate(tx *gorm.DB) error {
+	if fd.ID == "" {
+		fd.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new fee assignments
+func (fa *FeeAssignment) BeforeCreate(tx *gorm.DB) error {
+	if fa.ID == "" {
+		fa.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID and payment reference for new invoices
+func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	if i.Reference == "" {
+		i.Reference = "FEE-" + strings.ToUpper(strings.ReplaceAll(i.ID, "-", "")[:12])
+	}
+	return nil
+}
+
+// isValidFeePeriod validates fee plan periods
+func isValidFeePeriod(period string) bool {
+	switch period {
+	case FeePeriodMonthly, FeePeriodQuarterly, FeePeriodYearly:
+		return true
+	}
+	return false
+}
+
+// periodMonths returns the length of a billing period in months
+func (fp *FeePlan) periodMonths() int {
+	switch fp.Period {
+	case FeePeriodQuarterly:
+		return 3
+	case FeePeriodYearly:
+		return 12
+	default:
+		return 1
+	}
+}
+
+// NextPeriodStart returns the start of the billing period following the one starting at start.
+// Periods are counted from anchor, the start of the first period, so month-end start dates
+// do not drift: a plan starting on Jan 31 is billed on Feb 28 (or 29) and then on Mar 31.
+func (fp *FeePlan) NextPeriodStart(anchor, start time.Time) time.Time {
+	months := fp.periodMonths()
+	elapsed := (start.Year()-anchor.Year())*12 + int(start.Month()) - int(anchor.Month())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return addMonthsClamped(anchor, (elapsed/months+1)*months)
+}
+
+// addMonthsClamped adds months to t, clamping the day to the last day of the resulting month
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// ageAt returns the age in full years at the given date
+func ageAt(birthDate, date time.Time) int {
+	age := date.Year() - birthDate.Year()
+	if date.Month() < birthDate.Month() || (date.Month() == birthDate.Month() && date.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// applies checks whether the discount applies to a member with the given role and birth date
+func (fd *FeeDiscount) applies(role string, birthDate *time.Time, date time.Time) bool {
+	switch fd.Type {
+	case FeeDiscountTypeRole:
+		return fd.Role != nil && *fd.Role == role
+	case FeeDiscountTypeAge:
+		if birthDate == nil {
+			return false
+		}
+		age := ageAt(*birthDate, date)
+		if fd.MinAge != nil && age < *fd.MinAge {
+			return false
+		}
+		if fd.MaxAge != nil && age > *fd.MaxAge {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// AmountFor returns the amount and the discount for a member at the given date
+func (fp *FeePlan) AmountFor(userID string, date time.Time) (float64, float64, error) {
+	var discounts []FeeDiscount
+	if err := database.Db.Where("fee_plan_id = ?", fp.ID).Find(&discounts).Error; err != nil {
+		return 0, 0, err
+	}
+	if len(discounts) == 0 {
+		return fp.Amount, 0, nil
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", fp.ClubID, userID).First(&member).Error; err != nil {
+		return 0, 0, fmt.Errorf("user is not a member of the club")
+	}
+	var user User
+	if err := database.Db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return 0, 0, err
+	}
+
+	percent := 0.0
+	for _, discount := range discounts {
+		if discount.Percent > percent && discount.applies(member.Role, user.BirthDate, date) {
+			percent = discount.Percent
+		}
+	}
+
+	discount := roundCents(fp.Amount * percent / 100)
+	return roundCents(fp.Amount - discount), discount, nil
+}
+
+// roundCents rounds an amount to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// GenerateFeeInvoices creates invoices for all fee assignments whose billing date has been reached.
+// Missed periods are caught up, so each period is billed exactly once.
+func GenerateFeeInvoices() error {
+	now := time.Now()
+
+	var assignments []FeeAssignment
+	err := database.Db.Where("next_billing_date <= ?", now).
+		Where("end_date IS NULL OR next_billing_date < end_date").
+		Where("fee_plan_id IN (SELECT id FROM fee_plans WHERE active = ?)", true).
+		Find(&assignments).Error
+	if err != nil {
+		return fmt.Errorf("failed to load fee assignments: %w", err)
+	}
+
+	generated := 0
+	for i := range assignments {
+		count, err := assignments[i].generateDueInvoices(now)
+		if err != nil {
+			log.Printf("Failed to generate invoices for fee assignment %s: %v", assignments[i].ID, err)
+			continue
+		}
+		generated += count
+	}
+
+	if generated > 0 {
+		log.Printf("Generated %d fee invoices", generated)
+	}
+	return nil
+}
+
+// generateDueInvoices bills all periods of the assignment that started before now
+func (fa *FeeAssignment) generateDueInvoices(now time.Time) (int, error) {
+	var plan FeePlan
+	if err := database.Db.Where("id = ?", fa.FeePlanID).First(&plan).Error; err != nil {
+		return 0, fmt.Errorf("fee plan not found")
+	}
+
+	// Former members are not billed
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", fa.ClubID, fa.UserID).First(&member).Error; err != nil {
+		return 0, nil
+	}
+
+	generated := 0
+	for generated < maxInvoicesPerRun && !fa.NextBillingDate.After(now) {
+		if fa.EndDate != nil && !fa.NextBillingDate.Before(*fa.EndDate) {
+			break
+		}
+
+		amount, discount, err := plan.AmountFor(fa.UserID, fa.NextBillingDate)
+		if err != nil {
+			return generated, err
+		}
+
+		periodStart := fa.NextBillingDate
+		periodEnd := plan.NextPeriodStart(fa.StartDate, periodStart)
+		invoice := Invoice{
+			ClubID:          fa.ClubID,
+			UserID:          fa.UserID,
+			FeePlanID:       plan.ID,
+			FeeAssignmentID: fa.ID,
+			PeriodStart:     periodStart,
+			PeriodEnd:       periodEnd,
+			Amount:          amount,
+			DiscountAmount:  discount,
+			DueDate:         now.AddDate(0, 0, plan.DueDays),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+
+		err = database.Db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&invoice).Error; err != nil {
+				return err
+			}
+			return tx.Model(fa).Update("next_billing_date", periodEnd).Error
+		})
+		if err != nil {
+			return generated, err
+		}
+		fa.NextBillingDate = periodEnd
+		generated++
+
+		// Notification failures must not stop billing
+		_ = invoice.sendFeeNotification("fee_invoice", "New membership fee invoice",
+			fmt.Sprintf("An invoice of %.2f for %s is due on %s (reference %s).", invoice.Amount, plan.Name, invoice.DueDate.Format("2006-01-02"), invoice.Reference))
+	}
+
+	return generated, nil
+}
+
+// SendOverdueFeeReminders notifies members about unpaid invoices past their due date.
+// Reminders for the same invoice are repeated at most every OverdueReminderInterval.
+func SendOverdueFeeReminders() error {
+	now := time.Now()
+
+	var invoices []Invoice
+	err := database.Db.Where("paid = ? AND due_date < ?", false, now).
+		Where("last_reminder_at IS NULL OR last_reminder_at < ?", now.Add(-OverdueReminderInterval)).
+		Find(&invoices).Error
+	if err != nil {
+		return fmt.Errorf("failed to load overdue invoices: %w", err)
+	}
+
+	for _, invoice := range invoices {
+		message := fmt.Sprintf("Your invoice %s of %.2f was due on %s and is still unpaid.", invoice.Reference, invoice.Amount, invoice.DueDate.Format("2006-01-02"))
+		if err := invoice.sendFeeNotification("fee_overdue", "Membership fee overdue", message); err != nil {
+			log.Printf("Failed to send overdue reminder for invoice %s: %v", invoice.ID, err)
+		}
+		if err := database.Db.Model(&invoice).Updates(map[string]interface{}{
+			"reminder_count":   invoice.ReminderCount + 1,
+			"last_reminder_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update invoice reminder: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sendFeeNotification creates the in-app notification if the member enabled it
+func (i *Invoice) sendFeeNotification(notificationType, title, message string) error {
+	// Get user notification preferences
+	preferences, err := GetUserNotificationPreferences(i.UserID)
+	if err != nil {
+		// If preferences don't exist, create default ones and continue
+		preferences, err = CreateDefaultUserNotificationPreferences(i.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification preferences: %v", err)
+		}
+	}
+
+	// Send in-app notification if enabled
+	if preferences.FeeDueInApp {
+		if err := CreateNotification(i.UserID, notificationType, title, message, &i.ClubID, nil, nil); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// RecordPayment marks the invoice as paid
+func (i *Invoice) RecordPayment(paidAt time.Time, recordedBy string) error {
+	if i.Paid {
+		return ErrInvoiceAlreadyPaid
+	}
+
+	now := time.Now()
+	err := database.Db.Model(i).Updates(map[string]interface{}{
+		"paid":       true,
+		"paid_at":    paidAt,
+		"updated_at": now,
+		"updated_by": recordedBy,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	i.Paid = true
+	i.PaidAt = &paidAt
+	return nil
+}
+
+// GetFeeStatement returns all invoices of a member in a club with totals
+func GetFeeStatement(clubID, userID string) (FeeStatement, error) {
+	statement := FeeStatement{ClubID: clubID, UserID: userID, Invoices: []Invoice{}}
+
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, userID).Order("period_start ASC").Find(&statement.Invoices).Error; err != nil {
+		return statement, err
+	}
+
+	now := time.Now()
+	for _, invoice := range statement.Invoices {
+		statement.TotalBilled += invoice.Amount
+		if invoice.Paid {
+			statement.TotalPaid += invoice.Amount
+			continue
+		}
+		statement.Outstanding += invoice.Amount
+		if invoice.DueDate.Before(now) {
+			statement.Overdue += invoice.Amount
+		}
+	}
+
+	statement.TotalBilled = roundCents(statement.TotalBilled)
+	statement.TotalPaid = roundCents(statement.TotalPaid)
+	statement.Outstanding = roundCents(statement.Outstanding)
+	statement.Overdue = roundCents(statement.Overdue)
+
+	return statement, nil
+}
+
+// isFeeAdmin checks whether the user holds the fines.manage permission in the club
+func isFeeAdmin(clubID, userID string) bool {
+	return HasPermission(clubID, userID, PermissionFinesManage)
+}
+
+// validate checks the fee plan values
+func (fp *FeePlan) validate() error {
+	fp.Name = strings.TrimSpace(fp.Name)
+	if fp.Name == "" {
+		return fmt.Errorf("fee plan name cannot be empty")
+	}
+	if fp.Amount <= 0 {
+		return fmt.Errorf("fee plan amount must be positive")
+	}
+	if !isValidFeePeriod(fp.Period) {
+		return fmt.Errorf("invalid period: must be 'monthly', 'quarterly', or 'yearly'")
+	}
+	if fp.DueDays < 0 {
+		return fmt.Errorf("due days cannot be negative")
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection filters fee plans to clubs the user belongs to
+func (fp FeePlan) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific fee plan
+func (fp FeePlan) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return fp.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates fee plan creation permissions
+func (fp *FeePlan) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(fp.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create fee plans")
+	}
+
+	if err := fp.validate(); err != nil {
+		return err
+	}
+
+	// Set audit fields
+	now := time.Now()
+	fp.CreatedAt = now
+	fp.UpdatedAt = now
+	fp.CreatedBy = userID
+	fp.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates fee plan update permissions.
+// Changes only affect invoices generated afterwards.
+func (fp *FeePlan) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(fp.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update fee plans")
+	}
+
+	updated, err := updatedEntity(ctx, fp)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving fee plans to another club
+	if updated.ClubID != fp.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing fee plan")
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	// Set UpdatedBy
+	now := time.Now()
+	fp.UpdatedAt = now
+	fp.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates fee plan deletion. Plans with invoices must be deactivated instead.
+func (fp *FeePlan) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(fp.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete fee plans")
+	}
+
+	var invoices int64
+	database.Db.Model(&Invoice{}).Where("fee_plan_id = ?", fp.ID).Count(&invoices)
+	if invoices > 0 {
+		return fmt.Errorf("fee plan has invoices: deactivate it instead")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("fee_plan_id = ?", fp.ID).Delete(&FeeDiscount{}).Error; err != nil {
+		return fmt.Errorf("failed to delete fee discounts: %w", err)
+	}
+	if err := tx.Where("fee_plan_id = ?", fp.ID).Delete(&FeeAssignment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete fee assignments: %w", err)
+	}
+
+	return nil
+}
+
+// loadFeePlan loads the fee plan and verifies the user is an admin of its club
+func loadFeePlan(feePlanID, userID string) (*FeePlan, error) {
+	var plan FeePlan
+	if err := database.Db.Where("id = ?", feePlanID).First(&plan).Error; err != nil {
+		return nil, fmt.Errorf("fee plan not found")
+	}
+	if !isFeeAdmin(plan.ClubID, userID) {
+		return nil, fmt.Errorf("unauthorized: only admins and owners can manage fee plans")
+	}
+	return &plan, nil
+}
+
+// ODataBeforeReadCollection filters fee discounts to clubs the user belongs to
+func (fd FeeDiscount) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("fee_plan_id IN (SELECT id FROM fee_plans WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former'))", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific fee discount
+func (fd FeeDiscount) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return fd.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// validate checks the discount values
+func (fd *FeeDiscount) validate() error {
+	if fd.Percent <= 0 || fd.Percent > 100 {
+		return fmt.Errorf("discount percent must be between 0 and 100")
+	}
+
+	switch fd.Type {
+	case FeeDiscountTypeAge:
+		if fd.MinAge == nil && fd.MaxAge == nil {
+			return fmt.Errorf("age discounts require a minimum or maximum age")
+		}
+		if fd.MinAge != nil && fd.MaxAge != nil && *fd.MinAge > *fd.MaxAge {
+			return fmt.Errorf("minimum age cannot be greater than maximum age")
+		}
+	case FeeDiscountTypeRole:
+		if fd.Role == nil || (*fd.Role != "owner" && *fd.Role != "admin" && *fd.Role != "member") {
+			return fmt.Errorf("role discounts require a valid role")
+		}
+	default:
+		return fmt.Errorf("invalid discount type: must be 'age' or 'role'")
+	}
+	return nil
+}
+
+// ODataBeforeCreate validates fee discount creation permissions
+func (fd *FeeDiscount) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := loadFeePlan(fd.FeePlanID, userID); err != nil {
+		return err
+	}
+
+	if err := fd.validate(); err != nil {
+		return err
+	}
+	if fd.Type == FeeDiscountTypeAge {
+		fd.Role = nil
+	} else {
+		fd.MinAge = nil
+		fd.MaxAge = nil
+	}
+
+	fd.CreatedAt = time.Now()
+
+	return nil
+}
+
+// ODataBeforeUpdate validates fee discount update permissions
+func (fd *FeeDiscount) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := loadFeePlan(fd.FeePlanID, userID); err != nil {
+		return err
+	}
+
+	updated, err := updatedEntity(ctx, fd)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving discounts to another fee plan
+	if updated.FeePlanID != fd.FeePlanID {
+		return fmt.Errorf("forbidden: fee plan cannot be changed for an existing discount")
+	}
+
+	return updated.validate()
+}
+
+// ODataBeforeDelete validates fee discount deletion permissions
+func (fd *FeeDiscount) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	_, err := loadFeePlan(fd.FeePlanID, userID)
+	return err
+}
+
+// feeOwnerOrAdminScope restricts records to the user's own or to clubs in which the user manages fines.
+// Arguments: user ID, user ID, user ID, fines.manage permission.
+const feeOwnerOrAdminScope = "user_id = ? OR club_id IN (" + permittedClubsQuery + ")"
+
+// ODataBeforeReadCollection filters fee assignments to the user's own or those of administered clubs
+func (fa FeeAssignment) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(feeOwnerOrAdminScope, userID, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific fee assignment
+func (fa FeeAssignment) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return fa.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates fee assignment creation permissions
+func (fa *FeeAssignment) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	plan, err := loadFeePlan(fa.FeePlanID, userID)
+	if err != nil {
+		return err
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", plan.ClubID, fa.UserID).First(&member).Error; err != nil {
+		return fmt.Errorf("user is not a member of the club")
+	}
+
+	now := time.Now()
+	var existing int64
+	database.Db.Model(&FeeAssignment{}).
+		Where("fee_plan_id = ? AND user_id = ?", fa.FeePlanID, fa.UserID).
+		Where("end_date IS NULL OR end_date > ?", now).
+		Count(&existing)
+	if existing > 0 {
+		return fmt.Errorf("member is already assigned to this fee plan")
+	}
+
+	if fa.StartDate.IsZero() {
+		fa.StartDate = now
+	}
+	if fa.EndDate != nil && !fa.EndDate.After(fa.StartDate) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	// Set derived and audit fields
+	fa.ClubID = plan.ClubID
+	fa.NextBillingDate = fa.StartDate
+	fa.CreatedAt = now
+	fa.UpdatedAt = now
+	fa.CreatedBy = userID
+	fa.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates fee assignment update permissions (e.g. setting an end date)
+func (fa *FeeAssignment) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(fa.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update fee assignments")
+	}
+
+	updated, err := updatedEntity(ctx, fa)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving assignments to another club, fee plan or member
+	if updated.ClubID != fa.ClubID || updated.FeePlanID != fa.FeePlanID || updated.UserID != fa.UserID {
+		return fmt.Errorf("forbidden: club, fee plan and member cannot be changed for an existing fee assignment")
+	}
+
+	if updated.EndDate != nil && !updated.EndDate.After(updated.StartDate) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+
+	// Reopening an ended assignment must not leave the member assigned twice
+	now := time.Now()
+	if updated.EndDate == nil || updated.EndDate.After(now) {
+		var existing int64
+		if err := tx.Model(&FeeAssignment{}).
+			Where("id <> ? AND fee_plan_id = ? AND user_id = ?", fa.ID, fa.FeePlanID, fa.UserID).
+			Where("end_date IS NULL OR end_date > ?", now).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return fmt.Errorf("member is already assigned to this fee plan")
+		}
+	}
+
+	// Billed periods are based on the start date, so it is fixed once the first invoice exists
+	if !updated.StartDate.Equal(fa.StartDate) {
+		var invoices int64
+		if err := tx.Model(&Invoice{}).Where("fee_assignment_id = ?", fa.ID).Count(&invoices).Error; err != nil {
+			return err
+		}
+		if invoices > 0 {
+			return fmt.Errorf("start date cannot be changed once invoices have been generated")
+		}
+	}
+
+	// Set UpdatedBy
+	fa.UpdatedAt = now
+	fa.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate restarts billing at the start date as long as no invoice has been generated
+func (fa *FeeAssignment) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+
+	err := tx.Model(&FeeAssignment{}).
+		Where("id = ? AND NOT EXISTS (SELECT 1 FROM invoices WHERE fee_assignment_id = ?)", fa.ID, fa.ID).
+		Update("next_billing_date", gorm.Expr("start_date")).Error
+	if err != nil {
+		return fmt.Errorf("failed to reschedule billing: %w", err)
+	}
+	return nil
+}
+
+// ODataBeforeDelete validates fee assignment deletion permissions
+func (fa *FeeAssignment) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(fa.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete fee assignments")
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters invoices to the user's own or those of administered clubs
+func (i Invoice) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(feeOwnerOrAdminScope, userID, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific invoice
+func (i Invoice) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return i.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation of invoices; they are generated by the billing job
+func (i *Invoice) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: invoices are generated from fee assignments")
+}
+
+// ODataBeforeUpdate prevents direct updates of invoices; use the RecordPayment action instead
+func (i *Invoice) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the RecordPayment action to settle invoices")
+}
+
+// ODataBeforeDelete allows admins to cancel unpaid invoices
+func (i *Invoice) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(i.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can cancel invoices")
+	}
+
+	if i.Paid {
+		return ErrInvoiceAlreadyPaid
+	}
+
+	return nil
+}
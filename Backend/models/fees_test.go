@@ -0,0 +1,271 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func feeRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/FeePlans", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func createTestFeePlan(t *testing.T, userID string, plan *models.FeePlan) {
+	t.Helper()
+	ctx, req := feeRequest(userID)
+	require.NoError(t, plan.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(plan).Error)
+}
+
+func assignTestFeePlan(t *testing.T, adminID string, assignment *models.FeeAssignment) {
+	t.Helper()
+	ctx, req := feeRequest(adminID)
+	require.NoError(t, assignment.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(assignment).Error)
+}
+
+func TestFeePlanValidation(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+
+	owner, ownerToken := handlers.CreateTestUser(t, "fee-validation-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "fee-validation-member@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "fee-validation-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Fee Validation Club")
+	handlers.CreateTestMember(t, member, club, "member")
+
+	t.Run("members cannot create fee plans", func(t *testing.T) {
+		ctx, req := feeRequest(member.ID)
+		plan := &models.FeePlan{ClubID: club.ID, Name: "Adults", Amount: 10, Period: models.FeePeriodMonthly}
+		assert.Error(t, plan.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("invalid period and amount are rejected", func(t *testing.T) {
+		ctx, req := feeRequest(owner.ID)
+		plan := &models.FeePlan{ClubID: club.ID, Name: "Adults", Amount: 10, Period: "weekly"}
+		assert.Error(t, plan.ODataBeforeCreate(ctx, req))
+		plan = &models.FeePlan{ClubID: club.ID, Name: "Adults", Amount: 0, Period: models.FeePeriodMonthly}
+		assert.Error(t, plan.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("only club members can be assigned", func(t *testing.T) {
+		plan := &models.FeePlan{ClubID: club.ID, Name: "Adults", Amount: 10, Period: models.FeePeriodMonthly}
+		createTestFeePlan(t, owner.ID, plan)
+
+		ctx, req := feeRequest(owner.ID)
+		assignment := &models.FeeAssignment{FeePlanID: plan.ID, UserID: outsider.ID}
+		assert.Error(t, assignment.ODataBeforeCreate(ctx, req))
+
+		assignTestFeePlan(t, owner.ID, &models.FeeAssignment{FeePlanID: plan.ID, UserID: member.ID})
+		duplicate := &models.FeeAssignment{FeePlanID: plan.ID, UserID: member.ID}
+		assert.Error(t, duplicate.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("patched plans, discounts and assignments are validated", func(t *testing.T) {
+		otherClub := handlers.CreateTestClub(t, owner, "Other Fee Club")
+		plan := &models.FeePlan{ClubID: club.ID, Name: "Youth", Amount: 5, Period: models.FeePeriodYearly}
+		createTestFeePlan(t, owner.ID, plan)
+		otherPlan := &models.FeePlan{ClubID: otherClub.ID, Name: "Other", Amount: 5, Period: models.FeePeriodYearly}
+		createTestFeePlan(t, owner.ID, otherPlan)
+		maxAge := 18
+		discount := &models.FeeDiscount{FeePlanID: plan.ID, Type: models.FeeDiscountTypeAge, MaxAge: &maxAge, Percent: 50}
+		ctx, req := feeRequest(owner.ID)
+		require.NoError(t, discount.ODataBeforeCreate(ctx, req))
+		require.NoError(t, handlers.GetDB().Create(discount).Error)
+		assignment := &models.FeeAssignment{FeePlanID: plan.ID, UserID: member.ID}
+		assignTestFeePlan(t, owner.ID, assignment)
+
+		patches := map[string][]map[string]interface{}{
+			"/FeePlans(" + plan.ID + ")": {
+				{"ClubID": otherClub.ID},
+				{"Amount": -5},
+				{"Period": "weekly"},
+				{"Name": " "},
+				{"DueDays": -1},
+			},
+			"/FeeDiscounts(" + discount.ID + ")": {
+				{"FeePlanID": otherPlan.ID},
+				{"Percent": 150},
+				{"Type": "height"},
+			},
+			"/FeeAssignments(" + assignment.ID + ")": {
+				{"FeePlanID": otherPlan.ID},
+				{"UserID": outsider.ID},
+			},
+		}
+		for path, invalid := range patches {
+			for _, patch := range invalid {
+				w := odataRequest(t, ownerToken, http.MethodPatch, path, patch)
+				assert.Equal(t, http.StatusForbidden, w.Code, "%s %v", path, patch)
+			}
+		}
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, "/FeePlans("+plan.ID+")", map[string]interface{}{"Amount": 7.5})
+		require.Less(t, w.Code, 300, w.Body.String())
+		var stored models.FeePlan
+		require.NoError(t, handlers.GetDB().First(&stored, "id = ?", plan.ID).Error)
+		assert.Equal(t, 7.5, stored.Amount)
+		assert.Equal(t, club.ID, stored.ClubID)
+	})
+
+	t.Run("assignment updates keep the member and reschedule billing", func(t *testing.T) {
+		plan := &models.FeePlan{ClubID: club.ID, Name: "Seniors", Amount: 12, Period: models.FeePeriodMonthly}
+		createTestFeePlan(t, owner.ID, plan)
+		endedAt := time.Now().AddDate(0, -1, 0)
+		ended := &models.FeeAssignment{FeePlanID: plan.ID, UserID: member.ID, StartDate: endedAt.AddDate(-1, 0, 0), EndDate: &endedAt}
+		assignTestFeePlan(t, owner.ID, ended)
+		start := time.Now().AddDate(0, 0, 7).Truncate(time.Second)
+		assignment := &models.FeeAssignment{FeePlanID: plan.ID, UserID: member.ID, StartDate: start}
+		assignTestFeePlan(t, owner.ID, assignment)
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, "/FeeAssignments("+ended.ID+")", map[string]interface{}{"EndDate": nil})
+		assert.Equal(t, http.StatusForbidden, w.Code, "reopening would assign the member twice")
+
+		put := func(startDate time.Time) map[string]interface{} {
+			return map[string]interface{}{"FeePlanID": plan.ID, "ClubID": club.ID, "UserID": member.ID, "StartDate": startDate.Format(time.RFC3339)}
+		}
+		path := "/FeeAssignments(" + assignment.ID + ")"
+		moved := start.AddDate(0, 0, 7)
+		w = odataRequest(t, ownerToken, http.MethodPut, path, put(moved))
+		require.Less(t, w.Code, 300, w.Body.String())
+		var stored models.FeeAssignment
+		require.NoError(t, handlers.GetDB().First(&stored, "id = ?", assignment.ID).Error)
+		assert.True(t, stored.NextBillingDate.Equal(moved), "billing restarts at the new start date")
+
+		invoice := models.Invoice{ClubID: club.ID, UserID: member.ID, FeePlanID: plan.ID, FeeAssignmentID: assignment.ID, Amount: 12, DueDate: moved}
+		require.NoError(t, handlers.GetDB().Create(&invoice).Error)
+		w = odataRequest(t, ownerToken, http.MethodPut, path, put(moved.AddDate(0, 0, 7)))
+		assert.Equal(t, http.StatusForbidden, w.Code, "billed assignments keep their start date")
+	})
+
+	t.Run("invoices cannot be written directly", func(t *testing.T) {
+		ctx, req := feeRequest(owner.ID)
+		invoice := &models.Invoice{ClubID: club.ID, UserID: member.ID}
+		assert.Error(t, invoice.ODataBeforeCreate(ctx, req))
+		assert.Error(t, invoice.ODataBeforeUpdate(ctx, req))
+	})
+}
+
+func TestFeePlanNextPeriodStart(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	monthly := &models.FeePlan{Period: models.FeePeriodMonthly}
+	quarterly := &models.FeePlan{Period: models.FeePeriodQuarterly}
+	yearly := &models.FeePlan{Period: models.FeePeriodYearly}
+
+	t.Run("month-end start dates are clamped without drifting", func(t *testing.T) {
+		anchor := date(2026, time.January, 31)
+		feb := monthly.NextPeriodStart(anchor, anchor)
+		assert.Equal(t, date(2026, time.February, 28), feb)
+		mar := monthly.NextPeriodStart(anchor, feb)
+		assert.Equal(t, date(2026, time.March, 31), mar)
+		assert.Equal(t, date(2026, time.April, 30), monthly.NextPeriodStart(anchor, mar))
+		assert.Equal(t, date(2028, time.February, 29), monthly.NextPeriodStart(anchor, date(2028, time.January, 31)))
+	})
+
+	t.Run("quarterly and yearly periods count from the start date", func(t *testing.T) {
+		anchor := date(2026, time.November, 30)
+		feb := quarterly.NextPeriodStart(anchor, anchor)
+		assert.Equal(t, date(2027, time.February, 28), feb)
+		assert.Equal(t, date(2027, time.May, 30), quarterly.NextPeriodStart(anchor, feb))
+
+		leap := date(2028, time.February, 29)
+		assert.Equal(t, date(2029, time.February, 28), yearly.NextPeriodStart(leap, leap))
+		assert.Equal(t, date(2032, time.February, 29), yearly.NextPeriodStart(leap, date(2031, time.February, 28)))
+	})
+}
+
+func TestFeeInvoiceGeneration(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "fee-owner@example.com")
+	adult, _ := handlers.CreateTestUser(t, "fee-adult@example.com")
+	youth, _ := handlers.CreateTestUser(t, "fee-youth@example.com")
+	club := handlers.CreateTestClub(t, owner, "Fee Club")
+	handlers.CreateTestMember(t, adult, club, "member")
+	handlers.CreateTestMember(t, youth, club, "member")
+
+	birthDate := time.Now().AddDate(-12, 0, 0)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", youth.ID).Update("birth_date", birthDate).Error)
+
+	plan := &models.FeePlan{ClubID: club.ID, Name: "Monthly dues", Amount: 20, Period: models.FeePeriodMonthly, DueDays: 14}
+	createTestFeePlan(t, owner.ID, plan)
+
+	maxAge := 17
+	ctx, req := feeRequest(owner.ID)
+	discount := &models.FeeDiscount{FeePlanID: plan.ID, Type: models.FeeDiscountTypeAge, MaxAge: &maxAge, Percent: 50}
+	require.NoError(t, discount.ODataBeforeCreate(ctx, req))
+	require.NoError(t, db.Create(discount).Error)
+
+	// Started two months ago, so three periods are due
+	start := time.Now().AddDate(0, -2, 0).Add(-time.Hour)
+	assignTestFeePlan(t, owner.ID, &models.FeeAssignment{FeePlanID: plan.ID, UserID: adult.ID, StartDate: start})
+	assignTestFeePlan(t, owner.ID, &models.FeeAssignment{FeePlanID: plan.ID, UserID: youth.ID, StartDate: start})
+
+	require.NoError(t, models.GenerateFeeInvoices())
+
+	var adultInvoices []models.Invoice
+	require.NoError(t, db.Where("user_id = ?", adult.ID).Order("period_start ASC").Find(&adultInvoices).Error)
+	require.Len(t, adultInvoices, 3)
+	assert.Equal(t, 20.0, adultInvoices[0].Amount)
+	assert.NotEmpty(t, adultInvoices[0].Reference)
+	assert.True(t, adultInvoices[1].PeriodStart.Equal(adultInvoices[0].PeriodEnd))
+
+	var youthInvoice models.Invoice
+	require.NoError(t, db.Where("user_id = ?", youth.ID).First(&youthInvoice).Error)
+	assert.Equal(t, 10.0, youthInvoice.Amount)
+	assert.Equal(t, 10.0, youthInvoice.DiscountAmount)
+
+	t.Run("periods are billed only once", func(t *testing.T) {
+		require.NoError(t, models.GenerateFeeInvoices())
+		var count int64
+		db.Model(&models.Invoice{}).Where("user_id = ?", adult.ID).Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("members are notified", func(t *testing.T) {
+		var count int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", adult.ID, "fee_invoice").Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("statement and overdue reminders", func(t *testing.T) {
+		require.NoError(t, adultInvoices[0].RecordPayment(time.Now(), owner.ID))
+		assert.ErrorIs(t, adultInvoices[0].RecordPayment(time.Now(), owner.ID), models.ErrInvoiceAlreadyPaid)
+
+		// Make the second invoice overdue
+		require.NoError(t, db.Model(&adultInvoices[1]).Update("due_date", time.Now().AddDate(0, 0, -1)).Error)
+
+		statement, err := models.GetFeeStatement(club.ID, adult.ID)
+		require.NoError(t, err)
+		assert.Len(t, statement.Invoices, 3)
+		assert.Equal(t, 60.0, statement.TotalBilled)
+		assert.Equal(t, 20.0, statement.TotalPaid)
+		assert.Equal(t, 40.0, statement.Outstanding)
+		assert.Equal(t, 20.0, statement.Overdue)
+
+		require.NoError(t, models.SendOverdueFeeReminders())
+		require.NoError(t, models.SendOverdueFeeReminders())
+
+		var reminded models.Invoice
+		require.NoError(t, db.Where("id = ?", adultInvoices[1].ID).First(&reminded).Error)
+		assert.Equal(t, 1, reminded.ReminderCount, "reminders are not repeated within the interval")
+
+		var count int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", adult.ID, "fee_overdue").Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+}
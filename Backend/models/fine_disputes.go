@@ -0,0 +1,216 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fine dispute states
+const (
+	FineDisputeStatusOpen     = "open"     // Not contested
+	FineDisputeStatusDisputed = "disputed" // Contested by the member, awaiting an admin decision
+	FineDisputeStatusUpheld   = "upheld"   // Dispute rejected, the fine stands
+	FineDisputeStatusWaived   = "waived"   // Fine cancelled, excluded from totals
+	FineDisputeStatusReduced  = "reduced"  // Fine kept with a lower amount
+)
+
+var (
+	ErrFineNotDisputable = errors.New("only open, unpaid fines can be disputed")
+	ErrFineNotDisputed   = errors.New("fine is not disputed")
+)
+
+// fineNotWaivedScope excludes waived fines from unpaid totals and collections
+const fineNotWaivedScope = "dispute_status IS NULL OR dispute_status <> 'waived'"
+
+// sameFloatPtr reports whether two optional amounts are both empty or equal
+func sameFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// FineDisputeEntry is the audit trail of a fine's dispute, one entry per transition
+type FineDisputeEntry struct {
+	ID           string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	FineID       string    `json:"FineID" gorm:"type:uuid;not null;index" odata:"required"`
+	ClubID       string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	FromStatus   string    `json:"FromStatus"`
+	ToStatus     string    `json:"ToStatus"`
+	Comment      string    `json:"Comment"`
+	AmountBefore float64   `json:"AmountBefore"`
+	AmountAfter  float64   `json:"AmountAfter"`
+	CreatedAt    time.Time `json:"CreatedAt" odata:"immutable"`
+	CreatedBy    string    `json:"CreatedBy" gorm:"type:uuid"`
+
+	// Navigation properties for OData expansions
+	CreatedByUser *User `gorm:"foreignKey:CreatedBy" json:"CreatedByUser,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new dispute entries
+func (fde *FineDisputeEntry) BeforeCreate(tx *gorm.DB) error {
+	if fde.ID == "" {
+		fde.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Dispute contests the fine on behalf of the fined member
+func (f *Fine) Dispute(userID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("a reason is required to dispute a fine")
+	}
+	if f.UserID != userID {
+		return fmt.Errorf("unauthorized: only the fined member can dispute a fine")
+	}
+	if f.Paid || f.PendingReview || (f.DisputeStatus != "" && f.DisputeStatus != FineDisputeStatusOpen) {
+		return ErrFineNotDisputable
+	}
+
+	if err := f.transition(FineDisputeStatusDisputed, f.Amount, reason, userID); err != nil {
+		return err
+	}
+
+	_ = SendFineDisputeNotifications(*f, reason)
+	return nil
+}
+
+// ResolveDispute answers a dispute. Upheld keeps the fine, waived cancels it
+// and reduced lowers it to the given amount.
+func (f *Fine) ResolveDispute(status string, amount float64, comment, resolvedBy string) error {
+	if f.DisputeStatus != FineDisputeStatusDisputed {
+		return ErrFineNotDisputed
+	}
+
+	newAmount := f.Amount
+	switch status {
+	case FineDisputeStatusUpheld, FineDisputeStatusWaived:
+	case FineDisputeStatusReduced:
+		if amount <= 0 || amount >= f.Amount {
+			return fmt.Errorf("reduced amount must be greater than 0 and less than %.2f", f.Amount)
+		}
+		newAmount = amount
+	default:
+		return fmt.Errorf("invalid resolution: %s", status)
+	}
+
+	if err := f.transition(status, newAmount, strings.TrimSpace(comment), resolvedBy); err != nil {
+		return err
+	}
+
+	_ = SendFineDisputeNotifications(*f, comment)
+	return nil
+}
+
+// transition moves the fine to a new dispute state and records the audit entry
+func (f *Fine) transition(status string, amount float64, comment, by string) error {
+	from := f.DisputeStatus
+	if from == "" {
+		from = FineDisputeStatusOpen
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"dispute_status": status,
+		"amount":         amount,
+		"updated_at":     now,
+		"updated_by":     by,
+	}
+	if status == FineDisputeStatusDisputed {
+		updates["dispute_reason"] = comment
+	}
+	if amount != f.Amount && f.OriginalAmount == nil {
+		updates["original_amount"] = f.Amount
+	}
+
+	entry := FineDisputeEntry{
+		FineID:       f.ID,
+		ClubID:       f.ClubID,
+		FromStatus:   from,
+		ToStatus:     status,
+		Comment:      comment,
+		AmountBefore: f.Amount,
+		AmountAfter:  amount,
+		CreatedAt:    now,
+		CreatedBy:    by,
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		// The status condition guards against concurrent transitions
+		query := tx.Model(&Fine{}).Where("id = ?", f.ID)
+		if from == FineDisputeStatusOpen {
+			query = query.Where("dispute_status = ? OR dispute_status IS NULL", from)
+		} else {
+			query = query.Where("dispute_status = ?", from)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("fine was changed concurrently, please retry")
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if amount != f.Amount && f.OriginalAmount == nil {
+		original := f.Amount
+		f.OriginalAmount = &original
+	}
+	if status == FineDisputeStatusDisputed {
+		f.DisputeReason = &comment
+	}
+	f.DisputeStatus = status
+	f.Amount = amount
+	f.UpdatedAt = now
+	f.UpdatedBy = by
+	return nil
+}
+
+// ODataBeforeReadCollection limits dispute entries to the fined member and club admins
+func (fde FineDisputeEntry) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)").
+			Where("fine_id IN (SELECT id FROM fines WHERE user_id = ?) OR club_id IN ("+permittedClubsQuery+")", userID, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific dispute entry
+func (fde FineDisputeEntry) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return fde.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating dispute entries directly; use the Dispute and resolution actions
+func (fde *FineDisputeEntry) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: dispute entries are recorded by the Dispute and resolution actions")
+}
+
+// ODataBeforeUpdate prevents modifying the dispute audit trail
+func (fde *FineDisputeEntry) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: dispute entries cannot be modified")
+}
+
+// ODataBeforeDelete prevents deleting the dispute audit trail
+func (fde *FineDisputeEntry) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: dispute entries cannot be deleted")
+}
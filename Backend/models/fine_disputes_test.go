@@ -0,0 +1,169 @@
+package models_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFineDisputes(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "dispute-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "dispute-member@example.com")
+	other, _ := handlers.CreateTestUser(t, "dispute-other@example.com")
+	club := handlers.CreateTestClub(t, owner, "Dispute Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	handlers.CreateTestMember(t, other, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	newFine := func(amount float64) models.Fine {
+		fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: member.ID, Reason: "Late", Amount: amount, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&fine).Error)
+		require.NoError(t, db.Where("id = ?", fine.ID).First(&fine).Error)
+		return fine
+	}
+
+	t.Run("only the fined member can dispute with a reason", func(t *testing.T) {
+		fine := newFine(10)
+		assert.Equal(t, models.FineDisputeStatusOpen, fine.DisputeStatus)
+		assert.Error(t, fine.Dispute(other.ID, "Not me"))
+		assert.Error(t, fine.Dispute(member.ID, "  "))
+		assert.ErrorIs(t, fine.ResolveDispute(models.FineDisputeStatusWaived, 0, "", owner.ID), models.ErrFineNotDisputed)
+
+		require.NoError(t, fine.Dispute(member.ID, "I was ill"))
+		assert.Equal(t, models.FineDisputeStatusDisputed, fine.DisputeStatus)
+		assert.ErrorIs(t, fine.Dispute(member.ID, "Again"), models.ErrFineNotDisputable)
+
+		var adminNotifications, memberNotifications int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", owner.ID, "fine_dispute").Count(&adminNotifications)
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", member.ID, "fine_dispute").Count(&memberNotifications)
+		assert.Equal(t, int64(1), adminNotifications)
+		assert.Equal(t, int64(1), memberNotifications)
+	})
+
+	t.Run("paid fines cannot be disputed", func(t *testing.T) {
+		fine := newFine(5)
+		require.NoError(t, db.Model(&fine).Update("paid", true).Error)
+		fine.Paid = true
+		assert.ErrorIs(t, fine.Dispute(member.ID, "Too late"), models.ErrFineNotDisputable)
+	})
+
+	t.Run("reducing keeps the original amount", func(t *testing.T) {
+		fine := newFine(20)
+		require.NoError(t, fine.Dispute(member.ID, "Only five minutes late"))
+		assert.Error(t, fine.ResolveDispute(models.FineDisputeStatusReduced, 25, "", owner.ID))
+		require.NoError(t, fine.ResolveDispute(models.FineDisputeStatusReduced, 8, "Half the usual rate", owner.ID))
+
+		var stored models.Fine
+		require.NoError(t, db.Where("id = ?", fine.ID).First(&stored).Error)
+		assert.Equal(t, models.FineDisputeStatusReduced, stored.DisputeStatus)
+		assert.Equal(t, 8.0, stored.Amount)
+		require.NotNil(t, stored.OriginalAmount)
+		assert.Equal(t, 20.0, *stored.OriginalAmount)
+
+		var entries []models.FineDisputeEntry
+		require.NoError(t, db.Where("fine_id = ?", fine.ID).Order("created_at").Find(&entries).Error)
+		require.Len(t, entries, 2)
+		assert.Equal(t, models.FineDisputeStatusOpen, entries[0].FromStatus)
+		assert.Equal(t, member.ID, entries[0].CreatedBy)
+		assert.Equal(t, models.FineDisputeStatusReduced, entries[1].ToStatus)
+		assert.Equal(t, owner.ID, entries[1].CreatedBy)
+		assert.Equal(t, 8.0, entries[1].AmountAfter)
+	})
+
+	t.Run("waived fines are excluded from totals", func(t *testing.T) {
+		before, err := member.GetUnpaidFines()
+		require.NoError(t, err)
+
+		fine := newFine(15)
+		require.NoError(t, fine.Dispute(member.ID, "Training was cancelled"))
+		require.NoError(t, fine.ResolveDispute(models.FineDisputeStatusWaived, 0, "", owner.ID))
+		assert.ErrorIs(t, fine.ResolveDispute(models.FineDisputeStatusUpheld, 0, "", owner.ID), models.ErrFineNotDisputed)
+
+		after, err := member.GetUnpaidFines()
+		require.NoError(t, err)
+		assert.Len(t, after, len(before))
+
+		var resolved int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ? AND fine_id = ?", member.ID, "fine_dispute", fine.ID).Count(&resolved)
+		assert.Equal(t, int64(2), resolved)
+	})
+
+	t.Run("dispute entries are only visible to the fined member and admins", func(t *testing.T) {
+		countEntries := func(userID string) int64 {
+			ctx, req := authedRequest(userID)
+			scopes, err := models.FineDisputeEntry{}.ODataBeforeReadCollection(ctx, req, nil)
+			require.NoError(t, err)
+			query := db.Model(&models.FineDisputeEntry{})
+			for _, scope := range scopes {
+				query = scope(query)
+			}
+			var count int64
+			require.NoError(t, query.Count(&count).Error)
+			return count
+		}
+
+		assert.Equal(t, int64(5), countEntries(member.ID))
+		assert.Equal(t, int64(5), countEntries(owner.ID))
+		assert.Equal(t, int64(0), countEntries(other.ID))
+	})
+
+	t.Run("team statistics count waived fines only as settled", func(t *testing.T) {
+		team := models.Team{ClubID: club.ID, Name: "Dispute team"}
+		require.NoError(t, db.Create(&team).Error)
+		for _, waive := range []bool{false, true} {
+			fine := newFine(10)
+			require.NoError(t, db.Model(&fine).Update("team_id", team.ID).Error)
+			if waive {
+				require.NoError(t, fine.Dispute(member.ID, "Wrong team"))
+				require.NoError(t, fine.ResolveDispute(models.FineDisputeStatusWaived, 0, "", owner.ID))
+			}
+		}
+
+		stats, err := team.GetTeamStats("")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats["total_fines"])
+		assert.Equal(t, int64(1), stats["unpaid_fines"])
+	})
+
+	t.Run("dispute fields only change through the dispute actions", func(t *testing.T) {
+		fine := newFine(12)
+		for _, patch := range []map[string]interface{}{
+			{"DisputeStatus": models.FineDisputeStatusWaived},
+			{"DisputeReason": "Changed"},
+			{"OriginalAmount": 30},
+		} {
+			body, err := json.Marshal(patch)
+			require.NoError(t, err)
+			ctx := context.WithValue(models.WithUpdateBody(context.Background(), body), auth.UserIDKey, owner.ID)
+			req := httptest.NewRequest(http.MethodPatch, "/api/v2/Fines("+fine.ID+")", nil).WithContext(ctx)
+			stored := fine
+			assert.Error(t, stored.ODataBeforeUpdate(ctx, req), "%v", patch)
+
+			w := odataRequest(t, ownerToken, http.MethodPatch, "/Fines("+fine.ID+")", patch)
+			assert.GreaterOrEqual(t, w.Code, 400, "%v: %s", patch, w.Body.String())
+		}
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, "/Fines("+fine.ID+")", map[string]interface{}{"Reason": "Very late"})
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		var stored models.Fine
+		require.NoError(t, db.Where("id = ?", fine.ID).First(&stored).Error)
+		assert.Equal(t, "Very late", stored.Reason)
+		assert.Equal(t, models.FineDisputeStatusOpen, stored.DisputeStatus)
+		assert.Nil(t, stored.DisputeReason)
+		assert.Nil(t, stored.OriginalAmount)
+	})
+}
@@ -0,0 +1,423 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Fine rule triggers
+const (
+	FineRuleTriggerRSVPYesAbsent  = "rsvp_yes_absent"  // RSVP'd yes but marked absent
+	FineRuleTriggerMissedShift    = "missed_shift"     // Signed up for a shift but marked absent
+	FineRuleTriggerLateRSVPChange = "late_rsvp_change" // Changed a yes RSVP shortly before the event
+)
+
+var ErrFineNotPendingReview = errors.New("fine is not pending review")
+
+// FineRule creates fines automatically for events of a club or team
+type FineRule struct {
+	ID                   string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID               string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	TeamID               *string   `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Limits the rule to events of a team
+	Name                 string    `json:"Name" gorm:"not null" odata:"required"`
+	Trigger              string    `json:"Trigger" gorm:"not null" odata:"required"`
+	Amount               float64   `json:"Amount" odata:"required"`
+	Reason               *string   `json:"Reason,omitempty" odata:"nullable"`      // Fine reason; defaults to the rule name
+	LateChangeHours      int       `json:"LateChangeHours" gorm:"default:24"`      // late_rsvp_change: hours before the event start
+	EvaluationDelayHours int       `json:"EvaluationDelayHours" gorm:"default:24"` // Time after the event end to record attendance
+	Active               bool      `json:"Active" gorm:"default:true"`
+	CreatedAt            time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy            string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt            time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy            string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Team *Team `gorm:"foreignKey:TeamID" json:"Team,omitempty" odata:"nav"`
+}
+
+// FineRuleEvaluation records that a rule has been evaluated for an event, so
+// fines rejected during review are not created again
+type FineRuleEvaluation struct {
+	ID           string `gorm:"type:uuid;primary_key"`
+	FineRuleID   string `gorm:"type:uuid;not null;uniqueIndex:idx_fine_rule_evaluation"`
+	EventID      string `gorm:"type:uuid;not null;uniqueIndex:idx_fine_rule_evaluation"`
+	FinesCreated int
+	EvaluatedAt  time.Time
+}
+
+// BeforeCreate generates UUID for new fine rules
+func (fr *FineRule) BeforeCreate(tx *gorm.DB) error {
+	if fr.ID == "" {
+		fr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new rule evaluations
+func (fe *FineRuleEvaluation) BeforeCreate(tx *gorm.DB) error {
+	if fe.ID == "" {
+		fe.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsValidFineRuleTrigger checks if a trigger is supported
+func IsValidFineRuleTrigger(trigger string) bool {
+	switch trigger {
+	case FineRuleTriggerRSVPYesAbsent, FineRuleTriggerMissedShift, FineRuleTriggerLateRSVPChange:
+		return true
+	}
+	return false
+}
+
+// isClubAdmin checks whether the user holds the fines.manage permission in the rule's club
+func (fr *FineRule) isClubAdmin(userID string) bool {
+	return HasPermission(fr.ClubID, userID, PermissionFinesManage)
+}
+
+// validate checks the rule configuration
+func (fr *FineRule) validate() error {
+	fr.Name = strings.TrimSpace(fr.Name)
+	if fr.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !IsValidFineRuleTrigger(fr.Trigger) {
+		return fmt.Errorf("invalid trigger: must be '%s', '%s' or '%s'", FineRuleTriggerRSVPYesAbsent, FineRuleTriggerMissedShift, FineRuleTriggerLateRSVPChange)
+	}
+	if fr.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than zero")
+	}
+	if fr.LateChangeHours <= 0 {
+		fr.LateChangeHours = 24
+	}
+	if fr.EvaluationDelayHours < 0 {
+		return fmt.Errorf("evaluation delay must not be negative")
+	}
+	if fr.TeamID != nil && *fr.TeamID != "" {
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *fr.TeamID, fr.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+	}
+	return nil
+}
+
+// fineReason returns the reason used for fines of this rule
+func (fr *FineRule) fineReason(event Event) string {
+	reason := fr.Name
+	if fr.Reason != nil && strings.TrimSpace(*fr.Reason) != "" {
+		reason = strings.TrimSpace(*fr.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%s)", reason, event.Name, event.StartTime.Format("2006-01-02"))
+}
+
+// offenders returns the users the rule applies to for an event
+func (fr *FineRule) offenders(event Event) ([]string, error) {
+	var userIDs []string
+	var err error
+
+	switch fr.Trigger {
+	case FineRuleTriggerRSVPYesAbsent:
+		err = database.Db.Model(&EventRSVP{}).
+			Where("event_id = ? AND response = ? AND attended = ?", event.ID, "yes", false).
+			Pluck("user_id", &userIDs).Error
+	case FineRuleTriggerMissedShift:
+		err = database.Db.Model(&ShiftMember{}).
+			Where("shift_id IN (SELECT id FROM shifts WHERE event_id = ?) AND attended = ?", event.ID, false).
+			Distinct("user_id").
+			Pluck("user_id", &userIDs).Error
+	case FineRuleTriggerLateRSVPChange:
+		cutoff := event.StartTime.Add(-time.Duration(fr.LateChangeHours) * time.Hour)
+		err = database.Db.Model(&EventRSVP{}).
+			Where("event_id = ? AND previous_response = ? AND response <> ? AND response_changed_at >= ?", event.ID, "yes", "yes", cutoff).
+			Pluck("user_id", &userIDs).Error
+	}
+	if err != nil || len(userIDs) == 0 {
+		return nil, err
+	}
+
+	// Only current members of the club are fined
+	var members []string
+	err = database.Db.Model(&Member{}).Where("club_id = ? AND user_id IN ?", fr.ClubID, userIDs).Pluck("user_id", &members).Error
+	return members, err
+}
+
+// evaluate creates pending fines for an event and records the evaluation
+func (fr *FineRule) evaluate(event Event) (int, error) {
+	offenders, err := fr.offenders(event)
+	if err != nil {
+		return 0, err
+	}
+
+	teamID := fr.TeamID
+	if teamID == nil {
+		teamID = event.TeamID
+	}
+
+	now := time.Now()
+	created := 0
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range offenders {
+			fine := Fine{
+				ID:            uuid.New().String(),
+				ClubID:        fr.ClubID,
+				TeamID:        teamID,
+				UserID:        userID,
+				Reason:        fr.fineReason(event),
+				Amount:        fr.Amount,
+				CreatedAt:     now,
+				CreatedBy:     fr.CreatedBy,
+				UpdatedAt:     now,
+				UpdatedBy:     fr.CreatedBy,
+				FineRuleID:    &fr.ID,
+				EventID:       &event.ID,
+				PendingReview: true,
+			}
+			if err := tx.Create(&fine).Error; err != nil {
+				return err
+			}
+			created++
+		}
+
+		return tx.Create(&FineRuleEvaluation{
+			FineRuleID:   fr.ID,
+			EventID:      event.ID,
+			FinesCreated: created,
+			EvaluatedAt:  now,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+// EvaluateFineRules is the scheduler job that applies active fine rules to events that have ended.
+// Each rule is evaluated once per event after its evaluation delay, so there is time to record
+// attendance. Fines are created pending review and only notified once a treasurer approves them.
+func EvaluateFineRules() error {
+	var rules []FineRule
+	if err := database.Db.Where("active = ?", true).Find(&rules).Error; err != nil {
+		return fmt.Errorf("failed to load fine rules: %w", err)
+	}
+
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+
+		// Skip clubs where the fines feature has been disabled
+		if err := CheckFeatureEnabled(rule.ClubID, "fines"); err != nil {
+			continue
+		}
+
+		query := database.Db.Where("club_id = ? AND end_time <= ? AND end_time >= ?", rule.ClubID, now.Add(-time.Duration(rule.EvaluationDelayHours)*time.Hour), rule.CreatedAt).
+			Where("id NOT IN (SELECT event_id FROM fine_rule_evaluations WHERE fine_rule_id = ?)", rule.ID)
+		if rule.TeamID != nil {
+			query = query.Where("team_id = ?", *rule.TeamID)
+		}
+
+		var events []Event
+		if err := query.Find(&events).Error; err != nil {
+			return fmt.Errorf("failed to load events for fine rule %s: %w", rule.ID, err)
+		}
+
+		for _, event := range events {
+			created, err := rule.evaluate(event)
+			if err != nil {
+				log.Printf("Failed to evaluate fine rule %s for event %s: %v", rule.ID, event.ID, err)
+				continue
+			}
+			if created > 0 {
+				_ = SendFineReviewNotifications(rule.ClubID, created)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Approve releases an automatically created fine and notifies the member
+func (f *Fine) Approve(approvedBy string) error {
+	if !f.PendingReview {
+		return ErrFineNotPendingReview
+	}
+
+	now := time.Now()
+	if err := database.Db.Model(f).Updates(map[string]interface{}{
+		"pending_review": false,
+		"updated_at":     now,
+		"updated_by":     approvedBy,
+	}).Error; err != nil {
+		return err
+	}
+
+	f.PendingReview = false
+	f.UpdatedAt = now
+	f.UpdatedBy = approvedBy
+
+	_ = SendFineAssignedNotifications(*f)
+	return nil
+}
+
+// RecordAttendance records whether a member who responded to the event attended it
+func (e *Event) RecordAttendance(userID string, attended bool) error {
+	if time.Now().Before(e.StartTime) {
+		return fmt.Errorf("attendance can only be recorded once the event has started")
+	}
+
+	result := database.Db.Model(&EventRSVP{}).Where("event_id = ? AND user_id = ?", e.ID, userID).Update("attended", attended)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user has not responded to this event")
+	}
+	return nil
+}
+
+// RecordAttendance records whether a member signed up for the shift showed up
+func (s *Shift) RecordAttendance(userID string, attended bool) error {
+	if time.Now().Before(s.StartTime) {
+		return fmt.Errorf("attendance can only be recorded once the shift has started")
+	}
+
+	result := database.Db.Model(&ShiftMember{}).Where("shift_id = ? AND user_id = ?", s.ID, userID).Update("attended", attended)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user is not signed up for this shift")
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection filters fine rules to clubs the user belongs to
+func (fr FineRule) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Rules are visible to all members so the club's fine catalog is transparent
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific fine rule
+func (fr FineRule) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return fr.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates fine rule creation permissions
+func (fr *FineRule) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if err := CheckFeatureEnabled(fr.ClubID, "fines"); err != nil {
+		return err
+	}
+
+	if !fr.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create fine rules")
+	}
+
+	if err := fr.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	fr.CreatedAt = now
+	fr.CreatedBy = userID
+	fr.UpdatedAt = now
+	fr.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate records the new fine rule in the audit log
+func (fr *FineRule) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, fr.ClubID, "FineRule", fr.ID, AuditOperationCreate, fr)
+}
+
+// ODataBeforeUpdate validates fine rule update permissions
+func (fr *FineRule) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "FineRule", fr.ID, fr)
+
+	if err := CheckFeatureEnabled(fr.ClubID, "fines"); err != nil {
+		return err
+	}
+
+	if !fr.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update fine rules")
+	}
+
+	updated, err := updatedEntity(ctx, fr)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving rules to another club
+	if updated.ClubID != fr.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing fine rule")
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	fr.UpdatedAt = time.Now()
+	fr.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate records the changes to the fine rule in the audit log
+func (fr *FineRule) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, fr.ClubID, "FineRule", fr.ID, AuditOperationUpdate, fr)
+}
+
+// ODataBeforeDelete validates fine rule deletion permissions. Fines created by the rule are kept.
+func (fr *FineRule) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !fr.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete fine rules")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	return tx.Where("fine_rule_id = ?", fr.ID).Delete(&FineRuleEvaluation{}).Error
+}
+
+// ODataAfterDelete records the deleted fine rule in the audit log
+func (fr *FineRule) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, fr.ClubID, "FineRule", fr.ID, AuditOperationDelete, fr)
+}
@@ -0,0 +1,203 @@
+package models_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestFineRule(t *testing.T, userID string, rule *models.FineRule) {
+	t.Helper()
+	ctx, req := authedRequest(userID)
+	require.NoError(t, rule.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(rule).Error)
+	// Rules only apply to events that end after they were created
+	require.NoError(t, handlers.GetDB().Model(rule).Update("created_at", time.Now().AddDate(0, 0, -7)).Error)
+}
+
+func visibleFines(t *testing.T, userID string) int64 {
+	t.Helper()
+	ctx, req := authedRequest(userID)
+	scopes, err := models.Fine{}.ODataBeforeReadCollection(ctx, req, nil)
+	require.NoError(t, err)
+
+	var count int64
+	query := handlers.GetDB().Model(&models.Fine{})
+	for _, scope := range scopes {
+		query = scope(query)
+	}
+	require.NoError(t, query.Count(&count).Error)
+	return count
+}
+
+func TestFineRuleValidation(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "fine-rule-validation-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "fine-rule-validation-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Fine Rule Validation Club")
+	handlers.CreateTestMember(t, member, club, "member")
+
+	rule := &models.FineRule{ClubID: club.ID, Name: "No show", Trigger: models.FineRuleTriggerRSVPYesAbsent, Amount: 5}
+
+	t.Run("fines feature must be enabled", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		assert.Error(t, rule.ODataBeforeCreate(ctx, req))
+	})
+
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	t.Run("only admins can create rules", func(t *testing.T) {
+		ctx, req := authedRequest(member.ID)
+		assert.Error(t, rule.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("trigger and amount are validated", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		invalid := &models.FineRule{ClubID: club.ID, Name: "Unknown", Trigger: "too_loud", Amount: 5}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+		invalid = &models.FineRule{ClubID: club.ID, Name: "Free", Trigger: models.FineRuleTriggerMissedShift, Amount: 0}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+		assert.NoError(t, rule.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("patched values are validated", func(t *testing.T) {
+		require.NoError(t, db.Create(rule).Error)
+		otherClub := handlers.CreateTestClub(t, owner, "Other Fine Rule Club")
+
+		path := "/FineRules(" + rule.ID + ")"
+		for _, change := range []map[string]interface{}{
+			{"Trigger": "too_loud"},
+			{"Amount": -5},
+			{"Name": " "},
+			{"ClubID": otherClub.ID},
+		} {
+			resp := odataRequest(t, ownerToken, http.MethodPatch, path, change)
+			assert.Equal(t, http.StatusForbidden, resp.Code, change)
+		}
+		var stored models.FineRule
+		require.NoError(t, db.First(&stored, "id = ?", rule.ID).Error)
+		assert.Equal(t, models.FineRuleTriggerRSVPYesAbsent, stored.Trigger)
+		assert.Equal(t, club.ID, stored.ClubID)
+
+		resp := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Amount": 10})
+		assert.Less(t, resp.Code, 300, resp.Body.String())
+	})
+}
+
+func TestEvaluateFineRules(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "fine-rule-owner@example.com")
+	absent, _ := handlers.CreateTestUser(t, "fine-rule-absent@example.com")
+	lateChanger, _ := handlers.CreateTestUser(t, "fine-rule-late@example.com")
+	shiftMissed, _ := handlers.CreateTestUser(t, "fine-rule-shift@example.com")
+	club := handlers.CreateTestClub(t, owner, "Fine Rule Club")
+	handlers.CreateTestMember(t, absent, club, "member")
+	handlers.CreateTestMember(t, lateChanger, club, "member")
+	handlers.CreateTestMember(t, shiftMissed, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true, events_enabled = true, shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	createTestFineRule(t, owner.ID, &models.FineRule{ClubID: club.ID, Name: "No show", Trigger: models.FineRuleTriggerRSVPYesAbsent, Amount: 5})
+	createTestFineRule(t, owner.ID, &models.FineRule{ClubID: club.ID, Name: "Late cancellation", Trigger: models.FineRuleTriggerLateRSVPChange, Amount: 2, LateChangeHours: 24})
+	createTestFineRule(t, owner.ID, &models.FineRule{ClubID: club.ID, Name: "Missed shift", Trigger: models.FineRuleTriggerMissedShift, Amount: 10})
+
+	start := time.Now().Add(-50 * time.Hour)
+	event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Training", StartTime: start, EndTime: start.Add(2 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&event).Error)
+
+	for _, user := range []models.User{absent, lateChanger, shiftMissed} {
+		require.NoError(t, user.CreateOrUpdateRSVP(event.ID, "yes"))
+	}
+	// Changing the answer shortly before the event is a late change
+	require.NoError(t, lateChanger.CreateOrUpdateRSVP(event.ID, "no"))
+	require.NoError(t, db.Model(&models.EventRSVP{}).Where("event_id = ? AND user_id = ?", event.ID, lateChanger.ID).Update("response_changed_at", start.Add(-time.Hour)).Error)
+
+	shift := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: event.ID, StartTime: start, EndTime: start.Add(time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&shift).Error)
+	require.NoError(t, db.Create(&models.ShiftMember{ID: uuid.New().String(), ShiftID: shift.ID, UserID: shiftMissed.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID}).Error)
+
+	t.Run("attendance is recorded for respondents and shift members", func(t *testing.T) {
+		require.NoError(t, event.RecordAttendance(absent.ID, false))
+		require.NoError(t, event.RecordAttendance(shiftMissed.ID, true))
+		assert.Error(t, event.RecordAttendance(owner.ID, true), "users without RSVP have no attendance")
+		require.NoError(t, shift.RecordAttendance(shiftMissed.ID, false))
+	})
+
+	require.NoError(t, models.EvaluateFineRules())
+
+	var fines []models.Fine
+	require.NoError(t, db.Where("club_id = ?", club.ID).Find(&fines).Error)
+
+	t.Run("rules create pending fines linked to rule and event", func(t *testing.T) {
+		require.Len(t, fines, 3)
+		amounts := map[string]float64{}
+		for _, fine := range fines {
+			assert.True(t, fine.PendingReview)
+			require.NotNil(t, fine.FineRuleID)
+			require.NotNil(t, fine.EventID)
+			assert.Equal(t, event.ID, *fine.EventID)
+			amounts[fine.UserID] = fine.Amount
+		}
+		assert.Equal(t, 5.0, amounts[absent.ID])
+		assert.Equal(t, 2.0, amounts[lateChanger.ID])
+		assert.Equal(t, 10.0, amounts[shiftMissed.ID])
+
+		var reviewNotifications int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", owner.ID, "fine_review").Count(&reviewNotifications)
+		assert.Equal(t, int64(3), reviewNotifications)
+	})
+
+	t.Run("pending fines are hidden from members", func(t *testing.T) {
+		assert.Equal(t, int64(0), visibleFines(t, absent.ID))
+		assert.Equal(t, int64(3), visibleFines(t, owner.ID))
+	})
+
+	t.Run("events are evaluated only once", func(t *testing.T) {
+		// Rejecting a fine during review deletes it for good
+		require.NoError(t, db.Where("user_id = ?", lateChanger.ID).Delete(&models.Fine{}).Error)
+		require.NoError(t, models.EvaluateFineRules())
+
+		var count int64
+		db.Model(&models.Fine{}).Where("club_id = ?", club.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("approving notifies the member", func(t *testing.T) {
+		var fine models.Fine
+		require.NoError(t, db.Where("user_id = ?", absent.ID).First(&fine).Error)
+		require.NoError(t, fine.Approve(owner.ID))
+		assert.ErrorIs(t, fine.Approve(owner.ID), models.ErrFineNotPendingReview)
+
+		assert.Equal(t, int64(1), visibleFines(t, absent.ID))
+
+		var notification models.Notification
+		err := db.Where("user_id = ? AND type = ?", absent.ID, "fine_assigned").First(&notification).Error
+		require.NoError(t, err)
+		require.NotNil(t, notification.FineID)
+		assert.Equal(t, fine.ID, *notification.FineID)
+	})
+
+	t.Run("events of other teams are ignored by team rules", func(t *testing.T) {
+		team, err := club.CreateTeam("First team", "", owner.ID)
+		require.NoError(t, err)
+		require.NoError(t, db.Exec("UPDATE club_settings SET teams_enabled = true WHERE club_id = ?", club.ID).Error)
+		createTestFineRule(t, owner.ID, &models.FineRule{ClubID: club.ID, TeamID: &team.ID, Name: "Team no show", Trigger: models.FineRuleTriggerRSVPYesAbsent, Amount: 3})
+
+		require.NoError(t, models.EvaluateFineRules())
+
+		var count int64
+		require.NoError(t, db.Model(&models.Fine{}).Where("club_id = ? AND amount = ?", club.ID, 3.0).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -59,7 +59,7 @@ func (ft FineTemplate) ODataBeforeReadCollection(ctx context.Context, r *http.Re
 	// User can only see fine templates of clubs they belong to
 	// Also filter out templates from clubs where fines feature is disabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -75,7 +75,7 @@ func (ft FineTemplate) ODataBeforeReadEntity(ctx context.Context, r *http.Reques
 	// User can only see fine templates of clubs they belong to
 	// Also check that fines feature is enabled for the club
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -93,9 +93,8 @@ func (ft *FineTemplate) ODataBeforeCreate(ctx context.Context, r *http.Request)
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", ft.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(ft.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can create fine templates")
 	}
 
@@ -109,6 +108,11 @@ func (ft *FineTemplate) ODataBeforeCreate(ctx context.Context, r *http.Request)
 	return nil
 }
 
+// ODataAfterCreate records the new fine template in the audit log
+func (ft *FineTemplate) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, ft.ClubID, "FineTemplate", ft.ID, AuditOperationCreate, ft)
+}
+
 // ODataBeforeUpdate validates fine template update permissions
 func (ft *FineTemplate) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -116,6 +120,9 @@ func (ft *FineTemplate) ODataBeforeUpdate(ctx context.Context, r *http.Request)
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "FineTemplate", ft.ID, ft)
+
 	// Load the existing template to enforce immutable fields
 	var existingTemplate FineTemplate
 	if err := database.Db.First(&existingTemplate, "id = ?", ft.ID).Error; err != nil {
@@ -132,9 +139,8 @@ func (ft *FineTemplate) ODataBeforeUpdate(ctx context.Context, r *http.Request)
 		return fmt.Errorf("forbidden: club cannot be changed for an existing fine template")
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", ft.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(ft.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update fine templates")
 	}
 
@@ -149,6 +155,11 @@ func (ft *FineTemplate) ODataBeforeUpdate(ctx context.Context, r *http.Request)
 	return nil
 }
 
+// ODataAfterUpdate records the changes to the fine template in the audit log
+func (ft *FineTemplate) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, ft.ClubID, "FineTemplate", ft.ID, AuditOperationUpdate, ft)
+}
+
 // ODataBeforeDelete validates fine template deletion permissions
 func (ft *FineTemplate) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -167,11 +178,15 @@ func (ft *FineTemplate) ODataBeforeDelete(ctx context.Context, r *http.Request)
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", existingTemplate.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(existingTemplate.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete fine templates")
 	}
 
 	return nil
 }
+
+// ODataAfterDelete records the deleted fine template in the audit log
+func (ft *FineTemplate) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, ft.ClubID, "FineTemplate", ft.ID, AuditOperationDelete, ft)
+}
@@ -15,7 +15,8 @@ import (
 type Fine struct {
 	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
 	ClubID    string    `json:"ClubID" gorm:"type:uuid" odata:"required"`
-	TeamID    *string   `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Optional team association
+	TeamID    *string   `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"`         // Optional team association
+	SeasonID  *string   `json:"SeasonID,omitempty" gorm:"type:uuid;index" odata:"nullable"` // Defaults to the season of the event or the current season
 	UserID    string    `json:"UserID" gorm:"type:uuid" odata:"required"`
 	Reason    string    `json:"Reason" odata:"required"`
 	Amount    float64   `json:"Amount" odata:"required"`
@@ -25,12 +26,51 @@ type Fine struct {
 	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"required"`
 	Paid      bool      `json:"Paid"`
 
+	// Automatic fines link back to the rule and event that created them and
+	// stay hidden from the member until a treasurer approves them
+	FineRuleID    *string `json:"FineRuleID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	EventID       *string `json:"EventID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	PendingReview bool    `json:"PendingReview" gorm:"default:false" odata:"auto"`
+
+	// Disputes are raised with the Dispute action and answered by admins
+	DisputeStatus  string   `json:"DisputeStatus" gorm:"default:'open'" odata:"auto"`
+	DisputeReason  *string  `json:"DisputeReason,omitempty" odata:"auto,nullable"`
+	OriginalAmount *float64 `json:"OriginalAmount,omitempty" odata:"auto,nullable"` // Amount before a reduction
+
 	// Navigation properties for OData expansions
-	User          *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
-	CreatedByUser *User `gorm:"foreignKey:CreatedBy" json:"CreatedByUser,omitempty" odata:"nav"`
-	UpdatedByUser *User `gorm:"foreignKey:UpdatedBy" json:"UpdatedByUser,omitempty" odata:"nav"`
-	Club          *Club `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
-	Team          *Team `gorm:"foreignKey:TeamID" json:"Team,omitempty" odata:"nav"`
+	User          *User     `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+	CreatedByUser *User     `gorm:"foreignKey:CreatedBy" json:"CreatedByUser,omitempty" odata:"nav"`
+	UpdatedByUser *User     `gorm:"foreignKey:UpdatedBy" json:"UpdatedByUser,omitempty" odata:"nav"`
+	Club          *Club     `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
+	Team          *Team     `gorm:"foreignKey:TeamID" json:"Team,omitempty" odata:"nav"`
+	FineRule      *FineRule `gorm:"foreignKey:FineRuleID" json:"FineRule,omitempty" odata:"nav"`
+	Event         *Event    `gorm:"foreignKey:EventID" json:"Event,omitempty" odata:"nav"`
+	Season        *Season   `gorm:"foreignKey:SeasonID" json:"Season,omitempty" odata:"nav"`
+
+	DisputeEntries []FineDisputeEntry `gorm:"foreignKey:FineID" json:"DisputeEntries,omitempty" odata:"nav"`
+}
+
+// BeforeCreate adds the fine to the season of its event or, without event, the current season
+func (f *Fine) BeforeCreate(tx *gorm.DB) (err error) {
+	if f.SeasonID != nil {
+		return nil
+	}
+	if f.EventID != nil && *f.EventID != "" {
+		var event Event
+		if err := tx.Select("season_id").Where("id = ?", *f.EventID).Limit(1).Find(&event).Error; err != nil {
+			return err
+		}
+		if event.SeasonID != nil {
+			f.SeasonID = event.SeasonID
+			return nil
+		}
+	}
+	createdAt := f.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	f.SeasonID, err = seasonAt(tx, f.ClubID, createdAt)
+	return
 }
 
 func (c *Club) CreateFine(userID, reason, createdBy string, amount float64) (Fine, error) {
@@ -117,6 +157,10 @@ func (t *Team) DeleteFine(fineID string) error {
 	return database.Db.Where("id = ? AND team_id = ?", fineID, t.ID).Delete(&Fine{}).Error
 }
 
+// finePendingReviewScope hides fines awaiting review from everyone but members managing fines.
+// Arguments: user ID, user ID, PermissionFinesManage.
+const finePendingReviewScope = "pending_review = false OR club_id IN (" + permittedClubsQuery + ")"
+
 // ODataBeforeReadCollection filters fines to only those in clubs the user belongs to
 func (f Fine) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -124,10 +168,11 @@ func (f Fine) ODataBeforeReadCollection(ctx context.Context, r *http.Request, op
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see fines of clubs they belong to
+	// User can only see fines of clubs they belong to and the fines of their dependents
 	// Also filter out fines from clubs where fines feature is disabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
+		return db.Where("(club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') OR user_id IN ("+dependentsQuery+")) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID, userID).
+			Where(finePendingReviewScope, userID, userID, PermissionFinesManage)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -140,10 +185,11 @@ func (f Fine) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts i
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see fines of clubs they belong to
+	// User can only see fines of clubs they belong to and the fines of their dependents
 	// Also check that fines feature is enabled for the club
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID)
+		return db.Where("(club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') OR user_id IN ("+dependentsQuery+")) AND club_id IN (SELECT club_id FROM club_settings WHERE fines_enabled = true)", userID, userID).
+			Where(finePendingReviewScope, userID, userID, PermissionFinesManage)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -169,9 +215,13 @@ func (f *Fine) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		}
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", f.ClubID, userID).First(&existingMember).Error; err != nil {
+	// SECURITY: If SeasonID is provided, verify it belongs to the specified ClubID and is still open
+	if err := validateOpenSeason(f.SeasonID, f.ClubID); err != nil {
+		return err
+	}
+
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(f.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can create fines")
 	}
 
@@ -185,6 +235,11 @@ func (f *Fine) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
+// ODataAfterCreate records the new fine in the audit log
+func (f *Fine) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, f.ClubID, "Fine", f.ID, AuditOperationCreate, f)
+}
+
 // ODataBeforeUpdate validates fine update permissions
 func (f *Fine) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -192,6 +247,9 @@ func (f *Fine) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "Fine", f.ID, f)
+
 	// Load the existing fine to enforce immutable fields
 	var existingFine Fine
 	if err := database.Db.First(&existingFine, "id = ?", f.ID).Error; err != nil {
@@ -203,22 +261,40 @@ func (f *Fine) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
+	updated, err := updatedEntity(ctx, f)
+	if err != nil {
+		return err
+	}
+
 	// SECURITY: Prevent changing the club of an existing fine (ClubID is immutable)
-	if f.ClubID != existingFine.ClubID {
+	if updated.ClubID != existingFine.ClubID {
 		return fmt.Errorf("forbidden: club cannot be changed for an existing fine")
 	}
 
+	// Disputes are only raised and resolved through the Dispute and ResolveDispute actions,
+	// which record them in the dispute history
+	if updated.DisputeStatus != existingFine.DisputeStatus || !sameStringPtr(updated.DisputeReason, existingFine.DisputeReason) ||
+		!sameFloatPtr(updated.OriginalAmount, existingFine.OriginalAmount) {
+		return fmt.Errorf("forbidden: dispute status, reason and original amount can only be changed through the dispute actions")
+	}
+
 	// SECURITY: If TeamID is being updated, verify it belongs to the (unchanged) ClubID
-	if f.TeamID != nil && *f.TeamID != "" {
+	if updated.TeamID != nil && *updated.TeamID != "" {
 		var team Team
-		if err := database.Db.Where("id = ? AND club_id = ?", *f.TeamID, existingFine.ClubID).First(&team).Error; err != nil {
+		if err := database.Db.Where("id = ? AND club_id = ?", *updated.TeamID, existingFine.ClubID).First(&team).Error; err != nil {
 			return fmt.Errorf("unauthorized: team does not belong to the specified club")
 		}
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", existingFine.ClubID, userID).First(&existingMember).Error; err != nil {
+	// SECURITY: If SeasonID is being updated, verify it belongs to the (unchanged) ClubID and is still open
+	if !sameStringPtr(updated.SeasonID, existingFine.SeasonID) {
+		if err := validateOpenSeason(updated.SeasonID, existingFine.ClubID); err != nil {
+			return err
+		}
+	}
+
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(existingFine.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update fines")
 	}
 
@@ -230,6 +306,11 @@ func (f *Fine) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
+// ODataAfterUpdate records the changes to the fine in the audit log
+func (f *Fine) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, f.ClubID, "Fine", f.ID, AuditOperationUpdate, f)
+}
+
 // ODataBeforeDelete validates fine deletion permissions
 func (f *Fine) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -242,11 +323,15 @@ func (f *Fine) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", f.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the fines.manage permission in the club
+	if !HasPermission(f.ClubID, userID, PermissionFinesManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete fines")
 	}
 
 	return nil
 }
+
+// ODataAfterDelete records the deleted fine in the audit log
+func (f *Fine) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, f.ClubID, "Fine", f.ID, AuditOperationDelete, f)
+}
@@ -0,0 +1,271 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// dependentEmailDomain is used for the placeholder email of dependents without an own email
+// address. The domain is reserved, so no login link can ever be delivered to it.
+const dependentEmailDomain = "dependents.invalid"
+
+var (
+	ErrNotGuardian           = errors.New("only a guardian can manage this dependent")
+	ErrGuardianNotFound      = errors.New("no user with this email address")
+	ErrAlreadyGuardian       = errors.New("user is already a guardian of this dependent")
+	ErrOwnGuardian           = errors.New("users cannot be their own guardian")
+	ErrLastGuardian          = errors.New("a dependent without own account needs at least one guardian")
+	ErrDependentNameRequired = errors.New("first and last name are required")
+)
+
+// dependentsQuery selects the IDs of the dependents of a guardian.
+// Arguments: guardian user ID.
+const dependentsQuery = "SELECT dependent_id FROM guardianships WHERE guardian_id = ?"
+
+// actingClubsQuery selects the clubs a user or one of their dependents is a member of.
+// Arguments: user ID, user ID.
+const actingClubsQuery = "SELECT club_id FROM members WHERE (user_id = ? OR user_id IN (" + dependentsQuery + ")) AND status <> 'former'"
+
+// Guardianship lets a guardian, typically a parent, act on behalf of a dependent: RSVP to events,
+// view fines and receive the dependent's notifications. Dependents may have an own account or be
+// managed entirely by their guardians.
+type Guardianship struct {
+	ID          string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	GuardianID  string    `json:"GuardianID" gorm:"type:uuid;not null;uniqueIndex:idx_guardianship_pair" odata:"auto"`
+	DependentID string    `json:"DependentID" gorm:"type:uuid;not null;uniqueIndex:idx_guardianship_pair;index" odata:"auto"`
+	CreatedAt   time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy   string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	Guardian  *User `gorm:"foreignKey:GuardianID" json:"Guardian,omitempty" odata:"nav"`
+	Dependent *User `gorm:"foreignKey:DependentID" json:"Dependent,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new guardianships
+func (g *Guardianship) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsManagedDependent reports whether the user has no own email address and is managed by guardians
+func (u *User) IsManagedDependent() bool {
+	return strings.HasSuffix(u.Email, "@"+dependentEmailDomain)
+}
+
+// IsGuardianOf reports whether a user is a guardian of the dependent
+func IsGuardianOf(guardianID, dependentID string) bool {
+	if guardianID == "" || dependentID == "" {
+		return false
+	}
+	var count int64
+	database.Db.Model(&Guardianship{}).Where("guardian_id = ? AND dependent_id = ?", guardianID, dependentID).Count(&count)
+	return count > 0
+}
+
+// CanActFor reports whether a user may act on behalf of the subject: for themselves or as guardian
+func CanActFor(userID, subjectID string) bool {
+	return userID == subjectID || IsGuardianOf(userID, subjectID)
+}
+
+// GetGuardianIDs returns the user IDs of the guardians of a dependent
+func GetGuardianIDs(dependentID string) ([]string, error) {
+	var ids []string
+	err := database.Db.Model(&Guardianship{}).Where("dependent_id = ?", dependentID).Pluck("guardian_id", &ids).Error
+	return ids, err
+}
+
+// GetDependents returns the dependents of a guardian
+func GetDependents(guardianID string) ([]User, error) {
+	var dependents []User
+	err := database.Db.Where("id IN ("+dependentsQuery+")", guardianID).Order("first_name, last_name").Find(&dependents).Error
+	return dependents, err
+}
+
+// CreateDependent creates a dependent without own email address, managed by the guardian
+func CreateDependent(guardianID, firstName, lastName string, birthDate *time.Time) (*User, error) {
+	firstName = strings.TrimSpace(firstName)
+	lastName = strings.TrimSpace(lastName)
+	if firstName == "" || lastName == "" {
+		return nil, ErrDependentNameRequired
+	}
+
+	id := uuid.New().String()
+	dependent := User{
+		ID:             id,
+		FirstName:      firstName,
+		LastName:       lastName,
+		Email:          fmt.Sprintf("dependent-%s@%s", id, dependentEmailDomain),
+		BirthDate:      birthDate,
+		SetupCompleted: true,
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dependent).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Guardianship{GuardianID: guardianID, DependentID: id, CreatedBy: guardianID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dependent, nil
+}
+
+// AddGuardian makes the user with the given email address a guardian of the dependent. Existing
+// guardians can add further guardians; dependents with an own account can add their guardians.
+func AddGuardian(dependentID, addedBy, guardianEmail string) (*Guardianship, error) {
+	if !CanActFor(addedBy, dependentID) {
+		return nil, ErrNotGuardian
+	}
+
+	var guardian User
+	if err := database.Db.Where("email = ?", strings.TrimSpace(guardianEmail)).First(&guardian).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuardianNotFound
+		}
+		return nil, err
+	}
+	if guardian.ID == dependentID {
+		return nil, ErrOwnGuardian
+	}
+	if IsGuardianOf(guardian.ID, dependentID) {
+		return nil, ErrAlreadyGuardian
+	}
+
+	var dependent User
+	if err := database.Db.Where("id = ?", dependentID).First(&dependent).Error; err != nil {
+		return nil, err
+	}
+
+	guardianship := Guardianship{GuardianID: guardian.ID, DependentID: dependentID, CreatedBy: addedBy}
+	if err := database.Db.Create(&guardianship).Error; err != nil {
+		return nil, err
+	}
+
+	_ = CreateNotification(guardian.ID, "guardian_added", "You are now a guardian",
+		fmt.Sprintf("You can now act on behalf of %s.", dependent.GetFullName()), nil, nil, nil)
+
+	return &guardianship, nil
+}
+
+// soleGuardianDependents returns the managed dependents that only have the given guardian
+func soleGuardianDependents(tx *gorm.DB, guardianID string) ([]string, error) {
+	var ids []string
+	err := tx.Model(&Guardianship{}).
+		Where("guardian_id = ? AND dependent_id IN (SELECT id FROM users WHERE email LIKE ?)", guardianID, "%@"+dependentEmailDomain).
+		Where("dependent_id NOT IN (SELECT dependent_id FROM guardianships WHERE guardian_id <> ?)", guardianID).
+		Pluck("dependent_id", &ids).Error
+	return ids, err
+}
+
+// AfterCreate delivers a copy of a dependent's notification to each of their guardians
+func (n *Notification) AfterCreate(tx *gorm.DB) error {
+	if n.DependentID != nil {
+		return nil
+	}
+
+	var guardianIDs []string
+	if err := tx.Model(&Guardianship{}).Where("dependent_id = ?", n.UserID).Pluck("guardian_id", &guardianIDs).Error; err != nil {
+		log.Printf("failed to look up guardians of user %s: %v", n.UserID, err)
+		return nil
+	}
+	if len(guardianIDs) == 0 {
+		return nil
+	}
+
+	var dependent User
+	if err := tx.Select("id", "first_name").Where("id = ?", n.UserID).First(&dependent).Error; err != nil {
+		log.Printf("failed to load dependent %s: %v", n.UserID, err)
+		return nil
+	}
+
+	for _, guardianID := range guardianIDs {
+		dependentID := n.UserID
+		notification := Notification{
+			UserID:        guardianID,
+			DependentID:   &dependentID,
+			Type:          n.Type,
+			Title:         fmt.Sprintf("%s: %s", dependent.FirstName, n.Title),
+			Message:       n.Message,
+			ClubID:        n.ClubID,
+			EventID:       n.EventID,
+			FineID:        n.FineID,
+			InviteID:      n.InviteID,
+			JoinRequestID: n.JoinRequestID,
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			log.Printf("failed to notify guardian %s of user %s: %v", guardianID, n.UserID, err)
+		}
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection limits guardianships to those of the user, as guardian or dependent,
+// and to the co-guardians of the user's dependents
+func (g Guardianship) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("guardian_id = ? OR dependent_id = ? OR dependent_id IN ("+dependentsQuery+")", userID, userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific guardianship
+func (g Guardianship) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return g.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating guardianships directly; use the CreateDependent and AddGuardian actions
+func (g *Guardianship) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the CreateDependent or AddGuardian actions to add a guardian")
+}
+
+// ODataBeforeUpdate prevents changing guardianships
+func (g *Guardianship) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: guardianships cannot be changed")
+}
+
+// ODataBeforeDelete lets guardians of the dependent and dependents with an own account end a
+// guardianship. Managed dependents always keep at least one guardian.
+func (g *Guardianship) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !CanActFor(userID, g.DependentID) {
+		return fmt.Errorf("unauthorized: only guardians or the dependent can remove a guardian")
+	}
+
+	var dependent User
+	if err := database.Db.Where("id = ?", g.DependentID).First(&dependent).Error; err != nil {
+		return fmt.Errorf("dependent not found")
+	}
+	if dependent.IsManagedDependent() {
+		var guardians int64
+		if err := database.Db.Model(&Guardianship{}).Where("dependent_id = ?", g.DependentID).Count(&guardians).Error; err != nil {
+			return err
+		}
+		if guardians <= 1 {
+			return ErrLastGuardian
+		}
+	}
+	return nil
+}
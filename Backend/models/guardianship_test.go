@@ -0,0 +1,151 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func guardianRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/Guardianships", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestGuardianship(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	guardian, _ := handlers.CreateTestUser(t, "guardian@example.com")
+	coGuardian, _ := handlers.CreateTestUser(t, "co-guardian@example.com")
+	owner, _ := handlers.CreateTestUser(t, "youth-owner@example.com")
+	other, _ := handlers.CreateTestUser(t, "youth-other@example.com")
+	club := handlers.CreateTestClub(t, owner, "Youth Club")
+	handlers.CreateTestMember(t, other, club, "member")
+	require.NoError(t, db.Model(&models.ClubSettings{}).Where("club_id = ?", club.ID).
+		Updates(map[string]interface{}{"events_enabled": true, "fines_enabled": true}).Error)
+
+	_, err := models.CreateDependent(guardian.ID, " ", "Kid", nil)
+	assert.ErrorIs(t, err, models.ErrDependentNameRequired)
+
+	dependent, err := models.CreateDependent(guardian.ID, "Kid", "Example", nil)
+	require.NoError(t, err)
+	assert.True(t, dependent.IsManagedDependent())
+	assert.True(t, models.IsGuardianOf(guardian.ID, dependent.ID))
+	handlers.CreateTestMember(t, *dependent, club, "member")
+
+	t.Run("guardians can be added by guardians only", func(t *testing.T) {
+		_, err := models.AddGuardian(dependent.ID, other.ID, coGuardian.Email)
+		assert.ErrorIs(t, err, models.ErrNotGuardian)
+		_, err = models.AddGuardian(dependent.ID, guardian.ID, "nobody@example.com")
+		assert.ErrorIs(t, err, models.ErrGuardianNotFound)
+
+		_, err = models.AddGuardian(dependent.ID, guardian.ID, coGuardian.Email)
+		require.NoError(t, err)
+		_, err = models.AddGuardian(dependent.ID, guardian.ID, coGuardian.Email)
+		assert.ErrorIs(t, err, models.ErrAlreadyGuardian)
+	})
+
+	t.Run("guardian RSVPs for dependent", func(t *testing.T) {
+		start := time.Now().Add(24 * time.Hour)
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Training", StartTime: start, EndTime: start.Add(2 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&event).Error)
+
+		ctx, req := guardianRequest(guardian.ID)
+		rsvp := models.EventRSVP{EventID: event.ID, UserID: dependent.ID, Response: "yes"}
+		require.NoError(t, rsvp.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&rsvp).Error)
+		require.NoError(t, rsvp.ODataBeforeUpdate(ctx, req))
+
+		otherRSVP := models.EventRSVP{EventID: event.ID, UserID: other.ID, Response: "no"}
+		assert.Error(t, otherRSVP.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&otherRSVP).Error)
+
+		// The guardian sees the dependent's event and RSVP, but no RSVPs of other members
+		scopes, err := models.Event{}.ODataBeforeReadCollection(ctx, req, nil)
+		require.NoError(t, err)
+		var events []models.Event
+		require.NoError(t, db.Scopes(scopes...).Find(&events).Error)
+		assert.Len(t, events, 1)
+
+		scopes, err = models.EventRSVP{}.ODataBeforeReadCollection(ctx, req, nil)
+		require.NoError(t, err)
+		var rsvps []models.EventRSVP
+		require.NoError(t, db.Scopes(scopes...).Find(&rsvps).Error)
+		require.Len(t, rsvps, 1)
+		assert.Equal(t, dependent.ID, rsvps[0].UserID)
+
+		strangerCtx, strangerReq := guardianRequest(other.ID)
+		assert.Error(t, rsvp.ODataBeforeUpdate(strangerCtx, strangerReq))
+	})
+
+	t.Run("guardian sees only the dependent's fines", func(t *testing.T) {
+		for _, userID := range []string{dependent.ID, other.ID} {
+			fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: userID, Reason: "Late", Amount: 2, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+			require.NoError(t, db.Create(&fine).Error)
+		}
+
+		ctx, req := guardianRequest(guardian.ID)
+		scopes, err := models.Fine{}.ODataBeforeReadCollection(ctx, req, nil)
+		require.NoError(t, err)
+		var fines []models.Fine
+		require.NoError(t, db.Scopes(scopes...).Find(&fines).Error)
+		require.Len(t, fines, 1)
+		assert.Equal(t, dependent.ID, fines[0].UserID)
+	})
+
+	t.Run("guardians receive the dependent's notifications", func(t *testing.T) {
+		require.NoError(t, models.CreateNotification(dependent.ID, "event_created", "New event", "Training", &club.ID, nil, nil))
+
+		for _, guardianID := range []string{guardian.ID, coGuardian.ID} {
+			var copies []models.Notification
+			require.NoError(t, db.Where("user_id = ? AND type = ?", guardianID, "event_created").Find(&copies).Error)
+			require.Len(t, copies, 1)
+			require.NotNil(t, copies[0].DependentID)
+			assert.Equal(t, dependent.ID, *copies[0].DependentID)
+			assert.Equal(t, "Kid: New event", copies[0].Title)
+		}
+	})
+
+	t.Run("managed dependents keep one guardian", func(t *testing.T) {
+		var guardianships []models.Guardianship
+		require.NoError(t, db.Where("dependent_id = ?", dependent.ID).Order("created_at").Find(&guardianships).Error)
+		require.Len(t, guardianships, 2)
+
+		ctx, req := guardianRequest(other.ID)
+		assert.Error(t, guardianships[1].ODataBeforeDelete(ctx, req))
+
+		ctx, req = guardianRequest(guardian.ID)
+		require.NoError(t, guardianships[1].ODataBeforeDelete(ctx, req))
+		require.NoError(t, db.Delete(&guardianships[1]).Error)
+		assert.ErrorIs(t, guardianships[0].ODataBeforeDelete(ctx, req), models.ErrLastGuardian)
+	})
+
+	t.Run("purging the last guardian anonymizes managed dependents", func(t *testing.T) {
+		_, err := models.RequestAccountDeletion(guardian.ID, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.AccountDeletion{}).Where("user_id = ?", guardian.ID).
+			Update("scheduled_for", time.Now().Add(-time.Minute)).Error)
+		require.NoError(t, models.PurgeDeletedAccounts())
+
+		var purged models.User
+		require.NoError(t, db.Where("id = ?", dependent.ID).First(&purged).Error)
+		assert.Equal(t, "Deleted", purged.FirstName)
+		var memberships int64
+		db.Model(&models.Member{}).Where("user_id = ?", dependent.ID).Count(&memberships)
+		assert.Zero(t, memberships)
+		err = db.Where("dependent_id = ?", dependent.ID).First(&models.Guardianship{}).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/odata"
+	"github.com/stretchr/testify/require"
+)
+
+// authedRequest returns a context and request authenticated as the user, for calling hooks directly
+func authedRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+// odataRequest sends a request with the user's access token through the OData service, the way
+// the API serves it, so hooks run in the order go-odata calls them
+func odataRequest(t *testing.T, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	service, err := odata.NewService(handlers.GetDB())
+	require.NoError(t, err)
+	handler := http.StripPrefix("/api/v2", odata.AuthMiddleware(auth.GetJWTSecret())(service))
+
+	var payload []byte
+	if body != nil {
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, "/api/v2"+path, bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
@@ -226,7 +226,7 @@ func (i Invite) ODataBeforeReadCollection(ctx context.Context, r *http.Request,
 
 	// User can see invites for their email OR invites for clubs they are admin/owner of
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("email = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND role IN ('admin', 'owner'))", user.Email, userID)
+		return db.Where("email = ? OR club_id IN ("+permittedClubsQuery+")", user.Email, userID, userID, PermissionMembersInvite)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -247,7 +247,7 @@ func (i Invite) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts
 
 	// User can see invites for their email OR invites for clubs they are admin/owner of
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("email = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND role IN ('admin', 'owner'))", user.Email, userID)
+		return db.Where("email = ? OR club_id IN ("+permittedClubsQuery+")", user.Email, userID, userID, PermissionMembersInvite)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -260,9 +260,8 @@ func (i *Invite) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", i.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the members.invite permission in the club
+	if !HasPermission(i.ClubID, userID, PermissionMembersInvite) {
 		return fmt.Errorf("unauthorized: only admins and owners can create invites")
 	}
 
@@ -293,8 +292,7 @@ func (i *Invite) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return nil
 	}
 
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", i.ClubID, userID).First(&existingMember).Error; err != nil {
+	if !HasPermission(i.ClubID, userID, PermissionMembersInvite) {
 		return fmt.Errorf("unauthorized: can only delete your own invites or invites for clubs you admin")
 	}
 
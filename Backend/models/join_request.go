@@ -72,13 +72,7 @@ func AcceptJoinRequest(requestId, adminUserId string) error {
 	}
 
 	// Verify the admin has permission
-	var admin User
-	err = database.Db.Where("id = ?", adminUserId).First(&admin).Error
-	if err != nil {
-		return err
-	}
-
-	if !club.IsOwner(admin) && !club.IsAdmin(admin) {
+	if !HasPermission(joinRequest.ClubID, adminUserId, PermissionMembersInvite) {
 		return fmt.Errorf("user not authorized to accept this request")
 	}
 
@@ -113,20 +107,8 @@ func RejectJoinRequest(requestId, adminUserId string) error {
 		return err
 	}
 
-	var club Club
-	err = database.Db.Where("id = ?", joinRequest.ClubID).First(&club).Error
-	if err != nil {
-		return err
-	}
-
 	// Verify the admin has permission
-	var admin User
-	err = database.Db.Where("id = ?", adminUserId).First(&admin).Error
-	if err != nil {
-		return err
-	}
-
-	if !club.IsOwner(admin) && !club.IsAdmin(admin) {
+	if !HasPermission(joinRequest.ClubID, adminUserId, PermissionMembersInvite) {
 		return fmt.Errorf("user not authorized to reject this request")
 	}
 
@@ -226,16 +208,16 @@ func (c *Club) GetJoinRequestCount() (int64, error) {
 	return count, err
 }
 
-// ODataBeforeReadCollection filters join requests - admins see requests for their clubs, users see their own
+// ODataBeforeReadCollection filters join requests - members who handle invites see requests for their clubs, users see their own
 func (jr JoinRequest) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
 	if !ok || userID == "" {
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can see their own requests OR requests for clubs they are admin/owner of
+	// User can see their own requests, those of their dependents OR requests for clubs in which they handle invites
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("user_id = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND role IN ('admin', 'owner'))", userID, userID)
+		return db.Where("user_id = ? OR user_id IN ("+dependentsQuery+") OR club_id IN ("+permittedClubsQuery+")", userID, userID, userID, userID, PermissionMembersInvite)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -248,9 +230,9 @@ func (jr JoinRequest) ODataBeforeReadEntity(ctx context.Context, r *http.Request
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can see their own requests OR requests for clubs they are admin/owner of
+	// User can see their own requests, those of their dependents OR requests for clubs in which they handle invites
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("user_id = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND role IN ('admin', 'owner'))", userID, userID)
+		return db.Where("user_id = ? OR user_id IN ("+dependentsQuery+") OR club_id IN ("+permittedClubsQuery+")", userID, userID, userID, userID, PermissionMembersInvite)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -263,10 +245,10 @@ func (jr *JoinRequest) ODataBeforeCreate(ctx context.Context, r *http.Request) e
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// Users can only create their own join requests
+	// Users can only create join requests for themselves or their dependents
 	if jr.UserID == "" {
 		jr.UserID = userID
-	} else if jr.UserID != userID {
+	} else if !CanActFor(userID, jr.UserID) {
 		return fmt.Errorf("unauthorized: cannot create join request for another user")
 	}
 
@@ -285,13 +267,12 @@ func (jr *JoinRequest) ODataBeforeDelete(ctx context.Context, r *http.Request) e
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can delete their own requests or requests for clubs they admin
-	if jr.UserID == userID {
+	// User can delete their own requests, those of their dependents or requests for clubs they admin
+	if CanActFor(userID, jr.UserID) {
 		return nil
 	}
 
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", jr.ClubID, userID).First(&existingMember).Error; err != nil {
+	if !HasPermission(jr.ClubID, userID, PermissionMembersInvite) {
 		return fmt.Errorf("unauthorized: can only delete your own join requests or requests for clubs you admin")
 	}
 
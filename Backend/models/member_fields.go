@@ -0,0 +1,536 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Member field types
+const (
+	MemberFieldTypeText    = "text"
+	MemberFieldTypeNumber  = "number"
+	MemberFieldTypeDate    = "date"
+	MemberFieldTypeSelect  = "select"
+	MemberFieldTypeBoolean = "boolean"
+)
+
+// Member field visibility
+const (
+	MemberFieldVisibilitySelf   = "self"   // The member (and their guardians) and club admins
+	MemberFieldVisibilityAdmins = "admins" // Club admins only
+	MemberFieldVisibilityAll    = "all"    // All club members
+)
+
+// maxMemberFieldValueLength limits text values
+const maxMemberFieldValueLength = 500
+
+var (
+	ErrMemberFieldNotFound  = errors.New("member field not found in this club")
+	ErrMemberFieldRequired  = errors.New("a value is required for this member field")
+	ErrMemberFieldForbidden = errors.New("not allowed to change this member field")
+)
+
+// MemberFieldDefinition is a club-defined field of the member profile, such as jersey number,
+// license number, shirt size or emergency contact
+type MemberFieldDefinition struct {
+	ID         string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID     string    `json:"ClubID" gorm:"type:uuid;not null;uniqueIndex:idx_member_field_club_name" odata:"required"`
+	Name       string    `json:"Name" gorm:"not null;uniqueIndex:idx_member_field_club_name" odata:"required"`
+	Type       string    `json:"Type" gorm:"not null" odata:"required"`     // text, number, date, select, boolean
+	Options    string    `json:"Options,omitempty" gorm:"type:text"`        // JSON array of the choices of select fields
+	Visibility string    `json:"Visibility" gorm:"not null;default:admins"` // self, admins, all
+	Required   bool      `json:"Required" gorm:"default:false"`             // Values cannot be cleared
+	SortOrder  int       `json:"SortOrder" gorm:"default:0"`                // Position in the profile and exports
+	CreatedAt  time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy  string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt  time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy  string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+}
+
+// MemberFieldValue is the value of a member field for one member. Values are stored normalized:
+// numbers in their shortest form, dates as YYYY-MM-DD and booleans as true/false.
+type MemberFieldValue struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID    string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	MemberID  string    `json:"MemberID" gorm:"type:uuid;not null;uniqueIndex:idx_member_field_value" odata:"auto"`
+	FieldID   string    `json:"FieldID" gorm:"type:uuid;not null;uniqueIndex:idx_member_field_value;index" odata:"auto"`
+	Value     string    `json:"Value" gorm:"not null" odata:"auto"`
+	UpdatedAt time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Field  *MemberFieldDefinition `gorm:"foreignKey:FieldID" json:"Field,omitempty" odata:"nav"`
+	Member *Member                `gorm:"foreignKey:MemberID" json:"Member,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new member field definitions
+func (d *MemberFieldDefinition) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new member field values
+func (v *MemberFieldValue) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// GetOptions returns the choices of a select field
+func (d *MemberFieldDefinition) GetOptions() ([]string, error) {
+	if d.Options == "" {
+		return nil, nil
+	}
+	var options []string
+	if err := json.Unmarshal([]byte(d.Options), &options); err != nil {
+		return nil, fmt.Errorf("options must be a JSON array of strings")
+	}
+	return options, nil
+}
+
+// validate checks the definition before it is stored
+func (d *MemberFieldDefinition) validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" || len(d.Name) > 100 {
+		return fmt.Errorf("name is required and must be at most 100 characters")
+	}
+
+	switch d.Type {
+	case MemberFieldTypeText, MemberFieldTypeNumber, MemberFieldTypeDate, MemberFieldTypeBoolean:
+		if d.Options != "" {
+			return fmt.Errorf("options are only allowed for select fields")
+		}
+	case MemberFieldTypeSelect:
+		options, err := d.GetOptions()
+		if err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return fmt.Errorf("select fields need at least one option")
+		}
+		seen := make(map[string]bool)
+		for _, option := range options {
+			if strings.TrimSpace(option) == "" || seen[option] {
+				return fmt.Errorf("options must be unique and not empty")
+			}
+			seen[option] = true
+		}
+	default:
+		return fmt.Errorf("invalid type: must be text, number, date, select or boolean")
+	}
+
+	if d.Visibility == "" {
+		d.Visibility = MemberFieldVisibilityAdmins
+	}
+	switch d.Visibility {
+	case MemberFieldVisibilitySelf, MemberFieldVisibilityAdmins, MemberFieldVisibilityAll:
+	default:
+		return fmt.Errorf("invalid visibility: must be self, admins or all")
+	}
+
+	return nil
+}
+
+// checkStoredValues checks that the values stored for the field remain valid with the updated
+// definition: the type cannot change once values exist, removed options must be unused and a
+// field can only become required when every member has a value.
+func (d *MemberFieldDefinition) checkStoredValues(tx *gorm.DB, updated *MemberFieldDefinition) error {
+	var values []MemberFieldValue
+	if err := tx.Where("field_id = ?", d.ID).Find(&values).Error; err != nil {
+		return fmt.Errorf("failed to load member field values: %w", err)
+	}
+
+	if updated.Type != d.Type && len(values) > 0 {
+		return fmt.Errorf("type cannot be changed once members have values for this field")
+	}
+
+	if updated.Type == MemberFieldTypeSelect {
+		options, err := updated.GetOptions()
+		if err != nil {
+			return err
+		}
+		allowed := make(map[string]bool, len(options))
+		for _, option := range options {
+			allowed[option] = true
+		}
+		for _, value := range values {
+			if !allowed[value.Value] {
+				return fmt.Errorf("option '%s' is still used by members and cannot be removed", value.Value)
+			}
+		}
+	}
+
+	if updated.Required && !d.Required {
+		var missing int64
+		err := tx.Model(&Member{}).
+			Where("club_id = ? AND id NOT IN (SELECT member_id FROM member_field_values WHERE field_id = ?)", d.ClubID, d.ID).
+			Count(&missing).Error
+		if err != nil {
+			return fmt.Errorf("failed to check member field values: %w", err)
+		}
+		if missing > 0 {
+			return fmt.Errorf("field cannot be required while %d members have no value", missing)
+		}
+	}
+
+	return nil
+}
+
+// normalizeValue validates a value against the field type and returns it in its stored form.
+// An empty value clears the field.
+func (d *MemberFieldDefinition) normalizeValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	switch d.Type {
+	case MemberFieldTypeText:
+		if len(value) > maxMemberFieldValueLength {
+			return "", fmt.Errorf("value must be at most %d characters", maxMemberFieldValueLength)
+		}
+		return value, nil
+	case MemberFieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", fmt.Errorf("value must be a number")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case MemberFieldTypeDate:
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", fmt.Errorf("value must be a date in format YYYY-MM-DD")
+		}
+		return date.Format("2006-01-02"), nil
+	case MemberFieldTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("value must be true or false")
+		}
+		return strconv.FormatBool(b), nil
+	case MemberFieldTypeSelect:
+		options, err := d.GetOptions()
+		if err != nil {
+			return "", err
+		}
+		for _, option := range options {
+			if option == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("value must be one of the options of the field")
+	}
+	return "", fmt.Errorf("unsupported field type %q", d.Type)
+}
+
+// canEditMemberField reports whether a user may change a member's value of the field. Admins
+// edit every field; members and their guardians edit their own fields unless they are admin-only.
+func canEditMemberField(field *MemberFieldDefinition, member *Member, userID string) bool {
+	if HasPermission(field.ClubID, userID, PermissionMembersManage) {
+		return true
+	}
+	return field.Visibility != MemberFieldVisibilityAdmins && CanActFor(userID, member.UserID)
+}
+
+// GetMemberFieldDefinitions returns the member fields of a club in display order
+func GetMemberFieldDefinitions(clubID string) ([]MemberFieldDefinition, error) {
+	var fields []MemberFieldDefinition
+	err := database.Db.Where("club_id = ?", clubID).Order("sort_order, name").Find(&fields).Error
+	return fields, err
+}
+
+// SetMemberFieldValue stores a member's value of a field. An empty value clears the field
+// unless it is required; the returned value is nil then.
+func SetMemberFieldValue(member *Member, fieldID, value, setBy string) (*MemberFieldValue, error) {
+	var field MemberFieldDefinition
+	if err := database.Db.Where("id = ? AND club_id = ?", fieldID, member.ClubID).First(&field).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberFieldNotFound
+		}
+		return nil, err
+	}
+	if !canEditMemberField(&field, member, setBy) {
+		return nil, ErrMemberFieldForbidden
+	}
+
+	normalized, err := field.normalizeValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if normalized == "" {
+		if field.Required {
+			return nil, ErrMemberFieldRequired
+		}
+		return nil, database.Db.Where("member_id = ? AND field_id = ?", member.ID, field.ID).Delete(&MemberFieldValue{}).Error
+	}
+
+	var fieldValue MemberFieldValue
+	err = database.Db.Where("member_id = ? AND field_id = ?", member.ID, field.ID).First(&fieldValue).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	fieldValue.ClubID = member.ClubID
+	fieldValue.MemberID = member.ID
+	fieldValue.FieldID = field.ID
+	fieldValue.Value = normalized
+	fieldValue.UpdatedAt = time.Now()
+	fieldValue.UpdatedBy = setBy
+	if err := database.Db.Save(&fieldValue).Error; err != nil {
+		return nil, err
+	}
+	return &fieldValue, nil
+}
+
+// ExportMembers returns the members of a club with their member field values as CSV
+func ExportMembers(clubID string) ([]byte, error) {
+	fields, err := GetMemberFieldDefinitions(clubID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	if err := database.Db.Preload("User").Where("club_id = ?", clubID).Order("created_at ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	var values []MemberFieldValue
+	if err := database.Db.Where("club_id = ?", clubID).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	valueByMember := make(map[string]map[string]string)
+	for _, value := range values {
+		if valueByMember[value.MemberID] == nil {
+			valueByMember[value.MemberID] = make(map[string]string)
+		}
+		valueByMember[value.MemberID][value.FieldID] = value.Value
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := []string{"FirstName", "LastName", "Email", "Role", "Status", "JoinedAt"}
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		var firstName, lastName, email string
+		if member.User != nil {
+			firstName, lastName = member.User.FirstName, member.User.LastName
+			if !member.User.IsManagedDependent() {
+				email = member.User.Email
+			}
+		}
+		record := []string{firstName, lastName, email, member.Role, string(member.Status), member.CreatedAt.UTC().Format("2006-01-02")}
+		for _, field := range fields {
+			record = append(record, valueByMember[member.ID][field.ID])
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// ODataBeforeReadCollection lets club members read the field definitions; admin-only fields
+// are only visible to members holding members.manage
+func (d MemberFieldDefinition) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN ("+permittedClubsQuery+") OR (visibility <> ? AND club_id IN ("+actingClubsQuery+"))",
+			userID, userID, PermissionMembersManage, MemberFieldVisibilityAdmins, userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific field definition
+func (d MemberFieldDefinition) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return d.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates member field creation permissions
+func (d *MemberFieldDefinition) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(d.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only members with members.manage can define member fields")
+	}
+
+	if err := d.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	d.CreatedAt = now
+	d.CreatedBy = userID
+	d.UpdatedAt = now
+	d.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate records the new member field in the audit log
+func (d *MemberFieldDefinition) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, d.ClubID, "MemberFieldDefinition", d.ID, AuditOperationCreate, d)
+}
+
+// ODataBeforeUpdate validates member field update permissions
+func (d *MemberFieldDefinition) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "MemberFieldDefinition", d.ID, d)
+
+	if !HasPermission(d.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only members with members.manage can update member fields")
+	}
+
+	updated, err := updatedEntity(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving fields to another club
+	if updated.ClubID != d.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing member field")
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := d.checkStoredValues(tx, updated); err != nil {
+		return err
+	}
+
+	d.UpdatedAt = time.Now()
+	d.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate records the member field change in the audit log
+func (d *MemberFieldDefinition) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, d.ClubID, "MemberFieldDefinition", d.ID, AuditOperationUpdate, d)
+}
+
+// ODataBeforeDelete validates member field deletion permissions and removes the stored values
+func (d *MemberFieldDefinition) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(d.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only members with members.manage can delete member fields")
+	}
+
+	return database.Db.Where("field_id = ?", d.ID).Delete(&MemberFieldValue{}).Error
+}
+
+// ODataAfterDelete records the deleted member field in the audit log
+func (d *MemberFieldDefinition) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, d.ClubID, "MemberFieldDefinition", d.ID, AuditOperationDelete, d)
+}
+
+// memberFieldValueScope limits values to the fields' visibility: admins see all values of their
+// clubs, members see values of fields visible to all members, and members and their guardians
+// see the member's own values of fields visible to the member
+func memberFieldValueScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN ("+permittedClubsQuery+") OR "+
+			"(field_id IN (SELECT id FROM member_field_definitions WHERE visibility = ?) AND club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')) OR "+
+			"(field_id IN (SELECT id FROM member_field_definitions WHERE visibility IN ?) AND member_id IN (SELECT id FROM members WHERE (user_id = ? OR user_id IN ("+dependentsQuery+")) AND status <> 'former'))",
+			userID, userID, PermissionMembersManage,
+			MemberFieldVisibilityAll, userID,
+			[]string{MemberFieldVisibilitySelf, MemberFieldVisibilityAll}, userID, userID)
+	}
+}
+
+// ODataBeforeReadCollection filters member field values by the visibility of their fields
+func (v MemberFieldValue) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{memberFieldValueScope(userID)}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific member field value
+func (v MemberFieldValue) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return v.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating values directly; use the SetFieldValue action
+func (v *MemberFieldValue) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetFieldValue action on Members to set a member field")
+}
+
+// ODataBeforeUpdate prevents changing values directly; use the SetFieldValue action
+func (v *MemberFieldValue) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetFieldValue action on Members to set a member field")
+}
+
+// ODataBeforeDelete lets the editors of a field clear optional values
+func (v *MemberFieldValue) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var field MemberFieldDefinition
+	if err := database.Db.Where("id = ?", v.FieldID).First(&field).Error; err != nil {
+		return fmt.Errorf("member field not found")
+	}
+	var member Member
+	if err := database.Db.Where("id = ?", v.MemberID).First(&member).Error; err != nil {
+		return fmt.Errorf("member not found")
+	}
+
+	if !canEditMemberField(&field, &member, userID) {
+		return fmt.Errorf("unauthorized: %w", ErrMemberFieldForbidden)
+	}
+	if field.Required {
+		return ErrMemberFieldRequired
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memberFieldRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/MemberFieldDefinitions", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestMemberFields(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "fields-owner@example.com")
+	player, _ := handlers.CreateTestUser(t, "fields-player@example.com")
+	teammate, _ := handlers.CreateTestUser(t, "fields-teammate@example.com")
+	club := handlers.CreateTestClub(t, owner, "Fields Club")
+	playerMember := handlers.CreateTestMember(t, player, club, "member")
+	handlers.CreateTestMember(t, teammate, club, "member")
+
+	createField := func(field models.MemberFieldDefinition) models.MemberFieldDefinition {
+		t.Helper()
+		field.ClubID = club.ID
+		ctx, req := memberFieldRequest(owner.ID)
+		require.NoError(t, field.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&field).Error)
+		return field
+	}
+
+	t.Run("definitions are validated", func(t *testing.T) {
+		ctx, req := memberFieldRequest(owner.ID)
+		invalid := []models.MemberFieldDefinition{
+			{ClubID: club.ID, Name: "Size", Type: "color"},
+			{ClubID: club.ID, Name: "Size", Type: models.MemberFieldTypeSelect},
+			{ClubID: club.ID, Name: "Size", Type: models.MemberFieldTypeSelect, Options: `["M", "M"]`},
+			{ClubID: club.ID, Name: "Number", Type: models.MemberFieldTypeNumber, Options: `["1"]`},
+			{ClubID: club.ID, Name: "Number", Type: models.MemberFieldTypeNumber, Visibility: "public"},
+		}
+		for _, field := range invalid {
+			assert.Error(t, field.ODataBeforeCreate(ctx, req), field.Name)
+		}
+
+		ctx, req = memberFieldRequest(player.ID)
+		field := models.MemberFieldDefinition{ClubID: club.ID, Name: "Number", Type: models.MemberFieldTypeNumber}
+		assert.Error(t, field.ODataBeforeCreate(ctx, req))
+	})
+
+	jersey := createField(models.MemberFieldDefinition{Name: "Jersey number", Type: models.MemberFieldTypeNumber, Visibility: models.MemberFieldVisibilityAll, SortOrder: 1})
+	size := createField(models.MemberFieldDefinition{Name: "Shirt size", Type: models.MemberFieldTypeSelect, Options: `["S","M","L"]`, Visibility: models.MemberFieldVisibilitySelf, Required: true, SortOrder: 2})
+	license := createField(models.MemberFieldDefinition{Name: "License", Type: models.MemberFieldTypeText, SortOrder: 3})
+	assert.Equal(t, models.MemberFieldVisibilityAdmins, license.Visibility)
+
+	t.Run("values are validated and normalized", func(t *testing.T) {
+		value, err := models.SetMemberFieldValue(&playerMember, jersey.ID, " 07 ", player.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "7", value.Value)
+
+		_, err = models.SetMemberFieldValue(&playerMember, jersey.ID, "seven", player.ID)
+		assert.Error(t, err)
+		_, err = models.SetMemberFieldValue(&playerMember, size.ID, "XL", player.ID)
+		assert.Error(t, err)
+
+		_, err = models.SetMemberFieldValue(&playerMember, size.ID, "M", player.ID)
+		require.NoError(t, err)
+		_, err = models.SetMemberFieldValue(&playerMember, size.ID, "", player.ID)
+		assert.ErrorIs(t, err, models.ErrMemberFieldRequired)
+
+		// Admin-only fields are set by admins only
+		_, err = models.SetMemberFieldValue(&playerMember, license.ID, "DE-123", player.ID)
+		assert.ErrorIs(t, err, models.ErrMemberFieldForbidden)
+		_, err = models.SetMemberFieldValue(&playerMember, license.ID, "DE-123", owner.ID)
+		require.NoError(t, err)
+
+		// Members cannot change other members' fields
+		_, err = models.SetMemberFieldValue(&playerMember, jersey.ID, "9", teammate.ID)
+		assert.ErrorIs(t, err, models.ErrMemberFieldForbidden)
+	})
+
+	t.Run("definitions with values keep them valid", func(t *testing.T) {
+		patch := func(field models.MemberFieldDefinition, changes map[string]interface{}) int {
+			return odataRequest(t, ownerToken, http.MethodPatch, "/MemberFieldDefinitions("+field.ID+")", changes).Code
+		}
+
+		assert.Equal(t, http.StatusForbidden, patch(jersey, map[string]interface{}{"Type": models.MemberFieldTypeText}))
+		assert.Equal(t, http.StatusForbidden, patch(jersey, map[string]interface{}{"Type": "color"}))
+		assert.Equal(t, http.StatusForbidden, patch(jersey, map[string]interface{}{"Options": `["1"]`}))
+		assert.Equal(t, http.StatusForbidden, patch(jersey, map[string]interface{}{"Required": true}), "teammate has no value")
+		assert.Equal(t, http.StatusForbidden, patch(size, map[string]interface{}{"Options": `["S","L"]`}), "M is in use")
+		assert.Equal(t, http.StatusForbidden, patch(size, map[string]interface{}{"Options": `[]`}))
+		assert.Equal(t, http.StatusForbidden, patch(size, map[string]interface{}{"ClubID": playerMember.ID}))
+
+		assert.Less(t, patch(size, map[string]interface{}{"Options": `["M","L","XL"]`}), 300)
+		var storedSize, storedJersey models.MemberFieldDefinition
+		require.NoError(t, db.First(&storedSize, "id = ?", size.ID).Error)
+		assert.Equal(t, `["M","L","XL"]`, storedSize.Options)
+		require.NoError(t, db.First(&storedJersey, "id = ?", jersey.ID).Error)
+		assert.Equal(t, models.MemberFieldTypeNumber, storedJersey.Type)
+		assert.False(t, storedJersey.Required)
+	})
+
+	t.Run("values are visible according to the field", func(t *testing.T) {
+		visibleFields := func(userID string) []string {
+			ctx, req := memberFieldRequest(userID)
+			scopes, err := models.MemberFieldValue{}.ODataBeforeReadCollection(ctx, req, nil)
+			require.NoError(t, err)
+			var values []models.MemberFieldValue
+			require.NoError(t, db.Scopes(scopes...).Find(&values).Error)
+			var fieldIDs []string
+			for _, value := range values {
+				fieldIDs = append(fieldIDs, value.FieldID)
+			}
+			return fieldIDs
+		}
+
+		assert.ElementsMatch(t, []string{jersey.ID, size.ID, license.ID}, visibleFields(owner.ID))
+		assert.ElementsMatch(t, []string{jersey.ID, size.ID}, visibleFields(player.ID))
+		assert.ElementsMatch(t, []string{jersey.ID}, visibleFields(teammate.ID))
+
+		// Values are filterable by field and value
+		ctx, req := memberFieldRequest(teammate.ID)
+		scopes, err := models.MemberFieldValue{}.ODataBeforeReadCollection(ctx, req, nil)
+		require.NoError(t, err)
+		var matches int64
+		require.NoError(t, db.Model(&models.MemberFieldValue{}).Scopes(scopes...).Where("field_id = ? AND value = ?", jersey.ID, "7").Count(&matches).Error)
+		assert.Equal(t, int64(1), matches)
+	})
+
+	t.Run("export includes field values", func(t *testing.T) {
+		document, err := models.ExportMembers(club.ID)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(document)), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, "FirstName,LastName,Email,Role,Status,JoinedAt,Jersey number,Shirt size,License", lines[0])
+		assert.Contains(t, string(document), "fields-player@example.com,member,")
+		assert.Contains(t, string(document), ",7,M,DE-123")
+	})
+
+	t.Run("deleting a field removes its values", func(t *testing.T) {
+		ctx, req := memberFieldRequest(owner.ID)
+		require.NoError(t, license.ODataBeforeDelete(ctx, req))
+		var count int64
+		db.Model(&models.MemberFieldValue{}).Where("field_id = ?", license.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
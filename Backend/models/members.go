@@ -24,11 +24,16 @@ type Member struct {
 	ClubID    string    `json:"ClubID" gorm:"type:uuid;uniqueIndex:idx_members_club_user" odata:"required"`
 	UserID    string    `json:"UserID" gorm:"type:uuid;uniqueIndex:idx_members_club_user" odata:"required"`
 	Role      string    `json:"Role" gorm:"default:member" odata:"required"`
-	CreatedAt time.Time `json:"CreatedAt" odata:"immutable"`
+	CreatedAt time.Time `json:"CreatedAt" odata:"immutable"` // Joining date of the current membership
 	CreatedBy string    `json:"CreatedBy" gorm:"type:uuid" odata:"required"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"required"`
 
+	// Membership status, changed through the SetStatus action; see MembershipStatusChange for the history.
+	// Leaving the club makes the member former, which hides it from queries.
+	Status      MemberStatus `json:"Status" gorm:"default:active" odata:"auto"`
+	StatusSince *time.Time   `json:"StatusSince,omitempty" odata:"auto,nullable"` // Empty: since joining
+
 	// Navigation properties for OData
 	User            *User                  `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
 	Club            *Club                  `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
@@ -100,16 +105,33 @@ func (c *Club) AddMemberViaInvite(userId, role string) error {
 }
 
 func (c *Club) addMemberWithActor(userId, role string, sendNotification bool, actorID *string) error {
-	var member Member
-	member.ID = uuid.New().String()
-	member.ClubID = c.ID
-	member.UserID = userId
-	member.Role = role
 	// Set created_by/updated_by to the adding user when available, otherwise fall back to the added user.
 	createdBy := userId
 	if actorID != nil {
 		createdBy = *actorID
 	}
+
+	// Former members rejoin with their archived member row
+	var former Member
+	result := database.Db.Unscoped().Where("club_id = ? AND user_id = ? AND status = ?", c.ID, userId, MemberStatusFormer).Limit(1).Find(&former)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if err := former.rejoin(role, createdBy); err != nil {
+			return err
+		}
+		if sendNotification {
+			former.notifyAdded(actorID)
+		}
+		return nil
+	}
+
+	var member Member
+	member.ID = uuid.New().String()
+	member.ClubID = c.ID
+	member.UserID = userId
+	member.Role = role
 	member.CreatedBy = createdBy
 	member.UpdatedBy = createdBy
 	err := database.Db.Create(&member).Error
@@ -133,22 +155,87 @@ func (c *Club) addMemberWithActor(userId, role string, sendNotification bool, ac
 	return nil
 }
 
+// rejoin makes a former member active again with the given role. The member keeps its ID and field
+// values; the joining date starts anew and the history continues.
+func (m *Member) rejoin(role, changedBy string) error {
+	now := time.Now()
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Member{}).
+			Where("id = ? AND status = ?", m.ID, MemberStatusFormer).
+			Updates(map[string]interface{}{
+				"role":         role,
+				"status":       MemberStatusActive,
+				"status_since": now,
+				"created_at":   now,
+				"created_by":   changedBy,
+				"updated_at":   now,
+				"updated_by":   changedBy,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMemberAlreadyExists
+		}
+		return recordStatusChange(tx, m.ClubID, m.UserID, MemberStatusActive, role, now, nil, optionalUserID(changedBy))
+	})
+	if err != nil {
+		return err
+	}
+
+	m.Role = role
+	m.Status = MemberStatusActive
+	m.StatusSince = &now
+	m.CreatedAt = now
+	m.CreatedBy = changedBy
+	m.UpdatedAt = now
+	m.UpdatedBy = changedBy
+	return nil
+}
+
+// DeleteMember removes a member from the club; the membership is archived as former in the history
 func (c *Club) DeleteMember(memberID string) (int64, error) {
-	result := database.Db.Where("id = ? AND club_id = ?", memberID, c.ID).Delete(&Member{})
-	return result.RowsAffected, result.Error
+	var member Member
+	if err := database.Db.Where("id = ? AND club_id = ?", memberID, c.ID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return member.remove("removed", nil)
 }
 
+// DeleteMemberByUserID lets a user leave the club; the membership is archived as former in the history
 func (c *Club) DeleteMemberByUserID(userID string) error {
-	result := database.Db.Where("user_id = ? AND club_id = ?", userID, c.ID).Delete(&Member{})
-	if result.Error != nil {
-		return result.Error
+	var member Member
+	if err := database.Db.Where("user_id = ? AND club_id = ?", userID, c.ID).First(&member).Error; err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
+	removed, err := member.remove("left", &userID)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
 		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
+// remove makes the member former and archives the membership. The member row and its field values
+// are kept for a later rejoin.
+func (m *Member) remove(reason string, changedBy *string) (int64, error) {
+	var removed int64
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", m.ID).Delete(&Member{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = result.RowsAffected
+		return archiveMembership(tx, m, reason, changedBy)
+	})
+	return removed, err
+}
+
 func (c *Club) GetMemberRole(user User) (string, error) {
 	var member Member
 	result := database.Db.Where("club_id = ? AND user_id = ?", c.ID, user.ID).First(&member)
@@ -169,7 +256,7 @@ func (c *Club) UpdateMemberRole(changingUser User, memberID, role string) error
 	}
 
 	if canChange, err := c.canChangeRole(changingUser, member, role); err != nil {
-		if err == ErrLastOwnerDemotion {
+		if err == ErrLastOwnerDemotion || err == ErrSuspendedMemberRole {
 			return err
 		}
 		return gorm.ErrInvalidData
@@ -268,6 +355,11 @@ func (c *Club) canChangeRole(changingUser User, targetMember Member, newRole str
 		}
 	}
 
+	// Suspended members have to be reinstated before they can be given admin rights
+	if targetMember.Status == MemberStatusSuspended && newRole != "member" {
+		return false, ErrSuspendedMemberRole
+	}
+
 	// Only owners can change any role
 	if changingUserRole == "owner" {
 		return true, nil
@@ -310,6 +402,12 @@ func (m *Member) BeforeCreate(tx *gorm.DB) error {
 	if m.UpdatedAt.IsZero() {
 		m.UpdatedAt = now
 	}
+	if m.Status == "" {
+		m.Status = MemberStatusActive
+	}
+	if m.StatusSince == nil {
+		m.StatusSince = &m.CreatedAt
+	}
 
 	return nil
 }
@@ -321,9 +419,9 @@ func (m Member) ODataBeforeReadCollection(ctx context.Context, r *http.Request,
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see members of clubs they belong to
+	// User can only see members of clubs they belong to and the memberships of their dependents
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') OR user_id IN ("+dependentsQuery+")", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -336,9 +434,9 @@ func (m Member) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts
 		return nil, fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// User can only see members of clubs they belong to
+	// User can only see members of clubs they belong to and the memberships of their dependents
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') OR user_id IN ("+dependentsQuery+")", userID, userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -370,6 +468,19 @@ func (m *Member) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: only admins and owners can add members")
 	}
 
+	// Former members keep their member row, so they cannot be created again
+	var existing Member
+	result := database.Db.Unscoped().Where("club_id = ? AND user_id = ?", m.ClubID, m.UserID).Limit(1).Find(&existing)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if existing.Status == MemberStatusFormer {
+			return fmt.Errorf("user is a former member of the club: invite them to rejoin instead")
+		}
+		return ErrMemberAlreadyExists
+	}
+
 	// Set CreatedBy and UpdatedBy
 	now := time.Now()
 	m.CreatedAt = now
@@ -380,6 +491,11 @@ func (m *Member) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
+// ODataAfterCreate records the new member in the audit log
+func (m *Member) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, m.ClubID, "Member", m.ID, AuditOperationCreate, m)
+}
+
 // ODataBeforeUpdate validates member update permissions
 func (m *Member) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -387,6 +503,9 @@ func (m *Member) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "Member", m.ID, m)
+
 	// Get the current member state from database before update
 	var currentMember Member
 	if err := database.Db.Where("id = ?", m.ID).First(&currentMember).Error; err != nil {
@@ -411,6 +530,9 @@ func (m *Member) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 			if err == ErrLastOwnerDemotion {
 				return fmt.Errorf("cannot demote the last owner of the club")
 			}
+			if err == ErrSuspendedMemberRole {
+				return err
+			}
 			return fmt.Errorf("unauthorized: cannot change member role")
 		}
 		if !canChange {
@@ -418,9 +540,8 @@ func (m *Member) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		}
 	}
 
-	// Check if user is an admin/owner of the club for other updates
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", currentMember.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the members.manage permission in the club
+	if !HasPermission(currentMember.ClubID, userID, PermissionMembersManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update members")
 	}
 
@@ -432,6 +553,11 @@ func (m *Member) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	return nil
 }
 
+// ODataAfterUpdate records the changes to the member in the audit log
+func (m *Member) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, m.ClubID, "Member", m.ID, AuditOperationUpdate, m)
+}
+
 // ODataBeforeDelete validates member deletion permissions
 func (m *Member) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -440,15 +566,23 @@ func (m *Member) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	}
 
 	// Check if user is an admin/owner of the club, or is deleting themselves
-	if m.UserID == userID {
-		// Users can leave clubs (delete their own membership)
+	if CanActFor(userID, m.UserID) {
+		// Users can leave clubs (delete their own membership); guardians can take their dependents out
 		return nil
 	}
 
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", m.ClubID, userID).First(&existingMember).Error; err != nil {
+	if !HasPermission(m.ClubID, userID, PermissionMembersManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can remove members")
 	}
 
 	return nil
 }
+
+// ODataAfterDelete archives the membership of the now former member and records the removal in the audit log
+func (m *Member) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	userID, _ := ctx.Value(auth.UserIDKey).(string)
+	if err := archiveMembershipInContext(ctx, m, userID); err != nil {
+		return err
+	}
+	return auditEntityChange(ctx, r, m.ClubID, "Member", m.ID, AuditOperationDelete, m)
+}
@@ -0,0 +1,360 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Membership statuses. Former members have left the club; their member row is kept for the history
+// and a later rejoin, but hidden like a deleted row.
+const (
+	MemberStatusActive    = "active"
+	MemberStatusPassive   = "passive"
+	MemberStatusSuspended = "suspended"
+	MemberStatusHonorary  = "honorary"
+	MemberStatusFormer    = "former"
+)
+
+var (
+	ErrInvalidMemberStatus   = errors.New("status must be active, passive, suspended or honorary")
+	ErrMemberStatusUnchanged = errors.New("member already has this status")
+	ErrMemberStatusDate      = errors.New("effective date must not be in the future or before the current status began")
+	ErrSuspendAdmin          = errors.New("only members without admin or owner role can be suspended")
+	ErrSuspendedMemberRole   = errors.New("suspended members cannot be promoted")
+)
+
+// MemberStatus is the status of a membership. Like gorm.DeletedAt it hides former members from
+// GORM queries and updates, and deleting a member marks it as former instead of removing the row.
+// Use Unscoped to include former members or to remove the row for good.
+type MemberStatus string
+
+// QueryClauses hides former members from queries
+func (MemberStatus) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{formerMembersHiddenClause{Field: f}}
+}
+
+// UpdateClauses hides former members from updates
+func (MemberStatus) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{formerMembersNotUpdatedClause{Field: f}}
+}
+
+// DeleteClauses turns deleting a member into marking it as former
+func (MemberStatus) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{formerMembersArchivedClause{Field: f}}
+}
+
+// formerMembersHiddenClause adds the condition excluding former members, see gorm.SoftDeleteQueryClause
+type formerMembersHiddenClause struct {
+	Field *schema.Field
+}
+
+func (c formerMembersHiddenClause) Name() string               { return "" }
+func (c formerMembersHiddenClause) Build(clause.Builder)       {}
+func (c formerMembersHiddenClause) MergeClause(*clause.Clause) {}
+func (c formerMembersHiddenClause) ModifyStatement(stmt *gorm.Statement) {
+	if _, ok := stmt.Clauses["former_members_hidden"]; ok || stmt.Statement.Unscoped {
+		return
+	}
+
+	// A single OR condition would otherwise also apply to the added condition
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if expression, ok := where.Expression.(clause.Where); ok && len(expression.Exprs) >= 1 {
+			for _, expr := range expression.Exprs {
+				if or, ok := expr.(clause.OrConditions); ok && len(or.Exprs) == 1 {
+					expression.Exprs = []clause.Expression{clause.And(expression.Exprs...)}
+					where.Expression = expression
+					stmt.Clauses["WHERE"] = where
+					break
+				}
+			}
+		}
+	}
+
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: c.Field.DBName}, Value: MemberStatusFormer},
+	}})
+	stmt.Clauses["former_members_hidden"] = clause.Clause{}
+}
+
+// formerMembersNotUpdatedClause excludes former members from updates
+type formerMembersNotUpdatedClause struct {
+	Field *schema.Field
+}
+
+func (c formerMembersNotUpdatedClause) Name() string               { return "" }
+func (c formerMembersNotUpdatedClause) Build(clause.Builder)       {}
+func (c formerMembersNotUpdatedClause) MergeClause(*clause.Clause) {}
+func (c formerMembersNotUpdatedClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		formerMembersHiddenClause(c).ModifyStatement(stmt)
+	}
+}
+
+// formerMembersArchivedClause turns the DELETE of members into an UPDATE to the former status,
+// see gorm.SoftDeleteDeleteClause
+type formerMembersArchivedClause struct {
+	Field *schema.Field
+}
+
+func (c formerMembersArchivedClause) Name() string               { return "" }
+func (c formerMembersArchivedClause) Build(clause.Builder)       {}
+func (c formerMembersArchivedClause) MergeClause(*clause.Clause) {}
+func (c formerMembersArchivedClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() > 0 || stmt.Statement.Unscoped {
+		return
+	}
+
+	now := stmt.DB.NowFunc()
+	stmt.AddClause(clause.Set{
+		{Column: clause.Column{Name: c.Field.DBName}, Value: MemberStatusFormer},
+		{Column: clause.Column{Name: "status_since"}, Value: now},
+		{Column: clause.Column{Name: "updated_at"}, Value: now},
+	})
+	stmt.SetColumn(c.Field.DBName, MemberStatus(MemberStatusFormer), true)
+	stmt.SetColumn("status_since", &now, true)
+
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+	}
+
+	formerMembersHiddenClause(c).ModifyStatement(stmt)
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(stmt.DB.Callback().Update().Clauses...)
+}
+
+// MembershipStatusChange is an entry of the membership history of a user in a club. The open entry
+// (without EffectiveUntil) holds the current status. History is kept by club and user, so it
+// survives leaving and continues when the user rejoins.
+type MembershipStatusChange struct {
+	ID             string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID         string     `json:"ClubID" gorm:"type:uuid;not null;index:idx_membership_status_club_user" odata:"auto"`
+	UserID         string     `json:"UserID" gorm:"type:uuid;not null;index:idx_membership_status_club_user" odata:"auto"`
+	Status         string     `json:"Status" gorm:"not null" odata:"auto"`
+	Role           string     `json:"Role" odata:"auto"` // Role at the time of the change
+	EffectiveFrom  time.Time  `json:"EffectiveFrom" gorm:"not null" odata:"auto"`
+	EffectiveUntil *time.Time `json:"EffectiveUntil,omitempty" odata:"auto,nullable"`
+	Reason         *string    `json:"Reason,omitempty" odata:"auto,nullable"`
+	ChangedBy      *string    `json:"ChangedBy,omitempty" gorm:"type:uuid" odata:"auto,nullable"` // Empty for changes made by the system
+	CreatedAt      time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new history entries
+func (sc *MembershipStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if sc.ID == "" {
+		sc.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// isSettableMemberStatus reports whether a status can be set through SetMemberStatus
+func isSettableMemberStatus(status string) bool {
+	switch status {
+	case MemberStatusActive, MemberStatusPassive, MemberStatusSuspended, MemberStatusHonorary:
+		return true
+	}
+	return false
+}
+
+// optionalUserID returns nil for an empty user ID
+func optionalUserID(userID string) *string {
+	if userID == "" {
+		return nil
+	}
+	return &userID
+}
+
+// statusStart returns since when the member has their current status
+func (m *Member) statusStart() time.Time {
+	if m.StatusSince != nil {
+		return *m.StatusSince
+	}
+	return m.CreatedAt
+}
+
+// ensureStatusHistory opens the history of members that joined before statuses were recorded
+func ensureStatusHistory(tx *gorm.DB, m *Member) error {
+	var open int64
+	if err := tx.Model(&MembershipStatusChange{}).
+		Where("club_id = ? AND user_id = ? AND effective_until IS NULL", m.ClubID, m.UserID).
+		Count(&open).Error; err != nil {
+		return err
+	}
+	if open > 0 {
+		return nil
+	}
+
+	status := string(m.Status)
+	if status == "" {
+		status = MemberStatusActive
+	}
+	return tx.Create(&MembershipStatusChange{
+		ClubID:        m.ClubID,
+		UserID:        m.UserID,
+		Status:        status,
+		Role:          m.Role,
+		EffectiveFrom: m.statusStart(),
+		ChangedBy:     optionalUserID(m.CreatedBy),
+	}).Error
+}
+
+// recordStatusChange closes the open history entry of the membership and opens one with the new status
+func recordStatusChange(tx *gorm.DB, clubID, userID, status, role string, from time.Time, reason, changedBy *string) error {
+	if err := tx.Model(&MembershipStatusChange{}).
+		Where("club_id = ? AND user_id = ? AND effective_until IS NULL", clubID, userID).
+		Update("effective_until", from).Error; err != nil {
+		return err
+	}
+	return tx.Create(&MembershipStatusChange{
+		ClubID:        clubID,
+		UserID:        userID,
+		Status:        status,
+		Role:          role,
+		EffectiveFrom: from,
+		Reason:        reason,
+		ChangedBy:     changedBy,
+	}).Error
+}
+
+// archiveMembership records that the member left the club. The caller marks the member row as former.
+func archiveMembership(tx *gorm.DB, m *Member, reason string, changedBy *string) error {
+	if err := ensureStatusHistory(tx, m); err != nil {
+		return fmt.Errorf("failed to archive membership: %w", err)
+	}
+	if err := recordStatusChange(tx, m.ClubID, m.UserID, MemberStatusFormer, m.Role, time.Now(), &reason, changedBy); err != nil {
+		return fmt.Errorf("failed to archive membership: %w", err)
+	}
+	return nil
+}
+
+// AfterCreate opens the history entry of a new membership; for a rejoining user this closes the former status
+func (m *Member) AfterCreate(tx *gorm.DB) error {
+	return recordStatusChange(tx, m.ClubID, m.UserID, string(m.Status), m.Role, m.statusStart(), nil, optionalUserID(m.CreatedBy))
+}
+
+// SetMemberStatus changes the status of a member as of the given date and records it in the history.
+// The date may lie in the past, but not before the current status began.
+func SetMemberStatus(m *Member, status string, effectiveFrom time.Time, reason, changedBy string) error {
+	if !isSettableMemberStatus(status) {
+		return ErrInvalidMemberStatus
+	}
+	if status == string(m.Status) {
+		return ErrMemberStatusUnchanged
+	}
+	if status == MemberStatusSuspended && m.Role != "member" {
+		return ErrSuspendAdmin
+	}
+	if effectiveFrom.After(time.Now()) || effectiveFrom.Before(m.statusStart()) {
+		return ErrMemberStatusDate
+	}
+
+	var reasonPtr *string
+	if reason = strings.TrimSpace(reason); reason != "" {
+		reasonPtr = &reason
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureStatusHistory(tx, m); err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, m.ClubID, m.UserID, status, m.Role, effectiveFrom, reasonPtr, &changedBy); err != nil {
+			return err
+		}
+		return tx.Model(&Member{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+			"status":       status,
+			"status_since": effectiveFrom,
+			"updated_at":   time.Now(),
+			"updated_by":   changedBy,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	previous := m.Status
+	m.Status = MemberStatus(status)
+	m.StatusSince = &effectiveFrom
+	m.UpdatedBy = changedBy
+
+	var club Club
+	if err := database.Db.Select("id", "name").Where("id = ?", m.ClubID).First(&club).Error; err == nil {
+		_ = CreateNotification(m.UserID, "member_status_changed", "Membership status changed",
+			fmt.Sprintf("Your membership in %s changed from %s to %s as of %s.", club.Name, previous, status, effectiveFrom.Format("02.01.2006")),
+			&m.ClubID, nil, nil)
+	}
+	return nil
+}
+
+// GetMembershipHistory returns the membership history of a user in the club, the oldest entry first
+func (c *Club) GetMembershipHistory(userID string) ([]MembershipStatusChange, error) {
+	var history []MembershipStatusChange
+	err := database.Db.Where("club_id = ? AND user_id = ?", c.ID, userID).Order("effective_from ASC, created_at ASC").Find(&history).Error
+	return history, err
+}
+
+// ODataBeforeReadCollection limits the history to the user's and their dependents' own memberships;
+// members with members.manage see the history of their club, including former members
+func (sc MembershipStatusChange) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? OR user_id IN ("+dependentsQuery+") OR club_id IN ("+permittedClubsQuery+")",
+			userID, userID, userID, userID, PermissionMembersManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific history entry
+func (sc MembershipStatusChange) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sc.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents writing the history directly; use the SetStatus action on Members
+func (sc *MembershipStatusChange) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetStatus action on Members to change a membership status")
+}
+
+// ODataBeforeUpdate prevents changing the history
+func (sc *MembershipStatusChange) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: the membership history cannot be changed")
+}
+
+// ODataBeforeDelete prevents deleting the history
+func (sc *MembershipStatusChange) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: the membership history cannot be deleted")
+}
+
+// archiveMembershipInContext records the exit of a member deleted through the API
+func archiveMembershipInContext(ctx context.Context, m *Member, userID string) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	reason := "removed"
+	if CanActFor(userID, m.UserID) {
+		reason = "left"
+	}
+	return archiveMembership(tx, m, reason, &userID)
+}
@@ -0,0 +1,201 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func membershipHistoryRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/MembershipStatusChanges", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestMembershipStatus(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "status-owner@example.com")
+	admin, _ := handlers.CreateTestUser(t, "status-admin@example.com")
+	treasurer, _ := handlers.CreateTestUser(t, "status-treasurer@example.com")
+	player, _ := handlers.CreateTestUser(t, "status-player@example.com")
+	club := handlers.CreateTestClub(t, owner, "Status Club")
+	adminMember := handlers.CreateTestMember(t, admin, club, "admin")
+	treasurerMember := handlers.CreateTestMember(t, treasurer, club, "member")
+	playerMember := handlers.CreateTestMember(t, player, club, "member")
+
+	role := models.ClubRole{ClubID: club.ID, Name: "Treasurer"}
+	require.NoError(t, db.Create(&role).Error)
+	require.NoError(t, db.Create(&models.ClubRolePermission{RoleID: role.ID, Permission: models.PermissionFinesManage}).Error)
+	require.NoError(t, db.Create(&models.ClubRoleAssignment{RoleID: role.ID, ClubID: club.ID, UserID: treasurer.ID}).Error)
+
+	t.Run("new members start active with an open history entry", func(t *testing.T) {
+		assert.EqualValues(t, models.MemberStatusActive, playerMember.Status)
+
+		history, err := club.GetMembershipHistory(player.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, models.MemberStatusActive, history[0].Status)
+		assert.Nil(t, history[0].EffectiveUntil)
+	})
+
+	t.Run("status changes are validated", func(t *testing.T) {
+		now := time.Now()
+		assert.ErrorIs(t, models.SetMemberStatus(&playerMember, models.MemberStatusFormer, now, "", owner.ID), models.ErrInvalidMemberStatus)
+		assert.ErrorIs(t, models.SetMemberStatus(&playerMember, models.MemberStatusActive, now, "", owner.ID), models.ErrMemberStatusUnchanged)
+		assert.ErrorIs(t, models.SetMemberStatus(&playerMember, models.MemberStatusPassive, now.Add(48*time.Hour), "", owner.ID), models.ErrMemberStatusDate)
+		assert.ErrorIs(t, models.SetMemberStatus(&playerMember, models.MemberStatusPassive, now.AddDate(-1, 0, 0), "", owner.ID), models.ErrMemberStatusDate)
+		assert.ErrorIs(t, models.SetMemberStatus(&adminMember, models.MemberStatusSuspended, now, "", owner.ID), models.ErrSuspendAdmin)
+	})
+
+	t.Run("status changes are recorded in the history", func(t *testing.T) {
+		require.NoError(t, models.SetMemberStatus(&playerMember, models.MemberStatusPassive, time.Now(), " Injured ", owner.ID))
+		assert.EqualValues(t, models.MemberStatusPassive, playerMember.Status)
+
+		var stored models.Member
+		require.NoError(t, db.First(&stored, "id = ?", playerMember.ID).Error)
+		assert.EqualValues(t, models.MemberStatusPassive, stored.Status)
+		require.NotNil(t, stored.StatusSince)
+
+		history, err := club.GetMembershipHistory(player.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.NotNil(t, history[0].EffectiveUntil)
+		assert.Equal(t, models.MemberStatusPassive, history[1].Status)
+		assert.Nil(t, history[1].EffectiveUntil)
+		require.NotNil(t, history[1].Reason)
+		assert.Equal(t, "Injured", *history[1].Reason)
+		require.NotNil(t, history[1].ChangedBy)
+		assert.Equal(t, owner.ID, *history[1].ChangedBy)
+	})
+
+	t.Run("suspended members lose custom role permissions and cannot be promoted", func(t *testing.T) {
+		assert.True(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage))
+
+		require.NoError(t, models.SetMemberStatus(&treasurerMember, models.MemberStatusSuspended, time.Now(), "Unpaid fees", owner.ID))
+		assert.False(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage))
+		permissions, err := models.GetPermissions(club.ID, treasurer.ID)
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+
+		err = club.UpdateMemberRole(owner, treasurerMember.ID, "admin")
+		assert.ErrorIs(t, err, models.ErrSuspendedMemberRole)
+
+		require.NoError(t, models.SetMemberStatus(&treasurerMember, models.MemberStatusActive, time.Now(), "", owner.ID))
+		assert.True(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage))
+	})
+
+	t.Run("leaving archives the membership", func(t *testing.T) {
+		require.NoError(t, club.DeleteMemberByUserID(player.ID))
+
+		var count int64
+		require.NoError(t, db.Model(&models.Member{}).Where("club_id = ? AND user_id = ?", club.ID, player.ID).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+		assert.False(t, club.IsMember(player))
+
+		var archived models.Member
+		require.NoError(t, db.Unscoped().Where("id = ?", playerMember.ID).First(&archived).Error)
+		assert.EqualValues(t, models.MemberStatusFormer, archived.Status)
+
+		history, err := club.GetMembershipHistory(player.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		former := history[2]
+		assert.Equal(t, models.MemberStatusFormer, former.Status)
+		assert.Nil(t, former.EffectiveUntil)
+		require.NotNil(t, former.Reason)
+		assert.Equal(t, "left", *former.Reason)
+		require.NotNil(t, former.ChangedBy)
+		assert.Equal(t, player.ID, *former.ChangedBy)
+
+		removed, err := club.DeleteMember(treasurerMember.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+		assert.False(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage), "former members lose their custom roles' permissions")
+		history, err = club.GetMembershipHistory(treasurer.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		require.NotNil(t, history[len(history)-1].Reason)
+		assert.Equal(t, "removed", *history[len(history)-1].Reason)
+	})
+
+	t.Run("rejoining continues the history", func(t *testing.T) {
+		require.NoError(t, club.AddMember(player.ID, "member"))
+		assert.ErrorIs(t, club.AddMember(player.ID, "member"), models.ErrMemberAlreadyExists)
+
+		var rejoined models.Member
+		require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, player.ID).First(&rejoined).Error)
+		assert.Equal(t, playerMember.ID, rejoined.ID, "former members rejoin with their member row")
+		assert.EqualValues(t, models.MemberStatusActive, rejoined.Status)
+
+		history, err := club.GetMembershipHistory(player.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 4)
+		assert.Equal(t, models.MemberStatusFormer, history[2].Status)
+		assert.NotNil(t, history[2].EffectiveUntil)
+		assert.Equal(t, models.MemberStatusActive, history[3].Status)
+		assert.Nil(t, history[3].EffectiveUntil)
+	})
+
+	t.Run("removing a member through the API archives it", func(t *testing.T) {
+		keeper, _ := handlers.CreateTestUser(t, "status-keeper@example.com")
+		keeperMember := handlers.CreateTestMember(t, keeper, club, "member")
+		field := models.MemberFieldDefinition{ClubID: club.ID, Name: "Shirt size", Type: "text", Visibility: "admins"}
+		require.NoError(t, db.Create(&field).Error)
+		require.NoError(t, db.Create(&models.MemberFieldValue{ClubID: club.ID, MemberID: keeperMember.ID, FieldID: field.ID, Value: "L"}).Error)
+
+		w := odataRequest(t, ownerToken, http.MethodDelete, "/Members("+keeperMember.ID+")", nil)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		var archived models.Member
+		require.NoError(t, db.Unscoped().Where("id = ?", keeperMember.ID).First(&archived).Error)
+		assert.EqualValues(t, models.MemberStatusFormer, archived.Status)
+		var values int64
+		require.NoError(t, db.Model(&models.MemberFieldValue{}).Where("member_id = ?", keeperMember.ID).Count(&values).Error)
+		assert.Equal(t, int64(1), values, "field values are kept for a rejoin")
+
+		history, err := club.GetMembershipHistory(keeper.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		assert.Equal(t, models.MemberStatusFormer, history[len(history)-1].Status)
+
+		w = odataRequest(t, ownerToken, http.MethodPost, "/Members", map[string]interface{}{
+			"ClubID": club.ID, "UserID": keeper.ID, "Role": "member", "CreatedBy": owner.ID, "UpdatedBy": owner.ID,
+		})
+		assert.NotEqual(t, http.StatusCreated, w.Code, w.Body.String())
+
+		require.NoError(t, club.AddMemberWithActor(keeper.ID, "member", owner.ID))
+		var rejoined models.Member
+		require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, keeper.ID).First(&rejoined).Error)
+		assert.Equal(t, keeperMember.ID, rejoined.ID)
+	})
+
+	t.Run("history of former members is visible to member managers only", func(t *testing.T) {
+		entries := func(userID string) []models.MembershipStatusChange {
+			ctx, req := membershipHistoryRequest(userID)
+			scopes, err := models.MembershipStatusChange{}.ODataBeforeReadCollection(ctx, req, nil)
+			require.NoError(t, err)
+			var result []models.MembershipStatusChange
+			require.NoError(t, db.Scopes(scopes...).Where("user_id = ?", treasurer.ID).Find(&result).Error)
+			return result
+		}
+
+		assert.NotEmpty(t, entries(admin.ID))
+		assert.NotEmpty(t, entries(treasurer.ID))
+		assert.Empty(t, entries(player.ID))
+
+		ctx, req := membershipHistoryRequest(owner.ID)
+		entry := models.MembershipStatusChange{ClubID: club.ID, UserID: player.ID, Status: models.MemberStatusHonorary}
+		assert.Error(t, entry.ODataBeforeCreate(ctx, req))
+	})
+}
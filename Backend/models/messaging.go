@@ -0,0 +1,646 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Conversation types
+const (
+	ConversationTypeDirect       = "direct"       // 1:1 between two club members
+	ConversationTypeTeam         = "team"         // Channel of a team, backed by TeamMember
+	ConversationTypeAnnouncement = "announcement" // Club-wide, only admins post
+)
+
+const MaxMessageLength = 5000
+
+var ErrMessagingNotAllowed = errors.New("messaging this member is not allowed")
+
+// Conversation groups messages of a direct chat, a team channel or a club announcement channel
+type Conversation struct {
+	ID            string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID        string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Type          string     `json:"Type" gorm:"not null" odata:"required"`
+	TeamID        *string    `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Set for team channels
+	Title         *string    `json:"Title,omitempty" odata:"nullable"`
+	LastMessageAt *time.Time `json:"LastMessageAt,omitempty" odata:"auto,nullable"`
+	CreatedAt     time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy     string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt     time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy     string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Participants []ConversationParticipant `gorm:"foreignKey:ConversationID" json:"Participants,omitempty" odata:"nav"`
+	Messages     []Message                 `gorm:"foreignKey:ConversationID" json:"Messages,omitempty" odata:"nav"`
+}
+
+// ConversationParticipant stores the read position of a user in a conversation.
+// For direct conversations the participants also define who has access.
+type ConversationParticipant struct {
+	ID             string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ConversationID string     `json:"ConversationID" gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participants_unique" odata:"required"`
+	UserID         string     `json:"UserID" gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participants_unique" odata:"required"`
+	LastReadAt     *time.Time `json:"LastReadAt,omitempty" odata:"nullable"`
+	CreatedAt      time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// Message is a single message posted to a conversation
+type Message struct {
+	ID             string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ConversationID string    `json:"ConversationID" gorm:"type:uuid;not null;index" odata:"required"`
+	ClubID         string    `json:"ClubID" gorm:"type:uuid;not null" odata:"auto"`
+	Content        string    `json:"Content" gorm:"type:text;not null" odata:"required"`
+	CreatedAt      time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy      string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	CreatedByUser *User `gorm:"foreignKey:CreatedBy" json:"CreatedByUser,omitempty" odata:"nav"`
+}
+
+// ConversationUnreadCount holds the number of unread messages of a conversation
+type ConversationUnreadCount struct {
+	ConversationID string `json:"ConversationID"`
+	Unread         int64  `json:"Unread"`
+}
+
+// UnreadMessageCounts holds the unread message counts of a user
+type UnreadMessageCounts struct {
+	Total         int64                     `json:"Total"`
+	Conversations []ConversationUnreadCount `json:"Conversations"`
+}
+
+// MessageReader is a user who has read a message
+type MessageReader struct {
+	UserID string    `json:"UserID"`
+	Name   string    `json:"Name"`
+	ReadAt time.Time `json:"ReadAt"`
+}
+
+// BeforeCreate generates UUID for new conversations
+func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new conversation participants
+func (cp *ConversationParticipant) BeforeCreate(tx *gorm.DB) error {
+	if cp.ID == "" {
+		cp.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new messages
+func (m *Message) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// conversationVisibilityScope restricts conversations to clubs the user belongs to:
+// direct conversations to their participants, team channels to the team's members
+// and the members who manage the club's members, and announcements to all club members.
+// Arguments: user ID, user ID, user ID, user ID, user ID, members.manage permission.
+const conversationVisibilityScope = "club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND (" +
+	"(type = 'direct' AND id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)) OR " +
+	"(type = 'team' AND (team_id IN (SELECT team_id FROM team_members WHERE user_id = ?) OR club_id IN (" + permittedClubsQuery + "))) OR " +
+	"type = 'announcement')"
+
+// visibleConversationIDs returns a subquery of the IDs of conversations the user can see
+func visibleConversationIDs(userID string) *gorm.DB {
+	return database.Db.Model(&Conversation{}).Select("id").Where(conversationVisibilityScope, userID, userID, userID, userID, userID, PermissionMembersManage)
+}
+
+// isClubAdmin checks whether the user may manage the conversation: announcements require the
+// news.publish permission, team channels and direct conversations the members.manage permission
+func (c *Conversation) isClubAdmin(userID string) bool {
+	if c.Type == ConversationTypeAnnouncement {
+		return HasPermission(c.ClubID, userID, PermissionNewsPublish)
+	}
+	return HasPermission(c.ClubID, userID, PermissionMembersManage)
+}
+
+// CanAccess checks whether the user may read the conversation
+func (c *Conversation) CanAccess(userID string) bool {
+	var count int64
+	database.Db.Model(&Conversation{}).Where("id = ?", c.ID).Where(conversationVisibilityScope, userID, userID, userID, userID, userID, PermissionMembersManage).Count(&count)
+	return count > 0
+}
+
+// CanPost checks whether the user may post to the conversation.
+// Announcements can only be posted by members holding the news.publish permission.
+func (c *Conversation) CanPost(userID string) bool {
+	if !c.CanAccess(userID) {
+		return false
+	}
+	if c.Type == ConversationTypeAnnouncement {
+		return c.isClubAdmin(userID)
+	}
+	return true
+}
+
+// canMessageDirectly checks whether the initiator may start a direct conversation with the recipient.
+// Members who manage the club's members can message everyone and everyone can message them.
+// Otherwise the club's member list must be visible, or both users must share a team.
+func canMessageDirectly(clubID, initiatorID, recipientID string) bool {
+	if HasPermission(clubID, initiatorID, PermissionMembersManage) || HasPermission(clubID, recipientID, PermissionMembersManage) {
+		return true
+	}
+
+	settings, err := GetClubSettings(clubID)
+	if err == nil && settings.MembersListVisible {
+		return true
+	}
+
+	var sharedTeams int64
+	database.Db.Model(&TeamMember{}).
+		Where("user_id = ? AND team_id IN (SELECT id FROM teams WHERE club_id = ?)", initiatorID, clubID).
+		Where("team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)", recipientID).
+		Count(&sharedTeams)
+	return sharedTeams > 0
+}
+
+// StartDirectConversation returns the direct conversation between two club members,
+// creating it if it does not exist yet
+func StartDirectConversation(clubID, initiatorID, recipientID string) (*Conversation, error) {
+	if initiatorID == recipientID {
+		return nil, fmt.Errorf("cannot start a conversation with yourself")
+	}
+
+	var members int64
+	if err := database.Db.Model(&Member{}).Where("club_id = ? AND user_id IN (?, ?)", clubID, initiatorID, recipientID).Count(&members).Error; err != nil {
+		return nil, err
+	}
+	if members != 2 {
+		return nil, ErrMessagingNotAllowed
+	}
+
+	if !canMessageDirectly(clubID, initiatorID, recipientID) {
+		return nil, ErrMessagingNotAllowed
+	}
+
+	var existing Conversation
+	err := database.Db.Where("club_id = ? AND type = ?", clubID, ConversationTypeDirect).
+		Where("id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)", initiatorID).
+		Where("id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)", recipientID).
+		First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	conversation := Conversation{
+		ClubID:    clubID,
+		Type:      ConversationTypeDirect,
+		CreatedAt: now,
+		CreatedBy: initiatorID,
+		UpdatedAt: now,
+		UpdatedBy: initiatorID,
+	}
+
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conversation).Error; err != nil {
+			return err
+		}
+		for _, userID := range []string{initiatorID, recipientID} {
+			participant := ConversationParticipant{ConversationID: conversation.ID, UserID: userID, CreatedAt: now}
+			if err := tx.Create(&participant).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+// MarkRead records that the user has read the conversation up to now
+func (c *Conversation) MarkRead(userID string) error {
+	return markConversationRead(database.Db, c.ID, userID, time.Now())
+}
+
+// markConversationRead upserts the read position of a user in a conversation
+func markConversationRead(db *gorm.DB, conversationID, userID string, readAt time.Time) error {
+	var participant ConversationParticipant
+	err := db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&participant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		participant = ConversationParticipant{ConversationID: conversationID, UserID: userID, LastReadAt: &readAt, CreatedAt: readAt}
+		return db.Create(&participant).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Model(&participant).Update("last_read_at", readAt).Error
+}
+
+// GetUnreadMessageCounts returns the unread message counts of all conversations the user can see.
+// Messages are unread if they were posted by someone else after the user's read position.
+func GetUnreadMessageCounts(userID string) (UnreadMessageCounts, error) {
+	counts := UnreadMessageCounts{Conversations: []ConversationUnreadCount{}}
+
+	err := database.Db.Model(&Message{}).
+		Select("messages.conversation_id, COUNT(*) AS unread").
+		Joins("LEFT JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id AND conversation_participants.user_id = ?", userID).
+		Where("messages.conversation_id IN (?)", visibleConversationIDs(userID)).
+		Where("messages.created_by <> ?", userID).
+		Where("conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at").
+		Group("messages.conversation_id").
+		Order("messages.conversation_id").
+		Scan(&counts.Conversations).Error
+	if err != nil {
+		return counts, err
+	}
+
+	for _, conversation := range counts.Conversations {
+		counts.Total += conversation.Unread
+	}
+	return counts, nil
+}
+
+// GetReadBy returns the users who have read the message (read receipts), excluding its author
+func (m *Message) GetReadBy() ([]MessageReader, error) {
+	var participants []ConversationParticipant
+	err := database.Db.Preload("User").
+		Where("conversation_id = ? AND user_id <> ? AND last_read_at >= ?", m.ConversationID, m.CreatedBy, m.CreatedAt).
+		Order("last_read_at ASC").
+		Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]MessageReader, 0, len(participants))
+	for _, participant := range participants {
+		reader := MessageReader{UserID: participant.UserID, ReadAt: *participant.LastReadAt}
+		if participant.User != nil {
+			reader.Name = strings.TrimSpace(participant.User.FirstName + " " + participant.User.LastName)
+		}
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// recipientIDs returns the users who should be notified about a new message in the conversation
+func (c *Conversation) recipientIDs(senderID string) ([]string, error) {
+	var userIDs []string
+	var err error
+	switch c.Type {
+	case ConversationTypeDirect:
+		err = database.Db.Model(&ConversationParticipant{}).Where("conversation_id = ? AND user_id <> ?", c.ID, senderID).Pluck("user_id", &userIDs).Error
+	case ConversationTypeTeam:
+		if c.TeamID == nil {
+			return nil, nil
+		}
+		err = database.Db.Model(&TeamMember{}).Where("team_id = ? AND user_id <> ?", *c.TeamID, senderID).Pluck("user_id", &userIDs).Error
+	case ConversationTypeAnnouncement:
+		err = database.Db.Model(&Member{}).Where("club_id = ? AND user_id <> ?", c.ClubID, senderID).Pluck("user_id", &userIDs).Error
+	}
+	return userIDs, err
+}
+
+// SendMessageNotifications notifies the recipients of a new message
+func (m *Message) SendMessageNotifications() error {
+	var conversation Conversation
+	if err := database.Db.Where("id = ?", m.ConversationID).First(&conversation).Error; err != nil {
+		return fmt.Errorf("failed to find conversation: %v", err)
+	}
+
+	var club Club
+	if err := database.Db.Where("id = ?", m.ClubID).First(&club).Error; err != nil {
+		return fmt.Errorf("failed to find club: %v", err)
+	}
+
+	senderName := "Someone"
+	var sender User
+	if err := database.Db.Where("id = ?", m.CreatedBy).First(&sender).Error; err == nil {
+		senderName = strings.TrimSpace(sender.FirstName + " " + sender.LastName)
+	}
+
+	preview := m.Content
+	if len([]rune(preview)) > 100 {
+		preview = string([]rune(preview)[:100]) + "..."
+	}
+
+	notificationType := "message_received"
+	title := "New message in " + club.Name
+	message := fmt.Sprintf("%s: %s", senderName, preview)
+	if conversation.Type == ConversationTypeAnnouncement {
+		notificationType = "announcement"
+		title = "New announcement in " + club.Name
+		if conversation.Title != nil && *conversation.Title != "" {
+			message = fmt.Sprintf("%s: %s", *conversation.Title, preview)
+		}
+	}
+
+	recipients, err := conversation.recipientIDs(m.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to find recipients: %v", err)
+	}
+
+	for _, userID := range recipients {
+		if err := sendMessageNotification(userID, notificationType, title, message, m.ClubID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendMessageNotification creates the in-app notification if the recipient enabled it
+func sendMessageNotification(userID, notificationType, title, message, clubID string) error {
+	// Get user notification preferences
+	preferences, err := GetUserNotificationPreferences(userID)
+	if err != nil {
+		// If preferences don't exist, create default ones and continue
+		preferences, err = CreateDefaultUserNotificationPreferences(userID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification preferences: %v", err)
+		}
+	}
+
+	// Send in-app notification if enabled
+	if preferences.MessageReceivedInApp {
+		if err := CreateNotification(userID, notificationType, title, message, &clubID, nil, nil); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters conversations to those the user can see
+func (c Conversation) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(conversationVisibilityScope, userID, userID, userID, userID, userID, PermissionMembersManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific conversation
+func (c Conversation) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return c.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates creation of team channels and announcement channels.
+// Direct conversations are started through the StartDirectConversation action.
+func (c *Conversation) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	switch c.Type {
+	case ConversationTypeDirect:
+		return fmt.Errorf("forbidden: use the StartDirectConversation action to message a member")
+	case ConversationTypeTeam:
+		if err := CheckFeatureEnabled(c.ClubID, "teams"); err != nil {
+			return err
+		}
+		if c.TeamID == nil || *c.TeamID == "" {
+			return fmt.Errorf("team channels require a team")
+		}
+		// SECURITY: Verify the team belongs to the specified club
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *c.TeamID, c.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+		var teamMember TeamMember
+		if err := database.Db.Where("team_id = ? AND user_id = ?", *c.TeamID, userID).First(&teamMember).Error; err != nil && !c.isClubAdmin(userID) {
+			return fmt.Errorf("unauthorized: only team members and club admins can open the team channel")
+		}
+		var existing int64
+		database.Db.Model(&Conversation{}).Where("type = ? AND team_id = ?", ConversationTypeTeam, *c.TeamID).Count(&existing)
+		if existing > 0 {
+			return fmt.Errorf("team channel already exists")
+		}
+	case ConversationTypeAnnouncement:
+		if !c.isClubAdmin(userID) {
+			return fmt.Errorf("unauthorized: only admins and owners can create announcement channels")
+		}
+		c.TeamID = nil
+	default:
+		return fmt.Errorf("invalid conversation type: must be 'direct', 'team', or 'announcement'")
+	}
+
+	// Set audit fields
+	now := time.Now()
+	c.LastMessageAt = nil
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.CreatedBy = userID
+	c.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates conversation update permissions
+func (c *Conversation) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if c.Type == ConversationTypeDirect || !c.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update team and announcement channels")
+	}
+
+	updated, err := updatedEntity(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Club, type and team decide who can read the channel, so they are fixed
+	if updated.ClubID != c.ClubID || updated.Type != c.Type || !sameStringPtr(updated.TeamID, c.TeamID) {
+		return fmt.Errorf("forbidden: club, type and team cannot be changed for an existing conversation")
+	}
+
+	// Set UpdatedBy
+	now := time.Now()
+	c.UpdatedAt = now
+	c.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates conversation deletion permissions and removes messages and participants
+func (c *Conversation) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if c.Type == ConversationTypeDirect || !c.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete team and announcement channels")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("conversation_id = ?", c.ID).Delete(&Message{}).Error; err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+	if err := tx.Where("conversation_id = ?", c.ID).Delete(&ConversationParticipant{}).Error; err != nil {
+		return fmt.Errorf("failed to delete conversation participants: %w", err)
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters participants to conversations the user can see
+func (cp ConversationParticipant) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("conversation_id IN (?)", visibleConversationIDs(userID))
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific participant
+func (cp ConversationParticipant) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return cp.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation of participants
+func (cp *ConversationParticipant) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: participants are managed by the backend")
+}
+
+// ODataBeforeUpdate prevents direct updates of participants; use the MarkRead action instead
+func (cp *ConversationParticipant) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the MarkRead action on Conversations")
+}
+
+// ODataBeforeDelete prevents direct deletion of participants
+func (cp *ConversationParticipant) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: participants are managed by the backend")
+}
+
+// ODataBeforeReadCollection filters messages to conversations the user can see
+func (m Message) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("conversation_id IN (?)", visibleConversationIDs(userID))
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific message
+func (m Message) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return m.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates that the user may post to the conversation
+func (m *Message) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var conversation Conversation
+	if err := database.Db.Where("id = ?", m.ConversationID).First(&conversation).Error; err != nil {
+		return fmt.Errorf("conversation not found")
+	}
+
+	if !conversation.CanPost(userID) {
+		return fmt.Errorf("unauthorized: cannot post to this conversation")
+	}
+
+	m.Content = strings.TrimSpace(m.Content)
+	if m.Content == "" {
+		return fmt.Errorf("message cannot be empty")
+	}
+	if len([]rune(m.Content)) > MaxMessageLength {
+		return fmt.Errorf("message exceeds maximum length of %d characters", MaxMessageLength)
+	}
+
+	m.ClubID = conversation.ClubID
+	m.CreatedAt = time.Now()
+	m.CreatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate updates the conversation, marks it read for the sender and notifies recipients
+func (m *Message) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+
+	if err := tx.Model(&Conversation{}).Where("id = ?", m.ConversationID).Update("last_message_at", m.CreatedAt).Error; err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+	if err := markConversationRead(tx, m.ConversationID, m.CreatedBy, m.CreatedAt); err != nil {
+		return fmt.Errorf("failed to update read position: %w", err)
+	}
+
+	// Notification failures must not fail the message itself
+	_ = m.SendMessageNotifications()
+
+	return nil
+}
+
+// ODataBeforeUpdate prevents editing messages
+func (m *Message) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: messages cannot be edited")
+}
+
+// ODataBeforeDelete allows the author or club admins to delete a message
+func (m *Message) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if m.CreatedBy == userID {
+		return nil
+	}
+
+	var conversation Conversation
+	if err := database.Db.Where("id = ?", m.ConversationID).First(&conversation).Error; err != nil {
+		return fmt.Errorf("conversation not found")
+	}
+	if !conversation.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only the author or club admins can delete messages")
+	}
+
+	return nil
+}
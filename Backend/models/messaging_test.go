@@ -0,0 +1,224 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messageRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/Messages", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func postTestMessage(t *testing.T, userID, conversationID, content string) *models.Message {
+	t.Helper()
+	ctx, req := messageRequest(userID)
+	message := &models.Message{ConversationID: conversationID, Content: content}
+	require.NoError(t, message.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(message).Error)
+	require.NoError(t, message.ODataAfterCreate(ctx, req))
+	return message
+}
+
+func TestDirectConversations(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "dm-owner@example.com")
+	alice, _ := handlers.CreateTestUser(t, "dm-alice@example.com")
+	bob, _ := handlers.CreateTestUser(t, "dm-bob@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "dm-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "DM Club")
+	handlers.CreateTestMember(t, alice, club, "member")
+	handlers.CreateTestMember(t, bob, club, "member")
+
+	t.Run("non-members cannot be messaged", func(t *testing.T) {
+		_, err := models.StartDirectConversation(club.ID, alice.ID, outsider.ID)
+		assert.ErrorIs(t, err, models.ErrMessagingNotAllowed)
+	})
+
+	t.Run("hidden member list restricts direct messages", func(t *testing.T) {
+		_, err := models.StartDirectConversation(club.ID, alice.ID, bob.ID)
+		assert.ErrorIs(t, err, models.ErrMessagingNotAllowed)
+
+		// Admins can always be messaged
+		_, err = models.StartDirectConversation(club.ID, alice.ID, owner.ID)
+		assert.NoError(t, err)
+
+		require.NoError(t, db.Exec("UPDATE club_settings SET members_list_visible = true WHERE club_id = ?", club.ID).Error)
+		defer db.Exec("UPDATE club_settings SET members_list_visible = false WHERE club_id = ?", club.ID)
+		_, err = models.StartDirectConversation(club.ID, alice.ID, bob.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("messages, unread counts and read receipts", func(t *testing.T) {
+		conversation, err := models.StartDirectConversation(club.ID, owner.ID, alice.ID)
+		require.NoError(t, err)
+
+		// Starting again returns the same conversation
+		again, err := models.StartDirectConversation(club.ID, alice.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, conversation.ID, again.ID)
+
+		assert.False(t, conversation.CanAccess(bob.ID))
+		ctx, req := messageRequest(bob.ID)
+		intruder := &models.Message{ConversationID: conversation.ID, Content: "Hi"}
+		assert.Error(t, intruder.ODataBeforeCreate(ctx, req))
+
+		message := postTestMessage(t, owner.ID, conversation.ID, "  Training moved to 7pm  ")
+		assert.Equal(t, "Training moved to 7pm", message.Content)
+		assert.Equal(t, club.ID, message.ClubID)
+
+		counts, err := models.GetUnreadMessageCounts(alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), counts.Total)
+
+		counts, err = models.GetUnreadMessageCounts(owner.ID)
+		require.NoError(t, err)
+		assert.Zero(t, counts.Total, "own messages are not unread")
+
+		var notifications []models.Notification
+		require.NoError(t, db.Where("user_id = ? AND type = ?", alice.ID, "message_received").Find(&notifications).Error)
+		assert.Len(t, notifications, 1)
+
+		readers, err := message.GetReadBy()
+		require.NoError(t, err)
+		assert.Empty(t, readers)
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, conversation.MarkRead(alice.ID))
+
+		counts, err = models.GetUnreadMessageCounts(alice.ID)
+		require.NoError(t, err)
+		assert.Zero(t, counts.Total)
+
+		readers, err = message.GetReadBy()
+		require.NoError(t, err)
+		require.Len(t, readers, 1)
+		assert.Equal(t, alice.ID, readers[0].UserID)
+	})
+
+	t.Run("unread counts are grouped per conversation", func(t *testing.T) {
+		withAlice, err := models.StartDirectConversation(club.ID, owner.ID, alice.ID)
+		require.NoError(t, err)
+		withBob, err := models.StartDirectConversation(club.ID, owner.ID, bob.ID)
+		require.NoError(t, err)
+
+		postTestMessage(t, alice.ID, withAlice.ID, "See you there")
+		postTestMessage(t, bob.ID, withBob.ID, "Running late")
+		postTestMessage(t, bob.ID, withBob.ID, "Start without me")
+
+		counts, err := models.GetUnreadMessageCounts(owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), counts.Total)
+		unread := map[string]int64{}
+		for _, conversation := range counts.Conversations {
+			unread[conversation.ConversationID] = conversation.Unread
+		}
+		assert.Equal(t, map[string]int64{withAlice.ID: 1, withBob.ID: 2}, unread)
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, withBob.MarkRead(owner.ID))
+		counts, err = models.GetUnreadMessageCounts(owner.ID)
+		require.NoError(t, err)
+		require.Len(t, counts.Conversations, 1)
+		assert.Equal(t, withAlice.ID, counts.Conversations[0].ConversationID)
+		assert.Equal(t, int64(1), counts.Total)
+	})
+}
+
+func TestTeamAndAnnouncementConversations(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "channel-owner@example.com")
+	player, _ := handlers.CreateTestUser(t, "channel-player@example.com")
+	member, _ := handlers.CreateTestUser(t, "channel-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Channel Club")
+	handlers.CreateTestMember(t, player, club, "member")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET teams_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	team, err := club.CreateTeam("First Team", "", owner.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.TeamMember{TeamID: team.ID, UserID: player.ID, Role: "member"}).Error)
+
+	t.Run("team channel is limited to the team", func(t *testing.T) {
+		ctx, req := messageRequest(member.ID)
+		denied := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeTeam, TeamID: &team.ID}
+		assert.Error(t, denied.ODataBeforeCreate(ctx, req))
+
+		ctx, req = messageRequest(player.ID)
+		channel := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeTeam, TeamID: &team.ID}
+		require.NoError(t, channel.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(channel).Error)
+
+		duplicate := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeTeam, TeamID: &team.ID}
+		assert.Error(t, duplicate.ODataBeforeCreate(ctx, req))
+
+		assert.True(t, channel.CanPost(player.ID))
+		assert.True(t, channel.CanAccess(owner.ID), "club admins can read team channels")
+		assert.False(t, channel.CanAccess(member.ID))
+
+		postTestMessage(t, player.ID, channel.ID, "Who brings the balls?")
+		counts, err := models.GetUnreadMessageCounts(member.ID)
+		require.NoError(t, err)
+		assert.Zero(t, counts.Total)
+	})
+
+	t.Run("only admins post announcements", func(t *testing.T) {
+		ctx, req := messageRequest(member.ID)
+		denied := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeAnnouncement}
+		assert.Error(t, denied.ODataBeforeCreate(ctx, req))
+
+		title := "Club news"
+		ctx, req = messageRequest(owner.ID)
+		announcements := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeAnnouncement, Title: &title}
+		require.NoError(t, announcements.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(announcements).Error)
+
+		assert.True(t, announcements.CanAccess(member.ID))
+		assert.False(t, announcements.CanPost(member.ID))
+
+		postTestMessage(t, owner.ID, announcements.ID, "General assembly on Friday")
+
+		var count int64
+		db.Model(&models.Notification{}).Where("type = ? AND user_id IN (?, ?)", "announcement", player.ID, member.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+
+		// Club, type and team decide who can read the channel
+		path := "/Conversations(" + announcements.ID + ")"
+		for _, patch := range []map[string]interface{}{
+			{"ClubID": handlers.CreateTestClub(t, owner, "Other Channel Club").ID},
+			{"Type": models.ConversationTypeTeam, "TeamID": team.ID},
+			{"TeamID": team.ID},
+		} {
+			w := odataRequest(t, ownerToken, http.MethodPatch, path, patch)
+			assert.Equal(t, http.StatusForbidden, w.Code, patch)
+		}
+		w := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Title": "Club announcements"})
+		require.Less(t, w.Code, 300, w.Body.String())
+		var stored models.Conversation
+		require.NoError(t, db.First(&stored, "id = ?", announcements.ID).Error)
+		assert.Equal(t, models.ConversationTypeAnnouncement, stored.Type)
+		assert.Nil(t, stored.TeamID)
+	})
+
+	t.Run("direct conversations are started through the action", func(t *testing.T) {
+		ctx, req := messageRequest(owner.ID)
+		direct := &models.Conversation{ClubID: club.ID, Type: models.ConversationTypeDirect}
+		assert.Error(t, direct.ODataBeforeCreate(ctx, req))
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/NLstn/civo/auth"
 	"github.com/NLstn/civo/database"
 	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +22,9 @@ type News struct {
 	CreatedBy string    `json:"CreatedBy" gorm:"type:uuid" odata:"required"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"required"`
+
+	// Navigation properties
+	Comments []Comment `gorm:"foreignKey:NewsID" json:"Comments,omitempty" odata:"nav"`
 }
 
 // EntitySetName returns the custom entity set name for the News entity.
@@ -77,6 +81,11 @@ func (c *Club) UpdateNews(newsID string, title, content, updatedBy string) (*New
 
 // DeleteNews deletes a news post
 func (c *Club) DeleteNews(newsID string) error {
+	// Remove the discussion attached to the news post
+	if err := deleteComments(database.Db, "news_id", newsID); err != nil {
+		return err
+	}
+
 	return database.Db.Where("id = ? AND club_id = ?", newsID, c.ID).Delete(&News{}).Error
 }
 
@@ -89,7 +98,7 @@ func (n News) ODataBeforeReadCollection(ctx context.Context, r *http.Request, op
 
 	// User can only see news of clubs they belong to and where news feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE news_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE news_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -104,7 +113,7 @@ func (n News) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts i
 
 	// User can only see news of clubs they belong to and where news feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE news_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE news_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -122,9 +131,8 @@ func (n *News) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", n.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the news.publish permission in the club
+	if !HasPermission(n.ClubID, userID, PermissionNewsPublish) {
 		return fmt.Errorf("unauthorized: only admins and owners can create news")
 	}
 
@@ -150,9 +158,8 @@ func (n *News) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", n.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the news.publish permission in the club
+	if !HasPermission(n.ClubID, userID, PermissionNewsPublish) {
 		return fmt.Errorf("unauthorized: only admins and owners can update news")
 	}
 
@@ -176,11 +183,19 @@ func (n *News) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", n.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the news.publish permission in the club
+	if !HasPermission(n.ClubID, userID, PermissionNewsPublish) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete news")
 	}
 
+	// Remove the discussion attached to the news post
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := deleteComments(tx, "news_id", n.ID); err != nil {
+		return fmt.Errorf("failed to delete news comments: %w", err)
+	}
+
 	return nil
 }
@@ -27,28 +27,34 @@ type Notification struct {
 	FineID        *string `json:"FineID,omitempty" gorm:"type:uuid" odata:"nullable"`
 	InviteID      *string `json:"InviteID,omitempty" gorm:"type:uuid" odata:"nullable"`
 	JoinRequestID *string `json:"JoinRequestID,omitempty" gorm:"type:uuid" odata:"nullable"`
+	// Set on the copies guardians receive of their dependent's notifications
+	DependentID *string `json:"DependentID,omitempty" gorm:"type:uuid" odata:"nullable"`
 }
 
 // UserNotificationPreferences represents user's notification settings
 type UserNotificationPreferences struct {
-	ID                  string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
-	UserID              string    `json:"UserID" gorm:"type:uuid;not null;unique" odata:"required"`
-	MemberAddedInApp    bool      `json:"MemberAddedInApp" gorm:"default:true"`
-	MemberAddedEmail    bool      `json:"MemberAddedEmail" gorm:"default:true"`
-	InviteReceivedInApp bool      `json:"InviteReceivedInApp" gorm:"default:true"`
-	InviteReceivedEmail bool      `json:"InviteReceivedEmail" gorm:"default:true"`
-	EventCreatedInApp   bool      `json:"EventCreatedInApp" gorm:"default:true"`
-	EventCreatedEmail   bool      `json:"EventCreatedEmail" gorm:"default:false"`
-	FineAssignedInApp   bool      `json:"FineAssignedInApp" gorm:"default:true"`
-	FineAssignedEmail   bool      `json:"FineAssignedEmail" gorm:"default:true"`
-	NewsCreatedInApp    bool      `json:"NewsCreatedInApp" gorm:"default:true"`
-	NewsCreatedEmail    bool      `json:"NewsCreatedEmail" gorm:"default:false"`
-	RoleChangedInApp    bool      `json:"RoleChangedInApp" gorm:"default:true"`
-	RoleChangedEmail    bool      `json:"RoleChangedEmail" gorm:"default:true"`
-	JoinRequestInApp    bool      `json:"JoinRequestInApp" gorm:"default:true"`
-	JoinRequestEmail    bool      `json:"JoinRequestEmail" gorm:"default:true"`
-	CreatedAt           time.Time `json:"CreatedAt" odata:"immutable"`
-	UpdatedAt           time.Time `json:"UpdatedAt"`
+	ID                   string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	UserID               string    `json:"UserID" gorm:"type:uuid;not null;unique" odata:"required"`
+	MemberAddedInApp     bool      `json:"MemberAddedInApp" gorm:"default:true"`
+	MemberAddedEmail     bool      `json:"MemberAddedEmail" gorm:"default:true"`
+	InviteReceivedInApp  bool      `json:"InviteReceivedInApp" gorm:"default:true"`
+	InviteReceivedEmail  bool      `json:"InviteReceivedEmail" gorm:"default:true"`
+	EventCreatedInApp    bool      `json:"EventCreatedInApp" gorm:"default:true"`
+	EventCreatedEmail    bool      `json:"EventCreatedEmail" gorm:"default:false"`
+	FineAssignedInApp    bool      `json:"FineAssignedInApp" gorm:"default:true"`
+	FineAssignedEmail    bool      `json:"FineAssignedEmail" gorm:"default:true"`
+	NewsCreatedInApp     bool      `json:"NewsCreatedInApp" gorm:"default:true"`
+	NewsCreatedEmail     bool      `json:"NewsCreatedEmail" gorm:"default:false"`
+	RoleChangedInApp     bool      `json:"RoleChangedInApp" gorm:"default:true"`
+	RoleChangedEmail     bool      `json:"RoleChangedEmail" gorm:"default:true"`
+	JoinRequestInApp     bool      `json:"JoinRequestInApp" gorm:"default:true"`
+	JoinRequestEmail     bool      `json:"JoinRequestEmail" gorm:"default:true"`
+	CommentAddedInApp    bool      `json:"CommentAddedInApp" gorm:"default:true"`
+	MessageReceivedInApp bool      `json:"MessageReceivedInApp" gorm:"default:true"`
+	FeeDueInApp          bool      `json:"FeeDueInApp" gorm:"default:true"`
+	FeeDueEmail          bool      `json:"FeeDueEmail" gorm:"default:true"`
+	CreatedAt            time.Time `json:"CreatedAt" odata:"immutable"`
+	UpdatedAt            time.Time `json:"UpdatedAt"`
 }
 
 // BeforeCreate sets the ID for new notifications
@@ -165,21 +171,25 @@ func GetUserNotificationPreferences(userID string) (UserNotificationPreferences,
 // CreateDefaultUserNotificationPreferences creates default notification preferences for a user
 func CreateDefaultUserNotificationPreferences(userID string) (UserNotificationPreferences, error) {
 	preferences := UserNotificationPreferences{
-		UserID:              userID,
-		MemberAddedInApp:    true,
-		MemberAddedEmail:    true,
-		InviteReceivedInApp: true,
-		InviteReceivedEmail: true,
-		EventCreatedInApp:   true,
-		EventCreatedEmail:   false,
-		FineAssignedInApp:   true,
-		FineAssignedEmail:   true,
-		NewsCreatedInApp:    true,
-		NewsCreatedEmail:    false,
-		RoleChangedInApp:    true,
-		RoleChangedEmail:    true,
-		JoinRequestInApp:    true,
-		JoinRequestEmail:    true,
+		UserID:               userID,
+		MemberAddedInApp:     true,
+		MemberAddedEmail:     true,
+		InviteReceivedInApp:  true,
+		InviteReceivedEmail:  true,
+		EventCreatedInApp:    true,
+		EventCreatedEmail:    false,
+		FineAssignedInApp:    true,
+		FineAssignedEmail:    true,
+		NewsCreatedInApp:     true,
+		NewsCreatedEmail:     false,
+		RoleChangedInApp:     true,
+		RoleChangedEmail:     true,
+		JoinRequestInApp:     true,
+		JoinRequestEmail:     true,
+		CommentAddedInApp:    true,
+		MessageReceivedInApp: true,
+		FeeDueInApp:          true,
+		FeeDueEmail:          true,
 	}
 	err := database.Db.Create(&preferences).Error
 	return preferences, err
@@ -302,6 +312,217 @@ func SendRoleChangedNotifications(userID, clubID, clubName, oldRole, newRole str
 	return nil
 }
 
+// SendFineAssignedNotifications notifies a member about a fine
+func SendFineAssignedNotifications(fine Fine) error {
+	preferences, err := GetUserNotificationPreferences(fine.UserID)
+	if err != nil {
+		preferences, err = CreateDefaultUserNotificationPreferences(fine.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification preferences: %v", err)
+		}
+	}
+
+	if preferences.FineAssignedInApp {
+		var club Club
+		if err := database.Db.Where("id = ?", fine.ClubID).First(&club).Error; err != nil {
+			return fmt.Errorf("failed to find club: %v", err)
+		}
+
+		title := "New fine in " + club.Name
+		message := fmt.Sprintf("You have received a fine of %.2f: %s", fine.Amount, fine.Reason)
+		if err := CreateNotification(fine.UserID, "fine_assigned", title, message, &fine.ClubID, fine.EventID, &fine.ID); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SendFineReviewNotifications tells the members who manage fines that automatic fines await their review
+func SendFineReviewNotifications(clubID string, count int) error {
+	var club Club
+	if err := database.Db.Where("id = ?", clubID).First(&club).Error; err != nil {
+		return fmt.Errorf("failed to find club: %v", err)
+	}
+
+	adminIDs, err := permissionHolderIDs(clubID, PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	for _, adminID := range adminIDs {
+		preferences, err := GetUserNotificationPreferences(adminID)
+		if err != nil || !preferences.FineAssignedInApp {
+			continue
+		}
+
+		title := "Fines to review in " + club.Name
+		message := fmt.Sprintf("%d automatic fine(s) are waiting for your review.", count)
+		if err := CreateNotification(adminID, "fine_review", title, message, &clubID, nil, nil); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SendFineDisputeNotifications informs the fined member and the members who manage fines about a dispute transition
+func SendFineDisputeNotifications(fine Fine, comment string) error {
+	var club Club
+	if err := database.Db.Where("id = ?", fine.ClubID).First(&club).Error; err != nil {
+		return fmt.Errorf("failed to find club: %v", err)
+	}
+
+	var memberTitle, memberMessage, adminTitle, adminMessage string
+	switch fine.DisputeStatus {
+	case FineDisputeStatusDisputed:
+		memberTitle = "Fine disputed in " + club.Name
+		memberMessage = fmt.Sprintf("Your dispute of the fine \"%s\" has been submitted and will be reviewed.", fine.Reason)
+		adminTitle = "Fine disputed in " + club.Name
+		adminMessage = fmt.Sprintf("A fine of %.2f (\"%s\") has been disputed: %s", fine.Amount, fine.Reason, comment)
+	case FineDisputeStatusUpheld:
+		memberTitle = "Fine dispute rejected in " + club.Name
+		memberMessage = fmt.Sprintf("Your dispute of the fine \"%s\" was rejected. The fine of %.2f stands.", fine.Reason, fine.Amount)
+	case FineDisputeStatusWaived:
+		memberTitle = "Fine waived in " + club.Name
+		memberMessage = fmt.Sprintf("Your dispute of the fine \"%s\" was accepted. The fine has been waived.", fine.Reason)
+	case FineDisputeStatusReduced:
+		memberTitle = "Fine reduced in " + club.Name
+		memberMessage = fmt.Sprintf("Your dispute of the fine \"%s\" was partially accepted. The fine has been reduced to %.2f.", fine.Reason, fine.Amount)
+	default:
+		return fmt.Errorf("no notification for dispute status %s", fine.DisputeStatus)
+	}
+	if fine.DisputeStatus != FineDisputeStatusDisputed {
+		if comment != "" {
+			memberMessage += " Comment: " + comment
+		}
+		adminTitle = "Fine dispute resolved in " + club.Name
+		adminMessage = fmt.Sprintf("The dispute of the fine \"%s\" was resolved as %s.", fine.Reason, fine.DisputeStatus)
+	}
+
+	preferences, err := GetUserNotificationPreferences(fine.UserID)
+	if err != nil {
+		preferences, err = CreateDefaultUserNotificationPreferences(fine.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to create notification preferences: %v", err)
+		}
+	}
+	if preferences.FineAssignedInApp {
+		if err := CreateNotification(fine.UserID, "fine_dispute", memberTitle, memberMessage, &fine.ClubID, nil, &fine.ID); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	adminIDs, err := permissionHolderIDs(fine.ClubID, PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	for _, adminID := range adminIDs {
+		if adminID == fine.UserID {
+			continue
+		}
+		preferences, err := GetUserNotificationPreferences(adminID)
+		if err != nil || !preferences.FineAssignedInApp {
+			continue
+		}
+		if err := CreateNotification(adminID, "fine_dispute", adminTitle, adminMessage, &fine.ClubID, nil, &fine.ID); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SendShiftSwapNotifications informs everyone involved in a shift swap about a change
+func SendShiftSwapNotifications(request ShiftSwapRequest, shift Shift, change string) error {
+	var event Event
+	if err := database.Db.Where("id = ?", shift.EventID).First(&event).Error; err != nil {
+		return fmt.Errorf("failed to find event: %v", err)
+	}
+
+	slot := event.Name + " (" + shift.StartTime.Format("02.01.2006 15:04") + ")"
+	if shift.Role != nil && *shift.Role != "" {
+		slot = *shift.Role + " at " + slot
+	}
+
+	var recipients []string
+	var title, message string
+	switch change {
+	case ShiftSwapStatusOpen:
+		// Eligible members are all club members not on the shift yet
+		if err := database.Db.Model(&Member{}).
+			Where("club_id = ? AND user_id NOT IN (SELECT user_id FROM shift_members WHERE shift_id = ?)", shift.ClubID, shift.ID).
+			Pluck("user_id", &recipients).Error; err != nil {
+			return err
+		}
+		title = "Shift available"
+		message = "A shift needs a substitute: " + slot
+	case ShiftSwapStatusTradeProposed:
+		recipients = []string{request.RequestedBy}
+		title = "Shift trade proposed"
+		message = "Someone offered a trade for your shift " + slot + "."
+	case shiftSwapTradeDeclined:
+		recipients = []string{*request.ClaimedBy}
+		title = "Shift trade declined"
+		message = "Your proposed trade for the shift " + slot + " was declined."
+	case ShiftSwapStatusPendingApproval:
+		holders, err := permissionHolderIDs(shift.ClubID, PermissionShiftsManage)
+		if err != nil {
+			return err
+		}
+		recipients = holders
+		title = "Shift swap awaiting approval"
+		message = "A shift swap for " + slot + " needs your approval."
+	case ShiftSwapStatusCompleted:
+		recipients = []string{request.RequestedBy, *request.ClaimedBy}
+		title = "Shift swap completed"
+		message = "The shift swap for " + slot + " is complete and the roster has been updated."
+	case ShiftSwapStatusRejected:
+		recipients = []string{request.RequestedBy, *request.ClaimedBy}
+		title = "Shift swap rejected"
+		message = "The shift swap for " + slot + " was rejected by an admin."
+	case ShiftSwapStatusCancelled:
+		if request.ClaimedBy != nil {
+			recipients = []string{*request.ClaimedBy}
+		}
+		title = "Shift swap cancelled"
+		message = "The shift swap for " + slot + " was cancelled."
+	default:
+		return fmt.Errorf("no notification for shift swap change %s", change)
+	}
+
+	for _, userID := range recipients {
+		if userID == request.RequestedBy && change == ShiftSwapStatusOpen {
+			continue
+		}
+		if err := CreateNotification(userID, "shift_swap", title, message, &shift.ClubID, &shift.EventID, nil); err != nil {
+			return fmt.Errorf("failed to create in-app notification: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SendShiftAssignedNotification tells a member that they were assigned to a shift
+func SendShiftAssignedNotification(userID string, shift Shift) error {
+	var event Event
+	if err := database.Db.Where("id = ?", shift.EventID).First(&event).Error; err != nil {
+		return fmt.Errorf("failed to find event: %v", err)
+	}
+
+	slot := event.Name + " (" + shift.StartTime.Format("02.01.2006 15:04") + ")"
+	if shift.Role != nil && *shift.Role != "" {
+		slot = *shift.Role + " at " + slot
+	}
+
+	if err := CreateNotification(userID, "shift_assigned", "Assigned to a shift", "You have been assigned to the shift "+slot+".", &shift.ClubID, &shift.EventID, nil); err != nil {
+		return fmt.Errorf("failed to create in-app notification: %v", err)
+	}
+
+	return nil
+}
+
 // ODataBeforeReadCollection filters notifications to only those belonging to the user
 func (n Notification) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
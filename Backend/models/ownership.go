@@ -0,0 +1,502 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ownership transfer states
+const (
+	OwnershipTransferStatusPending   = "pending"
+	OwnershipTransferStatusAccepted  = "accepted"
+	OwnershipTransferStatusDeclined  = "declined"
+	OwnershipTransferStatusCancelled = "cancelled"
+)
+
+// Reasons for promoting a club successor
+const (
+	SuccessionReasonAccountDeleted = "account_deleted"
+	SuccessionReasonOwnerInactive  = "owner_inactive"
+)
+
+var (
+	ErrOwnershipTransferPending    = errors.New("an ownership transfer is already pending for this club")
+	ErrOwnershipTransferNotPending = errors.New("ownership transfer is no longer pending")
+	ErrTransferRecipientNotMember  = errors.New("ownership can only be transferred to a member of the club")
+	ErrTransferRecipientIsOwner    = errors.New("the recipient is already an owner of the club")
+	ErrSuccessorNotMember          = errors.New("successor must be a member of the club")
+	ErrSuccessorIsOwner            = errors.New("successor must not be an owner of the club")
+)
+
+// OwnershipTransfer is an owner's offer to hand over a club to another member. Ownership only
+// changes once the recipient accepts; the previous owner then becomes an admin.
+type OwnershipTransfer struct {
+	ID          string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	FromUserID  string     `json:"FromUserID" gorm:"type:uuid;not null" odata:"auto"`
+	ToUserID    string     `json:"ToUserID" gorm:"type:uuid;not null;index" odata:"auto"`
+	Status      string     `json:"Status" gorm:"not null;default:pending" odata:"auto"` // pending, accepted, declined, cancelled
+	CreatedAt   time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	RespondedAt *time.Time `json:"RespondedAt,omitempty" odata:"auto,nullable"`
+
+	// Navigation properties for OData expansions
+	Club     *Club `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
+	FromUser *User `gorm:"foreignKey:FromUserID" json:"FromUser,omitempty" odata:"nav"`
+	ToUser   *User `gorm:"foreignKey:ToUserID" json:"ToUser,omitempty" odata:"nav"`
+}
+
+// ClubSuccession designates the member who becomes owner if the last owner deletes their
+// account or all owners have been inactive for InactivityDays
+type ClubSuccession struct {
+	ID             string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID         string    `json:"ClubID" gorm:"type:uuid;not null;uniqueIndex" odata:"required"`
+	SuccessorID    string    `json:"SuccessorID" gorm:"type:uuid;not null;index" odata:"required"`
+	InactivityDays int       `json:"InactivityDays" gorm:"not null;default:0"` // 0 only promotes the successor when the last owner deletes their account
+	CreatedAt      time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy      string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt      time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy      string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Successor *User `gorm:"foreignKey:SuccessorID" json:"Successor,omitempty" odata:"nav"`
+}
+
+// UserPresence records when a user was last seen, for detecting inactive owners
+type UserPresence struct {
+	UserID     string    `gorm:"type:uuid;primaryKey"`
+	LastSeenAt time.Time `gorm:"not null"`
+}
+
+// BeforeCreate generates UUID for new ownership transfers
+func (ot *OwnershipTransfer) BeforeCreate(tx *gorm.DB) error {
+	if ot.ID == "" {
+		ot.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new club successions
+func (cs *ClubSuccession) BeforeCreate(tx *gorm.DB) error {
+	if cs.ID == "" {
+		cs.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TouchUserPresence records that the user is active now
+func TouchUserPresence(userID string) error {
+	return database.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	}).Create(&UserPresence{UserID: userID, LastSeenAt: time.Now()}).Error
+}
+
+// userLastSeen returns when the user last signed in or used an API key. Users without a
+// presence record are considered seen now, so inactivity is only counted from the first check.
+func userLastSeen(userID string) (time.Time, error) {
+	var presence UserPresence
+	err := database.Db.Where("user_id = ?", userID).First(&presence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Now(), TouchUserPresence(userID)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	lastSeen := presence.LastSeenAt
+	var apiKey APIKey
+	err = database.Db.Where("user_id = ? AND last_used_at IS NOT NULL", userID).Order("last_used_at DESC").First(&apiKey).Error
+	if err == nil && apiKey.LastUsedAt.After(lastSeen) {
+		lastSeen = *apiKey.LastUsedAt
+	}
+	return lastSeen, nil
+}
+
+// clubOwnerIDs returns the user IDs of the club's owners
+func clubOwnerIDs(clubID string) ([]string, error) {
+	var ownerIDs []string
+	err := database.Db.Model(&Member{}).Where("club_id = ? AND role = ?", clubID, "owner").Pluck("user_id", &ownerIDs).Error
+	return ownerIDs, err
+}
+
+// RequestOwnershipTransfer offers the club to another member, who has to accept it
+func RequestOwnershipTransfer(club *Club, fromUserID, toUserID string) (*OwnershipTransfer, error) {
+	var recipient Member
+	err := database.Db.Where("club_id = ? AND user_id = ?", club.ID, toUserID).First(&recipient).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTransferRecipientNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Role == "owner" {
+		return nil, ErrTransferRecipientIsOwner
+	}
+	if recipient.Status == MemberStatusSuspended {
+		return nil, ErrSuspendedMemberRole
+	}
+
+	var pending int64
+	if err := database.Db.Model(&OwnershipTransfer{}).Where("club_id = ? AND status = ?", club.ID, OwnershipTransferStatusPending).
+		Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, ErrOwnershipTransferPending
+	}
+
+	transfer := OwnershipTransfer{
+		ClubID:     club.ID,
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Status:     OwnershipTransferStatusPending,
+		CreatedAt:  time.Now(),
+	}
+	if err := database.Db.Create(&transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+
+	_ = CreateNotification(toUserID, "ownership_transfer_requested", "Club ownership offered",
+		fmt.Sprintf("You have been asked to take over ownership of %s.", club.Name), &club.ID, nil, nil)
+
+	return &transfer, nil
+}
+
+// respond closes a pending transfer with the given status
+func (ot *OwnershipTransfer) respond(tx *gorm.DB, status string) error {
+	now := time.Now()
+	result := tx.Model(&OwnershipTransfer{}).Where("id = ? AND status = ?", ot.ID, OwnershipTransferStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOwnershipTransferNotPending
+	}
+	ot.Status = status
+	ot.RespondedAt = &now
+	return nil
+}
+
+// Accept makes the recipient an owner of the club and the previous owner an admin
+func (ot *OwnershipTransfer) Accept(userID string) error {
+	if ot.ToUserID != userID {
+		return fmt.Errorf("unauthorized: only the recipient can accept an ownership transfer")
+	}
+
+	var club Club
+	if err := database.Db.Where("id = ?", ot.ClubID).First(&club).Error; err != nil {
+		return fmt.Errorf("failed to find club: %w", err)
+	}
+	if !club.IsOwner(User{ID: ot.FromUserID}) {
+		// The offer is void if its sender is no longer an owner
+		if err := ot.respond(database.Db, OwnershipTransferStatusCancelled); err != nil {
+			return err
+		}
+		return ErrOwnershipTransferNotPending
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := ot.respond(tx, OwnershipTransferStatusAccepted); err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&Member{}).Where("club_id = ? AND user_id = ?", ot.ClubID, ot.ToUserID).
+			Updates(map[string]interface{}{"role": "owner", "updated_at": now, "updated_by": userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferRecipientNotMember
+		}
+		if err := tx.Model(&Member{}).Where("club_id = ? AND user_id = ?", ot.ClubID, ot.FromUserID).
+			Updates(map[string]interface{}{"role": "admin", "updated_at": now, "updated_by": userID}).Error; err != nil {
+			return err
+		}
+		// A successor who became owner is no longer needed as successor
+		return tx.Where("club_id = ? AND successor_id = ?", ot.ClubID, ot.ToUserID).Delete(&ClubSuccession{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = CreateNotification(ot.FromUserID, "ownership_transfer_accepted", "Club ownership transferred",
+		fmt.Sprintf("Ownership of %s has been accepted. You are now an admin of the club.", club.Name), &club.ID, nil, nil)
+	return nil
+}
+
+// Decline rejects the transfer; ownership stays unchanged
+func (ot *OwnershipTransfer) Decline(userID string) error {
+	if ot.ToUserID != userID {
+		return fmt.Errorf("unauthorized: only the recipient can decline an ownership transfer")
+	}
+	if err := ot.respond(database.Db, OwnershipTransferStatusDeclined); err != nil {
+		return err
+	}
+
+	_ = CreateNotification(ot.FromUserID, "ownership_transfer_declined", "Club ownership declined",
+		"Your offer to transfer club ownership has been declined.", &ot.ClubID, nil, nil)
+	return nil
+}
+
+// Cancel withdraws a pending transfer. Any owner of the club can cancel it.
+func (ot *OwnershipTransfer) Cancel(userID string) error {
+	club := Club{ID: ot.ClubID}
+	if !club.IsOwner(User{ID: userID}) {
+		return fmt.Errorf("unauthorized: only club owners can cancel an ownership transfer")
+	}
+	if err := ot.respond(database.Db, OwnershipTransferStatusCancelled); err != nil {
+		return err
+	}
+
+	_ = CreateNotification(ot.ToUserID, "ownership_transfer_cancelled", "Club ownership offer withdrawn",
+		"An offer to take over club ownership has been withdrawn.", &ot.ClubID, nil, nil)
+	return nil
+}
+
+// GetClubSuccession returns the successor designation of a club, or nil if there is none
+func GetClubSuccession(clubID string) (*ClubSuccession, error) {
+	var succession ClubSuccession
+	err := database.Db.Where("club_id = ?", clubID).First(&succession).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &succession, nil
+}
+
+// clubsWithoutSuccessor returns the clubs that have no successor designated
+func clubsWithoutSuccessor(clubs []Club) ([]Club, error) {
+	var without []Club
+	for _, club := range clubs {
+		succession, err := GetClubSuccession(club.ID)
+		if err != nil {
+			return nil, err
+		}
+		if succession == nil {
+			without = append(without, club)
+		}
+	}
+	return without, nil
+}
+
+// promoteSuccessor makes the designated successor an owner of the club. The designation is
+// used up by the promotion. Promotions are recorded in the audit log without an actor.
+func promoteSuccessor(succession *ClubSuccession, reason string) error {
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", succession.ClubID, succession.SuccessorID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSuccessorNotMember
+		}
+		return err
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Member{}).Where("id = ?", member.ID).
+			Updates(map[string]interface{}{"role": "owner", "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(succession).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := RecordAuditAction(context.Background(), nil, succession.ClubID, "Member", member.ID, "PromoteSuccessor", map[string]AuditChange{
+		"Role":   {Old: member.Role, New: "owner"},
+		"Reason": {New: reason},
+	}); err != nil {
+		log.Printf("failed to record successor promotion in club %s: %v", succession.ClubID, err)
+	}
+
+	var club Club
+	clubName := "a club"
+	if err := database.Db.Where("id = ?", succession.ClubID).First(&club).Error; err == nil {
+		clubName = club.Name
+	}
+	_ = CreateNotification(succession.SuccessorID, "club_successor_promoted", "You are now a club owner",
+		fmt.Sprintf("As the designated successor, you have become an owner of %s.", clubName), &succession.ClubID, nil, nil)
+
+	ownerIDs, err := clubOwnerIDs(succession.ClubID)
+	if err != nil {
+		return nil
+	}
+	for _, ownerID := range ownerIDs {
+		if ownerID == succession.SuccessorID {
+			continue
+		}
+		_ = CreateNotification(ownerID, "club_successor_promoted", "Club successor promoted",
+			fmt.Sprintf("The designated successor has become an owner of %s.", clubName), &succession.ClubID, nil, nil)
+	}
+	return nil
+}
+
+// PromoteClubSuccessors promotes the successors of clubs whose owners have all been inactive for
+// longer than the club's inactivity period.
+// This should be called periodically by the scheduler.
+func PromoteClubSuccessors() error {
+	var successions []ClubSuccession
+	if err := database.Db.Where("inactivity_days > 0").Find(&successions).Error; err != nil {
+		return err
+	}
+
+	for i := range successions {
+		succession := &successions[i]
+		ownerIDs, err := clubOwnerIDs(succession.ClubID)
+		if err != nil {
+			log.Printf("failed to load owners of club %s: %v", succession.ClubID, err)
+			continue
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -succession.InactivityDays)
+		inactive := true
+		for _, ownerID := range ownerIDs {
+			lastSeen, err := userLastSeen(ownerID)
+			if err != nil || lastSeen.After(cutoff) {
+				inactive = false
+				break
+			}
+		}
+		if !inactive {
+			continue
+		}
+
+		if err := promoteSuccessor(succession, SuccessionReasonOwnerInactive); err != nil {
+			log.Printf("failed to promote successor of club %s: %v", succession.ClubID, err)
+		}
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection limits ownership transfers to owners of the club and the recipient
+func (ot OwnershipTransfer) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("to_user_id = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role = 'owner')", userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific ownership transfer
+func (ot OwnershipTransfer) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return ot.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating transfers directly; use the TransferOwnership action
+func (ot *OwnershipTransfer) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the TransferOwnership action to transfer club ownership")
+}
+
+// ODataBeforeUpdate prevents changing transfers; use the Accept, Decline and Cancel actions
+func (ot *OwnershipTransfer) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the Accept, Decline or Cancel actions to respond to an ownership transfer")
+}
+
+// ODataBeforeDelete keeps the record of ownership transfers
+func (ot *OwnershipTransfer) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: ownership transfers cannot be deleted")
+}
+
+// ODataBeforeReadCollection limits successions to owners of the club and the successor
+func (cs ClubSuccession) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("successor_id = ? OR club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role = 'owner')", userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific succession
+func (cs ClubSuccession) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return cs.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// SetClubSuccessor designates the successor of a club, replacing an earlier designation.
+// The successor must be a member but not an owner of the club.
+func SetClubSuccessor(club *Club, successorID string, inactivityDays int, setBy string) (*ClubSuccession, error) {
+	if inactivityDays < 0 {
+		return nil, fmt.Errorf("inactivity days must not be negative")
+	}
+	role, err := club.GetMemberRole(User{ID: successorID})
+	if err != nil {
+		return nil, ErrSuccessorNotMember
+	}
+	if role == "owner" {
+		return nil, ErrSuccessorIsOwner
+	}
+
+	succession, err := GetClubSuccession(club.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if succession == nil {
+		succession = &ClubSuccession{ClubID: club.ID, CreatedAt: now, CreatedBy: setBy}
+	}
+	previousSuccessor := succession.SuccessorID
+	succession.SuccessorID = successorID
+	succession.InactivityDays = inactivityDays
+	succession.UpdatedAt = now
+	succession.UpdatedBy = setBy
+	if err := database.Db.Save(succession).Error; err != nil {
+		return nil, fmt.Errorf("failed to save successor: %w", err)
+	}
+
+	if previousSuccessor != successorID {
+		_ = CreateNotification(successorID, "club_successor_designated", "Designated as club successor",
+			fmt.Sprintf("You have been designated as successor of %s and will become an owner if the club is left without an active owner.", club.Name),
+			&club.ID, nil, nil)
+	}
+	return succession, nil
+}
+
+// ODataBeforeCreate prevents creating successions directly; use the SetSuccessor action
+func (cs *ClubSuccession) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSuccessor action to designate a successor")
+}
+
+// ODataBeforeUpdate prevents changing successions directly; use the SetSuccessor action
+func (cs *ClubSuccession) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSuccessor action to change the successor")
+}
+
+// ODataBeforeDelete lets owners remove the designation and successors step down
+func (cs *ClubSuccession) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	club := Club{ID: cs.ClubID}
+	if cs.SuccessorID != userID && !club.IsOwner(User{ID: userID}) {
+		return fmt.Errorf("unauthorized: only club owners or the successor can remove a successor")
+	}
+	return nil
+}
+
+// ODataAfterDelete records the removed designation in the audit log
+func (cs *ClubSuccession) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cs.ClubID, "ClubSuccession", cs.ID, AuditOperationDelete, cs)
+}
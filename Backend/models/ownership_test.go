@@ -0,0 +1,140 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipTransfer(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "transfer-owner@example.com")
+	recipient, _ := handlers.CreateTestUser(t, "transfer-recipient@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "transfer-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Transfer Club")
+	handlers.CreateTestMember(t, recipient, club, "member")
+
+	role := func(user models.User) string {
+		r, err := club.GetMemberRole(user)
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("recipient must be a member", func(t *testing.T) {
+		_, err := models.RequestOwnershipTransfer(&club, owner.ID, outsider.ID)
+		assert.ErrorIs(t, err, models.ErrTransferRecipientNotMember)
+	})
+
+	t.Run("declined transfer keeps ownership", func(t *testing.T) {
+		transfer, err := models.RequestOwnershipTransfer(&club, owner.ID, recipient.ID)
+		require.NoError(t, err)
+
+		_, err = models.RequestOwnershipTransfer(&club, owner.ID, recipient.ID)
+		assert.ErrorIs(t, err, models.ErrOwnershipTransferPending)
+
+		assert.Error(t, transfer.Decline(owner.ID))
+		require.NoError(t, transfer.Decline(recipient.ID))
+		assert.Equal(t, models.OwnershipTransferStatusDeclined, transfer.Status)
+		assert.Equal(t, "owner", role(owner))
+		assert.Equal(t, "member", role(recipient))
+
+		assert.ErrorIs(t, transfer.Accept(recipient.ID), models.ErrOwnershipTransferNotPending)
+	})
+
+	t.Run("only owners can cancel", func(t *testing.T) {
+		transfer, err := models.RequestOwnershipTransfer(&club, owner.ID, recipient.ID)
+		require.NoError(t, err)
+		assert.Error(t, transfer.Cancel(recipient.ID))
+		require.NoError(t, transfer.Cancel(owner.ID))
+		assert.Equal(t, models.OwnershipTransferStatusCancelled, transfer.Status)
+	})
+
+	t.Run("accepted transfer swaps roles", func(t *testing.T) {
+		transfer, err := models.RequestOwnershipTransfer(&club, owner.ID, recipient.ID)
+		require.NoError(t, err)
+		require.NoError(t, transfer.Accept(recipient.ID))
+
+		assert.Equal(t, "owner", role(recipient))
+		assert.Equal(t, "admin", role(owner))
+
+		var notifications int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", owner.ID, "ownership_transfer_accepted").Count(&notifications)
+		assert.Equal(t, int64(1), notifications)
+	})
+}
+
+func TestClubSuccession(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "succession-owner@example.com")
+	successor, _ := handlers.CreateTestUser(t, "succession-successor@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "succession-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Succession Club")
+	handlers.CreateTestMember(t, successor, club, "member")
+
+	t.Run("successor must be a non-owner member", func(t *testing.T) {
+		_, err := models.SetClubSuccessor(&club, outsider.ID, 0, owner.ID)
+		assert.ErrorIs(t, err, models.ErrSuccessorNotMember)
+		_, err = models.SetClubSuccessor(&club, owner.ID, 0, owner.ID)
+		assert.ErrorIs(t, err, models.ErrSuccessorIsOwner)
+	})
+
+	t.Run("successor is promoted when owners are inactive", func(t *testing.T) {
+		_, err := models.SetClubSuccessor(&club, successor.ID, 30, owner.ID)
+		require.NoError(t, err)
+
+		// Owners without presence record count as active from the first check
+		require.NoError(t, models.PromoteClubSuccessors())
+		role, _ := club.GetMemberRole(successor)
+		assert.Equal(t, "member", role)
+
+		require.NoError(t, db.Model(&models.UserPresence{}).Where("user_id = ?", owner.ID).
+			Update("last_seen_at", time.Now().AddDate(0, 0, -31)).Error)
+		require.NoError(t, models.PromoteClubSuccessors())
+
+		role, _ = club.GetMemberRole(successor)
+		assert.Equal(t, "owner", role)
+		succession, err := models.GetClubSuccession(club.ID)
+		require.NoError(t, err)
+		assert.Nil(t, succession)
+
+		var entries []models.AuditLog
+		require.NoError(t, db.Where("club_id = ? AND action = ?", club.ID, "PromoteSuccessor").Find(&entries).Error)
+		require.Len(t, entries, 1)
+		assert.Empty(t, entries[0].ActorID)
+	})
+
+	t.Run("last owner with successor can delete account", func(t *testing.T) {
+		heir, _ := handlers.CreateTestUser(t, "succession-heir@example.com")
+		soleOwner, _ := handlers.CreateTestUser(t, "succession-sole@example.com")
+		otherClub := handlers.CreateTestClub(t, soleOwner, "Inherited Club")
+		handlers.CreateTestMember(t, heir, otherClub, "member")
+
+		_, err := models.RequestAccountDeletion(soleOwner.ID, nil)
+		assert.ErrorIs(t, err, models.ErrAccountDeletionSoleOwner)
+
+		_, err = models.SetClubSuccessor(&otherClub, heir.ID, 0, soleOwner.ID)
+		require.NoError(t, err)
+		_, err = models.RequestAccountDeletion(soleOwner.ID, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, db.Model(&models.AccountDeletion{}).Where("user_id = ?", soleOwner.ID).
+			Update("scheduled_for", time.Now().Add(-time.Minute)).Error)
+		require.NoError(t, models.PurgeDeletedAccounts())
+
+		role, err := otherClub.GetMemberRole(heir)
+		require.NoError(t, err)
+		assert.Equal(t, "owner", role)
+		_, err = models.GetPendingAccountDeletion(soleOwner.ID)
+		assert.ErrorIs(t, err, models.ErrNoAccountDeletion)
+	})
+}
@@ -0,0 +1,501 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Club permissions that can be bundled into custom roles
+const (
+	PermissionFinesManage   = "fines.manage"   // Fines, fine templates, fine rules, disputes, fees, SEPA and bank statements
+	PermissionEventsManage  = "events.manage"  // Events, venues, event comments and scheduling polls
+	PermissionMembersInvite = "members.invite" // Invites, invite links and join requests
+	PermissionNewsPublish   = "news.publish"   // News posts and their comments, polls and announcements
+	PermissionShiftsManage  = "shifts.manage"  // Shifts, shift templates, rosters and volunteer quotas
+	PermissionSettingsEdit  = "settings.edit"  // Club details, settings and seasons
+)
+
+// Permissions held by club admins and owners only; they cannot be granted through custom roles
+const (
+	PermissionMembersManage = "members.manage" // Member roles, removals, custom roles and absences of others
+	PermissionClubDelete    = "club.delete"    // Owners only
+	PermissionAuditRead     = "audit.read"     // Owners only
+)
+
+// AssignablePermissions lists the permissions custom roles can grant
+var AssignablePermissions = []string{
+	PermissionFinesManage,
+	PermissionEventsManage,
+	PermissionMembersInvite,
+	PermissionNewsPublish,
+	PermissionShiftsManage,
+	PermissionSettingsEdit,
+}
+
+// permittedClubsQuery selects the clubs in which a user holds a permission, either as admin/owner
+// or through a custom role of a member who is neither suspended nor former. Arguments: user ID, user ID, permission.
+const permittedClubsQuery = `SELECT club_id FROM members WHERE user_id = ? AND status <> 'former' AND role IN ('admin', 'owner')
+	UNION SELECT club_role_assignments.club_id FROM club_role_assignments
+	JOIN club_role_permissions ON club_role_permissions.role_id = club_role_assignments.role_id
+	JOIN members ON members.club_id = club_role_assignments.club_id AND members.user_id = club_role_assignments.user_id
+	WHERE club_role_assignments.user_id = ? AND club_role_permissions.permission = ? AND members.status NOT IN ('suspended', 'former')`
+
+// ClubRole is a custom role of a club that bundles permissions, e.g. "Treasurer" with fines.manage
+type ClubRole struct {
+	ID          string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Name        string    `json:"Name" gorm:"not null" odata:"required"`
+	Description *string   `json:"Description,omitempty" odata:"nullable"`
+	CreatedAt   time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy   string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt   time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy   string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Permissions []ClubRolePermission `gorm:"foreignKey:RoleID" json:"Permissions,omitempty" odata:"nav"`
+	Assignments []ClubRoleAssignment `gorm:"foreignKey:RoleID" json:"Assignments,omitempty" odata:"nav"`
+}
+
+// ClubRolePermission grants a permission to a custom role
+type ClubRolePermission struct {
+	ID         string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	RoleID     string    `json:"RoleID" gorm:"type:uuid;not null;uniqueIndex:idx_club_role_permission" odata:"required"`
+	Permission string    `json:"Permission" gorm:"not null;uniqueIndex:idx_club_role_permission" odata:"required"`
+	CreatedAt  time.Time `json:"CreatedAt" odata:"auto,immutable"`
+}
+
+// ClubRoleAssignment gives a club member a custom role. Members can hold several custom roles.
+type ClubRoleAssignment struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID    string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	RoleID    string    `json:"RoleID" gorm:"type:uuid;not null;uniqueIndex:idx_club_role_assignment" odata:"required"`
+	UserID    string    `json:"UserID" gorm:"type:uuid;not null;uniqueIndex:idx_club_role_assignment" odata:"required"`
+	CreatedAt time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	Role *ClubRole `gorm:"foreignKey:RoleID" json:"Role,omitempty" odata:"nav"`
+	User *User     `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new club roles
+func (cr *ClubRole) BeforeCreate(tx *gorm.DB) error {
+	if cr.ID == "" {
+		cr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new role permissions
+func (crp *ClubRolePermission) BeforeCreate(tx *gorm.DB) error {
+	if crp.ID == "" {
+		crp.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new role assignments
+func (cra *ClubRoleAssignment) BeforeCreate(tx *gorm.DB) error {
+	if cra.ID == "" {
+		cra.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsAssignablePermission checks if a permission can be granted through a custom role
+func IsAssignablePermission(permission string) bool {
+	for _, assignable := range AssignablePermissions {
+		if permission == assignable {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission is the central permission evaluator for club operations. Owners hold every
+// permission, admins every permission except the owner-only ones, and members the permissions
+// of their custom roles unless they are suspended.
+func HasPermission(clubID, userID, permission string) bool {
+	if clubID == "" || userID == "" {
+		return false
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, userID).First(&member).Error; err != nil {
+		return false
+	}
+
+	// Built-in roles: owners hold every permission, admins all but the owner-only ones
+	switch {
+	case member.Role == "owner":
+		return true
+	case permission == PermissionClubDelete, permission == PermissionAuditRead:
+		return false
+	case member.Role == "admin":
+		return true
+	}
+
+	var count int64
+	err := database.Db.Raw("SELECT COUNT(*) FROM ("+permittedClubsQuery+") permitted WHERE club_id = ?", userID, userID, permission, clubID).
+		Scan(&count).Error
+	return err == nil && count > 0
+}
+
+// permissionHolderIDs returns the IDs of the users who hold a permission in the club. Only meant
+// for assignable permissions, as every admin and owner is included.
+func permissionHolderIDs(clubID, permission string) ([]string, error) {
+	var userIDs []string
+	err := database.Db.Raw(`SELECT user_id FROM members WHERE club_id = ? AND status <> 'former' AND role IN ('admin', 'owner')
+		UNION SELECT club_role_assignments.user_id FROM club_role_assignments
+		JOIN club_role_permissions ON club_role_permissions.role_id = club_role_assignments.role_id
+		JOIN members ON members.club_id = club_role_assignments.club_id AND members.user_id = club_role_assignments.user_id
+		WHERE club_role_assignments.club_id = ? AND club_role_permissions.permission = ? AND members.status NOT IN ('suspended', 'former')`,
+		clubID, clubID, permission).Scan(&userIDs).Error
+	return userIDs, err
+}
+
+// GetPermissions returns all permissions the user holds in the club, sorted by name
+func GetPermissions(clubID, userID string) ([]string, error) {
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, userID).First(&member).Error; err != nil {
+		return []string{}, nil
+	}
+
+	var permissions []string
+	switch member.Role {
+	case "owner":
+		permissions = append(append(permissions, AssignablePermissions...), PermissionMembersManage, PermissionClubDelete, PermissionAuditRead)
+	case "admin":
+		permissions = append(append(permissions, AssignablePermissions...), PermissionMembersManage)
+	default:
+		if member.Status == MemberStatusSuspended {
+			return []string{}, nil
+		}
+		err := database.Db.Model(&ClubRolePermission{}).Distinct("permission").
+			Where("role_id IN (SELECT role_id FROM club_role_assignments WHERE club_id = ? AND user_id = ?)", clubID, userID).
+			Pluck("permission", &permissions).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+// validate checks the role
+func (cr *ClubRole) validate() error {
+	cr.Name = strings.TrimSpace(cr.Name)
+	if cr.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch strings.ToLower(cr.Name) {
+	case "owner", "admin", "member":
+		return fmt.Errorf("name is reserved for a built-in role")
+	}
+
+	var count int64
+	if err := database.Db.Model(&ClubRole{}).Where("club_id = ? AND LOWER(name) = ? AND id <> ?", cr.ClubID, strings.ToLower(cr.Name), cr.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check role name: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("a role named '%s' already exists in this club", cr.Name)
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection filters custom roles to clubs the user belongs to
+func (cr ClubRole) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific custom role
+func (cr ClubRole) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return cr.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates custom role creation permissions
+func (cr *ClubRole) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(cr.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can create roles")
+	}
+
+	if err := cr.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	cr.CreatedAt = now
+	cr.CreatedBy = userID
+	cr.UpdatedAt = now
+	cr.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate records the new role in the audit log
+func (cr *ClubRole) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cr.ClubID, "ClubRole", cr.ID, AuditOperationCreate, cr)
+}
+
+// ODataBeforeUpdate validates custom role update permissions
+func (cr *ClubRole) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "ClubRole", cr.ID, cr)
+
+	if !HasPermission(cr.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can update roles")
+	}
+
+	updated, err := updatedEntity(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving roles to another club
+	if updated.ClubID != cr.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing role")
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	cr.UpdatedAt = time.Now()
+	cr.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate records the changes to the role in the audit log
+func (cr *ClubRole) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cr.ClubID, "ClubRole", cr.ID, AuditOperationUpdate, cr)
+}
+
+// ODataBeforeDelete validates custom role deletion permissions and removes its permissions and assignments
+func (cr *ClubRole) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(cr.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete roles")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("role_id = ?", cr.ID).Delete(&ClubRolePermission{}).Error; err != nil {
+		return fmt.Errorf("failed to delete role permissions: %w", err)
+	}
+	if err := tx.Where("role_id = ?", cr.ID).Delete(&ClubRoleAssignment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete role assignments: %w", err)
+	}
+	return nil
+}
+
+// ODataAfterDelete records the deleted role in the audit log
+func (cr *ClubRole) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cr.ClubID, "ClubRole", cr.ID, AuditOperationDelete, cr)
+}
+
+// roleClubID returns the club of a custom role
+func roleClubID(roleID string) (string, error) {
+	var role ClubRole
+	if err := database.Db.Where("id = ?", roleID).First(&role).Error; err != nil {
+		return "", fmt.Errorf("role not found")
+	}
+	return role.ClubID, nil
+}
+
+// ODataBeforeReadCollection filters role permissions to clubs the user belongs to
+func (crp ClubRolePermission) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("role_id IN (SELECT id FROM club_roles WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former'))", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific role permission
+func (crp ClubRolePermission) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return crp.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates that only assignable permissions are granted, by admins and owners
+func (crp *ClubRolePermission) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	clubID, err := roleClubID(crp.RoleID)
+	if err != nil {
+		return err
+	}
+
+	if !HasPermission(clubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can change role permissions")
+	}
+
+	crp.Permission = strings.TrimSpace(crp.Permission)
+	if !IsAssignablePermission(crp.Permission) {
+		return fmt.Errorf("invalid permission: must be one of %s", strings.Join(AssignablePermissions, ", "))
+	}
+
+	crp.CreatedAt = time.Now()
+	return nil
+}
+
+// ODataAfterCreate records the new role permission in the audit log
+func (crp *ClubRolePermission) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	clubID, err := roleClubID(crp.RoleID)
+	if err != nil {
+		return err
+	}
+	return auditEntityChange(ctx, r, clubID, "ClubRolePermission", crp.ID, AuditOperationCreate, crp)
+}
+
+// ODataBeforeUpdate prevents changing permissions in place; remove and grant them instead
+func (crp *ClubRolePermission) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: role permissions cannot be changed, delete and create them instead")
+}
+
+// ODataBeforeDelete validates role permission removal
+func (crp *ClubRolePermission) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	clubID, err := roleClubID(crp.RoleID)
+	if err != nil {
+		return err
+	}
+
+	if !HasPermission(clubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can change role permissions")
+	}
+
+	return nil
+}
+
+// ODataAfterDelete records the deleted role permission in the audit log
+func (crp *ClubRolePermission) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	clubID, err := roleClubID(crp.RoleID)
+	if err != nil {
+		return err
+	}
+	return auditEntityChange(ctx, r, clubID, "ClubRolePermission", crp.ID, AuditOperationDelete, crp)
+}
+
+// ODataBeforeReadCollection filters role assignments to clubs the user belongs to
+func (cra ClubRoleAssignment) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific role assignment
+func (cra ClubRoleAssignment) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return cra.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates that admins assign roles of their club to its members
+func (cra *ClubRoleAssignment) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	clubID, err := roleClubID(cra.RoleID)
+	if err != nil {
+		return err
+	}
+
+	if !HasPermission(clubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can assign roles")
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, cra.UserID).First(&member).Error; err != nil {
+		return fmt.Errorf("user is not a member of the club")
+	}
+
+	cra.ClubID = clubID
+	cra.CreatedAt = time.Now()
+	cra.CreatedBy = userID
+	return nil
+}
+
+// ODataAfterCreate records the new role assignment in the audit log
+func (cra *ClubRoleAssignment) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cra.ClubID, "ClubRoleAssignment", cra.ID, AuditOperationCreate, cra)
+}
+
+// ODataBeforeUpdate prevents changing assignments in place
+func (cra *ClubRoleAssignment) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: role assignments cannot be changed, delete and create them instead")
+}
+
+// ODataBeforeDelete validates role assignment removal
+func (cra *ClubRoleAssignment) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(cra.ClubID, userID, PermissionMembersManage) {
+		return fmt.Errorf("unauthorized: only admins and owners can remove role assignments")
+	}
+
+	return nil
+}
+
+// ODataAfterDelete records the deleted role assignment in the audit log
+func (cra *ClubRoleAssignment) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, cra.ClubID, "ClubRoleAssignment", cra.ID, AuditOperationDelete, cra)
+}
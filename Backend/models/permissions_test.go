@@ -0,0 +1,162 @@
+package models_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomRolePermissions(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "roles-owner@example.com")
+	admin, _ := handlers.CreateTestUser(t, "roles-admin@example.com")
+	treasurer, _ := handlers.CreateTestUser(t, "roles-treasurer@example.com")
+	member, _ := handlers.CreateTestUser(t, "roles-member@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "roles-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Roles Club")
+	handlers.CreateTestMember(t, admin, club, "admin")
+	handlers.CreateTestMember(t, treasurer, club, "member")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET fines_enabled = true, events_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	ownerCtx, ownerReq := authedRequest(owner.ID)
+	treasurerCtx, treasurerReq := authedRequest(treasurer.ID)
+
+	role := models.ClubRole{ClubID: club.ID, Name: "Treasurer"}
+
+	t.Run("roles are managed by admins with assignable permissions only", func(t *testing.T) {
+		ctx, req := authedRequest(member.ID)
+		assert.Error(t, role.ODataBeforeCreate(ctx, req))
+
+		reserved := models.ClubRole{ClubID: club.ID, Name: "Admin"}
+		assert.Error(t, reserved.ODataBeforeCreate(ownerCtx, ownerReq))
+
+		require.NoError(t, role.ODataBeforeCreate(ownerCtx, ownerReq))
+		require.NoError(t, db.Create(&role).Error)
+
+		deleteClub := models.ClubRolePermission{RoleID: role.ID, Permission: models.PermissionClubDelete}
+		assert.Error(t, deleteClub.ODataBeforeCreate(ownerCtx, ownerReq))
+		manageMembers := models.ClubRolePermission{RoleID: role.ID, Permission: models.PermissionMembersManage}
+		assert.Error(t, manageMembers.ODataBeforeCreate(ownerCtx, ownerReq))
+
+		finesManage := models.ClubRolePermission{RoleID: role.ID, Permission: models.PermissionFinesManage}
+		require.NoError(t, finesManage.ODataBeforeCreate(ownerCtx, ownerReq))
+		require.NoError(t, db.Create(&finesManage).Error)
+
+		notMember := models.ClubRoleAssignment{RoleID: role.ID, UserID: outsider.ID}
+		assert.Error(t, notMember.ODataBeforeCreate(ownerCtx, ownerReq))
+
+		assignment := models.ClubRoleAssignment{RoleID: role.ID, UserID: treasurer.ID}
+		require.NoError(t, assignment.ODataBeforeCreate(ownerCtx, ownerReq))
+		assert.Equal(t, club.ID, assignment.ClubID)
+		require.NoError(t, db.Create(&assignment).Error)
+	})
+
+	t.Run("role names are unique per club", func(t *testing.T) {
+		duplicate := models.ClubRole{ClubID: club.ID, Name: " treasurer "}
+		assert.Error(t, duplicate.ODataBeforeCreate(ownerCtx, ownerReq))
+
+		coach := models.ClubRole{ClubID: club.ID, Name: "Coach"}
+		require.NoError(t, coach.ODataBeforeCreate(ownerCtx, ownerReq))
+		require.NoError(t, db.Create(&coach).Error)
+
+		path := "/ClubRoles(" + coach.ID + ")"
+		for _, patch := range []map[string]interface{}{
+			{"Name": "Treasurer"},
+			{"Name": "  "},
+			{"Name": "Owner"},
+			{"ClubID": uuid.New().String()},
+		} {
+			w := odataRequest(t, ownerToken, http.MethodPatch, path, patch)
+			assert.Equal(t, http.StatusForbidden, w.Code, patch)
+		}
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Name": "Head coach"})
+		require.Less(t, w.Code, 300, w.Body.String())
+		require.NoError(t, db.First(&coach, "id = ?", coach.ID).Error)
+		assert.Equal(t, "Head coach", coach.Name)
+		assert.Equal(t, club.ID, coach.ClubID)
+	})
+
+	t.Run("evaluator combines built-in and custom roles", func(t *testing.T) {
+		assert.True(t, models.HasPermission(club.ID, owner.ID, models.PermissionClubDelete))
+		assert.False(t, models.HasPermission(club.ID, admin.ID, models.PermissionClubDelete))
+		assert.True(t, models.HasPermission(club.ID, admin.ID, models.PermissionSettingsEdit))
+		assert.True(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage))
+		assert.False(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionEventsManage))
+		assert.False(t, models.HasPermission(club.ID, member.ID, models.PermissionFinesManage))
+
+		permissions, err := models.GetPermissions(club.ID, treasurer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.PermissionFinesManage}, permissions)
+	})
+
+	t.Run("treasurer manages fines but nothing else", func(t *testing.T) {
+		fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: member.ID, Reason: "Late", Amount: 5}
+		assert.NoError(t, fine.ODataBeforeCreate(treasurerCtx, treasurerReq))
+
+		template := models.FineTemplate{ClubID: club.ID, Description: "Late", Amount: 5}
+		assert.NoError(t, template.ODataBeforeCreate(treasurerCtx, treasurerReq))
+
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Training", StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(2 * time.Hour)}
+		assert.Error(t, event.ODataBeforeCreate(treasurerCtx, treasurerReq))
+
+		var membership models.Member
+		require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, member.ID).First(&membership).Error)
+		assert.Error(t, membership.ODataBeforeDelete(treasurerCtx, treasurerReq))
+	})
+
+	t.Run("custom roles reach fees, bank statements, scheduling polls and join requests", func(t *testing.T) {
+		plan := models.FeePlan{ClubID: club.ID, Name: "Adults", Amount: 10, Period: "monthly"}
+		assert.NoError(t, plan.ODataBeforeCreate(treasurerCtx, treasurerReq))
+
+		statement := models.BankStatementImport{ID: uuid.New().String(), ClubID: club.ID, Format: "csv", CreatedBy: owner.ID}
+		require.NoError(t, db.Create(&statement).Error)
+		scopes, err := models.BankStatementImport{}.ODataBeforeReadCollection(treasurerCtx, treasurerReq, nil)
+		require.NoError(t, err)
+		var visible int64
+		require.NoError(t, db.Model(&models.BankStatementImport{}).Scopes(scopes...).Where("id = ?", statement.ID).Count(&visible).Error)
+		assert.Equal(t, int64(1), visible)
+
+		poll := models.SchedulingPoll{ClubID: club.ID, Title: "Next training"}
+		assert.Error(t, poll.ODataBeforeCreate(treasurerCtx, treasurerReq))
+
+		coordinator := models.ClubRole{ClubID: club.ID, Name: "Coordinator"}
+		require.NoError(t, coordinator.ODataBeforeCreate(ownerCtx, ownerReq))
+		require.NoError(t, db.Create(&coordinator).Error)
+		for _, permission := range []string{models.PermissionEventsManage, models.PermissionMembersInvite} {
+			grant := models.ClubRolePermission{RoleID: coordinator.ID, Permission: permission}
+			require.NoError(t, grant.ODataBeforeCreate(ownerCtx, ownerReq))
+			require.NoError(t, db.Create(&grant).Error)
+		}
+		assignment := models.ClubRoleAssignment{RoleID: coordinator.ID, UserID: member.ID}
+		require.NoError(t, assignment.ODataBeforeCreate(ownerCtx, ownerReq))
+		require.NoError(t, db.Create(&assignment).Error)
+
+		memberCtx, memberReq := authedRequest(member.ID)
+		assert.NoError(t, poll.ODataBeforeCreate(memberCtx, memberReq))
+		assert.Error(t, plan.ODataBeforeCreate(memberCtx, memberReq))
+
+		request := models.JoinRequest{ID: uuid.New().String(), ClubID: club.ID, UserID: outsider.ID, Email: "roles-outsider@example.com", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		require.NoError(t, db.Create(&request).Error)
+		assert.Error(t, models.RejectJoinRequest(request.ID, treasurer.ID))
+		assert.NoError(t, models.AcceptJoinRequest(request.ID, member.ID))
+		var joined int64
+		require.NoError(t, db.Model(&models.Member{}).Where("club_id = ? AND user_id = ?", club.ID, outsider.ID).Count(&joined).Error)
+		assert.Equal(t, int64(1), joined)
+	})
+
+	t.Run("leaving the club drops custom role permissions", func(t *testing.T) {
+		require.NoError(t, db.Where("club_id = ? AND user_id = ?", club.ID, treasurer.ID).Delete(&models.Member{}).Error)
+		assert.False(t, models.HasPermission(club.ID, treasurer.ID, models.PermissionFinesManage))
+	})
+}
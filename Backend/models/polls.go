@@ -0,0 +1,584 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+var ErrPollClosed = errors.New("poll is closed")
+var ErrPollNotAllowed = errors.New("user is not allowed to vote in this poll")
+var ErrPollInvalidSelection = errors.New("invalid option selection")
+
+// Poll is a club decision that members vote on. A poll can be restricted to the
+// members of a single team, allow one or several options per voter, hide the
+// voters' identities and keep its results hidden until it is closed.
+type Poll struct {
+	ID                     string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID                 string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	TeamID                 *string    `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Optional restriction to a team
+	Question               string     `json:"Question" gorm:"not null" odata:"required"`
+	Description            *string    `json:"Description,omitempty" gorm:"type:text" odata:"nullable"`
+	MultipleChoice         bool       `json:"MultipleChoice" gorm:"default:false"`
+	Anonymous              bool       `json:"Anonymous" gorm:"default:false"`
+	HideResultsUntilClosed bool       `json:"HideResultsUntilClosed" gorm:"default:false"`
+	Deadline               *time.Time `json:"Deadline,omitempty" odata:"nullable"`
+	Closed                 bool       `json:"Closed" gorm:"default:false" odata:"auto"`
+	ClosedAt               *time.Time `json:"ClosedAt,omitempty" odata:"auto,nullable"`
+	ClosedBy               *string    `json:"ClosedBy,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	CreatedAt              time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy              string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt              time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy              string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Options []PollOption `gorm:"foreignKey:PollID" json:"Options,omitempty" odata:"nav"`
+	Club    *Club        `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
+	Team    *Team        `gorm:"foreignKey:TeamID" json:"Team,omitempty" odata:"nav"`
+}
+
+// PollOption is one of the answers a poll offers
+type PollOption struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	PollID    string    `json:"PollID" gorm:"type:uuid;not null;index" odata:"required"`
+	Text      string    `json:"Text" gorm:"not null" odata:"required"`
+	Position  int       `json:"Position" gorm:"default:0"`
+	CreatedAt time.Time `json:"CreatedAt" odata:"auto,immutable"`
+}
+
+// PollVote records that a user selected an option. Votes are not exposed through
+// OData directly; they are cast with the Vote action and read through GetResults,
+// which hides voter identities for anonymous polls.
+type PollVote struct {
+	ID        string    `json:"ID" gorm:"type:uuid;primary_key"`
+	PollID    string    `json:"PollID" gorm:"type:uuid;not null;index"`
+	OptionID  string    `json:"OptionID" gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_option_user"`
+	UserID    string    `json:"UserID" gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_option_user"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+// PollVoter identifies a voter in the results of a named poll
+type PollVoter struct {
+	UserID string `json:"UserID"`
+	Name   string `json:"Name"`
+}
+
+// PollOptionResult holds the tally for a single option
+type PollOptionResult struct {
+	OptionID string      `json:"OptionID"`
+	Text     string      `json:"Text"`
+	Votes    int64       `json:"Votes"`
+	Voters   []PollVoter `json:"Voters,omitempty"`
+}
+
+// PollResults is the result overview returned by the GetResults function
+type PollResults struct {
+	PollID         string             `json:"PollID"`
+	Closed         bool               `json:"Closed"`
+	ResultsVisible bool               `json:"ResultsVisible"`
+	TotalVoters    int64              `json:"TotalVoters"`
+	Options        []PollOptionResult `json:"Options"`
+	MyVotes        []string           `json:"MyVotes"`
+}
+
+// BeforeCreate generates UUID for new polls
+func (p *Poll) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new poll options
+func (po *PollOption) BeforeCreate(tx *gorm.DB) error {
+	if po.ID == "" {
+		po.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new poll votes
+func (pv *PollVote) BeforeCreate(tx *gorm.DB) error {
+	if pv.ID == "" {
+		pv.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsClosed reports whether the poll no longer accepts votes, either because it
+// was closed explicitly or because its deadline has passed
+func (p *Poll) IsClosed() bool {
+	if p.Closed {
+		return true
+	}
+	return p.Deadline != nil && !p.Deadline.After(time.Now())
+}
+
+// isClubAdmin checks whether the user holds the news.publish permission in the poll's club
+func (p *Poll) isClubAdmin(userID string) bool {
+	return HasPermission(p.ClubID, userID, PermissionNewsPublish)
+}
+
+// CanVote checks whether the user may vote: club members, limited to the team's
+// members if the poll is restricted to a team
+func (p *Poll) CanVote(userID string) bool {
+	var existingMember Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", p.ClubID, userID).First(&existingMember).Error; err != nil {
+		return false
+	}
+
+	if p.TeamID == nil || *p.TeamID == "" {
+		return true
+	}
+
+	var count int64
+	database.Db.Model(&TeamMember{}).Where("team_id = ? AND user_id = ?", *p.TeamID, userID).Count(&count)
+	return count > 0
+}
+
+// CanView checks whether the user may see the poll: everyone who can vote plus members who manage polls
+func (p *Poll) CanView(userID string) bool {
+	return p.CanVote(userID) || p.isClubAdmin(userID)
+}
+
+// Vote replaces the user's votes with the given options
+func (p *Poll) Vote(userID string, optionIDs []string) error {
+	if p.IsClosed() {
+		return ErrPollClosed
+	}
+
+	if !p.CanVote(userID) {
+		return ErrPollNotAllowed
+	}
+
+	// Remove duplicates while keeping the order of selection
+	seen := make(map[string]bool)
+	selected := make([]string, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		selected = append(selected, id)
+	}
+
+	if len(selected) == 0 {
+		return fmt.Errorf("%w: at least one option must be selected", ErrPollInvalidSelection)
+	}
+	if !p.MultipleChoice && len(selected) > 1 {
+		return fmt.Errorf("%w: only one option can be selected", ErrPollInvalidSelection)
+	}
+
+	var count int64
+	if err := database.Db.Model(&PollOption{}).Where("poll_id = ? AND id IN ?", p.ID, selected).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(selected)) {
+		return fmt.Errorf("%w: option does not belong to this poll", ErrPollInvalidSelection)
+	}
+
+	return database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("poll_id = ? AND user_id = ?", p.ID, userID).Delete(&PollVote{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, optionID := range selected {
+			vote := PollVote{
+				PollID:    p.ID,
+				OptionID:  optionID,
+				UserID:    userID,
+				CreatedAt: now,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close ends the poll so that no further votes are accepted
+func (p *Poll) Close(closedBy string) error {
+	if p.Closed {
+		return ErrPollClosed
+	}
+
+	now := time.Now()
+	return database.Db.Model(p).Updates(map[string]interface{}{
+		"closed":     true,
+		"closed_at":  &now,
+		"closed_by":  &closedBy,
+		"updated_at": now,
+		"updated_by": closedBy,
+	}).Error
+}
+
+// GetResults returns the tally of the poll for the given user. If the poll hides
+// its results until it is closed, only the user's own votes are returned while it
+// is still open. Voter names are only included for named polls.
+func (p *Poll) GetResults(userID string) (*PollResults, error) {
+	var options []PollOption
+	if err := database.Db.Where("poll_id = ?", p.ID).Order("position ASC, created_at ASC").Find(&options).Error; err != nil {
+		return nil, err
+	}
+
+	var votes []PollVote
+	if err := database.Db.Where("poll_id = ?", p.ID).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+
+	closed := p.IsClosed()
+	results := &PollResults{
+		PollID:         p.ID,
+		Closed:         closed,
+		ResultsVisible: closed || !p.HideResultsUntilClosed,
+		Options:        make([]PollOptionResult, 0, len(options)),
+		MyVotes:        []string{},
+	}
+
+	voters := make(map[string]bool)
+	votesByOption := make(map[string][]string)
+	for _, vote := range votes {
+		voters[vote.UserID] = true
+		votesByOption[vote.OptionID] = append(votesByOption[vote.OptionID], vote.UserID)
+		if vote.UserID == userID {
+			results.MyVotes = append(results.MyVotes, vote.OptionID)
+		}
+	}
+
+	names := make(map[string]string)
+	if results.ResultsVisible && !p.Anonymous && len(voters) > 0 {
+		userIDs := make([]string, 0, len(voters))
+		for id := range voters {
+			userIDs = append(userIDs, id)
+		}
+		var users []User
+		if err := database.Db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = strings.TrimSpace(u.FirstName + " " + u.LastName)
+		}
+	}
+
+	for _, option := range options {
+		result := PollOptionResult{
+			OptionID: option.ID,
+			Text:     option.Text,
+		}
+		if results.ResultsVisible {
+			result.Votes = int64(len(votesByOption[option.ID]))
+			if !p.Anonymous {
+				for _, voterID := range votesByOption[option.ID] {
+					result.Voters = append(result.Voters, PollVoter{UserID: voterID, Name: names[voterID]})
+				}
+			}
+		}
+		results.Options = append(results.Options, result)
+	}
+
+	if results.ResultsVisible {
+		results.TotalVoters = int64(len(voters))
+	}
+
+	return results, nil
+}
+
+// pollVisibilityScope restricts polls to clubs the user belongs to. Team-restricted polls are only
+// visible to the team's members and to holders of the permission managing the polls.
+// Arguments: user ID, user ID, user ID, user ID, permission.
+const pollVisibilityScope = "club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND (team_id IS NULL OR team_id IN (SELECT team_id FROM team_members WHERE user_id = ?) OR club_id IN (" + permittedClubsQuery + "))"
+
+// VisiblePollsScope restricts a poll query to the polls the user can see
+func VisiblePollsScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(pollVisibilityScope, userID, userID, userID, userID, PermissionNewsPublish)
+	}
+}
+
+// ODataBeforeReadCollection filters polls to those the user can see
+func (p Poll) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{VisiblePollsScope(userID)}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific poll
+func (p Poll) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{VisiblePollsScope(userID)}, nil
+}
+
+// ODataBeforeCreate validates poll creation permissions
+func (p *Poll) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// SECURITY: If TeamID is provided, verify it belongs to the specified ClubID
+	if p.TeamID != nil && *p.TeamID != "" {
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *p.TeamID, p.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+	}
+
+	// Check if user is an admin/owner of the club
+	if !p.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create polls")
+	}
+
+	p.Question = strings.TrimSpace(p.Question)
+	if p.Question == "" {
+		return fmt.Errorf("poll question cannot be empty")
+	}
+
+	if p.Deadline != nil && !p.Deadline.After(time.Now()) {
+		return fmt.Errorf("poll deadline must be in the future")
+	}
+
+	// Set audit fields
+	now := time.Now()
+	p.Closed = false
+	p.ClosedAt = nil
+	p.ClosedBy = nil
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	p.CreatedBy = userID
+	p.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates poll update permissions
+func (p *Poll) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if user is an admin/owner of the club
+	if !p.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update polls")
+	}
+
+	if p.Closed {
+		return fmt.Errorf("forbidden: closed polls cannot be updated")
+	}
+
+	updated, err := updatedEntity(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving the poll to another club
+	if updated.ClubID != p.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing poll")
+	}
+
+	// SECURITY: If TeamID is being updated, verify it belongs to the club
+	if updated.TeamID != nil && *updated.TeamID != "" {
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *updated.TeamID, p.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+	}
+
+	if strings.TrimSpace(updated.Question) == "" {
+		return fmt.Errorf("poll question cannot be empty")
+	}
+
+	// Voters rely on the anonymity and result visibility the poll had when they voted
+	if updated.Anonymous != p.Anonymous || updated.HideResultsUntilClosed != p.HideResultsUntilClosed {
+		var votes int64
+		if err := database.Db.Model(&PollVote{}).Where("poll_id = ?", p.ID).Count(&votes).Error; err != nil {
+			return err
+		}
+		if votes > 0 {
+			return fmt.Errorf("forbidden: anonymity and result visibility cannot be changed after voting has started")
+		}
+	}
+
+	// Set UpdatedBy
+	now := time.Now()
+	p.UpdatedAt = now
+	p.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates poll deletion permissions and removes options and votes
+func (p *Poll) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if user is an admin/owner of the club
+	if !p.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete polls")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("poll_id = ?", p.ID).Delete(&PollVote{}).Error; err != nil {
+		return fmt.Errorf("failed to delete poll votes: %w", err)
+	}
+	if err := tx.Where("poll_id = ?", p.ID).Delete(&PollOption{}).Error; err != nil {
+		return fmt.Errorf("failed to delete poll options: %w", err)
+	}
+
+	return nil
+}
+
+// loadManageablePoll loads the option's poll and verifies the user may change its options.
+// Options are locked once the first vote was cast so that votes keep their meaning.
+func (po *PollOption) loadManageablePoll(userID string) (*Poll, error) {
+	var poll Poll
+	if err := database.Db.Where("id = ?", po.PollID).First(&poll).Error; err != nil {
+		return nil, fmt.Errorf("poll not found")
+	}
+
+	if !poll.isClubAdmin(userID) {
+		return nil, fmt.Errorf("unauthorized: only admins and owners can manage poll options")
+	}
+
+	if poll.IsClosed() {
+		return nil, ErrPollClosed
+	}
+
+	var votes int64
+	database.Db.Model(&PollVote{}).Where("poll_id = ?", poll.ID).Count(&votes)
+	if votes > 0 {
+		return nil, fmt.Errorf("forbidden: options cannot be changed after voting has started")
+	}
+
+	return &poll, nil
+}
+
+// ODataBeforeReadCollection filters options to polls the user can see
+func (po PollOption) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("poll_id IN (SELECT id FROM polls WHERE "+pollVisibilityScope+")", userID, userID, userID, userID, PermissionNewsPublish)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific poll option
+func (po PollOption) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("poll_id IN (SELECT id FROM polls WHERE "+pollVisibilityScope+")", userID, userID, userID, userID, PermissionNewsPublish)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeCreate validates poll option creation permissions
+func (po *PollOption) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := po.loadManageablePoll(userID); err != nil {
+		return err
+	}
+
+	po.Text = strings.TrimSpace(po.Text)
+	if po.Text == "" {
+		return fmt.Errorf("poll option text cannot be empty")
+	}
+
+	po.CreatedAt = time.Now()
+
+	return nil
+}
+
+// ODataBeforeUpdate validates poll option update permissions
+func (po *PollOption) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := po.loadManageablePoll(userID); err != nil {
+		return err
+	}
+
+	updated, err := updatedEntity(ctx, po)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving options to another poll
+	if updated.PollID != po.PollID {
+		return fmt.Errorf("forbidden: poll cannot be changed for an existing poll option")
+	}
+
+	if strings.TrimSpace(updated.Text) == "" {
+		return fmt.Errorf("poll option text cannot be empty")
+	}
+
+	return nil
+}
+
+// ODataAfterUpdate stores the option text trimmed, as on creation
+func (po *PollOption) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	text := strings.TrimSpace(po.Text)
+	if text == po.Text {
+		return nil
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Model(&PollOption{}).Where("id = ?", po.ID).Update("text", text).Error; err != nil {
+		return fmt.Errorf("failed to trim poll option text: %w", err)
+	}
+	po.Text = text
+	return nil
+}
+
+// ODataBeforeDelete validates poll option deletion permissions
+func (po *PollOption) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	_, err := po.loadManageablePoll(userID)
+	return err
+}
@@ -0,0 +1,262 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pollRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/Polls", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func createTestPoll(t *testing.T, userID string, poll *models.Poll, options ...string) []models.PollOption {
+	t.Helper()
+	ctx, req := pollRequest(userID)
+	require.NoError(t, poll.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(poll).Error)
+
+	created := make([]models.PollOption, 0, len(options))
+	for i, text := range options {
+		option := models.PollOption{PollID: poll.ID, Text: text, Position: i}
+		require.NoError(t, option.ODataBeforeCreate(ctx, req))
+		require.NoError(t, handlers.GetDB().Create(&option).Error)
+		created = append(created, option)
+	}
+	return created
+}
+
+func TestPollCreation(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+
+	owner, _ := handlers.CreateTestUser(t, "poll-create-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "poll-create-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Poll Create Club")
+	handlers.CreateTestMember(t, member, club, "member")
+
+	t.Run("members cannot create polls", func(t *testing.T) {
+		ctx, req := pollRequest(member.ID)
+		poll := &models.Poll{ClubID: club.ID, Question: "Kit colour?"}
+		assert.Error(t, poll.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("deadline must be in the future", func(t *testing.T) {
+		ctx, req := pollRequest(owner.ID)
+		past := time.Now().Add(-time.Hour)
+		poll := &models.Poll{ClubID: club.ID, Question: "Kit colour?", Deadline: &past}
+		assert.Error(t, poll.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("team must belong to the club", func(t *testing.T) {
+		ctx, req := pollRequest(owner.ID)
+		teamID := uuid.New().String()
+		poll := &models.Poll{ClubID: club.ID, TeamID: &teamID, Question: "Kit colour?"}
+		assert.Error(t, poll.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("admin creates poll with audit fields", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "  Kit colour?  "}
+		createTestPoll(t, owner.ID, poll, "Red", "Blue")
+		assert.Equal(t, "Kit colour?", poll.Question)
+		assert.Equal(t, owner.ID, poll.CreatedBy)
+		assert.False(t, poll.Closed)
+	})
+}
+
+func TestPollVoting(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "poll-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "poll-member@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "poll-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Poll Club")
+	handlers.CreateTestMember(t, member, club, "member")
+
+	t.Run("single choice poll accepts exactly one option", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Kit colour?"}
+		options := createTestPoll(t, owner.ID, poll, "Red", "Blue")
+
+		err := poll.Vote(member.ID, []string{options[0].ID, options[1].ID})
+		assert.ErrorIs(t, err, models.ErrPollInvalidSelection)
+
+		require.NoError(t, poll.Vote(member.ID, []string{options[0].ID}))
+		// Voting again replaces the previous vote
+		require.NoError(t, poll.Vote(member.ID, []string{options[1].ID}))
+
+		results, err := poll.GetResults(member.ID)
+		require.NoError(t, err)
+		assert.True(t, results.ResultsVisible)
+		assert.Equal(t, int64(1), results.TotalVoters)
+		assert.Equal(t, []string{options[1].ID}, results.MyVotes)
+		assert.Equal(t, int64(0), results.Options[0].Votes)
+		assert.Equal(t, int64(1), results.Options[1].Votes)
+		require.Len(t, results.Options[1].Voters, 1)
+		assert.Equal(t, member.ID, results.Options[1].Voters[0].UserID)
+	})
+
+	t.Run("multiple choice poll accepts several options", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Which dates work?", MultipleChoice: true}
+		options := createTestPoll(t, owner.ID, poll, "Monday", "Tuesday", "Wednesday")
+
+		require.NoError(t, poll.Vote(member.ID, []string{options[0].ID, options[2].ID, options[0].ID}))
+
+		var count int64
+		db.Model(&models.PollVote{}).Where("poll_id = ? AND user_id = ?", poll.ID, member.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("options of other polls are rejected", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Poll A"}
+		createTestPoll(t, owner.ID, poll, "Yes", "No")
+		other := &models.Poll{ClubID: club.ID, Question: "Poll B"}
+		otherOptions := createTestPoll(t, owner.ID, other, "Yes", "No")
+
+		err := poll.Vote(member.ID, []string{otherOptions[0].ID})
+		assert.ErrorIs(t, err, models.ErrPollInvalidSelection)
+	})
+
+	t.Run("non-members cannot vote", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Members only"}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+
+		err := poll.Vote(outsider.ID, []string{options[0].ID})
+		assert.ErrorIs(t, err, models.ErrPollNotAllowed)
+	})
+
+	t.Run("closed poll rejects votes", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Closing soon"}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+
+		require.NoError(t, poll.Close(owner.ID))
+		assert.True(t, poll.IsClosed())
+		assert.ErrorIs(t, poll.Vote(member.ID, []string{options[0].ID}), models.ErrPollClosed)
+	})
+
+	t.Run("options are locked once voting started", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Locked"}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+		require.NoError(t, poll.Vote(member.ID, []string{options[0].ID}))
+
+		ctx, req := pollRequest(owner.ID)
+		option := models.PollOption{PollID: poll.ID, Text: "Maybe"}
+		assert.Error(t, option.ODataBeforeCreate(ctx, req))
+		assert.Error(t, options[1].ODataBeforeDelete(ctx, req))
+	})
+
+	t.Run("option updates keep the poll and a trimmed text", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Editable"}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+		other := &models.Poll{ClubID: club.ID, Question: "Other"}
+		createTestPoll(t, owner.ID, other, "Yes")
+
+		path := "/PollOptions(" + options[0].ID + ")"
+		for _, patch := range []map[string]interface{}{
+			{"PollID": other.ID},
+			{"Text": "   "},
+		} {
+			w := odataRequest(t, ownerToken, http.MethodPatch, path, patch)
+			assert.Equal(t, http.StatusForbidden, w.Code, patch)
+		}
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Text": "  Absolutely  "})
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		var option models.PollOption
+		require.NoError(t, db.First(&option, "id = ?", options[0].ID).Error)
+		assert.Equal(t, poll.ID, option.PollID)
+		assert.Equal(t, "Absolutely", option.Text)
+	})
+}
+
+func TestPollResultVisibility(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "poll-visibility-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "poll-visibility-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Poll Visibility Club")
+	handlers.CreateTestMember(t, member, club, "member")
+
+	t.Run("hidden results are revealed when the poll closes", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "AGM date", HideResultsUntilClosed: true}
+		options := createTestPoll(t, owner.ID, poll, "May", "June")
+		require.NoError(t, poll.Vote(member.ID, []string{options[0].ID}))
+
+		results, err := poll.GetResults(owner.ID)
+		require.NoError(t, err)
+		assert.False(t, results.ResultsVisible)
+		assert.Zero(t, results.TotalVoters)
+		assert.Zero(t, results.Options[0].Votes)
+
+		// The voter still sees their own selection
+		results, err = poll.GetResults(member.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{options[0].ID}, results.MyVotes)
+
+		require.NoError(t, poll.Close(owner.ID))
+		results, err = poll.GetResults(owner.ID)
+		require.NoError(t, err)
+		assert.True(t, results.ResultsVisible)
+		assert.Equal(t, int64(1), results.Options[0].Votes)
+	})
+
+	t.Run("anonymous polls do not reveal voters", func(t *testing.T) {
+		poll := &models.Poll{ClubID: club.ID, Question: "Secret ballot", Anonymous: true}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+		require.NoError(t, poll.Vote(member.ID, []string{options[0].ID}))
+
+		results, err := poll.GetResults(owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), results.Options[0].Votes)
+		assert.Empty(t, results.Options[0].Voters)
+
+		// Voters cannot be revealed by turning anonymity off afterwards
+		path := "/Polls(" + poll.ID + ")"
+		for _, change := range []map[string]interface{}{
+			{"Anonymous": false},
+			{"HideResultsUntilClosed": true},
+			{"ClubID": uuid.New().String()},
+			{"TeamID": uuid.New().String()},
+		} {
+			resp := odataRequest(t, ownerToken, http.MethodPatch, path, change)
+			assert.Equal(t, http.StatusForbidden, resp.Code, change)
+		}
+		var stored models.Poll
+		require.NoError(t, db.First(&stored, "id = ?", poll.ID).Error)
+		assert.True(t, stored.Anonymous)
+
+		resp := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Question": "Secret ballot on the kit"})
+		assert.Less(t, resp.Code, 300, resp.Body.String())
+	})
+
+	t.Run("team restricted polls", func(t *testing.T) {
+		team, err := club.CreateTeam("First Team", "", owner.ID)
+		require.NoError(t, err)
+
+		poll := &models.Poll{ClubID: club.ID, TeamID: &team.ID, Question: "Team dinner?"}
+		options := createTestPoll(t, owner.ID, poll, "Yes", "No")
+
+		assert.False(t, poll.CanVote(member.ID))
+		assert.ErrorIs(t, poll.Vote(member.ID, []string{options[0].ID}), models.ErrPollNotAllowed)
+		assert.True(t, poll.CanView(owner.ID), "club admins can see team polls")
+
+		require.NoError(t, db.Create(&models.TeamMember{TeamID: team.ID, UserID: member.ID, Role: "member"}).Error)
+		assert.True(t, poll.CanVote(member.ID))
+		assert.NoError(t, poll.Vote(member.ID, []string{options[0].ID}))
+	})
+}
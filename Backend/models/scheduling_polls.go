@@ -0,0 +1,649 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+// Answers members can give to a proposed time slot
+const (
+	SchedulingAnswerYes      = "yes"
+	SchedulingAnswerIfNeedBe = "if_need_be"
+	SchedulingAnswerNo       = "no"
+)
+
+var ErrSchedulingPollClosed = errors.New("scheduling poll is closed")
+var ErrSchedulingPollConverted = errors.New("scheduling poll has already been converted to an event")
+
+// SchedulingPoll is a date-finding poll: an admin proposes candidate time slots,
+// members answer each slot and the winning slot is converted into an Event.
+type SchedulingPoll struct {
+	ID          string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	TeamID      *string    `json:"TeamID,omitempty" gorm:"type:uuid" odata:"nullable"` // Optional restriction to a team
+	Title       string     `json:"Title" gorm:"not null" odata:"required"`
+	Description *string    `json:"Description,omitempty" gorm:"type:text" odata:"nullable"`
+	Location    *string    `json:"Location,omitempty" gorm:"type:varchar(255)" odata:"nullable"`
+	Deadline    *time.Time `json:"Deadline,omitempty" odata:"nullable"`
+	Closed      bool       `json:"Closed" gorm:"default:false" odata:"auto"`
+	EventID     *string    `json:"EventID,omitempty" gorm:"type:uuid" odata:"auto,nullable"` // Event created by ConvertToEvent
+	CreatedAt   time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy   string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt   time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy   string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Slots []SchedulingSlot `gorm:"foreignKey:SchedulingPollID" json:"Slots,omitempty" odata:"nav"`
+}
+
+// SchedulingSlot is a candidate time slot of a scheduling poll
+type SchedulingSlot struct {
+	ID               string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	SchedulingPollID string    `json:"SchedulingPollID" gorm:"type:uuid;not null;index" odata:"required"`
+	StartTime        time.Time `json:"StartTime" gorm:"not null" odata:"required"`
+	EndTime          time.Time `json:"EndTime" gorm:"not null" odata:"required"`
+	CreatedAt        time.Time `json:"CreatedAt" odata:"auto,immutable"`
+
+	// Navigation properties for OData expansions
+	Responses []SchedulingResponse `gorm:"foreignKey:SlotID" json:"Responses,omitempty" odata:"nav"`
+}
+
+// SchedulingResponse is a member's answer (yes, if_need_be, no) to a slot.
+// Responses are given through the Respond action on SchedulingSlots.
+type SchedulingResponse struct {
+	ID               string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	SchedulingPollID string    `json:"SchedulingPollID" gorm:"type:uuid;not null;index" odata:"auto"`
+	SlotID           string    `json:"SlotID" gorm:"type:uuid;not null;uniqueIndex:idx_scheduling_responses_slot_user" odata:"auto"`
+	UserID           string    `json:"UserID" gorm:"type:uuid;not null;uniqueIndex:idx_scheduling_responses_slot_user" odata:"auto"`
+	Answer           string    `json:"Answer" gorm:"not null" odata:"auto"`
+	CreatedAt        time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	UpdatedAt        time.Time `json:"UpdatedAt" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// SchedulingSlotSummary holds the answer counts for a slot
+type SchedulingSlotSummary struct {
+	SlotID    string    `json:"SlotID"`
+	StartTime time.Time `json:"StartTime"`
+	EndTime   time.Time `json:"EndTime"`
+	Yes       int64     `json:"Yes"`
+	IfNeedBe  int64     `json:"IfNeedBe"`
+	No        int64     `json:"No"`
+}
+
+// BeforeCreate generates UUID for new scheduling polls
+func (sp *SchedulingPoll) BeforeCreate(tx *gorm.DB) error {
+	if sp.ID == "" {
+		sp.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new scheduling slots
+func (ss *SchedulingSlot) BeforeCreate(tx *gorm.DB) error {
+	if ss.ID == "" {
+		ss.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new scheduling responses
+func (sr *SchedulingResponse) BeforeCreate(tx *gorm.DB) error {
+	if sr.ID == "" {
+		sr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsValidSchedulingAnswer validates scheduling answer values
+func IsValidSchedulingAnswer(answer string) bool {
+	switch answer {
+	case SchedulingAnswerYes, SchedulingAnswerIfNeedBe, SchedulingAnswerNo:
+		return true
+	}
+	return false
+}
+
+// IsClosed reports whether the poll no longer accepts answers
+func (sp *SchedulingPoll) IsClosed() bool {
+	if sp.Closed || sp.EventID != nil {
+		return true
+	}
+	return sp.Deadline != nil && !sp.Deadline.After(time.Now())
+}
+
+// isClubAdmin checks whether the user holds the events.manage permission in the poll's club
+func (sp *SchedulingPoll) isClubAdmin(userID string) bool {
+	return HasPermission(sp.ClubID, userID, PermissionEventsManage)
+}
+
+// CanRespond checks whether the user may answer: club members, limited to the
+// team's members if the poll is restricted to a team
+func (sp *SchedulingPoll) CanRespond(userID string) bool {
+	poll := Poll{ClubID: sp.ClubID, TeamID: sp.TeamID}
+	return poll.CanVote(userID)
+}
+
+// Respond records the user's answer for a slot, replacing an earlier answer
+func (ss *SchedulingSlot) Respond(userID, answer string) error {
+	if !IsValidSchedulingAnswer(answer) {
+		return fmt.Errorf("invalid answer: must be 'yes', 'if_need_be', or 'no', got '%s'", answer)
+	}
+
+	var poll SchedulingPoll
+	if err := database.Db.Where("id = ?", ss.SchedulingPollID).First(&poll).Error; err != nil {
+		return fmt.Errorf("scheduling poll not found")
+	}
+
+	if poll.IsClosed() {
+		return ErrSchedulingPollClosed
+	}
+
+	if !poll.CanRespond(userID) {
+		return ErrPollNotAllowed
+	}
+
+	now := time.Now()
+	var response SchedulingResponse
+	err := database.Db.Where("slot_id = ? AND user_id = ?", ss.ID, userID).First(&response).Error
+	if err != nil {
+		response = SchedulingResponse{
+			SchedulingPollID: poll.ID,
+			SlotID:           ss.ID,
+			UserID:           userID,
+			Answer:           answer,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		return database.Db.Create(&response).Error
+	}
+
+	return database.Db.Model(&response).Updates(map[string]interface{}{
+		"answer":     answer,
+		"updated_at": now,
+	}).Error
+}
+
+// GetSummary returns the answer counts per slot, ranked from best to worst.
+// Slots are ranked by yes answers, then by yes plus if-need-be answers, then by start time.
+func (sp *SchedulingPoll) GetSummary() ([]SchedulingSlotSummary, error) {
+	var slots []SchedulingSlot
+	if err := database.Db.Where("scheduling_poll_id = ?", sp.ID).Find(&slots).Error; err != nil {
+		return nil, err
+	}
+
+	var responses []SchedulingResponse
+	if err := database.Db.Where("scheduling_poll_id = ?", sp.ID).Find(&responses).Error; err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*SchedulingSlotSummary, len(slots))
+	result := make([]SchedulingSlotSummary, 0, len(slots))
+	for _, slot := range slots {
+		summaries[slot.ID] = &SchedulingSlotSummary{SlotID: slot.ID, StartTime: slot.StartTime, EndTime: slot.EndTime}
+	}
+
+	for _, response := range responses {
+		summary, ok := summaries[response.SlotID]
+		if !ok {
+			continue
+		}
+		switch response.Answer {
+		case SchedulingAnswerYes:
+			summary.Yes++
+		case SchedulingAnswerIfNeedBe:
+			summary.IfNeedBe++
+		case SchedulingAnswerNo:
+			summary.No++
+		}
+	}
+
+	for _, slot := range slots {
+		result = append(result, *summaries[slot.ID])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Yes != result[j].Yes {
+			return result[i].Yes > result[j].Yes
+		}
+		if result[i].Yes+result[i].IfNeedBe != result[j].Yes+result[j].IfNeedBe {
+			return result[i].Yes+result[i].IfNeedBe > result[j].Yes+result[j].IfNeedBe
+		}
+		return result[i].StartTime.Before(result[j].StartTime)
+	})
+
+	return result, nil
+}
+
+// schedulingAnswerToRSVP maps a scheduling answer to an event RSVP response
+func schedulingAnswerToRSVP(answer string) string {
+	switch answer {
+	case SchedulingAnswerYes:
+		return "yes"
+	case SchedulingAnswerIfNeedBe:
+		return "maybe"
+	default:
+		return "no"
+	}
+}
+
+// ConvertToEvent creates an event from the given slot (or the best ranked slot if
+// slotID is empty), pre-fills RSVPs from the answers and closes the poll. The event is
+// validated like one created through the API, and absent members are declined.
+func (sp *SchedulingPoll) ConvertToEvent(slotID, createdBy string) (*Event, error) {
+	if sp.EventID != nil {
+		return nil, ErrSchedulingPollConverted
+	}
+
+	if slotID == "" {
+		summary, err := sp.GetSummary()
+		if err != nil {
+			return nil, err
+		}
+		if len(summary) == 0 {
+			return nil, fmt.Errorf("scheduling poll has no slots")
+		}
+		slotID = summary[0].SlotID
+	}
+
+	var slot SchedulingSlot
+	if err := database.Db.Where("id = ? AND scheduling_poll_id = ?", slotID, sp.ID).First(&slot).Error; err != nil {
+		return nil, fmt.Errorf("slot not found in scheduling poll")
+	}
+
+	var responses []SchedulingResponse
+	if err := database.Db.Where("slot_id = ?", slot.ID).Find(&responses).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event := Event{
+		ID:          uuid.New().String(),
+		ClubID:      sp.ClubID,
+		TeamID:      sp.TeamID,
+		Name:        sp.Title,
+		Description: sp.Description,
+		Location:    sp.Location,
+		StartTime:   slot.StartTime,
+		EndTime:     slot.EndTime,
+		CreatedAt:   now,
+		CreatedBy:   createdBy,
+		UpdatedAt:   now,
+		UpdatedBy:   createdBy,
+	}
+	if err := event.validateNew(createdBy); err != nil {
+		return nil, err
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+
+		// Claim the poll only if no concurrent conversion got there first
+		result := tx.Model(&SchedulingPoll{}).Where("id = ? AND event_id IS NULL", sp.ID).Updates(map[string]interface{}{
+			"closed":     true,
+			"event_id":   event.ID,
+			"updated_at": now,
+			"updated_by": createdBy,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSchedulingPollConverted
+		}
+
+		for _, response := range responses {
+			rsvp := EventRSVP{
+				ID:        uuid.New().String(),
+				EventID:   event.ID,
+				UserID:    response.UserID,
+				Response:  schedulingAnswerToRSVP(response.Answer),
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := tx.Create(&rsvp).Error; err != nil {
+				return fmt.Errorf("failed to create RSVP: %w", err)
+			}
+		}
+
+		return event.declineAbsentMembers(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sp.Closed = true
+	sp.EventID = &event.ID
+
+	return &event, nil
+}
+
+// schedulingPollScope restricts scheduling polls to those the user can see and where events are enabled
+func schedulingPollScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(pollVisibilityScope+" AND club_id IN (SELECT club_id FROM club_settings WHERE events_enabled = true)", userID, userID, userID, userID, PermissionEventsManage)
+	}
+}
+
+// ODataBeforeReadCollection filters scheduling polls to those the user can see
+func (sp SchedulingPoll) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{schedulingPollScope(userID)}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific scheduling poll
+func (sp SchedulingPoll) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	return []func(*gorm.DB) *gorm.DB{schedulingPollScope(userID)}, nil
+}
+
+// ODataBeforeCreate validates scheduling poll creation permissions
+func (sp *SchedulingPoll) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if events feature is enabled for the club
+	if err := CheckFeatureEnabled(sp.ClubID, "events"); err != nil {
+		return err
+	}
+
+	// SECURITY: If TeamID is provided, verify it belongs to the specified ClubID
+	if sp.TeamID != nil && *sp.TeamID != "" {
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *sp.TeamID, sp.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+	}
+
+	// Check if user is an admin/owner of the club
+	if !sp.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create scheduling polls")
+	}
+
+	sp.Title = strings.TrimSpace(sp.Title)
+	if sp.Title == "" {
+		return fmt.Errorf("scheduling poll title cannot be empty")
+	}
+
+	// Set audit fields
+	now := time.Now()
+	sp.Closed = false
+	sp.EventID = nil
+	sp.CreatedAt = now
+	sp.UpdatedAt = now
+	sp.CreatedBy = userID
+	sp.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates scheduling poll update permissions
+func (sp *SchedulingPoll) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if events feature is enabled for the club
+	if err := CheckFeatureEnabled(sp.ClubID, "events"); err != nil {
+		return err
+	}
+
+	// Check if user is an admin/owner of the club
+	if !sp.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update scheduling polls")
+	}
+
+	if sp.EventID != nil {
+		return ErrSchedulingPollConverted
+	}
+
+	updated, err := updatedEntity(ctx, sp)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving polls to another club
+	if updated.ClubID != sp.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing scheduling poll")
+	}
+
+	// SECURITY: If TeamID is being updated, verify it belongs to the (unchanged) ClubID
+	if updated.TeamID != nil && *updated.TeamID != "" {
+		var team Team
+		if err := database.Db.Where("id = ? AND club_id = ?", *updated.TeamID, sp.ClubID).First(&team).Error; err != nil {
+			return fmt.Errorf("unauthorized: team does not belong to the specified club")
+		}
+	}
+
+	if strings.TrimSpace(updated.Title) == "" {
+		return fmt.Errorf("scheduling poll title cannot be empty")
+	}
+
+	// Set UpdatedBy
+	now := time.Now()
+	sp.UpdatedAt = now
+	sp.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates scheduling poll deletion permissions and removes slots and answers
+func (sp *SchedulingPoll) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Check if user is an admin/owner of the club
+	if !sp.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete scheduling polls")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("scheduling_poll_id = ?", sp.ID).Delete(&SchedulingResponse{}).Error; err != nil {
+		return fmt.Errorf("failed to delete scheduling responses: %w", err)
+	}
+	if err := tx.Where("scheduling_poll_id = ?", sp.ID).Delete(&SchedulingSlot{}).Error; err != nil {
+		return fmt.Errorf("failed to delete scheduling slots: %w", err)
+	}
+
+	return nil
+}
+
+// loadManageablePoll loads the slot's poll and verifies the user may change its slots
+func (ss *SchedulingSlot) loadManageablePoll(userID string) (*SchedulingPoll, error) {
+	var poll SchedulingPoll
+	if err := database.Db.Where("id = ?", ss.SchedulingPollID).First(&poll).Error; err != nil {
+		return nil, fmt.Errorf("scheduling poll not found")
+	}
+
+	if !poll.isClubAdmin(userID) {
+		return nil, fmt.Errorf("unauthorized: only admins and owners can manage scheduling slots")
+	}
+
+	if poll.IsClosed() {
+		return nil, ErrSchedulingPollClosed
+	}
+
+	return &poll, nil
+}
+
+// ODataBeforeReadCollection filters slots to scheduling polls the user can see
+func (ss SchedulingSlot) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("scheduling_poll_id IN (?)", schedulingPollScope(userID)(database.Db.Model(&SchedulingPoll{}).Select("id")))
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific slot
+func (ss SchedulingSlot) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return ss.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates slot creation permissions
+func (ss *SchedulingSlot) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := ss.loadManageablePoll(userID); err != nil {
+		return err
+	}
+
+	if !ss.EndTime.After(ss.StartTime) {
+		return fmt.Errorf("slot end time must be after start time")
+	}
+
+	ss.CreatedAt = time.Now()
+
+	return nil
+}
+
+// ODataBeforeUpdate validates slot update permissions. Answers were given for the old time,
+// so they are removed when the slot is moved.
+func (ss *SchedulingSlot) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := ss.loadManageablePoll(userID); err != nil {
+		return err
+	}
+
+	updated, err := updatedEntity(ctx, ss)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving slots to another scheduling poll
+	if updated.SchedulingPollID != ss.SchedulingPollID {
+		return fmt.Errorf("forbidden: scheduling poll cannot be changed for an existing slot")
+	}
+
+	if !updated.EndTime.After(updated.StartTime) {
+		return fmt.Errorf("slot end time must be after start time")
+	}
+
+	if !updated.StartTime.Equal(ss.StartTime) || !updated.EndTime.Equal(ss.EndTime) {
+		tx, ok := odata.TransactionFromContext(ctx)
+		if !ok {
+			tx = database.Db
+		}
+		if err := tx.Where("slot_id = ?", ss.ID).Delete(&SchedulingResponse{}).Error; err != nil {
+			return fmt.Errorf("failed to delete scheduling responses: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ODataBeforeDelete validates slot deletion permissions and removes its answers
+func (ss *SchedulingSlot) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if _, err := ss.loadManageablePoll(userID); err != nil {
+		return err
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Where("slot_id = ?", ss.ID).Delete(&SchedulingResponse{}).Error; err != nil {
+		return fmt.Errorf("failed to delete scheduling responses: %w", err)
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters answers to scheduling polls the user can see
+func (sr SchedulingResponse) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("scheduling_poll_id IN (?)", schedulingPollScope(userID)(database.Db.Model(&SchedulingPoll{}).Select("id")))
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific answer
+func (sr SchedulingResponse) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sr.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation of answers; use the Respond action instead
+func (sr *SchedulingResponse) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the Respond action on SchedulingSlots to answer")
+}
+
+// ODataBeforeUpdate prevents direct updates of answers; use the Respond action instead
+func (sr *SchedulingResponse) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the Respond action on SchedulingSlots to answer")
+}
+
+// ODataBeforeDelete allows users to withdraw their own answer while the poll is open
+func (sr *SchedulingResponse) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if sr.UserID != userID {
+		return fmt.Errorf("unauthorized: can only withdraw your own answers")
+	}
+
+	var poll SchedulingPoll
+	if err := database.Db.Where("id = ?", sr.SchedulingPollID).First(&poll).Error; err != nil {
+		return fmt.Errorf("scheduling poll not found")
+	}
+	if poll.IsClosed() {
+		return ErrSchedulingPollClosed
+	}
+
+	return nil
+}
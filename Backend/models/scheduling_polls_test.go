@@ -0,0 +1,257 @@
+package models_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestSchedulingPoll(t *testing.T, userID string, poll *models.SchedulingPoll, starts ...time.Time) []models.SchedulingSlot {
+	t.Helper()
+	ctx, req := pollRequest(userID)
+	require.NoError(t, poll.ODataBeforeCreate(ctx, req))
+	require.NoError(t, handlers.GetDB().Create(poll).Error)
+
+	slots := make([]models.SchedulingSlot, 0, len(starts))
+	for _, start := range starts {
+		slot := models.SchedulingSlot{SchedulingPollID: poll.ID, StartTime: start, EndTime: start.Add(2 * time.Hour)}
+		require.NoError(t, slot.ODataBeforeCreate(ctx, req))
+		require.NoError(t, handlers.GetDB().Create(&slot).Error)
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func TestSchedulingPollResponses(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+
+	owner, ownerToken := handlers.CreateTestUser(t, "scheduling-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "scheduling-member@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "scheduling-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Scheduling Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, handlers.GetDB().Exec("UPDATE club_settings SET events_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	start := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Hour)
+
+	t.Run("members cannot create scheduling polls", func(t *testing.T) {
+		ctx, req := pollRequest(member.ID)
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Season kick-off"}
+		assert.Error(t, poll.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("slot end must be after start", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Invalid slot"}
+		createTestSchedulingPoll(t, owner.ID, poll)
+
+		ctx, req := pollRequest(owner.ID)
+		slot := models.SchedulingSlot{SchedulingPollID: poll.ID, StartTime: start, EndTime: start}
+		assert.Error(t, slot.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("answers are validated and replaced", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Board meeting"}
+		slots := createTestSchedulingPoll(t, owner.ID, poll, start, start.Add(24*time.Hour))
+
+		assert.Error(t, slots[0].Respond(member.ID, "maybe"))
+		assert.ErrorIs(t, slots[0].Respond(outsider.ID, models.SchedulingAnswerYes), models.ErrPollNotAllowed)
+
+		require.NoError(t, slots[0].Respond(member.ID, models.SchedulingAnswerNo))
+		require.NoError(t, slots[0].Respond(member.ID, models.SchedulingAnswerYes))
+		require.NoError(t, slots[1].Respond(member.ID, models.SchedulingAnswerIfNeedBe))
+
+		summary, err := poll.GetSummary()
+		require.NoError(t, err)
+		require.Len(t, summary, 2)
+		assert.Equal(t, slots[0].ID, summary[0].SlotID)
+		assert.Equal(t, int64(1), summary[0].Yes)
+		assert.Zero(t, summary[0].No)
+		assert.Equal(t, int64(1), summary[1].IfNeedBe)
+	})
+
+	t.Run("patched club, team and title are validated", func(t *testing.T) {
+		otherClub := handlers.CreateTestClub(t, outsider, "Other Scheduling Club")
+		require.NoError(t, handlers.GetDB().Exec("UPDATE club_settings SET teams_enabled = true WHERE club_id = ?", otherClub.ID).Error)
+		foreignTeam, err := otherClub.CreateTeam("Foreign team", "", outsider.ID)
+		require.NoError(t, err)
+
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Summer party"}
+		createTestSchedulingPoll(t, owner.ID, poll)
+
+		path := "/SchedulingPolls(" + poll.ID + ")"
+		for _, patch := range []map[string]interface{}{
+			{"ClubID": otherClub.ID},
+			{"TeamID": foreignTeam.ID},
+			{"Title": " "},
+		} {
+			w := odataRequest(t, ownerToken, http.MethodPatch, path, patch)
+			assert.Equal(t, http.StatusForbidden, w.Code, patch)
+		}
+
+		w := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"Title": "Summer barbecue"})
+		require.Less(t, w.Code, 300, w.Body.String())
+		var stored models.SchedulingPoll
+		require.NoError(t, handlers.GetDB().First(&stored, "id = ?", poll.ID).Error)
+		assert.Equal(t, club.ID, stored.ClubID)
+		assert.Nil(t, stored.TeamID)
+	})
+
+	t.Run("slot updates stay on the poll and reset answers when moved", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Slot changes"}
+		slots := createTestSchedulingPoll(t, owner.ID, poll, start)
+		other := &models.SchedulingPoll{ClubID: club.ID, Title: "Other poll"}
+		createTestSchedulingPoll(t, owner.ID, other)
+		require.NoError(t, slots[0].Respond(member.ID, models.SchedulingAnswerYes))
+
+		path := "/SchedulingSlots(" + slots[0].ID + ")"
+		w := odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"SchedulingPollID": other.ID})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		slot := func(startTime, endTime time.Time) map[string]interface{} {
+			return map[string]interface{}{
+				"SchedulingPollID": poll.ID,
+				"StartTime":        startTime.Format(time.RFC3339),
+				"EndTime":          endTime.Format(time.RFC3339),
+			}
+		}
+		w = odataRequest(t, ownerToken, http.MethodPut, path, slot(start, start.Add(-time.Hour)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var answers int64
+		handlers.GetDB().Model(&models.SchedulingResponse{}).Where("slot_id = ?", slots[0].ID).Count(&answers)
+		assert.Equal(t, int64(1), answers)
+
+		moved := start.Add(3 * time.Hour)
+		w = odataRequest(t, ownerToken, http.MethodPut, path, slot(moved, moved.Add(2*time.Hour)))
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		var stored models.SchedulingSlot
+		require.NoError(t, handlers.GetDB().First(&stored, "id = ?", slots[0].ID).Error)
+		assert.Equal(t, poll.ID, stored.SchedulingPollID)
+		assert.True(t, stored.StartTime.Equal(moved))
+		handlers.GetDB().Model(&models.SchedulingResponse{}).Where("slot_id = ?", slots[0].ID).Count(&answers)
+		assert.Zero(t, answers)
+	})
+
+	t.Run("answers are direct-write protected", func(t *testing.T) {
+		ctx, req := pollRequest(member.ID)
+		response := models.SchedulingResponse{}
+		assert.Error(t, response.ODataBeforeCreate(ctx, req))
+		assert.Error(t, response.ODataBeforeUpdate(ctx, req))
+	})
+}
+
+func TestSchedulingPollConvertToEvent(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "convert-owner@example.com")
+	alice, _ := handlers.CreateTestUser(t, "convert-alice@example.com")
+	bob, _ := handlers.CreateTestUser(t, "convert-bob@example.com")
+	club := handlers.CreateTestClub(t, owner, "Convert Club")
+	handlers.CreateTestMember(t, alice, club, "member")
+	handlers.CreateTestMember(t, bob, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET events_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	start := time.Now().Add(14 * 24 * time.Hour).Truncate(time.Hour)
+	location := "Clubhouse"
+
+	t.Run("winning slot becomes event with pre-filled RSVPs", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Summer party", Location: &location}
+		slots := createTestSchedulingPoll(t, owner.ID, poll, start, start.Add(24*time.Hour))
+
+		require.NoError(t, slots[0].Respond(alice.ID, models.SchedulingAnswerNo))
+		require.NoError(t, slots[0].Respond(bob.ID, models.SchedulingAnswerIfNeedBe))
+		require.NoError(t, slots[1].Respond(alice.ID, models.SchedulingAnswerYes))
+		require.NoError(t, slots[1].Respond(bob.ID, models.SchedulingAnswerIfNeedBe))
+
+		event, err := poll.ConvertToEvent("", owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Summer party", event.Name)
+		assert.True(t, event.StartTime.Equal(slots[1].StartTime))
+		require.NotNil(t, event.Location)
+		assert.Equal(t, location, *event.Location)
+
+		var rsvps []models.EventRSVP
+		require.NoError(t, db.Where("event_id = ?", event.ID).Find(&rsvps).Error)
+		responses := map[string]string{}
+		for _, rsvp := range rsvps {
+			responses[rsvp.UserID] = rsvp.Response
+		}
+		assert.Equal(t, map[string]string{alice.ID: "yes", bob.ID: "maybe"}, responses)
+
+		var stored models.SchedulingPoll
+		require.NoError(t, db.Where("id = ?", poll.ID).First(&stored).Error)
+		assert.True(t, stored.Closed)
+		require.NotNil(t, stored.EventID)
+		assert.Equal(t, event.ID, *stored.EventID)
+
+		_, err = stored.ConvertToEvent("", owner.ID)
+		assert.ErrorIs(t, err, models.ErrSchedulingPollConverted)
+		assert.ErrorIs(t, slots[0].Respond(alice.ID, models.SchedulingAnswerYes), models.ErrSchedulingPollClosed)
+	})
+
+	t.Run("absent members are declined and a poll converts only once", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Away day"}
+		slots := createTestSchedulingPoll(t, owner.ID, poll, start.Add(72*time.Hour))
+		require.NoError(t, slots[0].Respond(alice.ID, models.SchedulingAnswerYes))
+		require.NoError(t, slots[0].Respond(bob.ID, models.SchedulingAnswerYes))
+
+		day := slots[0].StartTime
+		absence := models.MemberAbsence{ID: uuid.New().String(), ClubID: club.ID, UserID: bob.ID, StartDate: day, EndDate: day, Reason: models.AbsenceReasonHoliday}
+		require.NoError(t, db.Create(&absence).Error)
+
+		stale := *poll
+		event, err := poll.ConvertToEvent("", owner.ID)
+		require.NoError(t, err)
+
+		var rsvps []models.EventRSVP
+		require.NoError(t, db.Where("event_id = ?", event.ID).Find(&rsvps).Error)
+		responses := map[string]string{}
+		for _, rsvp := range rsvps {
+			responses[rsvp.UserID] = rsvp.Response
+		}
+		assert.Equal(t, map[string]string{alice.ID: "yes", bob.ID: "no"}, responses)
+
+		_, err = stale.ConvertToEvent("", owner.ID)
+		assert.ErrorIs(t, err, models.ErrSchedulingPollConverted)
+		var events int64
+		require.NoError(t, db.Model(&models.Event{}).Where("club_id = ? AND name = ?", club.ID, "Away day").Count(&events).Error)
+		assert.Equal(t, int64(1), events)
+	})
+
+	t.Run("members without events.manage cannot convert", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Not yours"}
+		createTestSchedulingPoll(t, owner.ID, poll, start)
+
+		_, err := poll.ConvertToEvent("", alice.ID)
+		assert.Error(t, err)
+		assert.Nil(t, poll.EventID)
+	})
+
+	t.Run("explicit slot is used", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Training camp"}
+		slots := createTestSchedulingPoll(t, owner.ID, poll, start, start.Add(48*time.Hour))
+		require.NoError(t, slots[0].Respond(alice.ID, models.SchedulingAnswerYes))
+
+		event, err := poll.ConvertToEvent(slots[1].ID, owner.ID)
+		require.NoError(t, err)
+		assert.True(t, event.StartTime.Equal(slots[1].StartTime))
+	})
+
+	t.Run("poll without slots cannot be converted", func(t *testing.T) {
+		poll := &models.SchedulingPoll{ClubID: club.ID, Title: "Empty"}
+		createTestSchedulingPoll(t, owner.ID, poll)
+
+		_, err := poll.ConvertToEvent("", owner.ID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,384 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSeasonNotFound = errors.New("season not found")
+	ErrSeasonArchived = errors.New("season is archived")
+	ErrSeasonOverlap  = errors.New("season overlaps another season of the club")
+	ErrSeasonOrder    = errors.New("the next season must start after the current season ends")
+)
+
+// Season is a period of a club, e.g. 2025/26. Teams, events and fines belong to a season so
+// that rosters and statistics can be kept apart from year to year.
+type Season struct {
+	ID         string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID     string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Name       string     `json:"Name" gorm:"not null" odata:"required"`
+	StartDate  time.Time  `json:"StartDate" gorm:"not null" odata:"required"`
+	EndDate    time.Time  `json:"EndDate" gorm:"not null" odata:"required"` // Exclusive, usually the start of the next season
+	Archived   bool       `json:"Archived" gorm:"default:false" odata:"auto"`
+	ArchivedAt *time.Time `json:"ArchivedAt,omitempty" odata:"auto,nullable"`
+	ArchivedBy *string    `json:"ArchivedBy,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	CreatedAt  time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy  string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt  time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy  string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Teams []Team `gorm:"foreignKey:SeasonID" json:"Teams,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new seasons
+func (s *Season) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Contains reports whether the given time falls into the season
+func (s *Season) Contains(at time.Time) bool {
+	return !at.Before(s.StartDate) && at.Before(s.EndDate)
+}
+
+// validate checks the season and rejects overlaps with other seasons of the club
+func (s *Season) validate(tx *gorm.DB) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !s.EndDate.After(s.StartDate) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	var overlapping int64
+	if err := tx.Model(&Season{}).
+		Where("club_id = ? AND id <> ? AND start_date < ? AND end_date > ?", s.ClubID, s.ID, s.EndDate, s.StartDate).
+		Count(&overlapping).Error; err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrSeasonOverlap
+	}
+	return nil
+}
+
+// validateSeason checks that the referenced season belongs to the club
+func validateSeason(seasonID *string, clubID string) (*Season, error) {
+	if seasonID == nil || *seasonID == "" {
+		return nil, nil
+	}
+	var season Season
+	if err := database.Db.Where("id = ? AND club_id = ?", *seasonID, clubID).First(&season).Error; err != nil {
+		return nil, fmt.Errorf("unauthorized: season does not belong to the specified club")
+	}
+	return &season, nil
+}
+
+// validateOpenSeason checks that the referenced season belongs to the club and is not archived,
+// so nothing is added to a season after it was rolled over
+func validateOpenSeason(seasonID *string, clubID string) error {
+	season, err := validateSeason(seasonID, clubID)
+	if err != nil {
+		return err
+	}
+	if season != nil && season.Archived {
+		return ErrSeasonArchived
+	}
+	return nil
+}
+
+// seasonAt returns the ID of the club's season containing the given time, or nil if there is none
+func seasonAt(tx *gorm.DB, clubID string, at time.Time) (*string, error) {
+	var ids []string
+	if err := tx.Model(&Season{}).Where("club_id = ? AND start_date <= ? AND end_date > ?", clubID, at, at).
+		Limit(1).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+// openSeason returns the ID of the club's earliest season that is neither archived nor over,
+// which is where new teams go, or nil if there is none
+func openSeason(tx *gorm.DB, clubID string, now time.Time) (*string, error) {
+	var ids []string
+	if err := tx.Model(&Season{}).Where("club_id = ? AND archived = ? AND end_date > ?", clubID, false, now).
+		Order("start_date ASC").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+// assignToSeason adds the club's events and fines without a season that fall into the season.
+// Teams without a season join it if the season is the one new teams go to.
+func assignToSeason(tx *gorm.DB, season *Season) error {
+	if err := tx.Model(&Event{}).
+		Where("club_id = ? AND season_id IS NULL AND start_time >= ? AND start_time < ?", season.ClubID, season.StartDate, season.EndDate).
+		Update("season_id", season.ID).Error; err != nil {
+		return fmt.Errorf("failed to assign events to season: %w", err)
+	}
+	if err := tx.Model(&Fine{}).
+		Where("club_id = ? AND season_id IS NULL AND created_at >= ? AND created_at < ?", season.ClubID, season.StartDate, season.EndDate).
+		Update("season_id", season.ID).Error; err != nil {
+		return fmt.Errorf("failed to assign fines to season: %w", err)
+	}
+
+	open, err := openSeason(tx, season.ClubID, time.Now())
+	if err != nil {
+		return err
+	}
+	if open != nil && *open == season.ID {
+		if err := tx.Model(&Team{}).Where("club_id = ? AND season_id IS NULL", season.ClubID).
+			Update("season_id", season.ID).Error; err != nil {
+			return fmt.Errorf("failed to assign teams to season: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSeasons returns the seasons of the club, the most recent first
+func (c *Club) GetSeasons() ([]Season, error) {
+	var seasons []Season
+	err := database.Db.Where("club_id = ?", c.ID).Order("start_date DESC").Find(&seasons).Error
+	return seasons, err
+}
+
+// GetSeason returns a season of the club
+func (c *Club) GetSeason(seasonID string) (*Season, error) {
+	var season Season
+	if err := database.Db.Where("id = ? AND club_id = ?", seasonID, c.ID).First(&season).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSeasonNotFound
+		}
+		return nil, err
+	}
+	return &season, nil
+}
+
+// Rollover starts the next season: the teams of this season are copied into it together with
+// their rosters and this season is archived. Teams of archived seasons stay as they were.
+func (s *Season) Rollover(name string, startDate, endDate time.Time, rolledOverBy string) (*Season, error) {
+	if s.Archived {
+		return nil, ErrSeasonArchived
+	}
+	if startDate.Before(s.EndDate) {
+		return nil, ErrSeasonOrder
+	}
+
+	next := Season{
+		ClubID:    s.ClubID,
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedBy: rolledOverBy,
+		UpdatedBy: rolledOverBy,
+	}
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := next.validate(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+
+		var teams []Team
+		if err := tx.Where("season_id = ?", s.ID).Find(&teams).Error; err != nil {
+			return err
+		}
+		for _, team := range teams {
+			copied := Team{
+				ClubID:      team.ClubID,
+				SeasonID:    &next.ID,
+				Name:        team.Name,
+				Description: team.Description,
+				CreatedBy:   rolledOverBy,
+				UpdatedBy:   rolledOverBy,
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return fmt.Errorf("failed to copy team %s: %w", team.Name, err)
+			}
+
+			var roster []TeamMember
+			if err := tx.Where("team_id = ?", team.ID).Find(&roster).Error; err != nil {
+				return err
+			}
+			for _, teamMember := range roster {
+				if err := tx.Create(&TeamMember{
+					TeamID:    copied.ID,
+					UserID:    teamMember.UserID,
+					Role:      teamMember.Role,
+					CreatedBy: rolledOverBy,
+					UpdatedBy: rolledOverBy,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to copy roster of team %s: %w", team.Name, err)
+				}
+			}
+		}
+
+		now := time.Now()
+		if err := tx.Model(&Season{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+			"archived":    true,
+			"archived_at": now,
+			"archived_by": rolledOverBy,
+			"updated_at":  now,
+			"updated_by":  rolledOverBy,
+		}).Error; err != nil {
+			return err
+		}
+		s.Archived = true
+		s.ArchivedAt = &now
+		s.ArchivedBy = &rolledOverBy
+
+		return assignToSeason(tx, &next)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// ODataBeforeReadCollection filters seasons to clubs the user or their dependents belong to
+func (s Season) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN ("+actingClubsQuery+")", userID, userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific season
+func (s Season) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return s.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates season creation permissions
+func (s *Season) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(s.ClubID, userID, PermissionSettingsEdit) {
+		return fmt.Errorf("unauthorized: only members with settings.edit can create seasons")
+	}
+
+	if err := s.validate(database.Db); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.Archived = false
+	s.ArchivedAt = nil
+	s.ArchivedBy = nil
+	s.CreatedAt = now
+	s.CreatedBy = userID
+	s.UpdatedAt = now
+	s.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterCreate assigns existing events, fines and teams to the new season and records it in the audit log
+func (s *Season) ODataAfterCreate(ctx context.Context, r *http.Request) error {
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := assignToSeason(tx, s); err != nil {
+		return err
+	}
+	return auditEntityChange(ctx, r, s.ClubID, "Season", s.ID, AuditOperationCreate, s)
+}
+
+// ODataBeforeUpdate validates season update permissions
+func (s *Season) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Remember the unchanged state for the audit log
+	captureAuditState(ctx, "Season", s.ID, s)
+
+	var existing Season
+	if err := database.Db.Where("id = ?", s.ID).First(&existing).Error; err != nil {
+		return ErrSeasonNotFound
+	}
+
+	// SECURITY: Prevent moving seasons to another club
+	if s.ClubID != existing.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing season")
+	}
+
+	if !HasPermission(existing.ClubID, userID, PermissionSettingsEdit) {
+		return fmt.Errorf("unauthorized: only members with settings.edit can update seasons")
+	}
+
+	if err := s.validate(database.Db); err != nil {
+		return err
+	}
+
+	s.UpdatedAt = time.Now()
+	s.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataAfterUpdate records the changes to the season in the audit log
+func (s *Season) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, s.ClubID, "Season", s.ID, AuditOperationUpdate, s)
+}
+
+// ODataBeforeDelete validates season deletion permissions and detaches teams, events and fines from the season
+func (s *Season) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !HasPermission(s.ClubID, userID, PermissionSettingsEdit) {
+		return fmt.Errorf("unauthorized: only members with settings.edit can delete seasons")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	for _, model := range []interface{}{&Team{}, &Event{}, &Fine{}} {
+		if err := tx.Model(model).Where("season_id = ?", s.ID).Update("season_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach %T from season: %w", model, err)
+		}
+	}
+	return nil
+}
+
+// ODataAfterDelete records the deleted season in the audit log
+func (s *Season) ODataAfterDelete(ctx context.Context, r *http.Request) error {
+	return auditEntityChange(ctx, r, s.ClubID, "Season", s.ID, AuditOperationDelete, s)
+}
@@ -0,0 +1,197 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seasonRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/Seasons", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestSeasons(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "season-owner@example.com")
+	player, _ := handlers.CreateTestUser(t, "season-player@example.com")
+	club := handlers.CreateTestClub(t, owner, "Season Club")
+	handlers.CreateTestMember(t, player, club, "member")
+	otherClub := handlers.CreateTestClub(t, owner, "Other Season Club")
+	require.NoError(t, db.Model(&models.ClubSettings{}).Where("club_id = ?", otherClub.ID).Update("events_enabled", true).Error)
+	require.NoError(t, db.Model(&models.ClubSettings{}).Where("club_id = ?", club.ID).Update("teams_enabled", true).Error)
+
+	now := time.Now()
+	start := now.AddDate(0, -1, 0)
+	end := start.AddDate(1, 0, 0)
+
+	// Created before the club had seasons
+	earlyEvent := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Opening match", StartTime: now.Add(24 * time.Hour), EndTime: now.Add(26 * time.Hour), CreatedBy: owner.ID}
+	require.NoError(t, db.Create(&earlyEvent).Error)
+	assert.Nil(t, earlyEvent.SeasonID)
+	legacyTeam, err := club.CreateTeam("First team", "", owner.ID)
+	require.NoError(t, err)
+	assert.Nil(t, legacyTeam.SeasonID)
+
+	current := models.Season{ClubID: club.ID, Name: "2025/26", StartDate: start, EndDate: end}
+
+	t.Run("seasons are validated", func(t *testing.T) {
+		ctx, req := seasonRequest(player.ID)
+		assert.Error(t, current.ODataBeforeCreate(ctx, req))
+
+		ctx, req = seasonRequest(owner.ID)
+		invalid := models.Season{ClubID: club.ID, Name: "Backwards", StartDate: end, EndDate: start}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+		unnamed := models.Season{ClubID: club.ID, Name: " ", StartDate: start, EndDate: end}
+		assert.Error(t, unnamed.ODataBeforeCreate(ctx, req))
+
+		require.NoError(t, current.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&current).Error)
+		require.NoError(t, current.ODataAfterCreate(ctx, req))
+
+		overlapping := models.Season{ClubID: club.ID, Name: "Overlap", StartDate: end.AddDate(0, -1, 0), EndDate: end.AddDate(1, 0, 0)}
+		assert.ErrorIs(t, overlapping.ODataBeforeCreate(ctx, req), models.ErrSeasonOverlap)
+	})
+
+	t.Run("existing events and teams join the new season", func(t *testing.T) {
+		require.NoError(t, db.First(&earlyEvent, "id = ?", earlyEvent.ID).Error)
+		require.NotNil(t, earlyEvent.SeasonID)
+		assert.Equal(t, current.ID, *earlyEvent.SeasonID)
+
+		require.NoError(t, db.First(&legacyTeam, "id = ?", legacyTeam.ID).Error)
+		require.NotNil(t, legacyTeam.SeasonID)
+		assert.Equal(t, current.ID, *legacyTeam.SeasonID)
+	})
+
+	t.Run("new events and fines are assigned to their season", func(t *testing.T) {
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, TeamID: &legacyTeam.ID, Name: "Training", StartTime: now.Add(48 * time.Hour), EndTime: now.Add(50 * time.Hour), CreatedBy: owner.ID}
+		require.NoError(t, db.Create(&event).Error)
+		require.NotNil(t, event.SeasonID)
+		assert.Equal(t, current.ID, *event.SeasonID)
+
+		later := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Next year", StartTime: end.Add(time.Hour), EndTime: end.Add(2 * time.Hour), CreatedBy: owner.ID}
+		require.NoError(t, db.Create(&later).Error)
+		assert.Nil(t, later.SeasonID)
+
+		fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, TeamID: &legacyTeam.ID, UserID: player.ID, Reason: "Late", Amount: 5, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&fine).Error)
+		require.NotNil(t, fine.SeasonID)
+		assert.Equal(t, current.ID, *fine.SeasonID)
+
+		// Seasons of other clubs cannot be referenced
+		ctx, req := seasonRequest(owner.ID)
+		foreign := models.Event{ClubID: otherClub.ID, SeasonID: &current.ID, Name: "Foreign", StartTime: now, EndTime: now.Add(time.Hour)}
+		err := foreign.ODataBeforeCreate(ctx, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "season does not belong")
+	})
+
+	var next *models.Season
+	t.Run("rollover copies teams and rosters and archives the season", func(t *testing.T) {
+		require.NoError(t, legacyTeam.AddMember(player.ID, "admin", owner.ID))
+
+		_, err := current.Rollover("Too early", start.AddDate(0, 6, 0), end.AddDate(0, 6, 0), owner.ID)
+		assert.ErrorIs(t, err, models.ErrSeasonOrder)
+
+		next, err = current.Rollover("2026/27", end, end.AddDate(1, 0, 0), owner.ID)
+		require.NoError(t, err)
+		assert.True(t, current.Archived)
+
+		var archived models.Season
+		require.NoError(t, db.First(&archived, "id = ?", current.ID).Error)
+		assert.True(t, archived.Archived)
+		require.NotNil(t, archived.ArchivedBy)
+		assert.Equal(t, owner.ID, *archived.ArchivedBy)
+
+		var copies []models.Team
+		require.NoError(t, db.Where("season_id = ?", next.ID).Find(&copies).Error)
+		require.Len(t, copies, 1)
+		assert.Equal(t, "First team", copies[0].Name)
+		assert.NotEqual(t, legacyTeam.ID, copies[0].ID)
+
+		var roster []models.TeamMember
+		require.NoError(t, db.Where("team_id = ?", copies[0].ID).Find(&roster).Error)
+		require.Len(t, roster, 1)
+		assert.Equal(t, player.ID, roster[0].UserID)
+		assert.Equal(t, "admin", roster[0].Role)
+
+		// Events already planned for the next season join it
+		var later models.Event
+		require.NoError(t, db.First(&later, "name = ?", "Next year").Error)
+		require.NotNil(t, later.SeasonID)
+		assert.Equal(t, next.ID, *later.SeasonID)
+
+		_, err = current.Rollover("Again", end.AddDate(1, 0, 0), end.AddDate(2, 0, 0), owner.ID)
+		assert.ErrorIs(t, err, models.ErrSeasonArchived)
+
+		// Teams cannot be added to archived seasons
+		ctx, req := seasonRequest(owner.ID)
+		archivedTeam := models.Team{ClubID: club.ID, SeasonID: &current.ID, Name: "Late team"}
+		err = archivedTeam.ODataBeforeCreate(ctx, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "archived season")
+	})
+
+	t.Run("patched seasons must belong to the club and be open", func(t *testing.T) {
+		require.NoError(t, db.Model(&models.ClubSettings{}).Where("club_id = ?", club.ID).Updates(map[string]interface{}{"events_enabled": true, "fines_enabled": true}).Error)
+		foreign := models.Season{ClubID: otherClub.ID, Name: "Foreign", StartDate: start, EndDate: end}
+		require.NoError(t, db.Create(&foreign).Error)
+
+		var later models.Event
+		require.NoError(t, db.First(&later, "name = ?", "Next year").Error)
+		var fine models.Fine
+		require.NoError(t, db.First(&fine, "club_id = ? AND reason = ?", club.ID, "Late").Error)
+		w := odataRequest(t, ownerToken, http.MethodPatch, "/Fines("+fine.ID+")", map[string]interface{}{"SeasonID": next.ID})
+		require.Less(t, w.Code, 300, w.Body.String())
+		var nextTeam models.Team
+		require.NoError(t, db.First(&nextTeam, "season_id = ?", next.ID).Error)
+
+		for _, path := range []string{"/Events(" + later.ID + ")", "/Fines(" + fine.ID + ")", "/Teams(" + nextTeam.ID + ")"} {
+			w = odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"SeasonID": foreign.ID})
+			assert.Equal(t, http.StatusForbidden, w.Code, path)
+			w = odataRequest(t, ownerToken, http.MethodPatch, path, map[string]interface{}{"SeasonID": current.ID})
+			assert.Equal(t, http.StatusForbidden, w.Code, path)
+			assert.Contains(t, w.Body.String(), "archived", path)
+		}
+
+		// Back to its season for the statistics below
+		require.NoError(t, db.Model(&fine).Update("season_id", current.ID).Error)
+
+		// Records of an archived season can still be edited
+		w = odataRequest(t, ownerToken, http.MethodPatch, "/Events("+earlyEvent.ID+")", map[string]interface{}{"Name": "Season opener"})
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		require.NoError(t, db.First(&later, "id = ?", later.ID).Error)
+		require.NotNil(t, later.SeasonID)
+		assert.Equal(t, next.ID, *later.SeasonID)
+	})
+
+	t.Run("team statistics are filtered by season", func(t *testing.T) {
+		all, err := legacyTeam.GetTeamStats("")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), all["total_events"])
+		assert.Equal(t, int64(1), all["total_fines"])
+
+		inCurrent, err := legacyTeam.GetTeamStats(current.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), inCurrent["total_events"])
+
+		inNext, err := legacyTeam.GetTeamStats(next.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), inNext["total_events"])
+		assert.Equal(t, int64(0), inNext["total_fines"])
+	})
+}
@@ -0,0 +1,864 @@
+package models
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/NLstn/civo/encryption"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SEPA direct debit statuses
+const (
+	SepaDebitStatusPending = "pending"
+	SepaDebitStatusPaid    = "paid"
+)
+
+var ErrInvalidIBAN = errors.New("invalid IBAN")
+var ErrInvalidBIC = errors.New("invalid BIC")
+var ErrSepaCreditorMissing = errors.New("SEPA creditor details are not configured for this club")
+
+var bicPattern = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+var ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+
+// SepaMandate is a member's SEPA direct debit mandate. The IBAN is stored encrypted;
+// mandates are recorded through the SetSepaMandate action.
+type SepaMandate struct {
+	ID               string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID           string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	UserID           string     `json:"UserID" gorm:"type:uuid;not null;index" odata:"auto"`
+	IBANEncrypted    string     `json:"-" gorm:"column:iban_encrypted;not null"` // Never exposed via API
+	IBANMasked       string     `json:"IBANMasked" gorm:"column:iban_masked" odata:"auto"`
+	BIC              string     `json:"BIC" gorm:"column:bic" odata:"auto"`
+	MandateReference string     `json:"MandateReference" gorm:"not null" odata:"auto"`
+	SignatureDate    time.Time  `json:"SignatureDate" odata:"auto"`
+	Active           bool       `json:"Active" gorm:"default:true" odata:"auto"`
+	RevokedAt        *time.Time `json:"RevokedAt,omitempty" odata:"auto,nullable"`
+	CreatedAt        time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy        string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt        time.Time  `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy        string     `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
+}
+
+// SepaCreditor holds the club's creditor details used for direct debit exports
+type SepaCreditor struct {
+	ID                 string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID             string    `json:"ClubID" gorm:"type:uuid;not null;uniqueIndex" odata:"auto"`
+	Name               string    `json:"Name" gorm:"not null" odata:"auto"`
+	IBANEncrypted      string    `json:"-" gorm:"column:iban_encrypted;not null"` // Never exposed via API
+	IBANMasked         string    `json:"IBANMasked" gorm:"column:iban_masked" odata:"auto"`
+	BIC                string    `json:"BIC" gorm:"column:bic" odata:"auto"`
+	CreditorIdentifier string    `json:"CreditorIdentifier" gorm:"not null" odata:"auto"` // Gläubiger-Identifikationsnummer
+	UpdatedAt          time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy          string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+}
+
+// SepaDebit is a single fine or invoice collected in an exported direct debit batch
+type SepaDebit struct {
+	ID             string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID         string     `json:"ClubID" gorm:"type:uuid;not null;index" odata:"auto"`
+	BatchID        string     `json:"BatchID" gorm:"not null;index" odata:"auto"` // MsgId of the pain.008 file
+	EndToEndID     string     `json:"EndToEndID" gorm:"column:end_to_end_id;not null;uniqueIndex" odata:"auto"`
+	UserID         string     `json:"UserID" gorm:"type:uuid;not null" odata:"auto"`
+	MandateID      string     `json:"MandateID" gorm:"type:uuid;not null" odata:"auto"`
+	FineID         *string    `json:"FineID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	InvoiceID      *string    `json:"InvoiceID,omitempty" gorm:"type:uuid" odata:"auto,nullable"`
+	Amount         float64    `json:"Amount" odata:"auto"`
+	CollectionDate time.Time  `json:"CollectionDate" odata:"auto"`
+	Status         string     `json:"Status" gorm:"not null" odata:"auto"` // "pending" or "paid"
+	PaidAt         *time.Time `json:"PaidAt,omitempty" odata:"auto,nullable"`
+	CreatedAt      time.Time  `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy      string     `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+}
+
+// SepaImportResult summarizes a camt.053 import
+type SepaImportResult struct {
+	Matched        int      `json:"Matched"`
+	Unmatched      int      `json:"Unmatched"`
+	MatchedDebits  []string `json:"MatchedDebits"`
+	UnmatchedRefs  []string `json:"UnmatchedRefs"`
+	AlreadyCleared int      `json:"AlreadyCleared"`
+}
+
+// BeforeCreate generates UUID for new SEPA mandates
+func (sm *SepaMandate) BeforeCreate(tx *gorm.DB) error {
+	if sm.ID == "" {
+		sm.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new SEPA creditors
+func (sc *SepaCreditor) BeforeCreate(tx *gorm.DB) error {
+	if sc.ID == "" {
+		sc.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new SEPA debits
+func (sd *SepaDebit) BeforeCreate(tx *gorm.DB) error {
+	if sd.ID == "" {
+		sd.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// NormalizeIBAN removes spaces and uppercases an IBAN
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+}
+
+// ValidateIBAN checks the format and the ISO 13616 mod-97 checksum of an IBAN
+// and returns it normalized
+func ValidateIBAN(iban string) (string, error) {
+	iban = NormalizeIBAN(iban)
+	if !ibanPattern.MatchString(iban) {
+		return "", ErrInvalidIBAN
+	}
+	if iban[:2] == "DE" && len(iban) != 22 {
+		return "", ErrInvalidIBAN
+	}
+
+	// Move the first four characters to the end and replace letters with numbers (A=10 ... Z=35)
+	rearranged := iban[4:] + iban[:4]
+	var digits strings.Builder
+	for _, c := range rearranged {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(fmt.Sprintf("%d", c-'A'+10))
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+
+	number, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok || new(big.Int).Mod(number, big.NewInt(97)).Int64() != 1 {
+		return "", ErrInvalidIBAN
+	}
+
+	return iban, nil
+}
+
+// ValidateBIC checks the format of a BIC and returns it normalized.
+// An empty BIC is allowed, as it is optional for SEPA payments within the EEA.
+func ValidateBIC(bic string) (string, error) {
+	bic = strings.ToUpper(strings.TrimSpace(bic))
+	if bic == "" {
+		return "", nil
+	}
+	if !bicPattern.MatchString(bic) {
+		return "", ErrInvalidBIC
+	}
+	return bic, nil
+}
+
+// MaskIBAN hides all but the country code and the last four characters of an IBAN
+func MaskIBAN(iban string) string {
+	if len(iban) <= 8 {
+		return iban
+	}
+	return iban[:4] + strings.Repeat("*", len(iban)-8) + iban[len(iban)-4:]
+}
+
+// IBAN returns the decrypted IBAN of the mandate
+func (sm *SepaMandate) IBAN() (string, error) {
+	return encryption.Decrypt(sm.IBANEncrypted)
+}
+
+// IBAN returns the decrypted IBAN of the creditor
+func (sc *SepaCreditor) IBAN() (string, error) {
+	return encryption.Decrypt(sc.IBANEncrypted)
+}
+
+// SetSepaMandate records a mandate for a club member. An existing active mandate of the
+// member is revoked, so each member has at most one active mandate per club.
+func SetSepaMandate(clubID, userID, iban, bic, mandateReference string, signatureDate time.Time, recordedBy string) (*SepaMandate, error) {
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", clubID, userID).First(&member).Error; err != nil {
+		return nil, fmt.Errorf("user is not a member of the club")
+	}
+
+	iban, err := ValidateIBAN(iban)
+	if err != nil {
+		return nil, err
+	}
+	bic, err = ValidateBIC(bic)
+	if err != nil {
+		return nil, err
+	}
+
+	mandateReference = strings.TrimSpace(mandateReference)
+	if mandateReference == "" || len(mandateReference) > 35 {
+		return nil, fmt.Errorf("mandate reference must be between 1 and 35 characters")
+	}
+	if signatureDate.IsZero() || signatureDate.After(time.Now()) {
+		return nil, fmt.Errorf("signature date must not be in the future")
+	}
+
+	var duplicate int64
+	database.Db.Model(&SepaMandate{}).Where("club_id = ? AND mandate_reference = ? AND user_id <> ?", clubID, mandateReference, userID).Count(&duplicate)
+	if duplicate > 0 {
+		return nil, fmt.Errorf("mandate reference is already used in this club")
+	}
+
+	encrypted, err := encryption.Encrypt(iban)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt IBAN: %w", err)
+	}
+
+	now := time.Now()
+	mandate := SepaMandate{
+		ClubID:           clubID,
+		UserID:           userID,
+		IBANEncrypted:    encrypted,
+		IBANMasked:       MaskIBAN(iban),
+		BIC:              bic,
+		MandateReference: mandateReference,
+		SignatureDate:    signatureDate,
+		Active:           true,
+		CreatedAt:        now,
+		CreatedBy:        recordedBy,
+		UpdatedAt:        now,
+		UpdatedBy:        recordedBy,
+	}
+
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&SepaMandate{}).Where("club_id = ? AND user_id = ? AND active = ?", clubID, userID, true).
+			Updates(map[string]interface{}{"active": false, "revoked_at": now, "updated_at": now, "updated_by": recordedBy}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&mandate).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store mandate: %w", err)
+	}
+
+	return &mandate, nil
+}
+
+// SetSepaCreditor stores the club's creditor details for direct debit exports
+func SetSepaCreditor(clubID, name, iban, bic, creditorIdentifier, updatedBy string) (*SepaCreditor, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 70 {
+		return nil, fmt.Errorf("creditor name must be between 1 and 70 characters")
+	}
+	creditorIdentifier = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(creditorIdentifier), " ", ""))
+	if len(creditorIdentifier) < 8 || len(creditorIdentifier) > 35 {
+		return nil, fmt.Errorf("invalid creditor identifier")
+	}
+
+	iban, err := ValidateIBAN(iban)
+	if err != nil {
+		return nil, err
+	}
+	bic, err = ValidateBIC(bic)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := encryption.Encrypt(iban)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt IBAN: %w", err)
+	}
+
+	var creditor SepaCreditor
+	err = database.Db.Where("club_id = ?", clubID).First(&creditor).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	creditor.ClubID = clubID
+	creditor.Name = name
+	creditor.IBANEncrypted = encrypted
+	creditor.IBANMasked = MaskIBAN(iban)
+	creditor.BIC = bic
+	creditor.CreditorIdentifier = creditorIdentifier
+	creditor.UpdatedAt = time.Now()
+	creditor.UpdatedBy = updatedBy
+
+	if err := database.Db.Save(&creditor).Error; err != nil {
+		return nil, fmt.Errorf("failed to store creditor: %w", err)
+	}
+
+	return &creditor, nil
+}
+
+// pain.008.001.02 document structure
+type painDocument struct {
+	XMLName xml.Name         `xml:"Document"`
+	Xmlns   string           `xml:"xmlns,attr"`
+	Content painCstmrDrctDbt `xml:"CstmrDrctDbtInitn"`
+}
+
+type painCstmrDrctDbt struct {
+	GrpHdr painGroupHeader `xml:"GrpHdr"`
+	PmtInf painPaymentInfo `xml:"PmtInf"`
+}
+
+type painGroupHeader struct {
+	MsgID    string    `xml:"MsgId"`
+	CreDtTm  string    `xml:"CreDtTm"`
+	NbOfTxs  int       `xml:"NbOfTxs"`
+	CtrlSum  string    `xml:"CtrlSum"`
+	InitgPty painParty `xml:"InitgPty"`
+}
+
+type painParty struct {
+	Nm string `xml:"Nm"`
+}
+
+type painAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type painAgent struct {
+	BIC  string `xml:"FinInstnId>BIC,omitempty"`
+	Othr string `xml:"FinInstnId>Othr>Id,omitempty"`
+}
+
+type painPaymentInfo struct {
+	PmtInfID     string          `xml:"PmtInfId"`
+	PmtMtd       string          `xml:"PmtMtd"`
+	NbOfTxs      int             `xml:"NbOfTxs"`
+	CtrlSum      string          `xml:"CtrlSum"`
+	SvcLvl       string          `xml:"PmtTpInf>SvcLvl>Cd"`
+	LclInstrm    string          `xml:"PmtTpInf>LclInstrm>Cd"`
+	SeqTp        string          `xml:"PmtTpInf>SeqTp"`
+	ReqdColltnDt string          `xml:"ReqdColltnDt"`
+	Cdtr         painParty       `xml:"Cdtr"`
+	CdtrAcct     painAccount     `xml:"CdtrAcct"`
+	CdtrAgt      painAgent       `xml:"CdtrAgt"`
+	ChrgBr       string          `xml:"ChrgBr"`
+	CdtrSchmeID  string          `xml:"CdtrSchmeId>Id>PrvtId>Othr>Id"`
+	SchmeNm      string          `xml:"CdtrSchmeId>Id>PrvtId>Othr>SchmeNm>Prtry"`
+	Transactions []painDirectDbt `xml:"DrctDbtTxInf"`
+}
+
+type painAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type painDirectDbt struct {
+	EndToEndID string      `xml:"PmtId>EndToEndId"`
+	InstdAmt   painAmount  `xml:"InstdAmt"`
+	MndtID     string      `xml:"DrctDbtTx>MndtRltdInf>MndtId"`
+	DtOfSgntr  string      `xml:"DrctDbtTx>MndtRltdInf>DtOfSgntr"`
+	DbtrAgt    painAgent   `xml:"DbtrAgt"`
+	Dbtr       painParty   `xml:"Dbtr"`
+	DbtrAcct   painAccount `xml:"DbtrAcct"`
+	Ustrd      string      `xml:"RmtInf>Ustrd"`
+}
+
+// sepaItem is a fine or invoice to be collected
+type sepaItem struct {
+	userID      string
+	amount      float64
+	description string
+	fineID      *string
+	invoiceID   *string
+}
+
+// sepaText restricts text to the SEPA character set and the given length
+func sepaText(text string, maxLen int) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.ContainsRune("/-?:().,'+ ", c):
+			b.WriteRune(c)
+		case c == 'ä':
+			b.WriteString("ae")
+		case c == 'ö':
+			b.WriteString("oe")
+		case c == 'ü':
+			b.WriteString("ue")
+		case c == 'Ä':
+			b.WriteString("Ae")
+		case c == 'Ö':
+			b.WriteString("Oe")
+		case c == 'Ü':
+			b.WriteString("Ue")
+		case c == 'ß':
+			b.WriteString("ss")
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	result := strings.TrimSpace(b.String())
+	if len(result) > maxLen {
+		result = result[:maxLen]
+	}
+	return result
+}
+
+// sepaID returns a unique identifier of at most 35 characters with the given prefix
+func sepaID(prefix string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))[:35-len(prefix)]
+}
+
+// loadSepaItems loads the selected unpaid fines and invoices of the club
+func loadSepaItems(clubID string, fineIDs, invoiceIDs []string) ([]sepaItem, error) {
+	items := make([]sepaItem, 0, len(fineIDs)+len(invoiceIDs))
+
+	if len(fineIDs) > 0 {
+		var fines []Fine
+		if err := database.Db.Where("id IN ? AND club_id = ? AND paid = ? AND pending_review = ?", fineIDs, clubID, false, false).
+			Where("dispute_status IS NULL OR dispute_status NOT IN ?", []string{FineDisputeStatusDisputed, FineDisputeStatusWaived}).Find(&fines).Error; err != nil {
+			return nil, err
+		}
+		if len(fines) != len(fineIDs) {
+			return nil, fmt.Errorf("some fines do not exist, belong to another club, are already paid or are disputed")
+		}
+		for i := range fines {
+			items = append(items, sepaItem{userID: fines[i].UserID, amount: fines[i].Amount, description: FineReference(fines[i].ID) + " " + fines[i].Reason, fineID: &fines[i].ID})
+		}
+	}
+
+	if len(invoiceIDs) > 0 {
+		var invoices []Invoice
+		if err := database.Db.Where("id IN ? AND club_id = ? AND paid = ?", invoiceIDs, clubID, false).Find(&invoices).Error; err != nil {
+			return nil, err
+		}
+		if len(invoices) != len(invoiceIDs) {
+			return nil, fmt.Errorf("some invoices do not exist, belong to another club or are already paid")
+		}
+		for i := range invoices {
+			items = append(items, sepaItem{userID: invoices[i].UserID, amount: invoices[i].Amount, description: "Membership fee " + invoices[i].Reference, invoiceID: &invoices[i].ID})
+		}
+	}
+
+	// Items already in a pending batch must not be collected twice
+	var pending int64
+	query := database.Db.Model(&SepaDebit{}).Where("status = ?", SepaDebitStatusPending)
+	switch {
+	case len(fineIDs) > 0 && len(invoiceIDs) > 0:
+		query = query.Where("fine_id IN ? OR invoice_id IN ?", fineIDs, invoiceIDs)
+	case len(fineIDs) > 0:
+		query = query.Where("fine_id IN ?", fineIDs)
+	default:
+		query = query.Where("invoice_id IN ?", invoiceIDs)
+	}
+	query.Count(&pending)
+	if pending > 0 {
+		return nil, fmt.Errorf("some items are already part of a pending direct debit batch")
+	}
+
+	return items, nil
+}
+
+// ExportSepaDirectDebit builds a pain.008.001.02 batch for the selected unpaid fines and invoices
+// and records the debits, so a later camt.053 import can mark them as paid.
+func ExportSepaDirectDebit(clubID string, fineIDs, invoiceIDs []string, collectionDate time.Time, exportedBy string) ([]byte, error) {
+	if len(fineIDs) == 0 && len(invoiceIDs) == 0 {
+		return nil, fmt.Errorf("no fines or invoices selected")
+	}
+	if collectionDate.Before(time.Now().Truncate(24 * time.Hour)) {
+		return nil, fmt.Errorf("collection date must not be in the past")
+	}
+
+	var creditor SepaCreditor
+	if err := database.Db.Where("club_id = ?", clubID).First(&creditor).Error; err != nil {
+		return nil, ErrSepaCreditorMissing
+	}
+	creditorIBAN, err := creditor.IBAN()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := loadSepaItems(clubID, fineIDs, invoiceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	batchID := sepaID("MSG")
+	payment := painPaymentInfo{
+		PmtInfID:     sepaID("PMT"),
+		PmtMtd:       "DD",
+		SvcLvl:       "SEPA",
+		LclInstrm:    "CORE",
+		SeqTp:        "RCUR", // FRST is no longer required for first collections since the 2016 SEPA rulebook
+		ReqdColltnDt: collectionDate.Format("2006-01-02"),
+		Cdtr:         painParty{Nm: sepaText(creditor.Name, 70)},
+		CdtrAcct:     painAccount{IBAN: creditorIBAN},
+		CdtrAgt:      painAgent{BIC: creditor.BIC},
+		ChrgBr:       "SLEV",
+		CdtrSchmeID:  creditor.CreditorIdentifier,
+		SchmeNm:      "SEPA",
+	}
+	if creditor.BIC == "" {
+		payment.CdtrAgt = painAgent{Othr: "NOTPROVIDED"}
+	}
+
+	debits := make([]SepaDebit, 0, len(items))
+	total := 0.0
+	for _, item := range items {
+		var mandate SepaMandate
+		if err := database.Db.Where("club_id = ? AND user_id = ? AND active = ?", clubID, item.userID, true).First(&mandate).Error; err != nil {
+			return nil, fmt.Errorf("member %s has no active SEPA mandate", item.userID)
+		}
+		debtorIBAN, err := mandate.IBAN()
+		if err != nil {
+			return nil, err
+		}
+		var debtor User
+		if err := database.Db.Where("id = ?", item.userID).First(&debtor).Error; err != nil {
+			return nil, fmt.Errorf("failed to find member: %w", err)
+		}
+
+		endToEndID := sepaID("E2E")
+		transaction := painDirectDbt{
+			EndToEndID: endToEndID,
+			InstdAmt:   painAmount{Ccy: "EUR", Value: fmt.Sprintf("%.2f", item.amount)},
+			MndtID:     mandate.MandateReference,
+			DtOfSgntr:  mandate.SignatureDate.Format("2006-01-02"),
+			DbtrAgt:    painAgent{BIC: mandate.BIC},
+			Dbtr:       painParty{Nm: sepaText(debtor.FirstName+" "+debtor.LastName, 70)},
+			DbtrAcct:   painAccount{IBAN: debtorIBAN},
+			Ustrd:      sepaText(item.description, 140),
+		}
+		if mandate.BIC == "" {
+			transaction.DbtrAgt = painAgent{Othr: "NOTPROVIDED"}
+		}
+		payment.Transactions = append(payment.Transactions, transaction)
+		total += item.amount
+
+		debits = append(debits, SepaDebit{
+			ClubID:         clubID,
+			BatchID:        batchID,
+			EndToEndID:     endToEndID,
+			UserID:         item.userID,
+			MandateID:      mandate.ID,
+			FineID:         item.fineID,
+			InvoiceID:      item.invoiceID,
+			Amount:         item.amount,
+			CollectionDate: collectionDate,
+			Status:         SepaDebitStatusPending,
+			CreatedAt:      now,
+			CreatedBy:      exportedBy,
+		})
+	}
+
+	controlSum := fmt.Sprintf("%.2f", total)
+	payment.NbOfTxs = len(payment.Transactions)
+	payment.CtrlSum = controlSum
+
+	document := painDocument{
+		Xmlns: "urn:iso:std:iso:20022:tech:xsd:pain.008.001.02",
+		Content: painCstmrDrctDbt{
+			GrpHdr: painGroupHeader{
+				MsgID:    batchID,
+				CreDtTm:  now.UTC().Format("2006-01-02T15:04:05"),
+				NbOfTxs:  len(payment.Transactions),
+				CtrlSum:  controlSum,
+				InitgPty: painParty{Nm: sepaText(creditor.Name, 70)},
+			},
+			PmtInf: payment,
+		},
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pain.008 document: %w", err)
+	}
+
+	if err := database.Db.Create(&debits).Error; err != nil {
+		return nil, fmt.Errorf("failed to record direct debits: %w", err)
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
+
+// camt.053 document structure (only the parts needed for matching)
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount `xml:"Amt"`
+	CdtDbtInd      string     `xml:"CdtDbtInd"`
+	Status         string     `xml:"Sts"`
+	BookingDt      string     `xml:"BookgDt>Dt"`
+	BookingDtTm    string     `xml:"BookgDt>DtTm"`
+	AdditionalInfo string     `xml:"AddtlNtryInf"`
+	Details        []struct {
+		Transactions []camtTransaction `xml:"TxDtls"`
+	} `xml:"NtryDtls"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtTransaction struct {
+	EndToEndID   string      `xml:"Refs>EndToEndId"`
+	Amount       *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	TxAmount     *camtAmount `xml:"Amt"`
+	DebtorName   string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorIBAN   string      `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+	Unstructured []string    `xml:"RmtInf>Ustrd"`
+	CreditorRef  string      `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+// parseCamtAmount parses a camt amount value
+func parseCamtAmount(amount *camtAmount) (float64, bool) {
+	if amount == nil {
+		return 0, false
+	}
+	var value float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(amount.Value), "%f", &value); err != nil {
+		return 0, false
+	}
+	return roundCents(value), true
+}
+
+// ImportCamt053 reads a camt.053 bank statement and marks the direct debits with
+// a matching end-to-end ID and amount, and their fines or invoices, as paid.
+func ImportCamt053(clubID string, data []byte, importedBy string) (SepaImportResult, error) {
+	result := SepaImportResult{MatchedDebits: []string{}, UnmatchedRefs: []string{}}
+
+	var document camtDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return result, fmt.Errorf("invalid camt.053 document: %w", err)
+	}
+
+	for _, statement := range document.Statements {
+		for _, entry := range statement.Entries {
+			// Only booked credits settle direct debits
+			if entry.CdtDbtInd != "CRDT" || (entry.Status != "" && entry.Status != "BOOK") {
+				continue
+			}
+
+			var transactions []camtTransaction
+			for _, details := range entry.Details {
+				transactions = append(transactions, details.Transactions...)
+			}
+
+			for _, transaction := range transactions {
+				if transaction.EndToEndID == "" || transaction.EndToEndID == "NOTPROVIDED" {
+					continue
+				}
+
+				amount, ok := parseCamtAmount(transaction.Amount)
+				if !ok {
+					amount, ok = parseCamtAmount(transaction.TxAmount)
+				}
+				if !ok && len(transactions) == 1 {
+					amount, ok = parseCamtAmount(&entry.Amount)
+				}
+
+				matched, alreadyCleared, err := settleSepaDebit(database.Db, clubID, transaction.EndToEndID, amount, ok, importedBy)
+				if err != nil {
+					return result, err
+				}
+				switch {
+				case alreadyCleared:
+					result.AlreadyCleared++
+				case matched != "":
+					result.Matched++
+					result.MatchedDebits = append(result.MatchedDebits, matched)
+				default:
+					result.Unmatched++
+					result.UnmatchedRefs = append(result.UnmatchedRefs, transaction.EndToEndID)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// settleSepaDebit marks a pending debit and its fine or invoice as paid.
+// It returns the debit ID if matched and whether the debit had already been settled.
+func settleSepaDebit(db *gorm.DB, clubID, endToEndID string, amount float64, hasAmount bool, settledBy string) (string, bool, error) {
+	var debit SepaDebit
+	if err := db.Where("club_id = ? AND end_to_end_id = ?", clubID, endToEndID).First(&debit).Error; err != nil {
+		return "", false, nil
+	}
+	if debit.Status == SepaDebitStatusPaid {
+		return "", true, nil
+	}
+	if hasAmount && roundCents(debit.Amount) != amount {
+		return "", false, nil
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&debit).Updates(map[string]interface{}{"status": SepaDebitStatusPaid, "paid_at": now}).Error; err != nil {
+			return err
+		}
+		if debit.FineID != nil {
+			if err := tx.Model(&Fine{}).Where("id = ?", *debit.FineID).
+				Updates(map[string]interface{}{"paid": true, "updated_at": now, "updated_by": settledBy}).Error; err != nil {
+				return err
+			}
+		}
+		if debit.InvoiceID != nil {
+			if err := tx.Model(&Invoice{}).Where("id = ? AND paid = ?", *debit.InvoiceID, false).
+				Updates(map[string]interface{}{"paid": true, "paid_at": now, "updated_at": now, "updated_by": settledBy}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to settle direct debit: %w", err)
+	}
+
+	return debit.ID, false, nil
+}
+
+// ODataBeforeReadCollection filters mandates to the user's own or those of administered clubs
+func (sm SepaMandate) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(feeOwnerOrAdminScope, userID, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific mandate
+func (sm SepaMandate) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sm.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation of mandates; use the SetSepaMandate action instead
+func (sm *SepaMandate) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSepaMandate action to record mandates")
+}
+
+// ODataBeforeUpdate prevents direct updates of mandates; use the SetSepaMandate action instead
+func (sm *SepaMandate) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSepaMandate action to record mandates")
+}
+
+// ODataBeforeDelete allows club admins to delete mandates that were never used
+func (sm *SepaMandate) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(sm.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete mandates")
+	}
+
+	var debits int64
+	database.Db.Model(&SepaDebit{}).Where("mandate_id = ?", sm.ID).Count(&debits)
+	if debits > 0 {
+		return fmt.Errorf("mandate has been used for direct debits and must be kept")
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters creditor details to administered clubs
+func (sc SepaCreditor) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN ("+permittedClubsQuery+")", userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to specific creditor details
+func (sc SepaCreditor) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sc.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation; use the SetSepaCreditor action instead
+func (sc *SepaCreditor) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSepaCreditor action")
+}
+
+// ODataBeforeUpdate prevents direct updates; use the SetSepaCreditor action instead
+func (sc *SepaCreditor) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: use the SetSepaCreditor action")
+}
+
+// ODataBeforeDelete validates creditor deletion permissions
+func (sc *SepaCreditor) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(sc.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete creditor details")
+	}
+
+	return nil
+}
+
+// ODataBeforeReadCollection filters direct debits to administered clubs
+func (sd SepaDebit) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where(feeOwnerOrAdminScope, userID, userID, userID, PermissionFinesManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific direct debit
+func (sd SepaDebit) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sd.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents direct creation; debits are recorded by the export
+func (sd *SepaDebit) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: direct debits are recorded by the SEPA export")
+}
+
+// ODataBeforeUpdate prevents direct updates; debits are settled by the camt.053 import
+func (sd *SepaDebit) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: direct debits are settled by the statement import")
+}
+
+// ODataBeforeDelete allows club admins to discard pending debits (e.g. a batch that was never submitted)
+func (sd *SepaDebit) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isFeeAdmin(sd.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can discard direct debits")
+	}
+
+	if sd.Status != SepaDebitStatusPending {
+		return fmt.Errorf("only pending direct debits can be discarded")
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDebtorIBAN   = "DE89370400440532013000"
+	testCreditorIBAN = "DE02120300000000202051"
+)
+
+func sepaRequest(userID string) (context.Context, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/SepaMandates", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	return ctx, req.WithContext(ctx)
+}
+
+func TestValidateIBAN(t *testing.T) {
+	valid := []string{testDebtorIBAN, "de89 3704 0044 0532 0130 00", "GB82WEST12345698765432", "NL91ABNA0417164300"}
+	for _, iban := range valid {
+		normalized, err := models.ValidateIBAN(iban)
+		assert.NoError(t, err, iban)
+		assert.NotContains(t, normalized, " ")
+	}
+
+	invalid := []string{"", "DE89370400440532013001", "DE8937040044053201300", "XX00", "DE89-3704-0044-0532-0130-00"}
+	for _, iban := range invalid {
+		_, err := models.ValidateIBAN(iban)
+		assert.ErrorIs(t, err, models.ErrInvalidIBAN, iban)
+	}
+
+	assert.Equal(t, "DE89**************3000", models.MaskIBAN(testDebtorIBAN))
+
+	_, err := models.ValidateBIC("COBADEFFXXX")
+	assert.NoError(t, err)
+	_, err = models.ValidateBIC("COBA")
+	assert.ErrorIs(t, err, models.ErrInvalidBIC)
+}
+
+func TestSepaDirectDebit(t *testing.T) {
+	t.Setenv("DATA_ENCRYPTION_KEY", "sepa-test-key")
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "sepa-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "sepa-member@example.com")
+	other, _ := handlers.CreateTestUser(t, "sepa-other@example.com")
+	club := handlers.CreateTestClub(t, owner, "SEPA Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	handlers.CreateTestMember(t, other, club, "member")
+
+	signed := time.Now().AddDate(0, -1, 0)
+
+	t.Run("mandates store the IBAN encrypted", func(t *testing.T) {
+		_, err := models.SetSepaMandate(club.ID, member.ID, "DE89370400440532013001", "", "MANDATE-1", signed, member.ID)
+		assert.ErrorIs(t, err, models.ErrInvalidIBAN)
+
+		mandate, err := models.SetSepaMandate(club.ID, member.ID, testDebtorIBAN, "COBADEFFXXX", "MANDATE-1", signed, member.ID)
+		require.NoError(t, err)
+
+		var stored models.SepaMandate
+		require.NoError(t, db.Where("id = ?", mandate.ID).First(&stored).Error)
+		assert.NotContains(t, stored.IBANEncrypted, "0532013000")
+		assert.Equal(t, "DE89**************3000", stored.IBANMasked)
+		iban, err := stored.IBAN()
+		require.NoError(t, err)
+		assert.Equal(t, testDebtorIBAN, iban)
+
+		_, err = models.SetSepaMandate(club.ID, other.ID, testDebtorIBAN, "", "MANDATE-1", signed, owner.ID)
+		assert.Error(t, err, "mandate references are unique per club")
+	})
+
+	t.Run("a new mandate replaces the active one", func(t *testing.T) {
+		_, err := models.SetSepaMandate(club.ID, member.ID, testDebtorIBAN, "", "MANDATE-2", signed, member.ID)
+		require.NoError(t, err)
+
+		var active int64
+		db.Model(&models.SepaMandate{}).Where("club_id = ? AND user_id = ? AND active = ?", club.ID, member.ID, true).Count(&active)
+		assert.Equal(t, int64(1), active)
+	})
+
+	t.Run("mandates cannot be written through OData", func(t *testing.T) {
+		ctx, req := sepaRequest(member.ID)
+		mandate := &models.SepaMandate{ClubID: club.ID, UserID: member.ID}
+		assert.Error(t, mandate.ODataBeforeCreate(ctx, req))
+		assert.Error(t, mandate.ODataBeforeUpdate(ctx, req))
+	})
+
+	fine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: member.ID, Reason: "Late for training", Amount: 5, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&fine).Error)
+	otherFine := models.Fine{ID: uuid.New().String(), ClubID: club.ID, UserID: other.ID, Reason: "Forgot jersey", Amount: 3, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&otherFine).Error)
+
+	collectionDate := time.Now().AddDate(0, 0, 7)
+
+	t.Run("export requires creditor details and mandates", func(t *testing.T) {
+		_, err := models.ExportSepaDirectDebit(club.ID, []string{fine.ID}, nil, collectionDate, owner.ID)
+		assert.ErrorIs(t, err, models.ErrSepaCreditorMissing)
+
+		_, err = models.SetSepaCreditor(club.ID, "SEPA Club e.V.", testCreditorIBAN, "BYLADEM1001", "DE98ZZZ09999999999", owner.ID)
+		require.NoError(t, err)
+
+		_, err = models.ExportSepaDirectDebit(club.ID, []string{otherFine.ID}, nil, collectionDate, owner.ID)
+		assert.Error(t, err, "members without a mandate cannot be debited")
+	})
+
+	var debit models.SepaDebit
+
+	t.Run("export builds a pain.008 file", func(t *testing.T) {
+		document, err := models.ExportSepaDirectDebit(club.ID, []string{fine.ID}, nil, collectionDate, owner.ID)
+		require.NoError(t, err)
+
+		xml := string(document)
+		assert.Contains(t, xml, "urn:iso:std:iso:20022:tech:xsd:pain.008.001.02")
+		assert.Contains(t, xml, "<NbOfTxs>1</NbOfTxs>")
+		assert.Contains(t, xml, "<CtrlSum>5.00</CtrlSum>")
+		assert.Contains(t, xml, `<InstdAmt Ccy="EUR">5.00</InstdAmt>`)
+		assert.Contains(t, xml, "<MndtId>MANDATE-2</MndtId>")
+		assert.Contains(t, xml, "<IBAN>"+testDebtorIBAN+"</IBAN>")
+		assert.Contains(t, xml, "<IBAN>"+testCreditorIBAN+"</IBAN>")
+		assert.Contains(t, xml, "<Id>DE98ZZZ09999999999</Id>")
+		assert.Contains(t, xml, "<ReqdColltnDt>"+collectionDate.Format("2006-01-02")+"</ReqdColltnDt>")
+
+		require.NoError(t, db.Where("fine_id = ?", fine.ID).First(&debit).Error)
+		assert.Equal(t, models.SepaDebitStatusPending, debit.Status)
+		assert.Contains(t, xml, "<EndToEndId>"+debit.EndToEndID+"</EndToEndId>")
+
+		_, err = models.ExportSepaDirectDebit(club.ID, []string{fine.ID}, nil, collectionDate, owner.ID)
+		assert.Error(t, err, "pending items are not exported twice")
+	})
+
+	t.Run("camt.053 import marks matching items paid", func(t *testing.T) {
+		statement := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <NtryDtls><TxDtls><Refs><EndToEndId>%s</EndToEndId></Refs></TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">12.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <NtryDtls><TxDtls><Refs><EndToEndId>UNKNOWN-REF</EndToEndId></Refs></TxDtls></NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`, debit.EndToEndID)
+
+		result, err := models.ImportCamt053(club.ID, []byte(statement), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Matched)
+		assert.Equal(t, 1, result.Unmatched)
+		assert.Equal(t, []string{"UNKNOWN-REF"}, result.UnmatchedRefs)
+
+		var paidFine models.Fine
+		require.NoError(t, db.Where("id = ?", fine.ID).First(&paidFine).Error)
+		assert.True(t, paidFine.Paid)
+
+		var settled models.SepaDebit
+		require.NoError(t, db.Where("id = ?", debit.ID).First(&settled).Error)
+		assert.Equal(t, models.SepaDebitStatusPaid, settled.Status)
+		assert.NotNil(t, settled.PaidAt)
+
+		result, err = models.ImportCamt053(club.ID, []byte(statement), owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Matched)
+		assert.Equal(t, 1, result.AlreadyCleared)
+	})
+
+	t.Run("invalid statements are rejected", func(t *testing.T) {
+		_, err := models.ImportCamt053(club.ID, []byte("<Document><BkToCstmrStmt>"), owner.ID)
+		assert.Error(t, err)
+	})
+}
@@ -2,13 +2,22 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/NLstn/civo/auth"
 	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrShiftFull             = errors.New("shift is already fully staffed")
+	ErrShiftStarted          = errors.New("shift has already started")
+	ErrShiftWithdrawalClosed = errors.New("the withdrawal deadline for this shift has passed")
 )
 
 type Shift struct {
@@ -22,10 +31,21 @@ type Shift struct {
 	UpdatedAt time.Time `json:"UpdatedAt"`
 	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"required"`
 
+	// Role and capacity; members sign up themselves until MaxHeadcount is reached
+	Role                  *string `json:"Role,omitempty" odata:"nullable"` // e.g. "bar", "grill", "cashier"
+	Description           *string `json:"Description,omitempty" odata:"nullable"`
+	MinHeadcount          int     `json:"MinHeadcount" gorm:"default:1"`             // People needed for the shift
+	MaxHeadcount          int     `json:"MaxHeadcount" gorm:"default:0"`             // 0 means unlimited
+	WithdrawalCutoffHours int     `json:"WithdrawalCutoffHours" gorm:"default:24"`   // Members cannot withdraw later than this before the start
+	SwapRequiresApproval  bool    `json:"SwapRequiresApproval" gorm:"default:false"` // Swaps and covers need an admin's approval
+	ShiftTemplateID       *string `json:"ShiftTemplateID,omitempty" gorm:"type:uuid;index" odata:"auto,nullable"`
+	VenueID               *string `json:"VenueID,omitempty" gorm:"type:uuid;index" odata:"nullable"` // Facility of the shift if it differs from the event's
+
 	// Navigation properties
 	Event        *Event        `gorm:"foreignKey:EventID" json:"Event,omitempty" odata:"nav"`
 	Club         *Club         `gorm:"foreignKey:ClubID" json:"Club,omitempty" odata:"nav"`
 	ShiftMembers []ShiftMember `gorm:"foreignKey:ShiftID" json:"ShiftMembers,omitempty" odata:"nav"`
+	Venue        *Venue        `gorm:"foreignKey:VenueID" json:"Venue,omitempty" odata:"nav"`
 }
 
 type ShiftMember struct {
@@ -36,6 +56,7 @@ type ShiftMember struct {
 	CreatedBy string    `json:"CreatedBy" gorm:"type:uuid" odata:"required"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 	UpdatedBy string    `json:"UpdatedBy" gorm:"type:uuid" odata:"required"`
+	Attended  *bool     `json:"Attended,omitempty" odata:"auto,nullable"` // Recorded by admins after the shift
 
 	// Navigation properties
 	Shift *Shift `gorm:"foreignKey:ShiftID" json:"Shift,omitempty" odata:"nav"`
@@ -78,6 +99,138 @@ func (c *Club) GetShiftsByEvent(eventID string) ([]Shift, error) {
 	return shifts, nil
 }
 
+// OpenShift is an upcoming shift that still needs people
+type OpenShift struct {
+	ShiftID      string    `json:"ShiftID"`
+	EventID      string    `json:"EventID"`
+	EventName    string    `json:"EventName"`
+	Role         *string   `json:"Role,omitempty"`
+	StartTime    time.Time `json:"StartTime"`
+	EndTime      time.Time `json:"EndTime"`
+	MinHeadcount int       `json:"MinHeadcount"`
+	MaxHeadcount int       `json:"MaxHeadcount"`
+	SignedUp     int       `json:"SignedUp"`
+	Missing      int       `json:"Missing"` // People missing to reach MinHeadcount
+}
+
+// validateCapacity checks the headcount settings of the shift
+func (s *Shift) validateCapacity() error {
+	if s.MinHeadcount < 0 || s.MaxHeadcount < 0 || s.WithdrawalCutoffHours < 0 {
+		return fmt.Errorf("headcount and withdrawal cutoff cannot be negative")
+	}
+	if s.MaxHeadcount > 0 && s.MinHeadcount > s.MaxHeadcount {
+		return fmt.Errorf("minimum headcount cannot exceed maximum headcount")
+	}
+	if !s.EndTime.After(s.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	return nil
+}
+
+// SignUp adds the user to the shift if there is room left
+func (s *Shift) SignUp(userID string) (ShiftMember, error) {
+	if !time.Now().Before(s.StartTime) {
+		return ShiftMember{}, ErrShiftStarted
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", s.ClubID, userID).First(&member).Error; err != nil {
+		return ShiftMember{}, fmt.Errorf("unauthorized: user is not a member of the club")
+	}
+
+	absent, err := IsAbsent(s.ClubID, userID, s.StartTime, s.EndTime)
+	if err != nil {
+		return ShiftMember{}, err
+	}
+	if absent {
+		return ShiftMember{}, ErrMemberAbsent
+	}
+
+	shiftMember := ShiftMember{
+		ID:        uuid.New().String(),
+		ShiftID:   s.ID,
+		UserID:    userID,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		// Lock the shift so concurrent sign-ups cannot exceed the maximum headcount
+		var shift Shift
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shift, "id = ?", s.ID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&ShiftMember{}).Where("shift_id = ? AND user_id = ?", s.ID, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("user is already signed up for this shift")
+		}
+
+		if shift.MaxHeadcount > 0 {
+			if err := tx.Model(&ShiftMember{}).Where("shift_id = ?", s.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(shift.MaxHeadcount) {
+				return ErrShiftFull
+			}
+		}
+
+		return tx.Create(&shiftMember).Error
+	})
+	if err != nil {
+		return ShiftMember{}, err
+	}
+
+	return shiftMember, nil
+}
+
+// Withdraw removes the user from the shift before the withdrawal cutoff
+func (s *Shift) Withdraw(userID string) error {
+	deadline := s.StartTime.Add(-time.Duration(s.WithdrawalCutoffHours) * time.Hour)
+	if time.Now().After(deadline) {
+		return ErrShiftWithdrawalClosed
+	}
+
+	result := database.Db.Where("shift_id = ? AND user_id = ?", s.ID, userID).Delete(&ShiftMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user is not signed up for this shift")
+	}
+	return nil
+}
+
+// GetShiftsNeedingPeople returns the club's shifts starting within the given
+// number of days that have fewer sign-ups than their minimum headcount
+func (c *Club) GetShiftsNeedingPeople(days int) ([]OpenShift, error) {
+	now := time.Now()
+
+	var openShifts []OpenShift
+	err := database.Db.Table("shifts").
+		Select(`shifts.id AS shift_id, shifts.event_id, events.name AS event_name, shifts.role, shifts.start_time, shifts.end_time,
+			shifts.min_headcount, shifts.max_headcount, COUNT(shift_members.id) AS signed_up`).
+		Joins("JOIN events ON events.id = shifts.event_id").
+		Joins("LEFT JOIN shift_members ON shift_members.shift_id = shifts.id").
+		Where("shifts.club_id = ? AND shifts.start_time > ? AND shifts.start_time <= ?", c.ID, now, now.AddDate(0, 0, days)).
+		Group("shifts.id, shifts.event_id, events.name, shifts.role, shifts.start_time, shifts.end_time, shifts.min_headcount, shifts.max_headcount").
+		Having("COUNT(shift_members.id) < shifts.min_headcount").
+		Order("shifts.start_time ASC").
+		Scan(&openShifts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range openShifts {
+		openShifts[i].Missing = openShifts[i].MinHeadcount - openShifts[i].SignedUp
+	}
+
+	return openShifts, nil
+}
+
 // ODataBeforeReadCollection filters shifts to only those in clubs the user belongs to
 func (s Shift) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
@@ -88,7 +241,7 @@ func (s Shift) ODataBeforeReadCollection(ctx context.Context, r *http.Request, o
 	// User can only see shifts of clubs they belong to
 	// Also filter out shifts from clubs where shifts feature is disabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -104,7 +257,7 @@ func (s Shift) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts
 	// User can only see shifts of clubs they belong to
 	// Also check that shifts feature is enabled for the club
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -133,12 +286,19 @@ func (s *Shift) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: event does not belong to the specified club")
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", s.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the shifts.manage permission in the club
+	if !HasPermission(s.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can create shifts")
 	}
 
+	if err := s.validateCapacity(); err != nil {
+		return err
+	}
+
+	if _, err := validateVenue(s.VenueID, s.ClubID); err != nil {
+		return err
+	}
+
 	// Set CreatedBy and UpdatedBy
 	now := time.Now()
 	s.CreatedAt = now
@@ -183,12 +343,15 @@ func (s *Shift) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: event does not belong to the specified club")
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", existingShift.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the shifts.manage permission in the club
+	if !HasPermission(existingShift.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update shifts")
 	}
 
+	if _, err := validateVenue(s.VenueID, existingShift.ClubID); err != nil {
+		return err
+	}
+
 	// Set UpdatedBy
 	now := time.Now()
 	s.UpdatedAt = now
@@ -209,9 +372,8 @@ func (s *Shift) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", s.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the shifts.manage permission in the club
+	if !HasPermission(s.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete shifts")
 	}
 
@@ -228,7 +390,7 @@ func (sm ShiftMember) ODataBeforeReadCollection(ctx context.Context, r *http.Req
 
 	// User can only see shift members of clubs they belong to and where shifts feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("shift_id IN (SELECT id FROM shifts WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true))", userID)
+		return db.Where("shift_id IN (SELECT id FROM shifts WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true))", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -243,7 +405,7 @@ func (sm ShiftMember) ODataBeforeReadEntity(ctx context.Context, r *http.Request
 
 	// User can only see shift members of clubs they belong to and where shifts feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("shift_id IN (SELECT id FROM shifts WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true))", userID)
+		return db.Where("shift_id IN (SELECT id FROM shifts WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true))", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -267,9 +429,8 @@ func (sm *ShiftMember) ODataBeforeCreate(ctx context.Context, r *http.Request) e
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", shift.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the shifts.manage permission in the club
+	if !HasPermission(shift.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can add shift members")
 	}
 
@@ -301,9 +462,8 @@ func (sm *ShiftMember) ODataBeforeUpdate(ctx context.Context, r *http.Request) e
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", shift.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user holds the shifts.manage permission in the club
+	if !HasPermission(shift.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can update shift members")
 	}
 
@@ -338,8 +498,7 @@ func (sm *ShiftMember) ODataBeforeDelete(ctx context.Context, r *http.Request) e
 		return nil
 	}
 
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", shift.ClubID, userID).First(&existingMember).Error; err != nil {
+	if !HasPermission(shift.ClubID, userID, PermissionShiftsManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can remove shift members")
 	}
 
@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftSignUp(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "shift-signup-owner@example.com")
+	anna, _ := handlers.CreateTestUser(t, "shift-signup-anna@example.com")
+	ben, _ := handlers.CreateTestUser(t, "shift-signup-ben@example.com")
+	outsider, _ := handlers.CreateTestUser(t, "shift-signup-outsider@example.com")
+	club := handlers.CreateTestClub(t, owner, "Shift Sign-up Club")
+	handlers.CreateTestMember(t, anna, club, "member")
+	handlers.CreateTestMember(t, ben, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	start := time.Now().Add(72 * time.Hour)
+	event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Summer fair", StartTime: start, EndTime: start.Add(6 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&event).Error)
+
+	role := "bar"
+	bar := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: event.ID, StartTime: start, EndTime: start.Add(2 * time.Hour), Role: &role, MinHeadcount: 2, MaxHeadcount: 2, WithdrawalCutoffHours: 24, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&bar).Error)
+
+	t.Run("capacity is validated on create", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		invalid := models.Shift{ClubID: club.ID, EventID: event.ID, StartTime: start, EndTime: start.Add(time.Hour), MinHeadcount: 3, MaxHeadcount: 2}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+	})
+
+	t.Run("shifts below minimum headcount need people", func(t *testing.T) {
+		openShifts, err := club.GetShiftsNeedingPeople(7)
+		require.NoError(t, err)
+		require.Len(t, openShifts, 1)
+		assert.Equal(t, bar.ID, openShifts[0].ShiftID)
+		assert.Equal(t, "Summer fair", openShifts[0].EventName)
+		assert.Equal(t, 2, openShifts[0].Missing)
+
+		openShifts, err = club.GetShiftsNeedingPeople(1)
+		require.NoError(t, err)
+		assert.Empty(t, openShifts, "shift starts after the window")
+	})
+
+	t.Run("members sign up until the shift is full", func(t *testing.T) {
+		_, err := bar.SignUp(outsider.ID)
+		assert.Error(t, err)
+
+		shiftMember, err := bar.SignUp(anna.ID)
+		require.NoError(t, err)
+		assert.Equal(t, anna.ID, shiftMember.UserID)
+		_, err = bar.SignUp(anna.ID)
+		assert.Error(t, err, "no double sign-up")
+
+		_, err = bar.SignUp(ben.ID)
+		require.NoError(t, err)
+		_, err = bar.SignUp(owner.ID)
+		assert.ErrorIs(t, err, models.ErrShiftFull)
+
+		openShifts, err := club.GetShiftsNeedingPeople(7)
+		require.NoError(t, err)
+		assert.Empty(t, openShifts)
+	})
+
+	t.Run("withdrawal respects the cutoff", func(t *testing.T) {
+		require.NoError(t, bar.Withdraw(ben.ID))
+		assert.Error(t, bar.Withdraw(ben.ID), "not signed up anymore")
+
+		bar.WithdrawalCutoffHours = 96
+		assert.ErrorIs(t, bar.Withdraw(anna.ID), models.ErrShiftWithdrawalClosed)
+	})
+
+	t.Run("started shifts are closed for sign-up", func(t *testing.T) {
+		past := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: event.ID, StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&past).Error)
+		_, err := past.SignUp(ben.ID)
+		assert.ErrorIs(t, err, models.ErrShiftStarted)
+	})
+}
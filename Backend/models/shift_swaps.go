@@ -0,0 +1,399 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shift swap request states
+const (
+	ShiftSwapStatusOpen            = "open"             // Offered, waiting for a claim
+	ShiftSwapStatusTradeProposed   = "trade_proposed"   // Claimed with a slot in exchange, waiting for the requester
+	ShiftSwapStatusPendingApproval = "pending_approval" // Agreed, waiting for an admin
+	ShiftSwapStatusCompleted       = "completed"
+	ShiftSwapStatusRejected        = "rejected"
+	ShiftSwapStatusCancelled       = "cancelled"
+)
+
+// shiftSwapTradeDeclined is the notification sent when a requester declines a trade
+const shiftSwapTradeDeclined = "trade_declined"
+
+var ErrShiftSwapNotOpen = errors.New("swap request is no longer open")
+
+// ShiftSwapRequest is a member's offer to hand over their shift slot, either
+// as a cover by another member or as a trade for one of their slots
+type ShiftSwapRequest struct {
+	ID                 string     `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID             string     `json:"ClubID" gorm:"type:uuid;not null;index"`
+	ShiftID            string     `json:"ShiftID" gorm:"type:uuid;not null;index"`
+	ShiftMemberID      string     `json:"ShiftMemberID" gorm:"type:uuid;not null;index"`
+	RequestedBy        string     `json:"RequestedBy" gorm:"type:uuid;not null"`
+	Note               *string    `json:"Note,omitempty"`
+	Status             string     `json:"Status" gorm:"not null;default:'open'"`
+	ClaimedBy          *string    `json:"ClaimedBy,omitempty" gorm:"type:uuid"`
+	TradeShiftMemberID *string    `json:"TradeShiftMemberID,omitempty" gorm:"type:uuid"` // Slot offered in exchange
+	ResolvedBy         *string    `json:"ResolvedBy,omitempty" gorm:"type:uuid"`
+	ResolvedAt         *time.Time `json:"ResolvedAt,omitempty"`
+	CreatedAt          time.Time  `json:"CreatedAt"`
+	UpdatedAt          time.Time  `json:"UpdatedAt"`
+
+	// Navigation properties for OData expansions
+	Shift            *Shift       `gorm:"foreignKey:ShiftID" json:"Shift,omitempty" odata:"nav"`
+	ShiftMember      *ShiftMember `gorm:"foreignKey:ShiftMemberID" json:"ShiftMember,omitempty" odata:"nav"`
+	RequestedByUser  *User        `gorm:"foreignKey:RequestedBy" json:"RequestedByUser,omitempty" odata:"nav"`
+	ClaimedByUser    *User        `gorm:"foreignKey:ClaimedBy" json:"ClaimedByUser,omitempty" odata:"nav"`
+	TradeShiftMember *ShiftMember `gorm:"foreignKey:TradeShiftMemberID" json:"TradeShiftMember,omitempty" odata:"nav"`
+}
+
+// BeforeCreate generates UUID for new swap requests
+func (ssr *ShiftSwapRequest) BeforeCreate(tx *gorm.DB) error {
+	if ssr.ID == "" {
+		ssr.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// OfferSwap offers the slot to other members of the club
+func (sm *ShiftMember) OfferSwap(userID, note string) (ShiftSwapRequest, error) {
+	if sm.UserID != userID {
+		return ShiftSwapRequest{}, fmt.Errorf("unauthorized: only the assigned member can offer their slot")
+	}
+
+	var shift Shift
+	if err := database.Db.Where("id = ?", sm.ShiftID).First(&shift).Error; err != nil {
+		return ShiftSwapRequest{}, fmt.Errorf("shift not found")
+	}
+	if !time.Now().Before(shift.StartTime) {
+		return ShiftSwapRequest{}, ErrShiftStarted
+	}
+
+	var pending int64
+	if err := database.Db.Model(&ShiftSwapRequest{}).
+		Where("shift_member_id = ? AND status IN ?", sm.ID, []string{ShiftSwapStatusOpen, ShiftSwapStatusTradeProposed, ShiftSwapStatusPendingApproval}).
+		Count(&pending).Error; err != nil {
+		return ShiftSwapRequest{}, err
+	}
+	if pending > 0 {
+		return ShiftSwapRequest{}, fmt.Errorf("this slot is already offered")
+	}
+
+	request := ShiftSwapRequest{
+		ClubID:        shift.ClubID,
+		ShiftID:       shift.ID,
+		ShiftMemberID: sm.ID,
+		RequestedBy:   userID,
+		Status:        ShiftSwapStatusOpen,
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		request.Note = &note
+	}
+	if err := database.Db.Create(&request).Error; err != nil {
+		return ShiftSwapRequest{}, err
+	}
+
+	_ = SendShiftSwapNotifications(request, shift, request.Status)
+	return request, nil
+}
+
+// Claim takes over the offered slot. With a trade slot the claimant offers one
+// of their own slots in exchange, which the requester has to accept first.
+func (ssr *ShiftSwapRequest) Claim(userID string, tradeShiftMemberID *string) error {
+	if ssr.Status != ShiftSwapStatusOpen {
+		return ErrShiftSwapNotOpen
+	}
+	if ssr.RequestedBy == userID {
+		return fmt.Errorf("you cannot claim your own slot")
+	}
+
+	var member Member
+	if err := database.Db.Where("club_id = ? AND user_id = ?", ssr.ClubID, userID).First(&member).Error; err != nil {
+		return fmt.Errorf("unauthorized: user is not a member of the club")
+	}
+
+	var shift Shift
+	if err := database.Db.Where("id = ?", ssr.ShiftID).First(&shift).Error; err != nil {
+		return fmt.Errorf("shift not found")
+	}
+	if !time.Now().Before(shift.StartTime) {
+		return ErrShiftStarted
+	}
+	if absent, err := IsAbsent(ssr.ClubID, userID, shift.StartTime, shift.EndTime); err != nil {
+		return err
+	} else if absent {
+		return ErrMemberAbsent
+	}
+
+	var onShift int64
+	database.Db.Model(&ShiftMember{}).Where("shift_id = ? AND user_id = ?", ssr.ShiftID, userID).Count(&onShift)
+	if onShift > 0 {
+		return fmt.Errorf("you are already signed up for this shift")
+	}
+
+	status := ShiftSwapStatusPendingApproval
+	if tradeShiftMemberID != nil {
+		var trade ShiftMember
+		if err := database.Db.Where("id = ? AND user_id = ?", *tradeShiftMemberID, userID).First(&trade).Error; err != nil {
+			return fmt.Errorf("trade slot not found")
+		}
+		var tradeShift Shift
+		if err := database.Db.Where("id = ? AND club_id = ?", trade.ShiftID, ssr.ClubID).First(&tradeShift).Error; err != nil {
+			return fmt.Errorf("trade slot must belong to a shift of the same club")
+		}
+		if !time.Now().Before(tradeShift.StartTime) {
+			return ErrShiftStarted
+		}
+		database.Db.Model(&ShiftMember{}).Where("shift_id = ? AND user_id = ?", trade.ShiftID, ssr.RequestedBy).Count(&onShift)
+		if onShift > 0 {
+			return fmt.Errorf("the requester is already signed up for the trade shift")
+		}
+		status = ShiftSwapStatusTradeProposed
+	}
+
+	if err := ssr.update(ShiftSwapStatusOpen, map[string]interface{}{
+		"status":                status,
+		"claimed_by":            userID,
+		"trade_shift_member_id": tradeShiftMemberID,
+	}); err != nil {
+		return err
+	}
+	ssr.ClaimedBy = &userID
+	ssr.TradeShiftMemberID = tradeShiftMemberID
+
+	if status == ShiftSwapStatusPendingApproval && !shift.SwapRequiresApproval {
+		return ssr.complete(userID)
+	}
+
+	_ = SendShiftSwapNotifications(*ssr, shift, ssr.Status)
+	return nil
+}
+
+// RespondToTrade accepts or declines a proposed trade on behalf of the requester
+func (ssr *ShiftSwapRequest) RespondToTrade(userID string, accept bool) error {
+	if ssr.Status != ShiftSwapStatusTradeProposed {
+		return fmt.Errorf("no trade has been proposed")
+	}
+	if ssr.RequestedBy != userID {
+		return fmt.Errorf("unauthorized: only the requester can respond to a trade")
+	}
+
+	if !accept {
+		declined := *ssr
+		if err := ssr.update(ShiftSwapStatusTradeProposed, map[string]interface{}{
+			"status":                ShiftSwapStatusOpen,
+			"claimed_by":            nil,
+			"trade_shift_member_id": nil,
+		}); err != nil {
+			return err
+		}
+		ssr.ClaimedBy = nil
+		ssr.TradeShiftMemberID = nil
+
+		var shift Shift
+		if err := database.Db.Where("id = ?", ssr.ShiftID).First(&shift).Error; err == nil {
+			_ = SendShiftSwapNotifications(declined, shift, shiftSwapTradeDeclined)
+		}
+		return nil
+	}
+
+	var shift Shift
+	if err := database.Db.Where("id = ?", ssr.ShiftID).First(&shift).Error; err != nil {
+		return fmt.Errorf("shift not found")
+	}
+	if !shift.SwapRequiresApproval {
+		return ssr.complete(userID)
+	}
+
+	if err := ssr.update(ShiftSwapStatusTradeProposed, map[string]interface{}{"status": ShiftSwapStatusPendingApproval}); err != nil {
+		return err
+	}
+	_ = SendShiftSwapNotifications(*ssr, shift, ssr.Status)
+	return nil
+}
+
+// Approve completes an agreed swap on behalf of a club admin
+func (ssr *ShiftSwapRequest) Approve(adminID string) error {
+	if ssr.Status != ShiftSwapStatusPendingApproval {
+		return fmt.Errorf("swap request is not waiting for approval")
+	}
+	return ssr.complete(adminID)
+}
+
+// Reject declines an agreed swap on behalf of a club admin
+func (ssr *ShiftSwapRequest) Reject(adminID string) error {
+	if ssr.Status != ShiftSwapStatusPendingApproval {
+		return fmt.Errorf("swap request is not waiting for approval")
+	}
+	return ssr.close(ShiftSwapStatusPendingApproval, ShiftSwapStatusRejected, adminID)
+}
+
+// Cancel withdraws the offer before it is completed
+func (ssr *ShiftSwapRequest) Cancel(userID string) error {
+	if ssr.RequestedBy != userID {
+		return fmt.Errorf("unauthorized: only the requester can cancel a swap request")
+	}
+	switch ssr.Status {
+	case ShiftSwapStatusOpen, ShiftSwapStatusTradeProposed, ShiftSwapStatusPendingApproval:
+		return ssr.close(ssr.Status, ShiftSwapStatusCancelled, userID)
+	default:
+		return ErrShiftSwapNotOpen
+	}
+}
+
+// update changes the request if it is still in the expected state
+func (ssr *ShiftSwapRequest) update(from string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	result := database.Db.Model(&ShiftSwapRequest{}).Where("id = ? AND status = ?", ssr.ID, from).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShiftSwapNotOpen
+	}
+	if status, ok := updates["status"].(string); ok {
+		ssr.Status = status
+	}
+	return nil
+}
+
+// close ends the request without changing the roster
+func (ssr *ShiftSwapRequest) close(from, status, by string) error {
+	now := time.Now()
+	if err := ssr.update(from, map[string]interface{}{
+		"status":      status,
+		"resolved_by": by,
+		"resolved_at": now,
+	}); err != nil {
+		return err
+	}
+	ssr.ResolvedBy = &by
+	ssr.ResolvedAt = &now
+
+	var shift Shift
+	if err := database.Db.Where("id = ?", ssr.ShiftID).First(&shift).Error; err == nil {
+		_ = SendShiftSwapNotifications(*ssr, shift, ssr.Status)
+	}
+	return nil
+}
+
+// complete hands the slot over, and for trades the claimant's slot back, in one transaction
+func (ssr *ShiftSwapRequest) complete(by string) error {
+	if ssr.ClaimedBy == nil {
+		return fmt.Errorf("swap request has not been claimed")
+	}
+	claimant := *ssr.ClaimedBy
+	from := ssr.Status
+	now := time.Now()
+
+	err := database.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ShiftSwapRequest{}).Where("id = ? AND status = ?", ssr.ID, from).Updates(map[string]interface{}{
+			"status":      ShiftSwapStatusCompleted,
+			"resolved_by": by,
+			"resolved_at": now,
+			"updated_at":  now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrShiftSwapNotOpen
+		}
+
+		// The roster must not have changed since the slot was offered
+		result = tx.Model(&ShiftMember{}).Where("id = ? AND user_id = ?", ssr.ShiftMemberID, ssr.RequestedBy).
+			Updates(map[string]interface{}{"user_id": claimant, "updated_at": now, "updated_by": by})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("the offered slot is no longer assigned to the requester")
+		}
+
+		if ssr.TradeShiftMemberID != nil {
+			result = tx.Model(&ShiftMember{}).Where("id = ? AND user_id = ?", *ssr.TradeShiftMemberID, claimant).
+				Updates(map[string]interface{}{"user_id": ssr.RequestedBy, "updated_at": now, "updated_by": by})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("the trade slot is no longer assigned to the claimant")
+			}
+		}
+
+		// Nobody may end up twice on the same shift
+		var count int64
+		if err := tx.Model(&ShiftMember{}).Where("shift_id = ? AND user_id = ?", ssr.ShiftID, claimant).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 1 {
+			return fmt.Errorf("the claimant is already signed up for this shift")
+		}
+		if ssr.TradeShiftMemberID != nil {
+			if err := tx.Model(&ShiftMember{}).
+				Where("shift_id = (SELECT shift_id FROM shift_members WHERE id = ?) AND user_id = ?", *ssr.TradeShiftMemberID, ssr.RequestedBy).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 1 {
+				return fmt.Errorf("the requester is already signed up for the trade shift")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ssr.Status = ShiftSwapStatusCompleted
+	ssr.ResolvedBy = &by
+	ssr.ResolvedAt = &now
+
+	var shift Shift
+	if err := database.Db.Where("id = ?", ssr.ShiftID).First(&shift).Error; err == nil {
+		_ = SendShiftSwapNotifications(*ssr, shift, ssr.Status)
+	}
+	return nil
+}
+
+// ODataBeforeReadCollection shows swap requests to members of clubs with shifts enabled
+func (ssr ShiftSwapRequest) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// Open offers are visible to all members so they can be claimed
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific swap request
+func (ssr ShiftSwapRequest) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return ssr.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate prevents creating swap requests directly; use the OfferSwap action
+func (ssr *ShiftSwapRequest) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: swap requests are created with the OfferSwap action")
+}
+
+// ODataBeforeUpdate prevents modifying swap requests directly; use the swap actions
+func (ssr *ShiftSwapRequest) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: swap requests are changed with the Claim, RespondToTrade, Approve, Reject and Cancel actions")
+}
+
+// ODataBeforeDelete prevents deleting swap requests; use the Cancel action
+func (ssr *ShiftSwapRequest) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	return fmt.Errorf("forbidden: swap requests are withdrawn with the Cancel action")
+}
@@ -0,0 +1,134 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftSwapRequests(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "swap-owner@example.com")
+	anna, _ := handlers.CreateTestUser(t, "swap-anna@example.com")
+	ben, _ := handlers.CreateTestUser(t, "swap-ben@example.com")
+	clara, _ := handlers.CreateTestUser(t, "swap-clara@example.com")
+	club := handlers.CreateTestClub(t, owner, "Swap Club")
+	handlers.CreateTestMember(t, anna, club, "member")
+	handlers.CreateTestMember(t, ben, club, "member")
+	handlers.CreateTestMember(t, clara, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	start := time.Now().Add(48 * time.Hour)
+	event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Tournament", StartTime: start, EndTime: start.Add(8 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&event).Error)
+
+	newShift := func(offset time.Duration, approval bool) models.Shift {
+		shift := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: event.ID, StartTime: start.Add(offset), EndTime: start.Add(offset + 2*time.Hour), SwapRequiresApproval: approval, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shift).Error)
+		return shift
+	}
+	assign := func(shift models.Shift, user models.User) models.ShiftMember {
+		shiftMember := models.ShiftMember{ID: uuid.New().String(), ShiftID: shift.ID, UserID: user.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shiftMember).Error)
+		return shiftMember
+	}
+	assignedTo := func(shiftMemberID string) string {
+		var shiftMember models.ShiftMember
+		require.NoError(t, db.Where("id = ?", shiftMemberID).First(&shiftMember).Error)
+		return shiftMember.UserID
+	}
+
+	t.Run("a cover is completed immediately without approval", func(t *testing.T) {
+		shift := newShift(0, false)
+		slot := assign(shift, anna)
+
+		_, err := slot.OfferSwap(ben.ID, "")
+		assert.Error(t, err, "only the assigned member can offer the slot")
+
+		request, err := slot.OfferSwap(anna.ID, "Family visit")
+		require.NoError(t, err)
+		_, err = slot.OfferSwap(anna.ID, "")
+		assert.Error(t, err, "slot is already offered")
+
+		var offered int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", ben.ID, "shift_swap").Count(&offered)
+		assert.Equal(t, int64(1), offered)
+
+		assert.Error(t, request.Claim(anna.ID, nil))
+		require.NoError(t, request.Claim(ben.ID, nil))
+		assert.Equal(t, models.ShiftSwapStatusCompleted, request.Status)
+		assert.Equal(t, ben.ID, assignedTo(slot.ID))
+
+		assert.ErrorIs(t, request.Claim(clara.ID, nil), models.ErrShiftSwapNotOpen)
+	})
+
+	t.Run("trades need the requester and an admin", func(t *testing.T) {
+		bar := newShift(time.Hour, true)
+		grill := newShift(3*time.Hour, false)
+		annaSlot := assign(bar, anna)
+		claraSlot := assign(grill, clara)
+
+		request, err := annaSlot.OfferSwap(anna.ID, "")
+		require.NoError(t, err)
+
+		require.NoError(t, request.Claim(clara.ID, &claraSlot.ID))
+		assert.Equal(t, models.ShiftSwapStatusTradeProposed, request.Status)
+
+		assert.Error(t, request.RespondToTrade(clara.ID, true), "only the requester responds")
+		require.NoError(t, request.RespondToTrade(anna.ID, true))
+		assert.Equal(t, models.ShiftSwapStatusPendingApproval, request.Status)
+		assert.Equal(t, anna.ID, assignedTo(annaSlot.ID), "roster unchanged until approved")
+
+		var approvals int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND title = ?", owner.ID, "Shift swap awaiting approval").Count(&approvals)
+		assert.Equal(t, int64(1), approvals)
+
+		require.NoError(t, request.Approve(owner.ID))
+		assert.Equal(t, clara.ID, assignedTo(annaSlot.ID))
+		assert.Equal(t, anna.ID, assignedTo(claraSlot.ID))
+
+		var completed int64
+		db.Model(&models.Notification{}).Where("title = ? AND user_id = ?", "Shift swap completed", clara.ID).Count(&completed)
+		assert.Equal(t, int64(1), completed)
+	})
+
+	t.Run("declined trades reopen the offer", func(t *testing.T) {
+		shift := newShift(5*time.Hour, false)
+		other := newShift(6*time.Hour, false)
+		slot := assign(shift, anna)
+		benSlot := assign(other, ben)
+
+		request, err := slot.OfferSwap(anna.ID, "")
+		require.NoError(t, err)
+		require.NoError(t, request.Claim(ben.ID, &benSlot.ID))
+		require.NoError(t, request.RespondToTrade(anna.ID, false))
+		assert.Equal(t, models.ShiftSwapStatusOpen, request.Status)
+		assert.Nil(t, request.ClaimedBy)
+
+		require.NoError(t, request.Cancel(anna.ID))
+		assert.Equal(t, models.ShiftSwapStatusCancelled, request.Status)
+		assert.Equal(t, anna.ID, assignedTo(slot.ID))
+	})
+
+	t.Run("rejected swaps keep the roster", func(t *testing.T) {
+		shift := newShift(7*time.Hour, true)
+		slot := assign(shift, ben)
+
+		request, err := slot.OfferSwap(ben.ID, "")
+		require.NoError(t, err)
+		require.NoError(t, request.Claim(clara.ID, nil))
+		assert.Equal(t, models.ShiftSwapStatusPendingApproval, request.Status)
+
+		require.NoError(t, request.Reject(owner.ID))
+		assert.Equal(t, models.ShiftSwapStatusRejected, request.Status)
+		assert.Equal(t, ben.ID, assignedTo(slot.ID))
+	})
+}
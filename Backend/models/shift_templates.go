@@ -0,0 +1,355 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShiftTemplate describes a reusable shift plan, e.g. for a tournament day
+type ShiftTemplate struct {
+	ID          string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID      string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Name        string    `json:"Name" gorm:"not null" odata:"required"`
+	Description *string   `json:"Description,omitempty" odata:"nullable"`
+	CreatedAt   time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy   string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt   time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy   string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+
+	// Navigation properties for OData expansions
+	Slots []ShiftTemplateSlot `gorm:"foreignKey:TemplateID" json:"Slots,omitempty" odata:"nav"`
+}
+
+// ShiftTemplateSlot is one role and time block of a template. Times are
+// relative to the start of the event the template is applied to.
+type ShiftTemplateSlot struct {
+	ID                 string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	TemplateID         string    `json:"TemplateID" gorm:"type:uuid;not null;index" odata:"required"`
+	Role               string    `json:"Role" gorm:"not null" odata:"required"` // e.g. "bar", "grill", "cashier"
+	Headcount          int       `json:"Headcount" gorm:"default:1"`
+	StartOffsetMinutes int       `json:"StartOffsetMinutes"` // May be negative for set-up before the event
+	DurationMinutes    int       `json:"DurationMinutes" odata:"required"`
+	CreatedAt          time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy          string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt          time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy          string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+}
+
+// BeforeCreate generates UUID for new shift templates
+func (st *ShiftTemplate) BeforeCreate(tx *gorm.DB) error {
+	if st.ID == "" {
+		st.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate generates UUID for new shift template slots
+func (sts *ShiftTemplateSlot) BeforeCreate(tx *gorm.DB) error {
+	if sts.ID == "" {
+		sts.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// isShiftAdmin checks whether the user holds the shifts.manage permission in the club
+func isShiftAdmin(clubID, userID string) bool {
+	return HasPermission(clubID, userID, PermissionShiftsManage)
+}
+
+func (sts *ShiftTemplateSlot) validate() error {
+	sts.Role = strings.TrimSpace(sts.Role)
+	if sts.Role == "" {
+		return fmt.Errorf("role is required")
+	}
+	if sts.Headcount < 1 {
+		return fmt.Errorf("headcount must be at least 1")
+	}
+	if sts.DurationMinutes <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	return nil
+}
+
+// seriesEvents returns the event and, if requested, all later occurrences of its recurring series
+func (e *Event) seriesEvents(includeOccurrences bool) ([]Event, error) {
+	if !includeOccurrences || (!e.IsRecurring && e.ParentEventID == nil) {
+		return []Event{*e}, nil
+	}
+
+	rootID := e.ID
+	if e.ParentEventID != nil {
+		rootID = *e.ParentEventID
+	}
+
+	var events []Event
+	err := database.Db.Where("(id = ? OR parent_event_id = ?) AND start_time >= ?", rootID, rootID, e.StartTime).
+		Order("start_time ASC").Find(&events).Error
+	return events, err
+}
+
+// ApplyShiftTemplate creates the shifts of a template for the event. With
+// includeOccurrences the template is also applied to all later occurrences of
+// a recurring event. Events the template was already applied to are skipped.
+func (e *Event) ApplyShiftTemplate(templateID string, includeOccurrences bool, createdBy string) ([]Shift, error) {
+	var template ShiftTemplate
+	if err := database.Db.Preload("Slots").Where("id = ? AND club_id = ?", templateID, e.ClubID).First(&template).Error; err != nil {
+		return nil, fmt.Errorf("shift template not found")
+	}
+	if len(template.Slots) == 0 {
+		return nil, fmt.Errorf("shift template has no slots")
+	}
+
+	events, err := e.seriesEvents(includeOccurrences)
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts []Shift
+	err = database.Db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			var applied int64
+			if err := tx.Model(&Shift{}).Where("event_id = ? AND shift_template_id = ?", event.ID, template.ID).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				continue
+			}
+
+			for _, slot := range template.Slots {
+				start := event.StartTime.Add(time.Duration(slot.StartOffsetMinutes) * time.Minute)
+				role := slot.Role
+				shift := Shift{
+					ID:              uuid.New().String(),
+					ClubID:          e.ClubID,
+					EventID:         event.ID,
+					StartTime:       start,
+					EndTime:         start.Add(time.Duration(slot.DurationMinutes) * time.Minute),
+					Role:            &role,
+					MinHeadcount:    slot.Headcount,
+					MaxHeadcount:    slot.Headcount,
+					ShiftTemplateID: &template.ID,
+					CreatedBy:       createdBy,
+					UpdatedBy:       createdBy,
+				}
+				if err := tx.Create(&shift).Error; err != nil {
+					return err
+				}
+				shifts = append(shifts, shift)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+// ODataBeforeReadCollection limits shift templates to admins of clubs with shifts enabled
+func (st ShiftTemplate) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN ("+permittedClubsQuery+") AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID, userID, PermissionShiftsManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific shift template
+func (st ShiftTemplate) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return st.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates shift template creation permissions
+func (st *ShiftTemplate) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if err := CheckFeatureEnabled(st.ClubID, "shifts"); err != nil {
+		return err
+	}
+
+	if !isShiftAdmin(st.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create shift templates")
+	}
+
+	if strings.TrimSpace(st.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	now := time.Now()
+	st.CreatedAt = now
+	st.UpdatedAt = now
+	st.CreatedBy = userID
+	st.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates shift template update permissions
+func (st *ShiftTemplate) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var existing ShiftTemplate
+	if err := database.Db.First(&existing, "id = ?", st.ID).Error; err != nil {
+		return fmt.Errorf("shift template not found")
+	}
+
+	// SECURITY: Prevent moving a template to another club
+	if st.ClubID != existing.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing shift template")
+	}
+
+	if !isShiftAdmin(existing.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update shift templates")
+	}
+
+	st.UpdatedAt = time.Now()
+	st.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates shift template deletion permissions and removes its slots
+func (st *ShiftTemplate) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isShiftAdmin(st.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete shift templates")
+	}
+
+	// Shifts generated from the template are kept
+	return database.Db.Where("template_id = ?", st.ID).Delete(&ShiftTemplateSlot{}).Error
+}
+
+// templateClubID resolves the club of the slot's template
+func (sts *ShiftTemplateSlot) templateClubID() (string, error) {
+	var template ShiftTemplate
+	if err := database.Db.Where("id = ?", sts.TemplateID).First(&template).Error; err != nil {
+		return "", fmt.Errorf("shift template not found")
+	}
+	return template.ClubID, nil
+}
+
+// ODataBeforeReadCollection limits template slots to admins of clubs with shifts enabled
+func (sts ShiftTemplateSlot) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("template_id IN (SELECT id FROM shift_templates WHERE club_id IN ("+permittedClubsQuery+") AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true))", userID, userID, PermissionShiftsManage)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific template slot
+func (sts ShiftTemplateSlot) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return sts.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates template slot creation permissions
+func (sts *ShiftTemplateSlot) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	clubID, err := sts.templateClubID()
+	if err != nil {
+		return err
+	}
+	if err := CheckFeatureEnabled(clubID, "shifts"); err != nil {
+		return err
+	}
+	if !isShiftAdmin(clubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can edit shift templates")
+	}
+
+	if sts.Headcount == 0 {
+		sts.Headcount = 1
+	}
+	if err := sts.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sts.CreatedAt = now
+	sts.UpdatedAt = now
+	sts.CreatedBy = userID
+	sts.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates template slot update permissions
+func (sts *ShiftTemplateSlot) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var existing ShiftTemplateSlot
+	if err := database.Db.First(&existing, "id = ?", sts.ID).Error; err != nil {
+		return fmt.Errorf("shift template slot not found")
+	}
+
+	// SECURITY: Prevent moving a slot to another template
+	if sts.TemplateID != existing.TemplateID {
+		return fmt.Errorf("forbidden: template cannot be changed for an existing slot")
+	}
+
+	clubID, err := existing.templateClubID()
+	if err != nil {
+		return err
+	}
+	if !isShiftAdmin(clubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can edit shift templates")
+	}
+
+	sts.UpdatedAt = time.Now()
+	sts.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates template slot deletion permissions
+func (sts *ShiftTemplateSlot) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	clubID, err := sts.templateClubID()
+	if err != nil {
+		return err
+	}
+	if !isShiftAdmin(clubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can edit shift templates")
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyShiftTemplate(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "shift-template-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "shift-template-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Shift Template Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	template := models.ShiftTemplate{ClubID: club.ID, Name: "Tournament day"}
+
+	t.Run("only admins manage templates", func(t *testing.T) {
+		ctx, req := authedRequest(member.ID)
+		assert.Error(t, template.ODataBeforeCreate(ctx, req))
+
+		ctx, req = authedRequest(owner.ID)
+		require.NoError(t, template.ODataBeforeCreate(ctx, req))
+		require.NoError(t, db.Create(&template).Error)
+	})
+
+	t.Run("slots are validated", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		invalid := models.ShiftTemplateSlot{TemplateID: template.ID, Role: " ", DurationMinutes: 60}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+		invalid = models.ShiftTemplateSlot{TemplateID: template.ID, Role: "bar", DurationMinutes: 0}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+
+		for _, slot := range []models.ShiftTemplateSlot{
+			{TemplateID: template.ID, Role: "bar", Headcount: 3, StartOffsetMinutes: -60, DurationMinutes: 180},
+			{TemplateID: template.ID, Role: "grill", StartOffsetMinutes: 120, DurationMinutes: 120},
+		} {
+			require.NoError(t, slot.ODataBeforeCreate(ctx, req))
+			require.NoError(t, db.Create(&slot).Error)
+		}
+	})
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Minute)
+	// Weekly home games: the first event is the series parent
+	var events []models.Event
+	for week := 0; week < 4; week++ {
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Home game", StartTime: start.AddDate(0, 0, 7*week), EndTime: start.AddDate(0, 0, 7*week).Add(4 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		if week == 0 {
+			event.IsRecurring = true
+		} else {
+			event.ParentEventID = &events[0].ID
+		}
+		require.NoError(t, db.Create(&event).Error)
+		events = append(events, event)
+	}
+
+	t.Run("template is applied to a single event", func(t *testing.T) {
+		shifts, err := events[0].ApplyShiftTemplate(template.ID, false, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, shifts, 2)
+
+		byRole := map[string]models.Shift{}
+		for _, shift := range shifts {
+			require.NotNil(t, shift.Role)
+			byRole[*shift.Role] = shift
+		}
+		assert.True(t, byRole["bar"].StartTime.Equal(start.Add(-time.Hour)))
+		assert.True(t, byRole["bar"].EndTime.Equal(start.Add(2*time.Hour)))
+		assert.Equal(t, 3, byRole["bar"].MinHeadcount)
+		assert.Equal(t, 3, byRole["bar"].MaxHeadcount)
+		assert.Equal(t, 1, byRole["grill"].MaxHeadcount)
+		assert.True(t, byRole["grill"].StartTime.Equal(start.Add(2*time.Hour)))
+	})
+
+	t.Run("later occurrences of a recurring event are included", func(t *testing.T) {
+		shifts, err := events[1].ApplyShiftTemplate(template.ID, true, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, shifts, 6, "occurrences two to four")
+
+		var count int64
+		db.Model(&models.Shift{}).Where("event_id = ?", events[3].ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("a template is applied only once per event", func(t *testing.T) {
+		shifts, err := events[0].ApplyShiftTemplate(template.ID, true, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, shifts)
+
+		var count int64
+		db.Model(&models.Shift{}).Where("club_id = ?", club.ID).Count(&count)
+		assert.Equal(t, int64(8), count)
+	})
+
+	t.Run("templates of other clubs cannot be applied", func(t *testing.T) {
+		otherClub := handlers.CreateTestClub(t, owner, "Other Club")
+		otherEvent := models.Event{ID: uuid.New().String(), ClubID: otherClub.ID, Name: "Other", StartTime: start, EndTime: start.Add(time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&otherEvent).Error)
+		_, err := otherEvent.ApplyShiftTemplate(template.ID, false, owner.ID)
+		assert.Error(t, err)
+	})
+}
@@ -69,12 +69,41 @@ func setupTeamSecurityTestDB(t *testing.T) {
 			created_by TEXT,
 			updated_at DATETIME,
 			updated_by TEXT,
+			status TEXT DEFAULT 'active',
+			status_since DATETIME,
 			FOREIGN KEY (club_id) REFERENCES clubs(id),
 			FOREIGN KEY (user_id) REFERENCES users(id)
 		);
+		CREATE TABLE IF NOT EXISTS membership_status_changes (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			role TEXT,
+			effective_from DATETIME NOT NULL,
+			effective_until DATETIME,
+			reason TEXT,
+			changed_by TEXT,
+			created_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS seasons (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			start_date DATETIME NOT NULL,
+			end_date DATETIME NOT NULL,
+			archived BOOLEAN DEFAULT FALSE,
+			archived_at DATETIME,
+			archived_by TEXT,
+			created_at DATETIME,
+			created_by TEXT,
+			updated_at DATETIME,
+			updated_by TEXT
+		);
 		CREATE TABLE IF NOT EXISTS teams (
 			id TEXT PRIMARY KEY,
 			club_id TEXT NOT NULL,
+			season_id TEXT,
 			name TEXT NOT NULL,
 			description TEXT,
 			created_at DATETIME,
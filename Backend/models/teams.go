@@ -20,6 +20,7 @@ var ErrLastTeamAdminDemotion = errors.New("cannot demote the last admin of the t
 type Team struct {
 	ID          string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
 	ClubID      string    `json:"ClubID" gorm:"type:uuid;not null" odata:"required"`
+	SeasonID    *string   `json:"SeasonID,omitempty" gorm:"type:uuid;index" odata:"nullable"` // Defaults to the club's open season
 	Name        string    `json:"Name" gorm:"not null" odata:"required"`
 	Description *string   `json:"Description,omitempty" odata:"nullable"`
 	CreatedAt   time.Time `json:"CreatedAt" odata:"auto,immutable"`
@@ -28,6 +29,7 @@ type Team struct {
 	UpdatedBy   string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
 
 	// Navigation properties for OData
+	Season      *Season      `gorm:"foreignKey:SeasonID" json:"Season,omitempty" odata:"nav"`
 	Events      []Event      `gorm:"foreignKey:TeamID" json:"Events,omitempty" odata:"nav"`
 	Fines       []Fine       `gorm:"foreignKey:TeamID" json:"Fines,omitempty" odata:"nav"`
 	TeamMembers []TeamMember `gorm:"foreignKey:TeamID" json:"TeamMembers,omitempty" odata:"nav"`
@@ -48,11 +50,14 @@ type TeamMember struct {
 	User *User `gorm:"foreignKey:UserID" json:"User,omitempty" odata:"nav"`
 }
 
-// BeforeCreate generates UUID for new team
+// BeforeCreate generates UUID for new team and adds it to the club's open season
 func (t *Team) BeforeCreate(tx *gorm.DB) (err error) {
 	if t.ID == "" {
 		t.ID = uuid.New().String()
 	}
+	if t.SeasonID == nil {
+		t.SeasonID, err = openSeason(tx, t.ClubID, time.Now())
+	}
 	return
 }
 
@@ -333,10 +338,17 @@ func (t *Team) CanUserDeleteTeam(user User) bool {
 	return club.IsAdmin(user)
 }
 
-// GetTeamStats returns statistics for the team
-func (t *Team) GetTeamStats() (map[string]interface{}, error) {
+// GetTeamStats returns statistics for the team. With a season ID, events and fines are limited to that season.
+func (t *Team) GetTeamStats(seasonID string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
+	inSeason := func(db *gorm.DB) *gorm.DB {
+		if seasonID == "" {
+			return db
+		}
+		return db.Where("season_id = ?", seasonID)
+	}
+
 	// Get member count
 	var memberCount int64
 	err := database.Db.Model(&TeamMember{}).Where("team_id = ?", t.ID).Count(&memberCount).Error
@@ -356,7 +368,7 @@ func (t *Team) GetTeamStats() (map[string]interface{}, error) {
 	// Get upcoming events count
 	var upcomingEventCount int64
 	now := time.Now()
-	err = database.Db.Model(&Event{}).Where("team_id = ? AND start_time >= ?", t.ID, now).Count(&upcomingEventCount).Error
+	err = database.Db.Model(&Event{}).Scopes(inSeason).Where("team_id = ? AND start_time >= ?", t.ID, now).Count(&upcomingEventCount).Error
 	if err != nil {
 		return nil, err
 	}
@@ -364,7 +376,7 @@ func (t *Team) GetTeamStats() (map[string]interface{}, error) {
 
 	// Get total events count
 	var totalEventCount int64
-	err = database.Db.Model(&Event{}).Where("team_id = ?", t.ID).Count(&totalEventCount).Error
+	err = database.Db.Model(&Event{}).Scopes(inSeason).Where("team_id = ?", t.ID).Count(&totalEventCount).Error
 	if err != nil {
 		return nil, err
 	}
@@ -372,7 +384,7 @@ func (t *Team) GetTeamStats() (map[string]interface{}, error) {
 
 	// Get unpaid fines count
 	var unpaidFineCount int64
-	err = database.Db.Model(&Fine{}).Where("team_id = ? AND paid = false", t.ID).Count(&unpaidFineCount).Error
+	err = database.Db.Model(&Fine{}).Scopes(inSeason).Where("team_id = ? AND paid = false", t.ID).Where(fineNotWaivedScope).Count(&unpaidFineCount).Error
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +392,7 @@ func (t *Team) GetTeamStats() (map[string]interface{}, error) {
 
 	// Get total fines count
 	var totalFineCount int64
-	err = database.Db.Model(&Fine{}).Where("team_id = ?", t.ID).Count(&totalFineCount).Error
+	err = database.Db.Model(&Fine{}).Scopes(inSeason).Where("team_id = ?", t.ID).Count(&totalFineCount).Error
 	if err != nil {
 		return nil, err
 	}
@@ -398,7 +410,7 @@ func (t Team) ODataBeforeReadCollection(ctx context.Context, r *http.Request, op
 
 	// User can only see teams of clubs they belong to and where teams feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -413,7 +425,7 @@ func (t Team) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts i
 
 	// User can only see teams of clubs they belong to and where teams feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true)", userID)
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true)", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -431,12 +443,20 @@ func (t *Team) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", t.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user may manage the club's members
+	if !HasPermission(t.ClubID, userID, PermissionMembersManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can create teams")
 	}
 
+	// SECURITY: If SeasonID is provided, verify it belongs to the specified ClubID
+	season, err := validateSeason(t.SeasonID, t.ClubID)
+	if err != nil {
+		return err
+	}
+	if season != nil && season.Archived {
+		return fmt.Errorf("cannot add teams to an archived season")
+	}
+
 	// Set CreatedBy and UpdatedBy
 	now := time.Now()
 	t.CreatedAt = now
@@ -472,6 +492,23 @@ func (t *Team) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("unauthorized: only club admins/owners and team admins can update teams")
 	}
 
+	updated, err := updatedEntity(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Prevent moving teams to another club
+	if updated.ClubID != t.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing team")
+	}
+
+	// SECURITY: If SeasonID is being updated, verify it belongs to the club and is still open
+	if !sameStringPtr(updated.SeasonID, t.SeasonID) {
+		if err := validateOpenSeason(updated.SeasonID, t.ClubID); err != nil {
+			return err
+		}
+	}
+
 	// Set UpdatedBy
 	now := time.Now()
 	t.UpdatedAt = now
@@ -492,9 +529,8 @@ func (t *Team) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return err
 	}
 
-	// Check if user is an admin/owner of the club
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", t.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user may manage the club's members
+	if !HasPermission(t.ClubID, userID, PermissionMembersManage) {
 		return fmt.Errorf("unauthorized: only admins and owners can delete teams")
 	}
 
@@ -511,7 +547,7 @@ func (tm TeamMember) ODataBeforeReadCollection(ctx context.Context, r *http.Requ
 
 	// User can only see team members of teams in clubs they belong to and where teams feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("team_id IN (SELECT id FROM teams WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true))", userID)
+		return db.Where("team_id IN (SELECT id FROM teams WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true))", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -526,7 +562,7 @@ func (tm TeamMember) ODataBeforeReadEntity(ctx context.Context, r *http.Request,
 
 	// User can only see team members of teams in clubs they belong to and where teams feature is enabled
 	scope := func(db *gorm.DB) *gorm.DB {
-		return db.Where("team_id IN (SELECT id FROM teams WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ?) AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true))", userID)
+		return db.Where("team_id IN (SELECT id FROM teams WHERE club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE teams_enabled = true))", userID)
 	}
 
 	return []func(*gorm.DB) *gorm.DB{scope}, nil
@@ -550,9 +586,8 @@ func (tm *TeamMember) ODataBeforeCreate(ctx context.Context, r *http.Request) er
 		return err
 	}
 
-	// Check if user is an admin/owner of the club or team admin
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", team.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user may manage the club's members or is team admin
+	if !HasPermission(team.ClubID, userID, PermissionMembersManage) {
 		// Check if user is team admin
 		var teamMember TeamMember
 		if err := database.Db.Where("team_id = ? AND user_id = ? AND role = 'admin'", tm.TeamID, userID).First(&teamMember).Error; err != nil {
@@ -618,9 +653,8 @@ func (tm *TeamMember) ODataBeforeUpdate(ctx context.Context, r *http.Request) er
 		}
 	} else {
 		// If role is not being changed, still validate general update permission
-		// Check if user is an admin/owner of the club or team admin
-		var existingMember Member
-		if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", team.ClubID, userID).First(&existingMember).Error; err != nil {
+		// Check if user may manage the club's members or is team admin
+		if !HasPermission(team.ClubID, userID, PermissionMembersManage) {
 			// Check if user is team admin
 			var teamMember TeamMember
 			if err := database.Db.Where("team_id = ? AND user_id = ? AND role = 'admin'", currentTeamMember.TeamID, userID).First(&teamMember).Error; err != nil {
@@ -660,9 +694,8 @@ func (tm *TeamMember) ODataBeforeDelete(ctx context.Context, r *http.Request) er
 		return nil
 	}
 
-	// Check if user is an admin/owner of the club or team admin
-	var existingMember Member
-	if err := database.Db.Where("club_id = ? AND user_id = ? AND role IN ('admin', 'owner')", team.ClubID, userID).First(&existingMember).Error; err != nil {
+	// Check if user may manage the club's members or is team admin
+	if !HasPermission(team.ClubID, userID, PermissionMembersManage) {
 		// Check if user is team admin
 		var teamMember TeamMember
 		if err := database.Db.Where("team_id = ? AND user_id = ? AND role = 'admin'", tm.TeamID, userID).First(&teamMember).Error; err != nil {
@@ -4,13 +4,13 @@ import (
 	"time"
 )
 
-// TimelineItem represents a unified timeline entry that can be an activity, event, or news item
+// TimelineItem represents a unified timeline entry that can be an activity, event, news item or poll
 // This is a virtual entity that aggregates data from multiple sources
 type TimelineItem struct {
 	ID        string    `json:"ID" odata:"key"`
 	ClubID    string    `json:"ClubID"`
 	ClubName  string    `json:"ClubName"`
-	Type      string    `json:"Type"` // "activity", "event", "news"
+	Type      string    `json:"Type"` // "activity", "event", "news", "poll"
 	Title     string    `json:"Title"`
 	Content   string    `json:"Content,omitempty"`
 	Timestamp time.Time `json:"Timestamp"` // Unified timestamp for sorting
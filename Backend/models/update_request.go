@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type updateBodyKey struct{}
+
+// WithUpdateBody stores the body of a PATCH or PUT request in the request context. go-odata calls
+// ODataBeforeUpdate with the stored entity and applies the request afterwards, so update hooks
+// need the body to validate the new values.
+func WithUpdateBody(ctx context.Context, body []byte) context.Context {
+	return context.WithValue(ctx, updateBodyKey{}, body)
+}
+
+// updatedEntity returns a copy of the stored entity with the values of the update request applied.
+// Without a request body, e.g. when a hook is called directly, the copy has the values of the entity.
+func updatedEntity[T any](ctx context.Context, entity *T) (*T, error) {
+	current, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, err
+	}
+
+	if body, ok := ctx.Value(updateBodyKey{}).([]byte); ok && len(body) > 0 {
+		var changes map[string]json.RawMessage
+		if err := json.Unmarshal(body, &changes); err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		for name, value := range changes {
+			fields[name] = value
+		}
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var updated T
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	return &updated, nil
+}
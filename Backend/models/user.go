@@ -256,9 +256,13 @@ func getUserVisibilityScope(userID string) func(*gorm.DB) *gorm.DB {
 		// User can only see:
 		// 1. Themselves
 		// 2. Users who are members of clubs they belong to
+		// 3. Their dependents and guardians
 		// Using JOIN for better performance than nested subqueries
 		return db.Where(
-			"id = ? OR id IN (SELECT DISTINCT m2.user_id FROM members m1 JOIN members m2 ON m1.club_id = m2.club_id WHERE m1.user_id = ?)",
+			"id = ? OR id IN (SELECT DISTINCT m2.user_id FROM members m1 JOIN members m2 ON m1.club_id = m2.club_id AND m2.status <> 'former' WHERE m1.user_id = ? AND m1.status <> 'former') OR "+
+				"id IN (SELECT dependent_id FROM guardianships WHERE guardian_id = ?) OR id IN (SELECT guardian_id FROM guardianships WHERE dependent_id = ?)",
+			userID,
+			userID,
 			userID,
 			userID,
 		)
@@ -294,15 +298,15 @@ func (u *User) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
 }
 
 // ODataBeforeUpdate validates user update permissions
-// Users can only update their own information
+// Users can only update their own information; guardians also update dependents without own account
 func (u *User) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
 	if !ok || userID == "" {
 		return fmt.Errorf("unauthorized: user ID not found in context")
 	}
 
-	// Users can only update their own profile
-	if u.ID != userID {
+	// Users can only update their own profile or the profile of a dependent they manage
+	if u.ID != userID && !(u.IsManagedDependent() && IsGuardianOf(userID, u.ID)) {
 		return fmt.Errorf("forbidden: can only update your own user profile")
 	}
 
@@ -337,7 +341,8 @@ func (u *User) ODataAfterUpdate(ctx context.Context, r *http.Request) error {
 }
 
 // ODataBeforeDelete validates user deletion permissions
-// Users can only delete their own account
+// Accounts are never deleted directly, as club history still references them. Users delete
+// their own account with the RequestAccountDeletion action, which anonymizes it after a grace period.
 func (u *User) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 	userID, ok := ctx.Value(auth.UserIDKey).(string)
 	if !ok || userID == "" {
@@ -349,7 +354,7 @@ func (u *User) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
 		return fmt.Errorf("forbidden: can only delete your own user account")
 	}
 
-	return nil
+	return fmt.Errorf("forbidden: use the RequestAccountDeletion action to delete your account")
 }
 
 func FindOrCreateUser(email string) (User, error) {
@@ -475,6 +480,11 @@ func (u *User) StoreRefreshToken(token, userAgent, ipAddress string) error {
 		return fmt.Errorf("failed to delete existing sessions for IP %s: %v", ipAddress, err)
 	}
 
+	// Signing in and refreshing the session count as activity
+	if err := TouchUserPresence(u.ID); err != nil {
+		log.Printf("failed to record presence of user %s: %v", u.ID, err)
+	}
+
 	refreshToken := RefreshToken{
 		UserID:    u.ID,
 		Token:     HashToken(token),
@@ -552,7 +562,7 @@ func (u *User) GetFines() ([]Fine, error) {
 
 func (u *User) GetUnpaidFines() ([]Fine, error) {
 	var fines []Fine
-	err := database.Db.Raw(`SELECT * FROM fines WHERE user_id = ? AND paid = FALSE`, u.ID).Scan(&fines).Error
+	err := database.Db.Raw(`SELECT * FROM fines WHERE user_id = ? AND paid = FALSE AND (`+fineNotWaivedScope+`)`, u.ID).Scan(&fines).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +52,28 @@ func setupUserAuthTestDB(t *testing.T) {
 			created_at DATETIME,
 			created_by TEXT,
 			updated_at DATETIME,
-			updated_by TEXT
+			updated_by TEXT,
+			status TEXT DEFAULT 'active',
+			status_since DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS membership_status_changes (
+			id TEXT PRIMARY KEY,
+			club_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			role TEXT,
+			effective_from DATETIME NOT NULL,
+			effective_until DATETIME,
+			reason TEXT,
+			changed_by TEXT,
+			created_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS guardianships (
+			id TEXT PRIMARY KEY,
+			guardian_id TEXT NOT NULL,
+			dependent_id TEXT NOT NULL,
+			created_at DATETIME,
+			created_by TEXT
 		);
 	`).Error
 	if err != nil {
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	odata "github.com/nlstn/go-odata"
+	"gorm.io/gorm"
+)
+
+var ErrVenueConflict = errors.New("venue is already booked at this time")
+
+// Venue is a pitch, hall or other facility of a club that events and shifts take place at
+type Venue struct {
+	ID           string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID       string    `json:"ClubID" gorm:"type:uuid;not null;index" odata:"required"`
+	Name         string    `json:"Name" gorm:"not null" odata:"required"`
+	Description  *string   `json:"Description,omitempty" odata:"nullable"`
+	Address      *string   `json:"Address,omitempty" odata:"nullable"`
+	Capacity     int       `json:"Capacity" gorm:"default:0"`         // Maximum number of people, 0 if unknown
+	AllowOverlap bool      `json:"AllowOverlap" gorm:"default:false"` // Shared facilities may host several events at once
+	CreatedAt    time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy    string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt    time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy    string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+}
+
+// VenueBooking is an event or shift occupying a venue, as returned by the occupancy calendar
+type VenueBooking struct {
+	Type         string    `json:"Type"` // "event" or "shift"
+	ID           string    `json:"ID"`
+	EventID      string    `json:"EventID"`
+	Name         string    `json:"Name"`
+	TeamID       *string   `json:"TeamID,omitempty"`
+	StartTime    time.Time `json:"StartTime"`
+	EndTime      time.Time `json:"EndTime"`
+	Attendees    int64     `json:"Attendees"`    // Yes RSVPs for events, assigned members for shifts
+	OverCapacity bool      `json:"OverCapacity"` // More attendees than the venue's capacity
+	Conflict     bool      `json:"Conflict"`     // Overlaps another booking of the venue
+}
+
+// BeforeCreate generates UUID for new venues
+func (v *Venue) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// isClubAdmin checks whether the user holds the events.manage permission in the venue's club
+func (v *Venue) isClubAdmin(userID string) bool {
+	return HasPermission(v.ClubID, userID, PermissionEventsManage)
+}
+
+// validate checks the venue
+func (v *Venue) validate() error {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if v.Capacity < 0 {
+		return fmt.Errorf("capacity must not be negative")
+	}
+	return nil
+}
+
+// validateVenue checks that the referenced venue belongs to the club
+func validateVenue(venueID *string, clubID string) (*Venue, error) {
+	if venueID == nil || *venueID == "" {
+		return nil, nil
+	}
+	var venue Venue
+	if err := database.Db.Where("id = ? AND club_id = ?", *venueID, clubID).First(&venue).Error; err != nil {
+		return nil, fmt.Errorf("unauthorized: venue does not belong to the specified club")
+	}
+	return &venue, nil
+}
+
+// checkVenueConflict rejects events that overlap another event at an exclusive venue
+func (e *Event) checkVenueConflict() error {
+	venue, err := validateVenue(e.VenueID, e.ClubID)
+	if err != nil || venue == nil || venue.AllowOverlap {
+		return err
+	}
+
+	var conflict Event
+	err = database.Db.Where("venue_id = ? AND id <> ? AND start_time < ? AND end_time > ?", venue.ID, e.ID, e.EndTime, e.StartTime).
+		Order("start_time ASC").
+		First(&conflict).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s is booked for %s from %s to %s", ErrVenueConflict, venue.Name, conflict.Name,
+		conflict.StartTime.Format("02.01.2006 15:04"), conflict.EndTime.Format("02.01.2006 15:04"))
+}
+
+// GetOccupancy returns the events and shifts at the venue in the given time range, ordered by start time.
+// Shifts are listed separately only if their event takes place elsewhere.
+func (v *Venue) GetOccupancy(from, to time.Time) ([]VenueBooking, error) {
+	var events []Event
+	if err := database.Db.Where("venue_id = ? AND start_time < ? AND end_time > ?", v.ID, to, from).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	var shifts []Shift
+	err := database.Db.Preload("Event").
+		Where("venue_id = ? AND start_time < ? AND end_time > ?", v.ID, to, from).
+		Where("event_id NOT IN (SELECT id FROM events WHERE venue_id = ?)", v.ID).
+		Find(&shifts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := make([]VenueBooking, 0, len(events)+len(shifts))
+	for _, event := range events {
+		booking := VenueBooking{Type: "event", ID: event.ID, EventID: event.ID, Name: event.Name, TeamID: event.TeamID, StartTime: event.StartTime, EndTime: event.EndTime}
+		if err := database.Db.Model(&EventRSVP{}).Where("event_id = ? AND response = ?", event.ID, "yes").Count(&booking.Attendees).Error; err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	for _, shift := range shifts {
+		booking := VenueBooking{Type: "shift", ID: shift.ID, EventID: shift.EventID, StartTime: shift.StartTime, EndTime: shift.EndTime}
+		if shift.Event != nil {
+			booking.Name = shift.Event.Name
+			booking.TeamID = shift.Event.TeamID
+		}
+		if shift.Role != nil && *shift.Role != "" {
+			booking.Name = *shift.Role + " (" + booking.Name + ")"
+		}
+		if err := database.Db.Model(&ShiftMember{}).Where("shift_id = ?", shift.ID).Count(&booking.Attendees).Error; err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	for i := range bookings {
+		bookings[i].OverCapacity = v.Capacity > 0 && bookings[i].Attendees > int64(v.Capacity)
+		for j := range bookings {
+			if i != j && bookings[i].StartTime.Before(bookings[j].EndTime) && bookings[j].StartTime.Before(bookings[i].EndTime) {
+				bookings[i].Conflict = true
+			}
+		}
+	}
+
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].StartTime.Before(bookings[j].StartTime)
+	})
+	return bookings, nil
+}
+
+// ODataBeforeReadCollection filters venues to clubs the user belongs to
+func (v Venue) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former')", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific venue
+func (v Venue) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return v.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates venue creation permissions
+func (v *Venue) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !v.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can create venues")
+	}
+
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	v.CreatedAt = now
+	v.CreatedBy = userID
+	v.UpdatedAt = now
+	v.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates venue update permissions
+func (v *Venue) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var existing Venue
+	if err := database.Db.Where("id = ?", v.ID).First(&existing).Error; err != nil {
+		return fmt.Errorf("venue not found")
+	}
+
+	// SECURITY: Prevent moving venues to another club
+	if v.ClubID != existing.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing venue")
+	}
+
+	if !existing.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can update venues")
+	}
+
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	v.UpdatedAt = time.Now()
+	v.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates venue deletion permissions and detaches events and shifts from the venue
+func (v *Venue) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !v.isClubAdmin(userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can delete venues")
+	}
+
+	tx, ok := odata.TransactionFromContext(ctx)
+	if !ok {
+		tx = database.Db
+	}
+	if err := tx.Model(&Event{}).Where("venue_id = ?", v.ID).Update("venue_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to detach events from venue: %w", err)
+	}
+	if err := tx.Model(&Shift{}).Where("venue_id = ?", v.ID).Update("venue_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to detach shifts from venue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package models_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVenueBookings(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, ownerToken := handlers.CreateTestUser(t, "venue-owner@example.com")
+	member, _ := handlers.CreateTestUser(t, "venue-member@example.com")
+	club := handlers.CreateTestClub(t, owner, "Venue Club")
+	otherClub := handlers.CreateTestClub(t, owner, "Other Venue Club")
+	handlers.CreateTestMember(t, member, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET events_enabled = true, shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	ownerCtx, ownerReq := authedRequest(owner.ID)
+
+	pitch := models.Venue{ClubID: club.ID, Name: "Main pitch", Capacity: 2}
+	require.NoError(t, pitch.ODataBeforeCreate(ownerCtx, ownerReq))
+	require.NoError(t, db.Create(&pitch).Error)
+	hall := models.Venue{ClubID: club.ID, Name: "Club house", AllowOverlap: true}
+	require.NoError(t, db.Create(&hall).Error)
+	foreign := models.Venue{ClubID: otherClub.ID, Name: "Foreign pitch"}
+	require.NoError(t, db.Create(&foreign).Error)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	createEvent := func(name string, venueID string, offset time.Duration) (models.Event, error) {
+		event := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: name, VenueID: &venueID, StartTime: start.Add(offset), EndTime: start.Add(offset + 2*time.Hour)}
+		if err := event.ODataBeforeCreate(ownerCtx, ownerReq); err != nil {
+			return event, err
+		}
+		return event, db.Create(&event).Error
+	}
+
+	t.Run("only admins manage venues", func(t *testing.T) {
+		ctx, req := authedRequest(member.ID)
+		venue := models.Venue{ClubID: club.ID, Name: "Side pitch"}
+		assert.Error(t, venue.ODataBeforeCreate(ctx, req))
+
+		invalid := models.Venue{ClubID: club.ID, Name: " "}
+		assert.Error(t, invalid.ODataBeforeCreate(ownerCtx, ownerReq))
+	})
+
+	t.Run("overlapping events at an exclusive venue are rejected", func(t *testing.T) {
+		training, err := createEvent("First team training", pitch.ID, 0)
+		require.NoError(t, err)
+
+		_, err = createEvent("Youth training", pitch.ID, time.Hour)
+		assert.ErrorIs(t, err, models.ErrVenueConflict)
+
+		_, err = createEvent("Late training", pitch.ID, 2*time.Hour)
+		assert.NoError(t, err, "back-to-back bookings do not overlap")
+
+		_, err = createEvent("Foreign venue", foreign.ID, 6*time.Hour)
+		assert.Error(t, err, "venue must belong to the club")
+
+		// Moving an event onto a booked slot is rejected as well, the event itself is ignored
+		meeting, err := createEvent("Coaches meeting", hall.ID, 30*time.Minute)
+		require.NoError(t, err)
+		resp := odataRequest(t, ownerToken, http.MethodPatch, "/Events("+meeting.ID+")", map[string]interface{}{"VenueID": pitch.ID})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), "is booked for First team training")
+		var stored models.Event
+		require.NoError(t, db.First(&stored, "id = ?", meeting.ID).Error)
+		require.NotNil(t, stored.VenueID)
+		assert.Equal(t, hall.ID, *stored.VenueID)
+		require.NoError(t, db.Delete(&stored).Error)
+
+		resp = odataRequest(t, ownerToken, http.MethodPatch, "/Events("+training.ID+")", map[string]interface{}{"Name": "First team practice"})
+		assert.Less(t, resp.Code, 300, resp.Body.String())
+		require.NoError(t, db.Model(&training).Update("name", "First team training").Error)
+	})
+
+	t.Run("shared venues allow overlaps", func(t *testing.T) {
+		_, err := createEvent("Board meeting", hall.ID, 0)
+		require.NoError(t, err)
+		party, err := createEvent("Party", hall.ID, time.Hour)
+		require.NoError(t, err)
+
+		bookings, err := hall.GetOccupancy(start.Add(-time.Hour), start.Add(24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, bookings, 2)
+		assert.True(t, bookings[0].Conflict)
+		assert.True(t, bookings[1].Conflict)
+		assert.Equal(t, party.ID, bookings[1].EventID)
+	})
+
+	t.Run("occupancy lists events and shifts at the venue", func(t *testing.T) {
+		meeting, err := createEvent("Members meeting", hall.ID, 8*time.Hour)
+		require.NoError(t, err)
+		shift := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: meeting.ID, VenueID: &pitch.ID, StartTime: meeting.StartTime, EndTime: meeting.EndTime, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shift).Error)
+		for _, user := range []models.User{owner, member} {
+			require.NoError(t, db.Create(&models.ShiftMember{ID: uuid.New().String(), ShiftID: shift.ID, UserID: user.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID}).Error)
+		}
+		extra, _ := handlers.CreateTestUser(t, "venue-extra@example.com")
+		require.NoError(t, db.Create(&models.ShiftMember{ID: uuid.New().String(), ShiftID: shift.ID, UserID: extra.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID}).Error)
+
+		bookings, err := pitch.GetOccupancy(start.Add(-time.Hour), start.Add(24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, bookings, 3)
+		assert.Equal(t, "First team training", bookings[0].Name)
+		assert.False(t, bookings[0].Conflict)
+		last := bookings[2]
+		assert.Equal(t, "shift", last.Type)
+		assert.Equal(t, int64(3), last.Attendees)
+		assert.True(t, last.OverCapacity)
+	})
+}
@@ -0,0 +1,381 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VolunteerQuota defines how many shift hours each member of a club has to work per period
+type VolunteerQuota struct {
+	ID               string    `json:"ID" gorm:"type:uuid;primary_key" odata:"key"`
+	ClubID           string    `json:"ClubID" gorm:"type:uuid;not null;unique" odata:"required"`
+	RequiredHours    float64   `json:"RequiredHours" odata:"required"`
+	PeriodStartMonth int       `json:"PeriodStartMonth" gorm:"default:1"` // Month (1-12) in which the yearly period starts, e.g. 8 for seasons starting in August
+	CreatedAt        time.Time `json:"CreatedAt" odata:"auto,immutable"`
+	CreatedBy        string    `json:"CreatedBy" gorm:"type:uuid" odata:"auto,immutable"`
+	UpdatedAt        time.Time `json:"UpdatedAt" odata:"auto"`
+	UpdatedBy        string    `json:"UpdatedBy" gorm:"type:uuid" odata:"auto"`
+}
+
+// TableName returns the table name; GORM would treat "quota" as already plural
+func (VolunteerQuota) TableName() string {
+	return "volunteer_quotas"
+}
+
+// EntitySetName returns the entity set name to match the table name
+func (VolunteerQuota) EntitySetName() string {
+	return "VolunteerQuotas"
+}
+
+// VolunteerHours summarizes the shift hours of a member in the current quota period
+type VolunteerHours struct {
+	UserID         string  `json:"UserID"`
+	FirstName      string  `json:"FirstName"`
+	LastName       string  `json:"LastName"`
+	CompletedHours float64 `json:"CompletedHours"` // Shifts that have ended and were not marked as missed
+	PlannedHours   float64 `json:"PlannedHours"`   // Shifts the member is signed up for that have not ended yet
+	RequiredHours  float64 `json:"RequiredHours"`
+	MissingHours   float64 `json:"MissingHours"` // Hours still to be worked, not counting planned shifts
+	UnderQuota     bool    `json:"UnderQuota"`
+}
+
+// BeforeCreate generates UUID for new volunteer quotas
+func (vq *VolunteerQuota) BeforeCreate(tx *gorm.DB) error {
+	if vq.ID == "" {
+		vq.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// validate checks the quota configuration
+func (vq *VolunteerQuota) validate() error {
+	if vq.RequiredHours < 0 {
+		return fmt.Errorf("required hours must not be negative")
+	}
+	if vq.PeriodStartMonth == 0 {
+		vq.PeriodStartMonth = 1
+	}
+	if vq.PeriodStartMonth < 1 || vq.PeriodStartMonth > 12 {
+		return fmt.Errorf("period start month must be between 1 and 12")
+	}
+	return nil
+}
+
+// Period returns the start and end of the quota period containing the given time
+func (vq *VolunteerQuota) Period(at time.Time) (time.Time, time.Time) {
+	month := time.Month(vq.PeriodStartMonth)
+	if month < time.January || month > time.December {
+		month = time.January
+	}
+	start := time.Date(at.Year(), month, 1, 0, 0, 0, 0, at.Location())
+	if start.After(at) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start, start.AddDate(1, 0, 0)
+}
+
+// GetVolunteerQuota returns the quota of the club. Clubs without a quota get an unsaved quota of zero hours.
+func (c *Club) GetVolunteerQuota() (VolunteerQuota, error) {
+	var quota VolunteerQuota
+	err := database.Db.Where("club_id = ?", c.ID).First(&quota).Error
+	if err == gorm.ErrRecordNotFound {
+		return VolunteerQuota{ClubID: c.ID, PeriodStartMonth: 1}, nil
+	}
+	return quota, err
+}
+
+// shiftHours returns the completed and planned shift hours per user in the given period
+func (c *Club) shiftHours(from, to, now time.Time) (map[string]float64, map[string]float64, error) {
+	var rows []struct {
+		UserID    string
+		StartTime time.Time
+		EndTime   time.Time
+		Attended  *bool
+	}
+	err := database.Db.Table("shift_members").
+		Select("shift_members.user_id, shifts.start_time, shifts.end_time, shift_members.attended").
+		Joins("JOIN shifts ON shifts.id = shift_members.shift_id").
+		Where("shifts.club_id = ? AND shifts.start_time >= ? AND shifts.start_time < ?", c.ID, from, to).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	completed := make(map[string]float64)
+	planned := make(map[string]float64)
+	for _, row := range rows {
+		hours := row.EndTime.Sub(row.StartTime).Hours()
+		switch {
+		case row.EndTime.After(now):
+			planned[row.UserID] += hours
+		case row.Attended == nil || *row.Attended:
+			// Shifts count as worked unless an admin recorded the member as absent
+			completed[row.UserID] += hours
+		}
+	}
+	return completed, planned, nil
+}
+
+// GetVolunteerHours returns the shift hours of all club members in the current quota period or,
+// if a season is given, in that season, members with the fewest completed hours first
+func (c *Club) GetVolunteerHours(underQuotaOnly bool, season *Season) ([]VolunteerHours, error) {
+	quota, err := c.GetVolunteerQuota()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	from, to := quota.Period(now)
+	if season != nil {
+		from, to = season.StartDate, season.EndDate
+	}
+	completed, planned, err := c.shiftHours(from, to, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	if err := database.Db.Preload("User").Where("club_id = ?", c.ID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	report := make([]VolunteerHours, 0, len(members))
+	for _, member := range members {
+		hours := VolunteerHours{
+			UserID:         member.UserID,
+			CompletedHours: completed[member.UserID],
+			PlannedHours:   planned[member.UserID],
+			RequiredHours:  quota.RequiredHours,
+		}
+		if member.User != nil {
+			hours.FirstName = member.User.FirstName
+			hours.LastName = member.User.LastName
+		}
+		if hours.CompletedHours < quota.RequiredHours {
+			hours.MissingHours = quota.RequiredHours - hours.CompletedHours
+			hours.UnderQuota = true
+		}
+		if underQuotaOnly && !hours.UnderQuota {
+			continue
+		}
+		report = append(report, hours)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].CompletedHours != report[j].CompletedHours {
+			return report[i].CompletedHours < report[j].CompletedHours
+		}
+		if report[i].LastName != report[j].LastName {
+			return report[i].LastName < report[j].LastName
+		}
+		return report[i].FirstName < report[j].FirstName
+	})
+
+	return report, nil
+}
+
+// isAvailableForShift checks whether the user can be assigned to the shift: not on it yet,
+// not absent, not declined the event and not on another shift at the same time
+func isAvailableForShift(userID string, shift Shift) (bool, error) {
+	absent, err := IsAbsent(shift.ClubID, userID, shift.StartTime, shift.EndTime)
+	if err != nil || absent {
+		return false, err
+	}
+
+	var count int64
+	if err := database.Db.Model(&ShiftMember{}).
+		Where("user_id = ? AND shift_id IN (SELECT id FROM shifts WHERE id = ? OR (start_time < ? AND end_time > ?))", userID, shift.ID, shift.EndTime, shift.StartTime).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if err := database.Db.Model(&EventRSVP{}).Where("event_id = ? AND user_id = ? AND response = ?", shift.EventID, userID, "no").Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// AutoAssignShifts fills the club's shifts starting within the given number of days up to
+// their minimum headcount. Each slot goes to the available member with the fewest completed
+// and planned hours in the current quota period, so the load is spread evenly.
+func (c *Club) AutoAssignShifts(days int, assignedBy string) ([]ShiftMember, error) {
+	openShifts, err := c.GetShiftsNeedingPeople(days)
+	if err != nil {
+		return nil, err
+	}
+	if len(openShifts) == 0 {
+		return []ShiftMember{}, nil
+	}
+
+	quota, err := c.GetVolunteerQuota()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	from, to := quota.Period(now)
+	completed, planned, err := c.shiftHours(from, to, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []string
+	if err := database.Db.Model(&Member{}).Where("club_id = ?", c.ID).Order("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	load := make(map[string]float64, len(userIDs))
+	for _, userID := range userIDs {
+		load[userID] = completed[userID] + planned[userID]
+	}
+
+	assigned := []ShiftMember{}
+	for _, openShift := range openShifts {
+		var shift Shift
+		if err := database.Db.Where("id = ?", openShift.ShiftID).First(&shift).Error; err != nil {
+			return nil, err
+		}
+
+		for missing := openShift.Missing; missing > 0; missing-- {
+			candidate := ""
+			for _, userID := range userIDs {
+				if candidate != "" && load[userID] >= load[candidate] {
+					continue
+				}
+				available, err := isAvailableForShift(userID, shift)
+				if err != nil {
+					return nil, err
+				}
+				if available {
+					candidate = userID
+				}
+			}
+			if candidate == "" {
+				break
+			}
+
+			shiftMember := ShiftMember{
+				ID:        uuid.New().String(),
+				ShiftID:   shift.ID,
+				UserID:    candidate,
+				CreatedBy: assignedBy,
+				UpdatedBy: assignedBy,
+			}
+			if err := database.Db.Create(&shiftMember).Error; err != nil {
+				return nil, err
+			}
+			load[candidate] += shift.EndTime.Sub(shift.StartTime).Hours()
+			assigned = append(assigned, shiftMember)
+
+			_ = SendShiftAssignedNotification(candidate, shift)
+		}
+	}
+
+	return assigned, nil
+}
+
+// ODataBeforeReadCollection filters volunteer quotas to clubs the user belongs to
+func (vq VolunteerQuota) ODataBeforeReadCollection(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	// The quota is visible to all members so everyone knows how many hours are expected
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("club_id IN (SELECT club_id FROM members WHERE user_id = ? AND status <> 'former') AND club_id IN (SELECT club_id FROM club_settings WHERE shifts_enabled = true)", userID)
+	}
+
+	return []func(*gorm.DB) *gorm.DB{scope}, nil
+}
+
+// ODataBeforeReadEntity validates access to a specific volunteer quota
+func (vq VolunteerQuota) ODataBeforeReadEntity(ctx context.Context, r *http.Request, opts interface{}) ([]func(*gorm.DB) *gorm.DB, error) {
+	return vq.ODataBeforeReadCollection(ctx, r, opts)
+}
+
+// ODataBeforeCreate validates volunteer quota creation permissions
+func (vq *VolunteerQuota) ODataBeforeCreate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if err := CheckFeatureEnabled(vq.ClubID, "shifts"); err != nil {
+		return err
+	}
+
+	if !isShiftAdmin(vq.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can configure the volunteer quota")
+	}
+
+	if err := vq.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	vq.CreatedAt = now
+	vq.CreatedBy = userID
+	vq.UpdatedAt = now
+	vq.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeUpdate validates volunteer quota update permissions
+func (vq *VolunteerQuota) ODataBeforeUpdate(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	var existing VolunteerQuota
+	if err := database.Db.Where("id = ?", vq.ID).First(&existing).Error; err != nil {
+		return fmt.Errorf("volunteer quota not found")
+	}
+
+	// SECURITY: Prevent moving the quota to another club
+	if vq.ClubID != existing.ClubID {
+		return fmt.Errorf("forbidden: club cannot be changed for an existing volunteer quota")
+	}
+
+	if err := CheckFeatureEnabled(existing.ClubID, "shifts"); err != nil {
+		return err
+	}
+
+	if !isShiftAdmin(existing.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can configure the volunteer quota")
+	}
+
+	if err := vq.validate(); err != nil {
+		return err
+	}
+
+	vq.UpdatedAt = time.Now()
+	vq.UpdatedBy = userID
+
+	return nil
+}
+
+// ODataBeforeDelete validates volunteer quota deletion permissions
+func (vq *VolunteerQuota) ODataBeforeDelete(ctx context.Context, r *http.Request) error {
+	userID, ok := ctx.Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: user ID not found in context")
+	}
+
+	if !isShiftAdmin(vq.ClubID, userID) {
+		return fmt.Errorf("unauthorized: only admins and owners can configure the volunteer quota")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NLstn/civo/handlers"
+	"github.com/NLstn/civo/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolunteerHours(t *testing.T) {
+	handlers.SetupTestDB(t)
+	defer handlers.TeardownTestDB(t)
+	db := handlers.GetDB()
+
+	owner, _ := handlers.CreateTestUser(t, "hours-owner@example.com")
+	anna, _ := handlers.CreateTestUser(t, "hours-anna@example.com")
+	ben, _ := handlers.CreateTestUser(t, "hours-ben@example.com")
+	clara, _ := handlers.CreateTestUser(t, "hours-clara@example.com")
+	club := handlers.CreateTestClub(t, owner, "Hours Club")
+	handlers.CreateTestMember(t, anna, club, "member")
+	handlers.CreateTestMember(t, ben, club, "member")
+	handlers.CreateTestMember(t, clara, club, "member")
+	require.NoError(t, db.Exec("UPDATE club_settings SET shifts_enabled = true WHERE club_id = ?", club.ID).Error)
+
+	now := time.Now()
+	past := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Cleanup", StartTime: now.Add(-4 * time.Hour), EndTime: now.Add(-time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	upcoming := models.Event{ID: uuid.New().String(), ClubID: club.ID, Name: "Fair", StartTime: now.Add(48 * time.Hour), EndTime: now.Add(56 * time.Hour), CreatedBy: owner.ID, UpdatedBy: owner.ID}
+	require.NoError(t, db.Create(&past).Error)
+	require.NoError(t, db.Create(&upcoming).Error)
+
+	newShift := func(event models.Event, start time.Time, hours int, min int) models.Shift {
+		shift := models.Shift{ID: uuid.New().String(), ClubID: club.ID, EventID: event.ID, StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour), MinHeadcount: min, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shift).Error)
+		return shift
+	}
+	assign := func(shift models.Shift, user models.User, attended *bool) {
+		shiftMember := models.ShiftMember{ID: uuid.New().String(), ShiftID: shift.ID, UserID: user.ID, Attended: attended, CreatedBy: owner.ID, UpdatedBy: owner.ID}
+		require.NoError(t, db.Create(&shiftMember).Error)
+	}
+
+	absent := false
+	cleanup := newShift(past, past.StartTime, 3, 1)
+	assign(cleanup, anna, nil)
+	assign(cleanup, ben, &absent)
+	setup := newShift(upcoming, upcoming.StartTime, 2, 1)
+	assign(setup, ben, nil)
+
+	quota := models.VolunteerQuota{ClubID: club.ID, RequiredHours: 3}
+	require.NoError(t, db.Create(&quota).Error)
+
+	t.Run("quota configuration is validated", func(t *testing.T) {
+		ctx, req := authedRequest(owner.ID)
+		invalid := models.VolunteerQuota{ClubID: club.ID, RequiredHours: 3, PeriodStartMonth: 13}
+		assert.Error(t, invalid.ODataBeforeCreate(ctx, req))
+
+		ctx, req = authedRequest(anna.ID)
+		valid := models.VolunteerQuota{ClubID: club.ID, RequiredHours: 3}
+		assert.Error(t, valid.ODataBeforeCreate(ctx, req), "members cannot configure the quota")
+	})
+
+	t.Run("hours are counted from shift times", func(t *testing.T) {
+		report, err := club.GetVolunteerHours(false, nil)
+		require.NoError(t, err)
+		require.Len(t, report, 4)
+
+		hours := map[string]models.VolunteerHours{}
+		for _, row := range report {
+			hours[row.UserID] = row
+		}
+		assert.InDelta(t, 3, hours[anna.ID].CompletedHours, 0.01)
+		assert.False(t, hours[anna.ID].UnderQuota)
+		assert.InDelta(t, 0, hours[ben.ID].CompletedHours, 0.01, "absent members get no hours")
+		assert.InDelta(t, 2, hours[ben.ID].PlannedHours, 0.01)
+		assert.InDelta(t, 3, hours[ben.ID].MissingHours, 0.01)
+
+		underQuota, err := club.GetVolunteerHours(true, nil)
+		require.NoError(t, err)
+		assert.Len(t, underQuota, 3)
+		for _, row := range underQuota {
+			assert.NotEqual(t, anna.ID, row.UserID)
+		}
+	})
+
+	t.Run("auto-assignment balances load and respects availability", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.EventRSVP{ID: uuid.New().String(), EventID: upcoming.ID, UserID: clara.ID, Response: "no"}).Error)
+
+		bar := newShift(upcoming, upcoming.StartTime, 1, 2)
+		grill := newShift(upcoming, upcoming.StartTime.Add(4*time.Hour), 2, 1)
+
+		assigned, err := club.AutoAssignShifts(7, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, assigned, 3)
+
+		byShift := map[string][]string{}
+		for _, shiftMember := range assigned {
+			byShift[shiftMember.ShiftID] = append(byShift[shiftMember.ShiftID], shiftMember.UserID)
+		}
+		// Ben is busy with the setup shift at the same time and Clara declined the event
+		assert.ElementsMatch(t, []string{owner.ID, anna.ID}, byShift[bar.ID])
+		// The owner has the lowest load afterwards, ahead of Ben with their planned hours
+		assert.Equal(t, []string{owner.ID}, byShift[grill.ID])
+
+		var notified int64
+		db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", anna.ID, "shift_assigned").Count(&notified)
+		assert.Equal(t, int64(1), notified)
+
+		assigned, err = club.AutoAssignShifts(7, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, assigned, "shifts are fully staffed")
+	})
+}
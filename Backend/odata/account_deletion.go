@@ -0,0 +1,85 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerAccountDeletionOperations registers the deletion of the current user's account
+func (s *Service) registerAccountDeletionOperations() error {
+	// Unbound action for scheduling the deletion of the current user's account
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:    "RequestAccountDeletion",
+		IsBound: false,
+		Parameters: []odata.ParameterDefinition{
+			{Name: "reason", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.AccountDeletion{}),
+		Handler:    s.requestAccountDeletionAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RequestAccountDeletion action: %w", err)
+	}
+
+	// Unbound action for cancelling a scheduled deletion during the grace period
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "CancelAccountDeletion",
+		IsBound:    false,
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.AccountDeletion{}),
+		Handler:    s.cancelAccountDeletionAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register CancelAccountDeletion action: %w", err)
+	}
+
+	return nil
+}
+
+// requestAccountDeletionAction handles the unbound RequestAccountDeletion action
+// The account is anonymized by the scheduler once the grace period has passed.
+// POST /api/v2/RequestAccountDeletion
+func (s *Service) requestAccountDeletionAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	var reason *string
+	if value, ok := params["reason"].(string); ok && value != "" {
+		reason = &value
+	}
+
+	deletion, err := models.RequestAccountDeletion(userID, reason)
+	if err != nil {
+		if errors.Is(err, models.ErrAccountDeletionPending) || errors.Is(err, models.ErrAccountDeletionSoleOwner) {
+			return err
+		}
+		return fmt.Errorf("failed to request account deletion: %w", err)
+	}
+
+	return writeEntityJSON(w, "AccountDeletions", deletion)
+}
+
+// cancelAccountDeletionAction handles the unbound CancelAccountDeletion action
+// POST /api/v2/CancelAccountDeletion
+func (s *Service) cancelAccountDeletionAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	deletion, err := models.CancelAccountDeletion(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoAccountDeletion) {
+			return err
+		}
+		return fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+
+	return writeEntityJSON(w, "AccountDeletions", deletion)
+}
@@ -2,6 +2,7 @@ package odata
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -157,6 +158,17 @@ func (s *Service) registerActions() error {
 		return fmt.Errorf("failed to register HardDelete action for Club: %w", err)
 	}
 
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "Restore",
+		IsBound:    true,
+		EntitySet:  "Clubs",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.Club{}),
+		Handler:    s.restoreClubAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Restore action for Club: %w", err)
+	}
+
 	// Bound actions for Notification entity
 	if err := s.Service.RegisterAction(odata.ActionDefinition{
 		Name:       "MarkAsRead",
@@ -202,6 +214,7 @@ func (s *Service) registerActions() error {
 		EntitySet: "Events",
 		Parameters: []odata.ParameterDefinition{
 			{Name: "response", Type: reflect.TypeOf(""), Required: true},
+			{Name: "userId", Type: reflect.TypeOf(""), Required: false},
 		},
 		ReturnType: nil,
 		Handler:    s.addRSVPAction,
@@ -211,10 +224,12 @@ func (s *Service) registerActions() error {
 
 	// Bound actions for Club entity - Additional operations
 	if err := s.Service.RegisterAction(odata.ActionDefinition{
-		Name:       "Join",
-		IsBound:    true,
-		EntitySet:  "Clubs",
-		Parameters: []odata.ParameterDefinition{},
+		Name:      "Join",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: false},
+		},
 		ReturnType: nil,
 		Handler:    s.joinClubAction,
 	}); err != nil {
@@ -275,6 +290,53 @@ func (s *Service) registerActions() error {
 		return fmt.Errorf("failed to register RemoveMember action for Shift: %w", err)
 	}
 
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "SignUp",
+		IsBound:    true,
+		EntitySet:  "Shifts",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.ShiftMember{}),
+		Handler:    s.signUpShiftAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SignUp action for Shift: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "Withdraw",
+		IsBound:    true,
+		EntitySet:  "Shifts",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.withdrawShiftAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Withdraw action for Shift: %w", err)
+	}
+
+	// Bound actions for Comment entity
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Edit",
+		IsBound:   true,
+		EntitySet: "Comments",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "content", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.editCommentAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Edit action for Comment: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "SoftDelete",
+		IsBound:    true,
+		EntitySet:  "Comments",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.softDeleteCommentAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SoftDelete action for Comment: %w", err)
+	}
+
 	return nil
 }
 
@@ -423,18 +485,8 @@ func (s *Service) leaveClubAction(w http.ResponseWriter, r *http.Request, ctx in
 func (s *Service) deleteLogoAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
 	club := ctx.(*models.Club)
 
-	// Get user ID from request context
-	userID := r.Context().Value(auth.UserIDKey).(string)
-
-	// Get user for authorization checks
-	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return fmt.Errorf("failed to find user: %w", err)
-	}
-
-	// Check if user is admin or owner
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
-		return fmt.Errorf("unauthorized: only club admins can delete club logo")
+	if _, err := s.requirePermission(r, club.ID, models.PermissionSettingsEdit); err != nil {
+		return err
 	}
 
 	// Delete the logo (LogoURL is a pointer to string)
@@ -453,30 +505,45 @@ func (s *Service) deleteLogoAction(w http.ResponseWriter, r *http.Request, ctx i
 func (s *Service) hardDeleteClubAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
 	club := ctx.(*models.Club)
 
-	// Get user ID from request context
-	userID := r.Context().Value(auth.UserIDKey).(string)
-
-	// Get user for authorization checks
-	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return fmt.Errorf("failed to find user: %w", err)
-	}
-
-	// Check if user is owner (only owners can hard delete)
-	if !club.IsOwner(user) {
-		return fmt.Errorf("unauthorized: only club owners can hard delete clubs")
+	// Only owners hold the club.delete permission
+	if _, err := s.requirePermission(r, club.ID, models.PermissionClubDelete); err != nil {
+		return err
 	}
 
-	// Hard delete the club (permanently delete, bypassing soft delete)
-	if err := s.db.Unscoped().Delete(club).Error; err != nil {
+	// Hard delete the club with all its data (permanently delete, bypassing soft delete)
+	if err := models.HardDeleteClub(club.ID); err != nil {
 		return fmt.Errorf("failed to hard delete club: %w", err)
 	}
+	s.auditAction(r, club.ID, "Club", club.ID, "HardDelete", nil)
 
 	w.Header().Set("OData-Version", "4.0")
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
+// restoreClubAction handles the Restore action on Club entity
+// Owners can restore a soft-deleted club until its retention period expires.
+// POST /api/v2/Clubs('{clubId}')/Restore
+func (s *Service) restoreClubAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	// Only owners hold the club.delete permission
+	userID, err := s.requirePermission(r, club.ID, models.PermissionClubDelete)
+	if err != nil {
+		return err
+	}
+
+	if err := club.Restore(userID); err != nil {
+		if errors.Is(err, models.ErrClubNotDeleted) || errors.Is(err, models.ErrClubRetentionExpired) {
+			return err
+		}
+		return fmt.Errorf("failed to restore club: %w", err)
+	}
+	s.auditAction(r, club.ID, "Club", club.ID, "Restore", map[string]models.AuditChange{"Deleted": {Old: true, New: false}})
+
+	return writeEntityJSON(w, "Clubs", club)
+}
+
 // markNotificationReadAction handles the MarkAsRead action on Notification entity
 // POST /api/v2/Notifications('{notificationId}')/MarkAsRead
 func (s *Service) markNotificationReadAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
@@ -531,6 +598,7 @@ func (s *Service) markAllNotificationsReadAction(w http.ResponseWriter, r *http.
 }
 
 // addRSVPAction handles the AddRSVP action on Event entity
+// Guardians pass the userId of a dependent to respond on their behalf.
 // POST /api/v2/Events('{eventId}')/AddRSVP
 func (s *Service) addRSVPAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
 	event := ctx.(*models.Event)
@@ -538,6 +606,14 @@ func (s *Service) addRSVPAction(w http.ResponseWriter, r *http.Request, ctx inte
 	// Get user ID from request context
 	userID := r.Context().Value(auth.UserIDKey).(string)
 
+	// Respond for a dependent if requested
+	if dependentID, ok := params["userId"].(string); ok && dependentID != "" {
+		if !models.CanActFor(userID, dependentID) {
+			return fmt.Errorf("forbidden: can only RSVP for yourself or your dependents")
+		}
+		userID = dependentID
+	}
+
 	// Get response parameter
 	response, ok := params["response"].(string)
 	if !ok {
@@ -571,7 +647,7 @@ func (s *Service) addRSVPAction(w http.ResponseWriter, r *http.Request, ctx inte
 
 	if result.Error == nil {
 		// Update existing RSVP
-		existingRSVP.Response = response
+		existingRSVP.SetResponse(response)
 		if err := s.db.Save(&existingRSVP).Error; err != nil {
 			return fmt.Errorf("failed to update RSVP: %w", err)
 		}
@@ -593,6 +669,7 @@ func (s *Service) addRSVPAction(w http.ResponseWriter, r *http.Request, ctx inte
 }
 
 // joinClubAction handles the Join action on Club entity
+// Guardians pass the userId of a dependent to request membership for them.
 // POST /api/v2/Clubs('{clubId}')/Join
 func (s *Service) joinClubAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
 	club := ctx.(*models.Club)
@@ -600,6 +677,14 @@ func (s *Service) joinClubAction(w http.ResponseWriter, r *http.Request, ctx int
 	// Get user ID from request context
 	userID := r.Context().Value(auth.UserIDKey).(string)
 
+	// Request membership for a dependent if requested
+	if dependentID, ok := params["userId"].(string); ok && dependentID != "" {
+		if !models.CanActFor(userID, dependentID) {
+			return fmt.Errorf("forbidden: can only join for yourself or your dependents")
+		}
+		userID = dependentID
+	}
+
 	// Get user
 	var user models.User
 	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -652,8 +737,8 @@ func (s *Service) createInviteAction(w http.ResponseWriter, r *http.Request, ctx
 		return fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Check if user is admin or owner
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
+	// Check if user holds the members.invite permission
+	if !models.HasPermission(club.ID, user.ID, models.PermissionMembersInvite) {
 		return fmt.Errorf("only club admins can send invites")
 	}
 
@@ -730,10 +815,14 @@ func (s *Service) updateMemberRoleAction(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Update the member's role
+	oldRole := member.Role
 	member.Role = newRole
 	if err := s.db.Save(member).Error; err != nil {
 		return fmt.Errorf("failed to update member role: %w", err)
 	}
+	s.auditAction(r, member.ClubID, "Member", member.ID, "UpdateRole", map[string]models.AuditChange{
+		"Role": {Old: oldRole, New: newRole},
+	})
 
 	w.Header().Set("OData-Version", "4.0")
 	w.WriteHeader(http.StatusNoContent)
@@ -777,8 +866,8 @@ func (s *Service) addShiftMemberAction(w http.ResponseWriter, r *http.Request, c
 		return fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Check if user is admin or owner
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
+	// Check if user holds the shifts.manage permission
+	if !models.HasPermission(club.ID, user.ID, models.PermissionShiftsManage) {
 		return fmt.Errorf("only club admins can assign shift members")
 	}
 
@@ -849,8 +938,8 @@ func (s *Service) removeShiftMemberAction(w http.ResponseWriter, r *http.Request
 		return fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Check if user is admin or owner
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
+	// Check if user holds the shifts.manage permission
+	if !models.HasPermission(club.ID, user.ID, models.PermissionShiftsManage) {
 		return fmt.Errorf("only club admins can remove shift members")
 	}
 
@@ -875,6 +964,49 @@ func (s *Service) removeShiftMemberAction(w http.ResponseWriter, r *http.Request
 	return nil
 }
 
+// signUpShiftAction handles the SignUp action on Shift entity
+// Members sign themselves up as long as the shift has room left.
+// POST /api/v2/Shifts('{shiftId}')/SignUp
+func (s *Service) signUpShiftAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	shift := ctx.(*models.Shift)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if err := models.CheckFeatureEnabled(shift.ClubID, "shifts"); err != nil {
+		return err
+	}
+
+	shiftMember, err := shift.SignUp(userID)
+	if err != nil {
+		return err
+	}
+
+	return writeEntityJSON(w, "ShiftMembers", shiftMember)
+}
+
+// withdrawShiftAction handles the Withdraw action on Shift entity
+// Members can withdraw until the withdrawal cutoff of the shift.
+// POST /api/v2/Shifts('{shiftId}')/Withdraw
+func (s *Service) withdrawShiftAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	shift := ctx.(*models.Shift)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if err := shift.Withdraw(userID); err != nil {
+		return err
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 // createAPIKeyAction handles the CreateAPIKey unbound action
 // POST /api/v2/CreateAPIKey
 //
@@ -983,3 +1115,56 @@ func (s *Service) createAPIKeyAction(w http.ResponseWriter, r *http.Request, ctx
 	w.WriteHeader(http.StatusOK)
 	return json.NewEncoder(w).Encode(response)
 }
+
+// editCommentAction handles the Edit action on Comment entity
+// POST /api/v2/Comments('{commentId}')/Edit
+func (s *Service) editCommentAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	comment := ctx.(*models.Comment)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	// Get content parameter
+	content, ok := params["content"].(string)
+	if !ok {
+		return fmt.Errorf("content parameter is required")
+	}
+
+	if comment.CreatedBy != userID {
+		return fmt.Errorf("unauthorized: only the author can edit a comment")
+	}
+
+	if err := models.CheckFeatureEnabled(comment.ClubID, comment.Feature()); err != nil {
+		return err
+	}
+
+	if err := comment.Edit(content, userID); err != nil {
+		return fmt.Errorf("failed to edit comment: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// softDeleteCommentAction handles the SoftDelete action on Comment entity
+// Authors can remove their own comments, admins and owners can moderate all comments of the club.
+// POST /api/v2/Comments('{commentId}')/SoftDelete
+func (s *Service) softDeleteCommentAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	comment := ctx.(*models.Comment)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	if !comment.CanModerate(userID) {
+		return fmt.Errorf("unauthorized: only the author or club admins can delete a comment")
+	}
+
+	if err := comment.SoftDelete(userID); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,83 @@
+package odata
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerAuditLogOperations registers the audit log export
+func (s *Service) registerAuditLogOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ExportAuditLog",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "startDate", Type: reflect.TypeOf(""), Required: false},
+			{Name: "endDate", Type: reflect.TypeOf(""), Required: false},
+			{Name: "entityType", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: nil,
+		Handler:    s.exportAuditLogAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ExportAuditLog action for Club: %w", err)
+	}
+
+	return nil
+}
+
+// auditAction records a custom action in the club's audit log. The action itself already
+// succeeded, so failures are only logged.
+func (s *Service) auditAction(r *http.Request, clubID, entityType, entityID, action string, details map[string]models.AuditChange) {
+	if err := models.RecordAuditAction(r.Context(), r, clubID, entityType, entityID, action, details); err != nil {
+		log.Printf("failed to record %s on %s %s in audit log: %v", action, entityType, entityID, err)
+	}
+}
+
+// exportAuditLogAction handles the ExportAuditLog action on Club entity
+// Returns the audit log as CSV. Dates are YYYY-MM-DD and inclusive; the default is the last 30 days.
+// POST /api/v2/Clubs('{clubId}')/ExportAuditLog
+func (s *Service) exportAuditLogAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	if _, err := s.requirePermission(r, club.ID, models.PermissionAuditRead); err != nil {
+		return err
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	startDate, endDate := today.AddDate(0, 0, -30), today
+	if value, _ := params["startDate"].(string); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("invalid startDate format: must be YYYY-MM-DD")
+		}
+		startDate = parsed
+	}
+	if value, _ := params["endDate"].(string); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("invalid endDate format: must be YYYY-MM-DD")
+		}
+		endDate = parsed
+	}
+	if endDate.Before(startDate) {
+		return fmt.Errorf("endDate must not be before startDate")
+	}
+
+	entityType, _ := params["entityType"].(string)
+	document, err := models.ExportAuditLog(club.ID, startDate, endDate.AddDate(0, 0, 1), entityType)
+	if err != nil {
+		return fmt.Errorf("failed to export audit log: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"audit-log-%s-%s.csv\"", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(document)
+	return err
+}
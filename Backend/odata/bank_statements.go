@@ -0,0 +1,143 @@
+package odata
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerBankStatementOperations registers the actions for bank statement reconciliation
+func (s *Service) registerBankStatementOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ImportBankStatement",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "content", Type: reflect.TypeOf(""), Required: true},
+			{Name: "format", Type: reflect.TypeOf(""), Required: false},
+			{Name: "fileName", Type: reflect.TypeOf(""), Required: false},
+			{Name: "base64", Type: reflect.TypeOf(false), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.BankStatementImport{}),
+		Handler:    s.importBankStatementAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ImportBankStatement action for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ConfirmMatch",
+		IsBound:   true,
+		EntitySet: "BankTransactions",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "fineId", Type: reflect.TypeOf(""), Required: false},
+			{Name: "invoiceId", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: nil,
+		Handler:    s.confirmBankTransactionAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ConfirmMatch action for BankTransaction: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "Ignore",
+		IsBound:    true,
+		EntitySet:  "BankTransactions",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.ignoreBankTransactionAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Ignore action for BankTransaction: %w", err)
+	}
+
+	return nil
+}
+
+// importBankStatementAction handles the ImportBankStatement action on Club entity
+// Accepts CAMT.053, MT940 or CSV statements; the format is detected when omitted.
+// Binary uploads can be sent base64 encoded.
+// POST /api/v2/Clubs('{clubId}')/ImportBankStatement
+func (s *Service) importBankStatementAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, err := s.requirePermission(r, club.ID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	content, _ := params["content"].(string)
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("statement content is required")
+	}
+	data := []byte(content)
+	if encoded, _ := params["base64"].(bool); encoded {
+		data, err = base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return fmt.Errorf("invalid base64 content")
+		}
+	}
+
+	format, _ := params["format"].(string)
+	fileName, _ := params["fileName"].(string)
+
+	statementImport, err := models.ImportBankStatement(club.ID, format, fileName, data, userID)
+	if err != nil {
+		return fmt.Errorf("failed to import statement: %w", err)
+	}
+
+	return writeEntityJSON(w, "BankStatementImports", statementImport)
+}
+
+// confirmBankTransactionAction handles the ConfirmMatch action on BankTransaction entity
+// Marks the suggested fine or invoice, or the one passed in, as paid.
+// POST /api/v2/BankTransactions('{transactionId}')/ConfirmMatch
+func (s *Service) confirmBankTransactionAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	transaction := ctx.(*models.BankTransaction)
+
+	userID, err := s.requirePermission(r, transaction.ClubID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	var fineID, invoiceID *string
+	if id, ok := params["fineId"].(string); ok && id != "" {
+		fineID = &id
+	}
+	if id, ok := params["invoiceId"].(string); ok && id != "" {
+		invoiceID = &id
+	}
+
+	if err := transaction.Confirm(fineID, invoiceID, userID); err != nil {
+		if errors.Is(err, models.ErrBankTransactionSettled) {
+			return err
+		}
+		return fmt.Errorf("failed to confirm match: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// ignoreBankTransactionAction handles the Ignore action on BankTransaction entity
+// POST /api/v2/BankTransactions('{transactionId}')/Ignore
+func (s *Service) ignoreBankTransactionAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	transaction := ctx.(*models.BankTransaction)
+
+	if _, err := s.requirePermission(r, transaction.ClubID, models.PermissionFinesManage); err != nil {
+		return err
+	}
+
+	if err := transaction.Ignore(); err != nil {
+		return err
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
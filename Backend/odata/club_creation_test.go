@@ -74,7 +74,22 @@ func TestCreateClubForNewUser(t *testing.T) {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		created_by TEXT,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_by TEXT
+		updated_by TEXT,
+		status TEXT DEFAULT 'active',
+		status_since DATETIME
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS membership_status_changes (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		role TEXT,
+		effective_from DATETIME NOT NULL,
+		effective_until DATETIME,
+		reason TEXT,
+		changed_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	)`)
 
 	// Clean up - ensure cleanup succeeds
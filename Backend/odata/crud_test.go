@@ -92,12 +92,51 @@ func setupTestContext(t *testing.T) *testContext {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		created_by TEXT,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT,
+		status TEXT DEFAULT 'active',
+		status_since DATETIME
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS membership_status_changes (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		role TEXT,
+		effective_from DATETIME NOT NULL,
+		effective_until DATETIME,
+		reason TEXT,
+		changed_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS guardianships (
+		id TEXT PRIMARY KEY,
+		guardian_id TEXT NOT NULL,
+		dependent_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS seasons (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		start_date DATETIME NOT NULL,
+		end_date DATETIME NOT NULL,
+		archived BOOLEAN DEFAULT FALSE,
+		archived_at DATETIME,
+		archived_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_by TEXT
 	)`)
 
 	testDB.Exec(`CREATE TABLE IF NOT EXISTS teams (
 		id TEXT PRIMARY KEY,
 		club_id TEXT NOT NULL,
+		season_id TEXT,
 		name TEXT,
 		description TEXT,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
@@ -124,6 +163,7 @@ func setupTestContext(t *testing.T) *testContext {
 		id TEXT PRIMARY KEY,
 		club_id TEXT NOT NULL,
 		team_id TEXT,
+		season_id TEXT,
 		name TEXT,
 		description TEXT,
 		start_time DATETIME NOT NULL,
@@ -134,6 +174,7 @@ func setupTestContext(t *testing.T) *testContext {
 		recurrence_interval INTEGER DEFAULT 1,
 		recurrence_end DATETIME,
 		parent_event_id TEXT,
+		venue_id TEXT,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		created_by TEXT,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
@@ -175,6 +216,7 @@ func setupTestContext(t *testing.T) *testContext {
 		user_id TEXT NOT NULL,
 		club_id TEXT NOT NULL,
 		team_id TEXT,
+		season_id TEXT,
 		reason TEXT,
 		amount REAL,
 		paid BOOLEAN DEFAULT FALSE,
@@ -1127,6 +1169,16 @@ func TestErrorHandling(t *testing.T) {
 			assert.True(t, resp.StatusCode >= 400 && resp.StatusCode < 500, "Should return 4xx error for invalid data")
 		}
 	})
+
+	t.Run("request entity too large - oversized update body", func(t *testing.T) {
+		update := map[string]interface{}{
+			"Description": strings.Repeat("x", 2<<20),
+		}
+
+		path := fmt.Sprintf("/Clubs(%s)", ctx.testClub.ID)
+		resp := ctx.makeAuthenticatedRequest(t, "PATCH", path, update)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
 }
 
 // TestClubDiscoverability tests the club discoverability feature
@@ -75,7 +75,7 @@ func parseClubCustomRoute(path string) (clubID, action string) {
 // Request: multipart/form-data with "logo" field containing image file
 // Response: 200 OK with JSON containing LogoURL
 //
-// Authorization: User must be admin or owner of the club
+// Authorization: User must hold the settings.edit permission in the club
 func (s *Service) handleUploadClubLogo(w http.ResponseWriter, r *http.Request, clubID string) {
 	// Only accept POST
 	if r.Method != http.MethodPost {
@@ -116,8 +116,8 @@ func (s *Service) handleUploadClubLogo(w http.ResponseWriter, r *http.Request, c
 		return
 	}
 
-	// Check if user is an admin or owner of the club
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
+	// Check if user may edit the club's details
+	if !models.HasPermission(club.ID, user.ID, models.PermissionSettingsEdit) {
 		log.Printf("ERROR: Unauthorized logo upload attempt by user %s for club %s", userID, clubID)
 		http.Error(w, "Forbidden - only club admins and owners can upload logos", http.StatusForbidden)
 		return
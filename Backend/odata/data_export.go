@@ -0,0 +1,72 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerDataExportOperations registers the GDPR data export of the current user
+func (s *Service) registerDataExportOperations() error {
+	// Unbound action for requesting an export of the current user's data
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "RequestDataExport",
+		IsBound:    false,
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.DataExport{}),
+		Handler:    s.requestDataExportAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RequestDataExport action: %w", err)
+	}
+
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetDownloadLink",
+		IsBound:    true,
+		EntitySet:  "DataExports",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(""),
+		Handler:    s.getDataExportDownloadLinkFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetDownloadLink function for DataExport: %w", err)
+	}
+
+	return nil
+}
+
+// requestDataExportAction handles the unbound RequestDataExport action
+// Queues an export that the scheduler turns into a ZIP archive; the user is notified when it is ready.
+// POST /api/v2/RequestDataExport
+func (s *Service) requestDataExportAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	export, err := models.RequestDataExport(userID)
+	if err != nil {
+		return fmt.Errorf("failed to request data export: %w", err)
+	}
+
+	return writeEntityJSON(w, "DataExports", export)
+}
+
+// getDataExportDownloadLinkFunction returns a signed download link for a ready export.
+// The link works without authentication until the export expires.
+// GET /api/v2/DataExports('{exportId}')/GetDownloadLink()
+func (s *Service) getDataExportDownloadLinkFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	export := ctx.(*models.DataExport)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+	if export.UserID != userID {
+		return nil, fmt.Errorf("forbidden: can only download your own data exports")
+	}
+
+	return export.DownloadPath()
+}
@@ -40,6 +40,92 @@ func (s *Service) registerEntities() error {
 		&models.Notification{},
 		&models.UserNotificationPreferences{},
 
+		// Comment entities
+		&models.Comment{},
+		&models.CommentReaction{},
+
+		// Poll entities
+		&models.Poll{},
+		&models.PollOption{},
+
+		// Scheduling poll entities
+		&models.SchedulingPoll{},
+		&models.SchedulingSlot{},
+		&models.SchedulingResponse{},
+
+		// Messaging entities
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
+
+		// Membership fee entities
+		&models.FeePlan{},
+		&models.FeeDiscount{},
+		&models.FeeAssignment{},
+		&models.Invoice{},
+
+		// SEPA direct debit entities
+		&models.SepaMandate{},
+		&models.SepaCreditor{},
+		&models.SepaDebit{},
+
+		// Bank statement entities
+		&models.BankStatementImport{},
+		&models.BankTransaction{},
+
+		// Automatic fine entities
+		&models.FineRule{},
+
+		// Fine dispute entities
+		&models.FineDisputeEntry{},
+
+		// Shift template entities
+		&models.ShiftTemplate{},
+		&models.ShiftTemplateSlot{},
+
+		// Shift swap entities
+		&models.ShiftSwapRequest{},
+
+		// Volunteer hour entities
+		&models.VolunteerQuota{},
+
+		// Absence entities
+		&models.MemberAbsence{},
+
+		// Venue entities
+		&models.Venue{},
+
+		// Custom role entities
+		&models.ClubRole{},
+		&models.ClubRolePermission{},
+		&models.ClubRoleAssignment{},
+
+		// Audit log entities
+		&models.AuditLog{},
+
+		// Data export entities
+		&models.DataExport{},
+
+		// Account deletion entities
+		&models.AccountDeletion{},
+
+		// Ownership entities
+		&models.OwnershipTransfer{},
+		&models.ClubSuccession{},
+
+		// Guardianship entities
+		&models.Guardianship{},
+
+		// Member profile field entities
+		&models.MemberFieldDefinition{},
+		&models.MemberFieldValue{},
+
+		// Season entities
+		&models.Season{},
+
+		// Membership history entities
+		&models.MembershipStatusChange{},
+
 		// Settings and privacy entities
 		&models.ClubSettings{},
 		&models.UserPrivacySettings{},
@@ -0,0 +1,101 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerFeeOperations registers the actions and functions for membership fees
+func (s *Service) registerFeeOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "RecordPayment",
+		IsBound:   true,
+		EntitySet: "Invoices",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "paidAt", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: nil,
+		Handler:    s.recordInvoicePaymentAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RecordPayment action for Invoice: %w", err)
+	}
+
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetFeeStatement",
+		IsBound:    true,
+		EntitySet:  "Members",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.FeeStatement{}),
+		Handler:    s.getMemberFeeStatementFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetFeeStatement function for Member: %w", err)
+	}
+
+	return nil
+}
+
+// recordInvoicePaymentAction handles the RecordPayment action on Invoice entity
+// paidAt is optional (RFC3339) and defaults to now.
+// POST /api/v2/Invoices('{invoiceId}')/RecordPayment
+func (s *Service) recordInvoicePaymentAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	invoice := ctx.(*models.Invoice)
+
+	userID, err := s.requirePermission(r, invoice.ClubID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	paidAt := time.Now()
+	if paidAtStr, ok := params["paidAt"].(string); ok && paidAtStr != "" {
+		parsed, err := time.Parse(time.RFC3339, paidAtStr)
+		if err != nil {
+			return fmt.Errorf("invalid paidAt format: must be RFC3339")
+		}
+		paidAt = parsed
+	}
+
+	if err := invoice.RecordPayment(paidAt, userID); err != nil {
+		if errors.Is(err, models.ErrInvoiceAlreadyPaid) {
+			return err
+		}
+		return fmt.Errorf("failed to record payment: %w", err)
+	}
+	s.auditAction(r, invoice.ClubID, "Invoice", invoice.ID, "RecordPayment", map[string]models.AuditChange{
+		"PaidAt": {New: paidAt},
+	})
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// getMemberFeeStatementFunction returns the fee statement of a member
+// Members can see their own statement, club admins the statements of all members.
+// GET /api/v2/Members('{memberId}')/GetFeeStatement()
+func (s *Service) getMemberFeeStatementFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	member := ctx.(*models.Member)
+
+	// Get user ID from request context
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if member.UserID != userID && !models.HasPermission(member.ClubID, userID, models.PermissionFinesManage) {
+		return nil, fmt.Errorf("forbidden: only club admins can view statements of other members")
+	}
+
+	statement, err := models.GetFeeStatement(member.ClubID, member.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee statement: %w", err)
+	}
+
+	return statement, nil
+}
@@ -0,0 +1,108 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerFineDisputeOperations registers the actions for disputing fines and resolving disputes
+func (s *Service) registerFineDisputeOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Dispute",
+		IsBound:   true,
+		EntitySet: "Fines",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "reason", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.Fine{}),
+		Handler:    s.disputeFineAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Dispute action for Fine: %w", err)
+	}
+
+	resolutions := []struct {
+		name   string
+		status string
+		params []odata.ParameterDefinition
+	}{
+		{name: "UpholdDispute", status: models.FineDisputeStatusUpheld},
+		{name: "WaiveFine", status: models.FineDisputeStatusWaived},
+		{name: "ReduceFine", status: models.FineDisputeStatusReduced, params: []odata.ParameterDefinition{
+			{Name: "amount", Type: reflect.TypeOf(float64(0)), Required: true},
+		}},
+	}
+	for _, resolution := range resolutions {
+		params := append(resolution.params, odata.ParameterDefinition{Name: "comment", Type: reflect.TypeOf(""), Required: false})
+		if err := s.Service.RegisterAction(odata.ActionDefinition{
+			Name:       resolution.name,
+			IsBound:    true,
+			EntitySet:  "Fines",
+			Parameters: params,
+			ReturnType: reflect.TypeOf(models.Fine{}),
+			Handler:    s.resolveFineDisputeAction(resolution.status),
+		}); err != nil {
+			return fmt.Errorf("failed to register %s action for Fine: %w", resolution.name, err)
+		}
+	}
+
+	return nil
+}
+
+// disputeFineAction handles the Dispute action on Fine entity
+// Only the fined member can dispute an open, unpaid fine.
+// POST /api/v2/Fines('{fineId}')/Dispute
+func (s *Service) disputeFineAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	fine := ctx.(*models.Fine)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	reason, _ := params["reason"].(string)
+	if err := fine.Dispute(userID, reason); err != nil {
+		if errors.Is(err, models.ErrFineNotDisputable) {
+			return err
+		}
+		return fmt.Errorf("failed to dispute fine: %w", err)
+	}
+
+	return writeEntityJSON(w, "Fines", fine)
+}
+
+// resolveFineDisputeAction returns the handler for the admin resolution actions on Fine entity
+// POST /api/v2/Fines('{fineId}')/UpholdDispute
+// POST /api/v2/Fines('{fineId}')/WaiveFine
+// POST /api/v2/Fines('{fineId}')/ReduceFine
+func (s *Service) resolveFineDisputeAction(status string) odata.ActionHandler {
+	return func(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+		fine := ctx.(*models.Fine)
+
+		userID, err := s.requirePermission(r, fine.ClubID, models.PermissionFinesManage)
+		if err != nil {
+			return err
+		}
+
+		amount, _ := params["amount"].(float64)
+		comment, _ := params["comment"].(string)
+		oldAmount := fine.Amount
+		if err := fine.ResolveDispute(status, amount, comment, userID); err != nil {
+			if errors.Is(err, models.ErrFineNotDisputed) {
+				return err
+			}
+			return fmt.Errorf("failed to resolve dispute: %w", err)
+		}
+		s.auditAction(r, fine.ClubID, "Fine", fine.ID, "ResolveDispute", map[string]models.AuditChange{
+			"DisputeStatus": {New: status},
+			"Amount":        {Old: oldAmount, New: fine.Amount},
+		})
+
+		return writeEntityJSON(w, "Fines", fine)
+	}
+}
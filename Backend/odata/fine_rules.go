@@ -0,0 +1,129 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerFineRuleOperations registers the actions for attendance tracking and automatic fines
+func (s *Service) registerFineRuleOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "RecordAttendance",
+		IsBound:   true,
+		EntitySet: "Events",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: true},
+			{Name: "attended", Type: reflect.TypeOf(false), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.recordEventAttendanceAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RecordAttendance action for Event: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "RecordAttendance",
+		IsBound:   true,
+		EntitySet: "Shifts",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: true},
+			{Name: "attended", Type: reflect.TypeOf(false), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.recordShiftAttendanceAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RecordAttendance action for Shift: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "Approve",
+		IsBound:    true,
+		EntitySet:  "Fines",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.approveFineAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Approve action for Fine: %w", err)
+	}
+
+	return nil
+}
+
+// recordEventAttendanceAction handles the RecordAttendance action on Event entity
+// POST /api/v2/Events('{eventId}')/RecordAttendance
+func (s *Service) recordEventAttendanceAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	event := ctx.(*models.Event)
+
+	if _, err := s.requirePermission(r, event.ClubID, models.PermissionFinesManage); err != nil {
+		return err
+	}
+
+	userID, _ := params["userId"].(string)
+	attended, ok := params["attended"].(bool)
+	if userID == "" || !ok {
+		return fmt.Errorf("userId and attended parameters are required")
+	}
+
+	if err := event.RecordAttendance(userID, attended); err != nil {
+		return err
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// recordShiftAttendanceAction handles the RecordAttendance action on Shift entity
+// POST /api/v2/Shifts('{shiftId}')/RecordAttendance
+func (s *Service) recordShiftAttendanceAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	shift := ctx.(*models.Shift)
+
+	if _, err := s.requirePermission(r, shift.ClubID, models.PermissionFinesManage); err != nil {
+		return err
+	}
+
+	userID, _ := params["userId"].(string)
+	attended, ok := params["attended"].(bool)
+	if userID == "" || !ok {
+		return fmt.Errorf("userId and attended parameters are required")
+	}
+
+	if err := shift.RecordAttendance(userID, attended); err != nil {
+		return err
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// approveFineAction handles the Approve action on Fine entity
+// Releases an automatic fine after review and notifies the member.
+// POST /api/v2/Fines('{fineId}')/Approve
+func (s *Service) approveFineAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	fine := ctx.(*models.Fine)
+
+	userID, err := s.requirePermission(r, fine.ClubID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	if err := fine.Approve(userID); err != nil {
+		if errors.Is(err, models.ErrFineNotPendingReview) {
+			return err
+		}
+		return fmt.Errorf("failed to approve fine: %w", err)
+	}
+	s.auditAction(r, fine.ClubID, "Fine", fine.ID, "Approve", map[string]models.AuditChange{
+		"PendingReview": {Old: true, New: false},
+	})
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
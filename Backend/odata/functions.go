@@ -49,6 +49,19 @@ func (s *Service) registerFunctions() error {
 		return fmt.Errorf("failed to register GetInviteLink function for Club: %w", err)
 	}
 
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:      "GetShiftsNeedingPeople",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "days", Type: reflect.TypeOf(int64(0)), Required: false},
+		},
+		ReturnType: reflect.TypeOf([]models.OpenShift{}),
+		Handler:    s.getShiftsNeedingPeopleFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetShiftsNeedingPeople function for Club: %w", err)
+	}
+
 	// Bound functions for Event entity
 	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
 		Name:      "ExpandRecurrence",
@@ -90,10 +103,12 @@ func (s *Service) registerFunctions() error {
 
 	// Bound functions for Team entity
 	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
-		Name:       "GetOverview",
-		IsBound:    true,
-		EntitySet:  "Teams",
-		Parameters: []odata.ParameterDefinition{},
+		Name:      "GetOverview",
+		IsBound:   true,
+		EntitySet: "Teams",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "seasonId", Type: reflect.TypeOf(""), Required: false},
+		},
 		ReturnType: reflect.TypeOf(TeamOverviewResponse{}),
 		Handler:    s.getTeamOverviewFunction,
 	}); err != nil {
@@ -125,6 +140,7 @@ type TeamOverviewResponse struct {
 	Stats    map[string]interface{} `json:"Stats"`
 	UserRole string                 `json:"UserRole"`
 	IsAdmin  bool                   `json:"IsAdmin"`
+	Absences []models.MemberAbsence `json:"Absences"` // Current and upcoming absences of team members visible to the user
 }
 
 type EventWithRSVP struct {
@@ -178,8 +194,8 @@ func (s *Service) getInviteLinkFunction(w http.ResponseWriter, r *http.Request,
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Check if user is admin or owner
-	if !club.IsOwner(user) && !club.IsAdmin(user) {
+	// Check if user holds the members.invite permission
+	if !models.HasPermission(club.ID, user.ID, models.PermissionMembersInvite) {
 		return nil, fmt.Errorf("unauthorized: only club admins can get invite link")
 	}
 
@@ -483,7 +499,8 @@ func calculateNextOccurrence(current time.Time, pattern string, interval int) ti
 }
 
 // getTeamOverviewFunction returns team overview with stats and user role
-// GET /api/v2/Teams('{teamId}')/GetOverview()
+// The stats can be limited to the events and fines of a season.
+// GET /api/v2/Teams('{teamId}')/GetOverview(seasonId='{seasonId}')
 func (s *Service) getTeamOverviewFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
 	team := ctx.(*models.Team)
 
@@ -510,7 +527,13 @@ func (s *Service) getTeamOverviewFunction(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get team stats
-	stats, err := team.GetTeamStats()
+	seasonID, _ := params["seasonId"].(string)
+	if seasonID != "" {
+		if _, err := club.GetSeason(seasonID); err != nil {
+			return nil, err
+		}
+	}
+	stats, err := team.GetTeamStats(seasonID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team stats: %w", err)
 	}
@@ -521,11 +544,17 @@ func (s *Service) getTeamOverviewFunction(w http.ResponseWriter, r *http.Request
 		userRole, _ = team.GetUserRole(user)
 	}
 
+	absences, err := team.GetUpcomingAbsences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team absences: %w", err)
+	}
+
 	return TeamOverviewResponse{
 		Team:     *team,
 		Stats:    stats,
 		UserRole: userRole,
 		IsAdmin:  team.IsAdmin(user),
+		Absences: absences,
 	}, nil
 }
 
@@ -597,3 +626,47 @@ func (s *Service) getRSVPCountsFunction(w http.ResponseWriter, r *http.Request,
 
 	return result, nil
 }
+
+// getShiftsNeedingPeopleFunction returns upcoming shifts below their minimum headcount
+// GET /api/v2/Clubs('{clubId}')/GetShiftsNeedingPeople(days=14)
+func (s *Service) getShiftsNeedingPeopleFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	club := ctx.(*models.Club)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if !club.IsMember(user) {
+		return nil, fmt.Errorf("forbidden: user is not a member of this club")
+	}
+
+	if err := models.CheckFeatureEnabled(club.ID, "shifts"); err != nil {
+		return nil, err
+	}
+
+	days := 14
+	switch v := params["days"].(type) {
+	case int64:
+		days = int(v)
+	case int:
+		days = v
+	case float64:
+		days = int(v)
+	}
+	if days < 1 || days > 365 {
+		return nil, fmt.Errorf("days must be between 1 and 365")
+	}
+
+	openShifts, err := club.GetShiftsNeedingPeople(days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shifts needing people: %w", err)
+	}
+
+	return openShifts, nil
+}
@@ -0,0 +1,108 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerGuardianshipOperations registers the management of dependents by their guardians
+func (s *Service) registerGuardianshipOperations() error {
+	// Unbound action for creating a dependent without own email address
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:    "CreateDependent",
+		IsBound: false,
+		Parameters: []odata.ParameterDefinition{
+			{Name: "firstName", Type: reflect.TypeOf(""), Required: true},
+			{Name: "lastName", Type: reflect.TypeOf(""), Required: true},
+			{Name: "birthDate", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.User{}),
+		Handler:    s.createDependentAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register CreateDependent action: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "AddGuardian",
+		IsBound:   true,
+		EntitySet: "Users",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "email", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.Guardianship{}),
+		Handler:    s.addGuardianAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register AddGuardian action for User: %w", err)
+	}
+
+	return nil
+}
+
+// createDependentAction handles the unbound CreateDependent action
+// The current user becomes the guardian of the new dependent.
+// POST /api/v2/CreateDependent
+func (s *Service) createDependentAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	firstName, _ := params["firstName"].(string)
+	lastName, _ := params["lastName"].(string)
+
+	var birthDate *time.Time
+	if value, ok := params["birthDate"].(string); ok && value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("invalid birthDate: expected YYYY-MM-DD")
+		}
+		birthDate = &parsed
+	}
+
+	dependent, err := models.CreateDependent(userID, firstName, lastName, birthDate)
+	if err != nil {
+		if errors.Is(err, models.ErrDependentNameRequired) {
+			return err
+		}
+		return fmt.Errorf("failed to create dependent: %w", err)
+	}
+
+	return writeEntityJSON(w, "Users", dependent)
+}
+
+// addGuardianAction handles the AddGuardian action on User entity
+// Guardians add another guardian, dependents with own account add their guardians.
+// POST /api/v2/Users('{userId}')/AddGuardian
+func (s *Service) addGuardianAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	dependent := ctx.(*models.User)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	email, ok := params["email"].(string)
+	if !ok || email == "" {
+		return fmt.Errorf("email parameter is required")
+	}
+
+	guardianship, err := models.AddGuardian(dependent.ID, userID, email)
+	if err != nil {
+		if errors.Is(err, models.ErrNotGuardian) {
+			return fmt.Errorf("forbidden: %w", err)
+		}
+		if errors.Is(err, models.ErrGuardianNotFound) || errors.Is(err, models.ErrAlreadyGuardian) || errors.Is(err, models.ErrOwnGuardian) {
+			return err
+		}
+		return fmt.Errorf("failed to add guardian: %w", err)
+	}
+
+	return writeEntityJSON(w, "Guardianships", guardianship)
+}
@@ -23,14 +23,17 @@ func getUserIDFromContext(ctx context.Context) (string, error) {
 // AUTHORIZATION RULES:
 //
 // Users:
-// - Users can only read themselves
-// - Users can only update themselves
+// - Users can read themselves, members of their clubs, their dependents and their guardians
+// - Users can only update themselves and the dependents they manage
+// - Users cannot be deleted directly (see Account Deletion)
 //
 // Clubs:
 // - Users can read clubs they're members of (non-deleted) or created (all states)
 // - Users can create clubs
 // - Only admins/owners can update clubs
 // - Only owners can delete clubs
+// - Owners see their soft-deleted clubs with ?includeDeleted=true and can Restore them within 30 days
+// - The purge_deleted_clubs job notifies owners 7 days ahead, then hard-deletes the club with all its data
 //
 // Members:
 // - Users can read members of clubs they're members of
@@ -57,6 +60,180 @@ func getUserIDFromContext(ctx context.Context) (string, error) {
 // - Users can read news in clubs they're members of
 // - Only club admins can create/update news
 //
+// Comments:
+// - Users can read comments on news/events in clubs they're members of
+// - Club members can create comments and reactions
+// - Only the author can edit a comment (Edit action)
+// - The author or club admins can soft-delete a comment (SoftDelete action)
+//
+// Polls:
+// - Users can read polls in clubs they're members of; team polls only if in the team (or club admin)
+// - Only club admins can create/update/delete polls and options, and close polls
+// - Options, anonymity and result visibility are locked once voting has started; the club of a poll never changes
+// - Eligible members vote through the Vote action; results through GetResults
+//
+// SchedulingPolls:
+// - Same visibility as polls (club members; team polls only for the team or club admins)
+// - Only club admins can create/update/delete scheduling polls and slots
+// - Eligible members answer slots through the Respond action and may delete their own answers
+// - Only club admins can ConvertToEvent, which creates the event and pre-fills RSVPs
+//
+// Conversations & Messages:
+// - Users only see conversations of clubs they're members of
+// - Direct conversations: only the two participants (StartDirectConversation action);
+//   members may message admins, members sharing a team, or anyone if MembersListVisible is set
+// - Team channels: team members and club admins
+// - Announcements: all club members can read, only club admins can post
+// - Messages cannot be edited; the author or club admins can delete them
+// - Read positions are updated through the MarkRead action
+//
+// Membership Fees:
+// - Users can read fee plans and discounts of clubs they're members of
+// - Users can read their own fee assignments and invoices; club admins can read all of their club
+// - Only club admins can create/update/delete fee plans, discounts and assignments
+// - Invoices are generated by the billing job; only club admins can record payments or cancel unpaid invoices
+// - GetFeeStatement is available to the member and club admins
+//
+// SEPA Direct Debit:
+// - IBANs are encrypted at rest and only exposed masked
+// - Members can read and record (SetSepaMandate) their own mandates; club admins can manage all mandates of their club
+// - Creditor details are readable by club admins only and set through the SetSepaCreditor action
+// - Direct debits are recorded by ExportSepaDirectDebit and settled by ImportSepaStatement (camt.053), admins only
+// - Mandates that were used for a debit cannot be deleted; only pending debits can be discarded
+//
+// Automatic Fines:
+// - Fine rules are readable by club members when the fines feature is enabled; only admins can manage them
+// - The fine_rule_evaluation job creates fines pending review after events end
+// - Fines pending review are only visible to club admins until approved with the Approve action
+// - Admins record event and shift attendance with the RecordAttendance actions
+//
+// Fine Disputes:
+// - The fined member disputes an open, unpaid fine with the Dispute action
+// - Admins answer with UpholdDispute, WaiveFine or ReduceFine
+// - Every transition is recorded as a FineDisputeEntry, readable by the fined member and club admins, never writable
+// - Waived fines are excluded from unpaid totals, team statistics and payment collection
+//
+// Shift Sign-up:
+// - Members sign themselves up with SignUp until MaxHeadcount is reached (0 = unlimited)
+// - Withdraw is possible until WithdrawalCutoffHours before the shift starts; admins can still use RemoveMember
+// - GetShiftsNeedingPeople on Clubs lists upcoming shifts below MinHeadcount for club members
+//
+// Shift Swaps:
+// - Swap requests are readable by members of the club (shifts feature enabled) so offers can be claimed
+// - Only the assigned member can offer a slot (OfferSwap on ShiftMembers) or cancel the offer
+// - Other members claim as a cover or propose a trade; trades need the requester's RespondToTrade
+// - Shifts with SwapRequiresApproval wait for an admin's Approve/Reject; the roster is updated in one transaction
+// - Direct create/update/delete of swap requests is forbidden
+//
+// Custom Roles & Permissions:
+// - Clubs define roles (e.g. Treasurer) bundling permissions: fines.manage, events.manage, members.invite,
+//   news.publish, shifts.manage, settings.edit
+// - models.HasPermission is the central evaluator; owners hold every permission, admins all but club.delete
+// - Roles, their permissions and assignments are readable by club members; managing them needs members.manage
+// - Only club members can be assigned a role; permissions and assignments are created or deleted, never updated
+// - GetMyPermissions on Clubs returns the permissions of the current user
+//
+// Audit Log:
+// - Append-only; entries are written by the system, never created, updated or deleted through the API
+// - Clubs, settings, members, events, fines, fine templates, fine rules, custom roles, member fields and seasons are
+//   recorded from their ODataAfterCreate/Update/Delete hooks inside the write transaction; updates store a before/after diff
+// - Custom actions (role changes, fine approvals, dispute resolutions, payments, hard deletes) add an action entry
+// - Each entry holds the actor, the API key used (if any) and the client IP
+// - Readable and exportable (ExportAuditLog on Clubs, CSV) by club owners only (audit.read permission)
+//
+// Data Exports:
+// - Requested through the unbound RequestDataExport action; a pending request is reused
+// - The process_data_exports job builds a ZIP of JSON files with the user's data and notifies the user
+// - Users can only read and delete their own exports; exports cannot be created or changed directly
+// - GetDownloadLink returns a signed link to /api/v1/data-exports that works without login until the export expires
+//
+// Account Deletion:
+// - Users cannot DELETE their User entity; RequestAccountDeletion schedules the deletion after a 30 day grace period
+// - Rejected while the user is the last owner of an active club (ownership must be transferred first)
+// - CancelAccountDeletion cancels a pending deletion; users can only read their own deletion requests
+// - The purge_deleted_accounts job anonymizes the user row and removes sessions, API keys, memberships,
+//   notifications and upcoming commitments; fines, payments and past activity keep pointing at the anonymized user
+//
+// Ownership Transfers & Succession:
+// - Owners offer the club to a member with TransferOwnership; the recipient Accepts or Declines, any owner can Cancel
+// - On acceptance the recipient becomes owner and the sending owner becomes admin; one pending transfer per club
+// - Transfers are readable by club owners and the recipient and cannot be created, changed or deleted directly
+// - Owners designate a successor (a non-owner member) with SetSuccessor; owners and the successor can read or delete it
+// - The successor is promoted to owner when the last owner's account is purged, or by the promote_club_successors
+//   job once all owners have been inactive for the configured number of days
+// - Every step sends notifications and writes an audit log entry; automatic promotions have no actor
+//
+// Guardians & Dependents:
+// - Guardians create dependents without own email (CreateDependent); the dependent gets a placeholder address
+// - Guardians and dependents with own account add further guardians with AddGuardian on Users
+// - Guardianships are readable by the guardian, the dependent and co-guardians; they are never created or changed
+//   directly and can be deleted by a guardian or the dependent, but managed dependents keep at least one guardian
+// - Guardians can act for dependents: Join clubs and AddRSVP (userId parameter), create/update/delete their RSVPs
+//   and join requests, take them out of a club, and update the profile of managed dependents
+// - Guardians see their dependents' clubs, events, memberships, RSVPs and fines, not other members' RSVPs or fines
+// - Every notification for a dependent is copied to their guardians with DependentID set
+//
+// Member Profile Fields:
+// - Clubs define fields (text, number, date, select, boolean) with visibility self, admins or all and a required flag
+// - Definitions are managed by members with members.manage; other members only see non-admin fields
+// - Values are set through SetFieldValue on Members, which validates and normalizes them; admins set every field,
+//   members and their guardians set their own non-admin fields; required fields cannot be cleared
+// - MemberFieldValues are filterable by FieldID and Value; admins see all values of their club, members see values
+//   of fields visible to all and their own (and their dependents') values of self fields
+// - ExportMembers on Clubs returns the members with all field values as CSV (members.manage); data exports
+//   include the user's own values
+//
+// Seasons:
+// - Seasons are readable by club members; only members with settings.edit can create/update/delete them
+// - Seasons of a club must not overlap; EndDate is exclusive
+// - Events join the season containing their start time, fines the season of their event or the current season,
+//   new teams the earliest season that is neither archived nor over; a new season takes over matching
+//   events and fines without a season
+// - Rollover copies the teams of a season with their rosters into the next season and archives the old one
+// - GetOverview on Teams and GetVolunteerHours on Clubs accept a seasonId to limit the statistics to a season
+// - Deleting a season detaches its teams, events and fines
+//
+// Membership Status & History:
+// - Members are active, passive, suspended or honorary; SetStatus on Members changes the status as of an
+//   effectiveFrom date (today or in the past, not before the current status began) and requires members.manage
+// - Only members without admin or owner role can be suspended; suspended members lose the permissions of their
+//   custom roles and cannot be promoted until they are reinstated
+// - Every change opens a MembershipStatusChange and closes the previous one; the history is read-only
+// - Leaving, removal and account deletion make the member former instead of deleting the row; former members
+//   are hidden from all member scopes and rejoin with the same member ID and field values, continuing the history
+// - Users and guardians read their own history; members with members.manage read the club's, including former members
+//
+// Venues:
+// - Venues are readable by club members; only club admins can create/update/delete them
+// - Events and shifts may reference a venue of their own club
+// - Creating or updating an event that overlaps another event at a venue without AllowOverlap fails
+// - GetOccupancy lists the bookings of a venue for club members, flagging conflicts and exceeded capacity
+//
+// Member Absences:
+// - Members record their own absences (date range, reason, visibility); club admins can record them for any member
+// - Visibility: private (member and club admins), team (also admins of the member's teams), club (all members)
+// - Creating or extending an absence declines upcoming events in the range through CreateOrUpdateRSVP
+// - Absent members cannot sign up for or claim shifts and are skipped by AutoAssignShifts
+// - GetOverview on Teams lists the visible current and upcoming absences of team members
+//
+// Volunteer Hours:
+// - The club's VolunteerQuota is readable by members (shifts feature enabled); only admins can configure it
+// - Hours are counted from shift times; ended shifts count unless the member was recorded as absent
+// - GetVolunteerHours on Clubs returns the full report to admins and only the caller's own row to members
+// - Only admins can run AutoAssignShifts, which fills shifts below MinHeadcount with the least loaded available members
+//
+// Shift Templates:
+// - Shift templates and their slots are readable and writable by club admins only (shifts feature enabled)
+// - ApplyShiftTemplate on Events generates the shifts, optionally for all later occurrences of a recurring event
+// - A template is applied at most once per event
+//
+// Bank Statements:
+// - Statement imports and their transactions are readable by club admins only
+// - Statements are uploaded through the ImportBankStatement action (CAMT.053, MT940, CSV)
+// - Matches by reference code and amount are settled automatically; uncertain matches
+//   (reference with other amount, payer name and amount, amount only) need ConfirmMatch
+// - Transactions cannot be written directly; admins can Ignore them or delete the whole import
+//
 // Notifications:
 // - Users can only read their own notifications
 // - Users can only update their own notifications (mark as read)
@@ -0,0 +1,108 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerMemberFieldOperations registers setting member field values and the member export
+func (s *Service) registerMemberFieldOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "SetFieldValue",
+		IsBound:   true,
+		EntitySet: "Members",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "fieldId", Type: reflect.TypeOf(""), Required: true},
+			{Name: "value", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.MemberFieldValue{}),
+		Handler:    s.setMemberFieldValueAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SetFieldValue action for Member: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "ExportMembers",
+		IsBound:    true,
+		EntitySet:  "Clubs",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.exportMembersAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ExportMembers action for Club: %w", err)
+	}
+
+	return nil
+}
+
+// setMemberFieldValueAction handles the SetFieldValue action on Member entity
+// The value is validated against the field type; an empty value clears an optional field.
+// POST /api/v2/Members('{memberId}')/SetFieldValue
+func (s *Service) setMemberFieldValueAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	member := ctx.(*models.Member)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	fieldID, ok := params["fieldId"].(string)
+	if !ok || fieldID == "" {
+		return fmt.Errorf("fieldId parameter is required")
+	}
+	value, _ := params["value"].(string)
+
+	var previous models.MemberFieldValue
+	s.db.Where("member_id = ? AND field_id = ?", member.ID, fieldID).Limit(1).Find(&previous)
+
+	fieldValue, err := models.SetMemberFieldValue(member, fieldID, value, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrMemberFieldForbidden) {
+			return fmt.Errorf("forbidden: %w", err)
+		}
+		return err
+	}
+
+	change := models.AuditChange{Old: previous.Value}
+	if fieldValue != nil {
+		change.New = fieldValue.Value
+	}
+	var field models.MemberFieldDefinition
+	s.db.Select("name").Where("id = ?", fieldID).First(&field)
+	s.auditAction(r, member.ClubID, "Member", member.ID, "SetFieldValue", map[string]models.AuditChange{field.Name: change})
+
+	if fieldValue == nil {
+		w.Header().Set("OData-Version", "4.0")
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return writeEntityJSON(w, "MemberFieldValues", fieldValue)
+}
+
+// exportMembersAction handles the ExportMembers action on Club entity
+// Returns the members with all member field values as CSV.
+// POST /api/v2/Clubs('{clubId}')/ExportMembers
+func (s *Service) exportMembersAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	if _, err := s.requirePermission(r, club.ID, models.PermissionMembersManage); err != nil {
+		return err
+	}
+
+	document, err := models.ExportMembers(club.ID)
+	if err != nil {
+		return fmt.Errorf("failed to export members: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"members.csv\"")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(document)
+	return err
+}
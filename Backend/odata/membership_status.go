@@ -0,0 +1,72 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerMembershipStatusOperations registers changing the membership status
+func (s *Service) registerMembershipStatusOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "SetStatus",
+		IsBound:   true,
+		EntitySet: "Members",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "status", Type: reflect.TypeOf(""), Required: true},
+			{Name: "effectiveFrom", Type: reflect.TypeOf(""), Required: false},
+			{Name: "reason", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.Member{}),
+		Handler:    s.setMemberStatusAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SetStatus action for Member: %w", err)
+	}
+
+	return nil
+}
+
+// setMemberStatusAction handles the SetStatus action on Member entity
+// Changes the status to active, passive, suspended or honorary as of effectiveFrom (default: now)
+// and records the change in the membership history.
+// POST /api/v2/Members('{memberId}')/SetStatus
+func (s *Service) setMemberStatusAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	member := ctx.(*models.Member)
+
+	userID, err := s.requirePermission(r, member.ClubID, models.PermissionMembersManage)
+	if err != nil {
+		return err
+	}
+
+	status, _ := params["status"].(string)
+	if status == "" {
+		return fmt.Errorf("status is required")
+	}
+	effectiveFrom := time.Now()
+	if value, ok := params["effectiveFrom"].(string); ok && value != "" {
+		if effectiveFrom, err = time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("invalid effectiveFrom: expected YYYY-MM-DD")
+		}
+	}
+	reason, _ := params["reason"].(string)
+
+	previous := member.Status
+	if err := models.SetMemberStatus(member, status, effectiveFrom, reason, userID); err != nil {
+		if errors.Is(err, models.ErrInvalidMemberStatus) || errors.Is(err, models.ErrMemberStatusUnchanged) ||
+			errors.Is(err, models.ErrMemberStatusDate) || errors.Is(err, models.ErrSuspendAdmin) {
+			return err
+		}
+		return fmt.Errorf("failed to change membership status: %w", err)
+	}
+	s.auditAction(r, member.ClubID, "Member", member.ID, "SetStatus", map[string]models.AuditChange{
+		"Status":      {Old: previous, New: member.Status},
+		"StatusSince": {New: effectiveFrom},
+	})
+
+	return writeEntityJSON(w, "Members", member)
+}
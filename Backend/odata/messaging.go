@@ -0,0 +1,153 @@
+package odata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerMessagingOperations registers the actions and functions for conversations and messages
+func (s *Service) registerMessagingOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "StartDirectConversation",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.Conversation{}),
+		Handler:    s.startDirectConversationAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register StartDirectConversation action for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "MarkRead",
+		IsBound:    true,
+		EntitySet:  "Conversations",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.markConversationReadAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register MarkRead action for Conversation: %w", err)
+	}
+
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetReadBy",
+		IsBound:    true,
+		EntitySet:  "Messages",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf([]models.MessageReader{}),
+		Handler:    s.getMessageReadByFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetReadBy function for Message: %w", err)
+	}
+
+	// Unbound function
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetUnreadMessageCounts",
+		IsBound:    false,
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.UnreadMessageCounts{}),
+		Handler:    s.getUnreadMessageCountsFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetUnreadMessageCounts function: %w", err)
+	}
+
+	return nil
+}
+
+// startDirectConversationAction handles the StartDirectConversation action on Club entity
+// Returns the existing conversation between the two members if there is one.
+// POST /api/v2/Clubs('{clubId}')/StartDirectConversation
+func (s *Service) startDirectConversationAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	recipientID, ok := params["userId"].(string)
+	if !ok || !isValidUUID(recipientID) {
+		return fmt.Errorf("invalid userId format: must be a valid UUID")
+	}
+
+	conversation, err := models.StartDirectConversation(club.ID, userID, recipientID)
+	if err != nil {
+		if errors.Is(err, models.ErrMessagingNotAllowed) {
+			return fmt.Errorf("unauthorized: %w", err)
+		}
+		return fmt.Errorf("failed to start conversation: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"@odata.context": "/api/v2/$metadata#Conversations/$entity",
+		"ID":             conversation.ID,
+		"ClubID":         conversation.ClubID,
+		"Type":           conversation.Type,
+		"LastMessageAt":  conversation.LastMessageAt,
+		"CreatedAt":      conversation.CreatedAt,
+		"CreatedBy":      conversation.CreatedBy,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// markConversationReadAction handles the MarkRead action on Conversation entity
+// POST /api/v2/Conversations('{conversationId}')/MarkRead
+func (s *Service) markConversationReadAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	conversation := ctx.(*models.Conversation)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	if !conversation.CanAccess(userID) {
+		return fmt.Errorf("unauthorized: cannot access this conversation")
+	}
+
+	if err := conversation.MarkRead(userID); err != nil {
+		return fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// getMessageReadByFunction returns the read receipts of a message
+// GET /api/v2/Messages('{messageId}')/GetReadBy()
+func (s *Service) getMessageReadByFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	message := ctx.(*models.Message)
+
+	readers, err := message.GetReadBy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read receipts: %w", err)
+	}
+
+	return readers, nil
+}
+
+// getUnreadMessageCountsFunction returns the unread message counts of the current user
+// GET /api/v2/GetUnreadMessageCounts()
+func (s *Service) getUnreadMessageCountsFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	// Get user ID from request context
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	counts, err := models.GetUnreadMessageCounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread message counts: %w", err)
+	}
+
+	return counts, nil
+}
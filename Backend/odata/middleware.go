@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -25,7 +26,7 @@ func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 			}
 
 			authHeader := r.Header.Get("Authorization")
-			var userID string
+			var userID, apiKeyID string
 			var err error
 
 			// Try Bearer token (JWT) first if Authorization header exists
@@ -59,7 +60,7 @@ func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 			} else if authHeader != "" && strings.HasPrefix(authHeader, "ApiKey ") {
 				// Try API key authentication if ApiKey scheme is used
 				apiKey := strings.TrimPrefix(authHeader, "ApiKey ")
-				userID, _, err = auth.ValidateAPIKey(apiKey)
+				apiKeyID, userID, _, err = auth.ValidateAPIKeyWithID(apiKey)
 				if err != nil {
 					log.Printf("API key validation failed: %v", err)
 					http.Error(w, "Invalid API key", http.StatusUnauthorized)
@@ -67,7 +68,7 @@ func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 				}
 			} else if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
 				// Also support X-API-Key header as convenience
-				userID, _, err = auth.ValidateAPIKey(apiKey)
+				apiKeyID, userID, _, err = auth.ValidateAPIKeyWithID(apiKey)
 				if err != nil {
 					log.Printf("API key validation failed: %v", err)
 					http.Error(w, "Invalid API key", http.StatusUnauthorized)
@@ -85,6 +86,12 @@ func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 
 			// Add user ID to context for use in read/write hooks
 			ctx := context.WithValue(r.Context(), auth.UserIDKey, userID)
+			if apiKeyID != "" {
+				ctx = context.WithValue(ctx, auth.APIKeyIDKey, apiKeyID)
+			}
+
+			// Carry entity states from update hooks to the audit log
+			ctx = models.WithAuditState(ctx)
 
 			// Phase 5: Parse includeDeleted query parameter
 			ctx = ParseIncludeDeletedFromQuery(ctx, r)
@@ -113,7 +120,7 @@ const (
 func ParseIncludeDeletedFromQuery(ctx context.Context, r *http.Request) context.Context {
 	// Check query parameter
 	if r.URL.Query().Get("includeDeleted") == "true" {
-		return context.WithValue(ctx, IncludeDeletedKey, true)
+		return models.WithIncludeDeleted(context.WithValue(ctx, IncludeDeletedKey, true))
 	}
 
 	return ctx
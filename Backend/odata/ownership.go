@@ -0,0 +1,190 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerOwnershipOperations registers ownership transfers and successor designation
+func (s *Service) registerOwnershipOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "TransferOwnership",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.OwnershipTransfer{}),
+		Handler:    s.transferOwnershipAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register TransferOwnership action for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "SetSuccessor",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "userId", Type: reflect.TypeOf(""), Required: true},
+			{Name: "inactivityDays", Type: reflect.TypeOf(int64(0)), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.ClubSuccession{}),
+		Handler:    s.setSuccessorAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SetSuccessor action for Club: %w", err)
+	}
+
+	for _, action := range []struct {
+		name    string
+		handler func(http.ResponseWriter, *http.Request, interface{}, map[string]interface{}) error
+	}{
+		{"Accept", s.acceptOwnershipTransferAction},
+		{"Decline", s.declineOwnershipTransferAction},
+		{"Cancel", s.cancelOwnershipTransferAction},
+	} {
+		if err := s.Service.RegisterAction(odata.ActionDefinition{
+			Name:       action.name,
+			IsBound:    true,
+			EntitySet:  "OwnershipTransfers",
+			Parameters: []odata.ParameterDefinition{},
+			ReturnType: reflect.TypeOf(models.OwnershipTransfer{}),
+			Handler:    action.handler,
+		}); err != nil {
+			return fmt.Errorf("failed to register %s action for OwnershipTransfer: %w", action.name, err)
+		}
+	}
+
+	return nil
+}
+
+// transferOwnershipAction handles the TransferOwnership action on Club entity
+// Offers the club to another member; ownership changes once they accept.
+// POST /api/v2/Clubs('{clubId}')/TransferOwnership
+func (s *Service) transferOwnershipAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	if !club.IsOwner(models.User{ID: userID}) {
+		return fmt.Errorf("unauthorized: only club owners can transfer ownership")
+	}
+
+	recipientID, _ := params["userId"].(string)
+	if recipientID == "" {
+		return fmt.Errorf("userId is required")
+	}
+
+	transfer, err := models.RequestOwnershipTransfer(club, userID, recipientID)
+	if err != nil {
+		if errors.Is(err, models.ErrTransferRecipientNotMember) || errors.Is(err, models.ErrTransferRecipientIsOwner) ||
+			errors.Is(err, models.ErrOwnershipTransferPending) || errors.Is(err, models.ErrSuspendedMemberRole) {
+			return err
+		}
+		return fmt.Errorf("failed to transfer ownership: %w", err)
+	}
+	s.auditAction(r, club.ID, "OwnershipTransfer", transfer.ID, "TransferOwnership", map[string]models.AuditChange{
+		"ToUserID": {New: recipientID},
+	})
+
+	return writeEntityJSON(w, "OwnershipTransfers", transfer)
+}
+
+// respondToOwnershipTransfer runs a response to a transfer and records it in the audit log
+func (s *Service) respondToOwnershipTransfer(w http.ResponseWriter, r *http.Request, ctx interface{}, action string,
+	respond func(*models.OwnershipTransfer, string) error) error {
+	transfer := ctx.(*models.OwnershipTransfer)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	previousStatus := transfer.Status
+	if err := respond(transfer, userID); err != nil {
+		return err
+	}
+	s.auditAction(r, transfer.ClubID, "OwnershipTransfer", transfer.ID, action, map[string]models.AuditChange{
+		"Status": {Old: previousStatus, New: transfer.Status},
+	})
+
+	return writeEntityJSON(w, "OwnershipTransfers", transfer)
+}
+
+// acceptOwnershipTransferAction handles the Accept action on OwnershipTransfer entity
+// POST /api/v2/OwnershipTransfers('{transferId}')/Accept
+func (s *Service) acceptOwnershipTransferAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	return s.respondToOwnershipTransfer(w, r, ctx, "AcceptOwnershipTransfer", (*models.OwnershipTransfer).Accept)
+}
+
+// declineOwnershipTransferAction handles the Decline action on OwnershipTransfer entity
+// POST /api/v2/OwnershipTransfers('{transferId}')/Decline
+func (s *Service) declineOwnershipTransferAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	return s.respondToOwnershipTransfer(w, r, ctx, "DeclineOwnershipTransfer", (*models.OwnershipTransfer).Decline)
+}
+
+// cancelOwnershipTransferAction handles the Cancel action on OwnershipTransfer entity
+// POST /api/v2/OwnershipTransfers('{transferId}')/Cancel
+func (s *Service) cancelOwnershipTransferAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	return s.respondToOwnershipTransfer(w, r, ctx, "CancelOwnershipTransfer", (*models.OwnershipTransfer).Cancel)
+}
+
+// setSuccessorAction handles the SetSuccessor action on Club entity
+// Designates the member who becomes owner if the club is left without an active owner.
+// POST /api/v2/Clubs('{clubId}')/SetSuccessor
+func (s *Service) setSuccessorAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+	if !club.IsOwner(models.User{ID: userID}) {
+		return fmt.Errorf("unauthorized: only club owners can designate a successor")
+	}
+
+	successorID, _ := params["userId"].(string)
+	if successorID == "" {
+		return fmt.Errorf("userId is required")
+	}
+	inactivityDays := 0
+	switch v := params["inactivityDays"].(type) {
+	case int64:
+		inactivityDays = int(v)
+	case float64:
+		inactivityDays = int(v)
+	case int:
+		inactivityDays = v
+	}
+
+	previous, err := models.GetClubSuccession(club.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load successor: %w", err)
+	}
+	succession, err := models.SetClubSuccessor(club, successorID, inactivityDays, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrSuccessorNotMember) || errors.Is(err, models.ErrSuccessorIsOwner) {
+			return err
+		}
+		return fmt.Errorf("failed to set successor: %w", err)
+	}
+
+	changes := map[string]models.AuditChange{
+		"SuccessorID":    {New: succession.SuccessorID},
+		"InactivityDays": {New: succession.InactivityDays},
+	}
+	if previous != nil {
+		changes["SuccessorID"] = models.AuditChange{Old: previous.SuccessorID, New: succession.SuccessorID}
+		changes["InactivityDays"] = models.AuditChange{Old: previous.InactivityDays, New: succession.InactivityDays}
+	}
+	s.auditAction(r, club.ID, "ClubSuccession", succession.ID, "SetSuccessor", changes)
+
+	return writeEntityJSON(w, "ClubSuccessions", succession)
+}
@@ -0,0 +1,44 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerPermissionOperations registers the permission lookup for custom club roles
+func (s *Service) registerPermissionOperations() error {
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetMyPermissions",
+		IsBound:    true,
+		EntitySet:  "Clubs",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf([]string{}),
+		Handler:    s.getMyPermissionsFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetMyPermissions function for Club: %w", err)
+	}
+
+	return nil
+}
+
+// getMyPermissionsFunction returns the permissions the current user holds in the club
+// GET /api/v2/Clubs('{clubId}')/GetMyPermissions()
+func (s *Service) getMyPermissionsFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	club := ctx.(*models.Club)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	permissions, err := models.GetPermissions(club.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,138 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerPollOperations registers the bound actions and functions for Poll entity
+func (s *Service) registerPollOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Vote",
+		IsBound:   true,
+		EntitySet: "Polls",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "optionIds", Type: reflect.TypeOf([]string{}), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.votePollAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Vote action for Poll: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:       "ClosePoll",
+		IsBound:    true,
+		EntitySet:  "Polls",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: nil,
+		Handler:    s.closePollAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ClosePoll action for Poll: %w", err)
+	}
+
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetResults",
+		IsBound:    true,
+		EntitySet:  "Polls",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf(models.PollResults{}),
+		Handler:    s.getPollResultsFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetResults function for Poll: %w", err)
+	}
+
+	return nil
+}
+
+// votePollAction handles the Vote action on Poll entity
+// Voting again replaces the previous votes of the user.
+// POST /api/v2/Polls('{pollId}')/Vote
+func (s *Service) votePollAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	poll := ctx.(*models.Poll)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	// Get optionIds parameter
+	var optionIDs []string
+	switch ids := params["optionIds"].(type) {
+	case []string:
+		optionIDs = ids
+	case []interface{}:
+		for _, id := range ids {
+			if optionID, ok := id.(string); ok {
+				optionIDs = append(optionIDs, optionID)
+			}
+		}
+	default:
+		return fmt.Errorf("optionIds parameter is required")
+	}
+
+	for _, optionID := range optionIDs {
+		if !isValidUUID(optionID) {
+			return fmt.Errorf("invalid optionIds format: must be valid UUIDs")
+		}
+	}
+
+	if err := poll.Vote(userID, optionIDs); err != nil {
+		if errors.Is(err, models.ErrPollNotAllowed) {
+			return fmt.Errorf("unauthorized: %w", err)
+		}
+		return fmt.Errorf("failed to vote: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// closePollAction handles the ClosePoll action on Poll entity
+// POST /api/v2/Polls('{pollId}')/ClosePoll
+func (s *Service) closePollAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	poll := ctx.(*models.Poll)
+
+	userID, err := s.requirePermission(r, poll.ClubID, models.PermissionNewsPublish)
+	if err != nil {
+		return err
+	}
+
+	if err := poll.Close(userID); err != nil {
+		return fmt.Errorf("failed to close poll: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// getPollResultsFunction returns the tally of a poll
+// Results are hidden while the poll is open if HideResultsUntilClosed is set,
+// and voter names are omitted for anonymous polls.
+// GET /api/v2/Polls('{pollId}')/GetResults()
+func (s *Service) getPollResultsFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	poll := ctx.(*models.Poll)
+
+	// Get user ID from request context
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if !poll.CanView(userID) {
+		return nil, fmt.Errorf("forbidden: user cannot access this poll")
+	}
+
+	results, err := poll.GetResults(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll results: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,139 @@
+package odata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerSchedulingPollOperations registers the bound actions and functions for scheduling polls
+func (s *Service) registerSchedulingPollOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Respond",
+		IsBound:   true,
+		EntitySet: "SchedulingSlots",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "answer", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.respondSchedulingSlotAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Respond action for SchedulingSlot: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ConvertToEvent",
+		IsBound:   true,
+		EntitySet: "SchedulingPolls",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "slotId", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.Event{}),
+		Handler:    s.convertSchedulingPollToEventAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ConvertToEvent action for SchedulingPoll: %w", err)
+	}
+
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:       "GetSummary",
+		IsBound:    true,
+		EntitySet:  "SchedulingPolls",
+		Parameters: []odata.ParameterDefinition{},
+		ReturnType: reflect.TypeOf([]models.SchedulingSlotSummary{}),
+		Handler:    s.getSchedulingPollSummaryFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetSummary function for SchedulingPoll: %w", err)
+	}
+
+	return nil
+}
+
+// respondSchedulingSlotAction handles the Respond action on SchedulingSlot entity
+// Answering again replaces the previous answer of the user.
+// POST /api/v2/SchedulingSlots('{slotId}')/Respond
+func (s *Service) respondSchedulingSlotAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	slot := ctx.(*models.SchedulingSlot)
+
+	// Get user ID from request context
+	userID := r.Context().Value(auth.UserIDKey).(string)
+
+	answer, ok := params["answer"].(string)
+	if !ok {
+		return fmt.Errorf("answer parameter is required")
+	}
+
+	if err := slot.Respond(userID, answer); err != nil {
+		if errors.Is(err, models.ErrPollNotAllowed) {
+			return fmt.Errorf("unauthorized: %w", err)
+		}
+		return fmt.Errorf("failed to respond: %w", err)
+	}
+
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// convertSchedulingPollToEventAction handles the ConvertToEvent action on SchedulingPoll entity
+// Without slotId the best ranked slot is used. RSVPs are pre-filled from the answers and absent members are declined.
+// POST /api/v2/SchedulingPolls('{pollId}')/ConvertToEvent
+func (s *Service) convertSchedulingPollToEventAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	poll := ctx.(*models.SchedulingPoll)
+
+	userID, err := s.requirePermission(r, poll.ClubID, models.PermissionEventsManage)
+	if err != nil {
+		return err
+	}
+
+	if err := models.CheckFeatureEnabled(poll.ClubID, "events"); err != nil {
+		return err
+	}
+
+	slotID, _ := params["slotId"].(string)
+	if slotID != "" && !isValidUUID(slotID) {
+		return fmt.Errorf("invalid slotId format: must be a valid UUID")
+	}
+
+	event, err := poll.ConvertToEvent(slotID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to convert scheduling poll: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"@odata.context": "/api/v2/$metadata#Events/$entity",
+		"ID":             event.ID,
+		"ClubID":         event.ClubID,
+		"TeamID":         event.TeamID,
+		"Name":           event.Name,
+		"Description":    event.Description,
+		"Location":       event.Location,
+		"StartTime":      event.StartTime,
+		"EndTime":        event.EndTime,
+		"CreatedAt":      event.CreatedAt,
+		"CreatedBy":      event.CreatedBy,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// getSchedulingPollSummaryFunction returns the answer counts per slot, best ranked first
+// GET /api/v2/SchedulingPolls('{pollId}')/GetSummary()
+func (s *Service) getSchedulingPollSummaryFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	poll := ctx.(*models.SchedulingPoll)
+
+	summary, err := poll.GetSummary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduling poll summary: %w", err)
+	}
+
+	return summary, nil
+}
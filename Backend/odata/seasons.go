@@ -0,0 +1,78 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerSeasonOperations registers the season rollover
+func (s *Service) registerSeasonOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Rollover",
+		IsBound:   true,
+		EntitySet: "Seasons",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "name", Type: reflect.TypeOf(""), Required: true},
+			{Name: "startDate", Type: reflect.TypeOf(""), Required: false},
+			{Name: "endDate", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.Season{}),
+		Handler:    s.rolloverSeasonAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Rollover action for Season: %w", err)
+	}
+
+	return nil
+}
+
+// rolloverSeasonAction handles the Rollover action on Season entity
+// Starts the next season with copies of the teams and their rosters and archives the season.
+// Without dates the next season starts when this one ends and lasts as long.
+// POST /api/v2/Seasons('{seasonId}')/Rollover
+func (s *Service) rolloverSeasonAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	season := ctx.(*models.Season)
+
+	userID, err := s.requirePermission(r, season.ClubID, models.PermissionSettingsEdit)
+	if err != nil {
+		return err
+	}
+
+	name, _ := params["name"].(string)
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	startDate := season.EndDate
+	if value, ok := params["startDate"].(string); ok && value != "" {
+		if startDate, err = time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("invalid startDate: expected YYYY-MM-DD")
+		}
+	}
+	endDate := startDate.Add(season.EndDate.Sub(season.StartDate))
+	if value, ok := params["endDate"].(string); ok && value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("invalid endDate: expected YYYY-MM-DD")
+		}
+	}
+
+	next, err := season.Rollover(name, startDate, endDate, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrSeasonArchived) || errors.Is(err, models.ErrSeasonOrder) ||
+			errors.Is(err, models.ErrSeasonOverlap) {
+			return err
+		}
+		return fmt.Errorf("failed to roll over season: %w", err)
+	}
+	s.auditAction(r, season.ClubID, "Season", season.ID, "Rollover", map[string]models.AuditChange{
+		"Archived":     {Old: false, New: true},
+		"NextSeasonID": {New: next.ID},
+	})
+
+	return writeEntityJSON(w, "Seasons", next)
+}
@@ -0,0 +1,258 @@
+package odata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerSepaOperations registers the actions for SEPA direct debit
+func (s *Service) registerSepaOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "SetSepaMandate",
+		IsBound:   true,
+		EntitySet: "Members",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "iban", Type: reflect.TypeOf(""), Required: true},
+			{Name: "bic", Type: reflect.TypeOf(""), Required: false},
+			{Name: "mandateReference", Type: reflect.TypeOf(""), Required: true},
+			{Name: "signatureDate", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.SepaMandate{}),
+		Handler:    s.setSepaMandateAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SetSepaMandate action for Member: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "SetSepaCreditor",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "name", Type: reflect.TypeOf(""), Required: true},
+			{Name: "iban", Type: reflect.TypeOf(""), Required: true},
+			{Name: "bic", Type: reflect.TypeOf(""), Required: false},
+			{Name: "creditorId", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.SepaCreditor{}),
+		Handler:    s.setSepaCreditorAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register SetSepaCreditor action for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ExportSepaDirectDebit",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "fineIds", Type: reflect.TypeOf([]string{}), Required: false},
+			{Name: "invoiceIds", Type: reflect.TypeOf([]string{}), Required: false},
+			{Name: "collectionDate", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: nil,
+		Handler:    s.exportSepaDirectDebitAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ExportSepaDirectDebit action for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ImportSepaStatement",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "content", Type: reflect.TypeOf(""), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.SepaImportResult{}),
+		Handler:    s.importSepaStatementAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ImportSepaStatement action for Club: %w", err)
+	}
+
+	return nil
+}
+
+// requirePermission returns the requesting user ID if the user holds the permission in the club
+func (s *Service) requirePermission(r *http.Request, clubID, permission string) (string, error) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if !models.HasPermission(clubID, userID, permission) {
+		return "", fmt.Errorf("unauthorized: missing permission %s", permission)
+	}
+
+	return userID, nil
+}
+
+// stringSliceParam reads an optional string list parameter
+func stringSliceParam(params map[string]interface{}, name string) []string {
+	switch v := params[name].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok && str != "" {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// writeEntityJSON writes an entity as the response of an action
+func writeEntityJSON(w http.ResponseWriter, entitySet string, entity interface{}) error {
+	payload, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	response := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return err
+	}
+	response["@odata.context"] = "/api/v2/$metadata#" + entitySet + "/$entity"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// writeCollectionJSON writes a collection response for an action
+func writeCollectionJSON(w http.ResponseWriter, entitySet string, values interface{}) error {
+	response := map[string]interface{}{
+		"@odata.context": "/api/v2/$metadata#" + entitySet,
+		"value":          values,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// setSepaMandateAction handles the SetSepaMandate action on Member entity
+// Members can record their own mandate, club admins the mandates of all members.
+// POST /api/v2/Members('{memberId}')/SetSepaMandate
+func (s *Service) setSepaMandateAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	member := ctx.(*models.Member)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return fmt.Errorf("unauthorized: missing user id")
+	}
+
+	if member.UserID != userID {
+		if _, err := s.requirePermission(r, member.ClubID, models.PermissionFinesManage); err != nil {
+			return err
+		}
+	}
+
+	iban, _ := params["iban"].(string)
+	bic, _ := params["bic"].(string)
+	reference, _ := params["mandateReference"].(string)
+	signatureDateStr, _ := params["signatureDate"].(string)
+
+	signatureDate, err := time.Parse("2006-01-02", signatureDateStr)
+	if err != nil {
+		return fmt.Errorf("invalid signatureDate format: must be YYYY-MM-DD")
+	}
+
+	mandate, err := models.SetSepaMandate(member.ClubID, member.UserID, iban, bic, reference, signatureDate, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidIBAN) || errors.Is(err, models.ErrInvalidBIC) {
+			return err
+		}
+		return fmt.Errorf("failed to set mandate: %w", err)
+	}
+
+	return writeEntityJSON(w, "SepaMandates", mandate)
+}
+
+// setSepaCreditorAction handles the SetSepaCreditor action on Club entity
+// POST /api/v2/Clubs('{clubId}')/SetSepaCreditor
+func (s *Service) setSepaCreditorAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, err := s.requirePermission(r, club.ID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	name, _ := params["name"].(string)
+	iban, _ := params["iban"].(string)
+	bic, _ := params["bic"].(string)
+	creditorID, _ := params["creditorId"].(string)
+
+	creditor, err := models.SetSepaCreditor(club.ID, name, iban, bic, creditorID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set creditor: %w", err)
+	}
+
+	return writeEntityJSON(w, "SepaCreditors", creditor)
+}
+
+// exportSepaDirectDebitAction handles the ExportSepaDirectDebit action on Club entity
+// Returns a pain.008.001.02 XML file for the selected unpaid fines and invoices.
+// POST /api/v2/Clubs('{clubId}')/ExportSepaDirectDebit
+func (s *Service) exportSepaDirectDebitAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, err := s.requirePermission(r, club.ID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	collectionDateStr, _ := params["collectionDate"].(string)
+	collectionDate, err := time.Parse("2006-01-02", collectionDateStr)
+	if err != nil {
+		return fmt.Errorf("invalid collectionDate format: must be YYYY-MM-DD")
+	}
+
+	document, err := models.ExportSepaDirectDebit(club.ID, stringSliceParam(params, "fineIds"), stringSliceParam(params, "invoiceIds"), collectionDate, userID)
+	if err != nil {
+		return fmt.Errorf("failed to export direct debits: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"sepa-%s.xml\"", collectionDate.Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(document)
+	return err
+}
+
+// importSepaStatementAction handles the ImportSepaStatement action on Club entity
+// Marks the direct debits found in a camt.053 statement as paid.
+// POST /api/v2/Clubs('{clubId}')/ImportSepaStatement
+func (s *Service) importSepaStatementAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, err := s.requirePermission(r, club.ID, models.PermissionFinesManage)
+	if err != nil {
+		return err
+	}
+
+	content, _ := params["content"].(string)
+	if content == "" {
+		return fmt.Errorf("statement content is required")
+	}
+
+	result, err := models.ImportCamt053(club.ID, []byte(content), userID)
+	if err != nil {
+		return fmt.Errorf("failed to import statement: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(result)
+}
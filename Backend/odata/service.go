@@ -1,9 +1,14 @@
 package odata
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 
+	"github.com/NLstn/civo/models"
 	"github.com/nlstn/go-odata"
 	"gorm.io/gorm"
 )
@@ -59,6 +64,111 @@ func NewService(db *gorm.DB) (*Service, error) {
 		return nil, fmt.Errorf("failed to register functions: %w", err)
 	}
 
+	// Register poll actions and functions
+	if err := service.registerPollOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register poll operations: %w", err)
+	}
+
+	// Register scheduling poll actions and functions
+	if err := service.registerSchedulingPollOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register scheduling poll operations: %w", err)
+	}
+
+	// Register messaging actions and functions
+	if err := service.registerMessagingOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register messaging operations: %w", err)
+	}
+
+	// Register membership fee actions and functions
+	if err := service.registerFeeOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register fee operations: %w", err)
+	}
+
+	// Register SEPA direct debit operations
+	if err := service.registerSepaOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register SEPA operations: %w", err)
+	}
+
+	// Register bank statement operations
+	if err := service.registerBankStatementOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register bank statement operations: %w", err)
+	}
+
+	// Register fine rule operations
+	if err := service.registerFineRuleOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register fine rule operations: %w", err)
+	}
+
+	// Register fine dispute operations
+	if err := service.registerFineDisputeOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register fine dispute operations: %w", err)
+	}
+
+	// Register shift template operations
+	if err := service.registerShiftTemplateOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register shift template operations: %w", err)
+	}
+
+	// Register shift swap operations
+	if err := service.registerShiftSwapOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register shift swap operations: %w", err)
+	}
+
+	// Register volunteer hour operations
+	if err := service.registerVolunteerHourOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register volunteer hour operations: %w", err)
+	}
+
+	// Register venue operations
+	if err := service.registerVenueOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register venue operations: %w", err)
+	}
+
+	// Register custom role permission operations
+	if err := service.registerPermissionOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register permission operations: %w", err)
+	}
+
+	// Register audit log operations
+	if err := service.registerAuditLogOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register audit log operations: %w", err)
+	}
+
+	// Register data export operations
+	if err := service.registerDataExportOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register data export operations: %w", err)
+	}
+
+	// Register account deletion operations
+	if err := service.registerAccountDeletionOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register account deletion operations: %w", err)
+	}
+
+	// Register ownership transfer and succession operations
+	if err := service.registerOwnershipOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register ownership operations: %w", err)
+	}
+
+	// Register guardian and dependent operations
+	if err := service.registerGuardianshipOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register guardianship operations: %w", err)
+	}
+
+	// Register member profile field operations
+	if err := service.registerMemberFieldOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register member field operations: %w", err)
+	}
+
+	// Register season operations
+	if err := service.registerSeasonOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register season operations: %w", err)
+	}
+
+	// Register membership status operations
+	if err := service.registerMembershipStatusOperations(); err != nil {
+		return nil, fmt.Errorf("failed to register membership status operations: %w", err)
+	}
+
 	// Register virtual entity handlers
 	if err := service.registerTimelineHandlers(); err != nil {
 		return nil, fmt.Errorf("failed to register timeline handlers: %w", err)
@@ -66,3 +176,26 @@ func NewService(db *gorm.DB) (*Service, error) {
 
 	return service, nil
 }
+
+// maxUpdateBodySize limits the body of update requests, which is buffered in memory
+const maxUpdateBodySize = 1 << 20
+
+// ServeHTTP serves OData requests. The body of update requests is kept in the request context,
+// so update hooks can validate the new values before go-odata applies them.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPatch || r.Method == http.MethodPut {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUpdateBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r = r.WithContext(models.WithUpdateBody(r.Context(), body))
+	}
+	s.Service.ServeHTTP(w, r)
+}
@@ -0,0 +1,196 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerShiftSwapOperations registers the actions for shift swap and cover requests
+func (s *Service) registerShiftSwapOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "OfferSwap",
+		IsBound:   true,
+		EntitySet: "ShiftMembers",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "note", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.ShiftSwapRequest{}),
+		Handler:    s.offerShiftSwapAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register OfferSwap action for ShiftMember: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "Claim",
+		IsBound:   true,
+		EntitySet: "ShiftSwapRequests",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "tradeShiftMemberId", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf(models.ShiftSwapRequest{}),
+		Handler:    s.claimShiftSwapAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register Claim action for ShiftSwapRequest: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "RespondToTrade",
+		IsBound:   true,
+		EntitySet: "ShiftSwapRequests",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "accept", Type: reflect.TypeOf(false), Required: true},
+		},
+		ReturnType: reflect.TypeOf(models.ShiftSwapRequest{}),
+		Handler:    s.respondToShiftTradeAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register RespondToTrade action for ShiftSwapRequest: %w", err)
+	}
+
+	for _, action := range []struct {
+		name    string
+		handler odata.ActionHandler
+	}{
+		{name: "Approve", handler: s.approveShiftSwapAction},
+		{name: "Reject", handler: s.rejectShiftSwapAction},
+		{name: "Cancel", handler: s.cancelShiftSwapAction},
+	} {
+		if err := s.Service.RegisterAction(odata.ActionDefinition{
+			Name:       action.name,
+			IsBound:    true,
+			EntitySet:  "ShiftSwapRequests",
+			Parameters: []odata.ParameterDefinition{},
+			ReturnType: reflect.TypeOf(models.ShiftSwapRequest{}),
+			Handler:    action.handler,
+		}); err != nil {
+			return fmt.Errorf("failed to register %s action for ShiftSwapRequest: %w", action.name, err)
+		}
+	}
+
+	return nil
+}
+
+// swapUserID reads the user ID from the request and checks the shifts feature
+func swapUserID(r *http.Request, clubID string) (string, error) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("unauthorized: missing user id")
+	}
+	if err := models.CheckFeatureEnabled(clubID, "shifts"); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// writeSwapResult maps swap errors and writes the updated request
+func writeSwapResult(w http.ResponseWriter, request *models.ShiftSwapRequest, err error) error {
+	if err != nil {
+		if errors.Is(err, models.ErrShiftSwapNotOpen) || errors.Is(err, models.ErrShiftStarted) {
+			return err
+		}
+		return fmt.Errorf("failed to update swap request: %w", err)
+	}
+	return writeEntityJSON(w, "ShiftSwapRequests", request)
+}
+
+// offerShiftSwapAction handles the OfferSwap action on ShiftMember entity
+// The assigned member offers their slot for a cover or a trade.
+// POST /api/v2/ShiftMembers('{shiftMemberId}')/OfferSwap
+func (s *Service) offerShiftSwapAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	shiftMember := ctx.(*models.ShiftMember)
+
+	var shift models.Shift
+	if err := s.db.Where("id = ?", shiftMember.ShiftID).First(&shift).Error; err != nil {
+		return fmt.Errorf("failed to find shift: %w", err)
+	}
+
+	userID, err := swapUserID(r, shift.ClubID)
+	if err != nil {
+		return err
+	}
+
+	note, _ := params["note"].(string)
+	request, err := shiftMember.OfferSwap(userID, note)
+	return writeSwapResult(w, &request, err)
+}
+
+// claimShiftSwapAction handles the Claim action on ShiftSwapRequest entity
+// Without tradeShiftMemberId the claimant covers the slot, otherwise they propose a trade.
+// POST /api/v2/ShiftSwapRequests('{requestId}')/Claim
+func (s *Service) claimShiftSwapAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	request := ctx.(*models.ShiftSwapRequest)
+
+	userID, err := swapUserID(r, request.ClubID)
+	if err != nil {
+		return err
+	}
+
+	var tradeShiftMemberID *string
+	if id, ok := params["tradeShiftMemberId"].(string); ok && id != "" {
+		tradeShiftMemberID = &id
+	}
+
+	return writeSwapResult(w, request, request.Claim(userID, tradeShiftMemberID))
+}
+
+// respondToShiftTradeAction handles the RespondToTrade action on ShiftSwapRequest entity
+// POST /api/v2/ShiftSwapRequests('{requestId}')/RespondToTrade
+func (s *Service) respondToShiftTradeAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	request := ctx.(*models.ShiftSwapRequest)
+
+	userID, err := swapUserID(r, request.ClubID)
+	if err != nil {
+		return err
+	}
+
+	accept, ok := params["accept"].(bool)
+	if !ok {
+		return fmt.Errorf("accept parameter is required")
+	}
+
+	return writeSwapResult(w, request, request.RespondToTrade(userID, accept))
+}
+
+// approveShiftSwapAction handles the Approve action on ShiftSwapRequest entity
+// POST /api/v2/ShiftSwapRequests('{requestId}')/Approve
+func (s *Service) approveShiftSwapAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	request := ctx.(*models.ShiftSwapRequest)
+
+	userID, err := s.requirePermission(r, request.ClubID, models.PermissionShiftsManage)
+	if err != nil {
+		return err
+	}
+
+	return writeSwapResult(w, request, request.Approve(userID))
+}
+
+// rejectShiftSwapAction handles the Reject action on ShiftSwapRequest entity
+// POST /api/v2/ShiftSwapRequests('{requestId}')/Reject
+func (s *Service) rejectShiftSwapAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	request := ctx.(*models.ShiftSwapRequest)
+
+	userID, err := s.requirePermission(r, request.ClubID, models.PermissionShiftsManage)
+	if err != nil {
+		return err
+	}
+
+	return writeSwapResult(w, request, request.Reject(userID))
+}
+
+// cancelShiftSwapAction handles the Cancel action on ShiftSwapRequest entity
+// POST /api/v2/ShiftSwapRequests('{requestId}')/Cancel
+func (s *Service) cancelShiftSwapAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	request := ctx.(*models.ShiftSwapRequest)
+
+	userID, err := swapUserID(r, request.ClubID)
+	if err != nil {
+		return err
+	}
+
+	return writeSwapResult(w, request, request.Cancel(userID))
+}
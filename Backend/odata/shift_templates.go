@@ -0,0 +1,62 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerShiftTemplateOperations registers the actions for generating shifts from templates
+func (s *Service) registerShiftTemplateOperations() error {
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "ApplyShiftTemplate",
+		IsBound:   true,
+		EntitySet: "Events",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "templateId", Type: reflect.TypeOf(""), Required: true},
+			{Name: "includeOccurrences", Type: reflect.TypeOf(false), Required: false},
+		},
+		ReturnType: reflect.TypeOf([]models.Shift{}),
+		Handler:    s.applyShiftTemplateAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register ApplyShiftTemplate action for Event: %w", err)
+	}
+
+	return nil
+}
+
+// applyShiftTemplateAction handles the ApplyShiftTemplate action on Event entity
+// Generates the shifts of the template for the event and, with includeOccurrences,
+// for all later occurrences of a recurring event.
+// POST /api/v2/Events('{eventId}')/ApplyShiftTemplate
+func (s *Service) applyShiftTemplateAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	event := ctx.(*models.Event)
+
+	userID, err := s.requirePermission(r, event.ClubID, models.PermissionShiftsManage)
+	if err != nil {
+		return err
+	}
+
+	if err := models.CheckFeatureEnabled(event.ClubID, "shifts"); err != nil {
+		return err
+	}
+
+	templateID, _ := params["templateId"].(string)
+	if templateID == "" {
+		return fmt.Errorf("templateId parameter is required")
+	}
+	includeOccurrences, _ := params["includeOccurrences"].(bool)
+
+	shifts, err := event.ApplyShiftTemplate(templateID, includeOccurrences, userID)
+	if err != nil {
+		return fmt.Errorf("failed to apply shift template: %w", err)
+	}
+	if shifts == nil {
+		shifts = []models.Shift{}
+	}
+
+	return writeCollectionJSON(w, "Shifts", shifts)
+}
@@ -56,7 +56,7 @@ func (s *Service) getUserClubs(userID string) ([]string, map[string]string, erro
 	return validClubIDs, clubNameMap, nil
 }
 
-// getTimelineCollection retrieves all timeline items (activities, events, news) for the user
+// getTimelineCollection retrieves all timeline items (activities, events, news, polls) for the user
 func (s *Service) getTimelineCollection(ctx *odata.OverwriteContext) (*odata.CollectionResult, error) {
 	// Get user ID from request context
 	userID, ok := ctx.Request.Context().Value(auth.UserIDKey).(string)
@@ -104,6 +104,15 @@ func (s *Service) getTimelineCollection(ctx *odata.OverwriteContext) (*odata.Col
 		timelineItems = append(timelineItems, news...)
 	}
 
+	// Fetch polls
+	polls, err := s.fetchPolls(userClubIDs, clubNameMap, userID)
+	if err != nil {
+		s.logger.Error("Failed to fetch polls for timeline", "error", err)
+		// Continue even if polls fail
+	} else {
+		timelineItems = append(timelineItems, polls...)
+	}
+
 	// Sort by timestamp (most recent first)
 	sort.Slice(timelineItems, func(i, j int) bool {
 		return timelineItems[i].Timestamp.After(timelineItems[j].Timestamp)
@@ -128,7 +137,7 @@ func (s *Service) getTimelineEntity(ctx *odata.OverwriteContext) (interface{}, e
 	}
 
 	// Parse the timeline item ID to determine type and actual ID
-	// Format: "activity-{id}", "event-{id}", or "news-{id}"
+	// Format: "activity-{id}", "event-{id}", "news-{id}", or "poll-{id}"
 	timelineID := ctx.EntityKey
 
 	// Parse ID to extract type and entity ID
@@ -273,6 +282,22 @@ func (s *Service) getTimelineEntity(ctx *odata.OverwriteContext) (interface{}, e
 			Metadata:  make(map[string]interface{}),
 		}, nil
 
+	case "poll":
+		var poll models.Poll
+		if err := s.db.Where("id = ?", itemID).First(&poll).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("poll not found")
+			}
+			return nil, fmt.Errorf("failed to fetch poll: %w", err)
+		}
+
+		// Check authorization (team-restricted polls are only visible to the team and club admins)
+		if !clubIDSet[poll.ClubID] || !poll.CanView(userID) {
+			return nil, fmt.Errorf("access denied: user cannot access this poll")
+		}
+
+		return pollTimelineItem(poll, clubNameMap), nil
+
 	default:
 		return nil, fmt.Errorf("unknown timeline item type: %s", itemType)
 	}
@@ -443,3 +468,61 @@ func (s *Service) fetchNews(clubIDs []string, clubNameMap map[string]string) ([]
 
 	return items, nil
 }
+
+// fetchPolls fetches recent polls visible to the user and converts them to timeline items
+func (s *Service) fetchPolls(clubIDs []string, clubNameMap map[string]string, userID string) ([]models.TimelineItem, error) {
+	if len(clubIDs) == 0 {
+		return []models.TimelineItem{}, nil
+	}
+
+	var polls []models.Poll
+	err := s.db.Where("club_id IN ?", clubIDs).
+		Scopes(models.VisiblePollsScope(userID)).
+		Order("created_at DESC").
+		Limit(50).
+		Find(&polls).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.TimelineItem
+	for _, poll := range polls {
+		items = append(items, pollTimelineItem(poll, clubNameMap))
+	}
+
+	return items, nil
+}
+
+// pollTimelineItem converts a poll to a timeline item
+func pollTimelineItem(poll models.Poll, clubNameMap map[string]string) models.TimelineItem {
+	metadata := map[string]interface{}{
+		"multipleChoice": poll.MultipleChoice,
+		"anonymous":      poll.Anonymous,
+		"closed":         poll.IsClosed(),
+	}
+	if poll.Deadline != nil {
+		metadata["deadline"] = *poll.Deadline
+	}
+	if poll.TeamID != nil {
+		metadata["teamId"] = *poll.TeamID
+	}
+
+	content := ""
+	if poll.Description != nil {
+		content = *poll.Description
+	}
+
+	return models.TimelineItem{
+		ID:        fmt.Sprintf("poll-%s", poll.ID),
+		ClubID:    poll.ClubID,
+		ClubName:  clubNameMap[poll.ClubID],
+		Type:      "poll",
+		Title:     poll.Question,
+		Content:   content,
+		Timestamp: poll.CreatedAt,
+		CreatedAt: poll.CreatedAt,
+		UpdatedAt: poll.UpdatedAt,
+		Metadata:  metadata,
+	}
+}
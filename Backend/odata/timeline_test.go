@@ -91,7 +91,22 @@ func setupTimelineTestContext(t *testing.T) *timelineTestContext {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		created_by TEXT,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_by TEXT
+		updated_by TEXT,
+		status TEXT DEFAULT 'active',
+		status_since DATETIME
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS membership_status_changes (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		role TEXT,
+		effective_from DATETIME NOT NULL,
+		effective_until DATETIME,
+		reason TEXT,
+		changed_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	)`)
 
 	testDB.Exec(`CREATE TABLE IF NOT EXISTS activities (
@@ -107,10 +122,26 @@ func setupTimelineTestContext(t *testing.T) *timelineTestContext {
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	)`)
 
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS seasons (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		start_date DATETIME NOT NULL,
+		end_date DATETIME NOT NULL,
+		archived BOOLEAN DEFAULT FALSE,
+		archived_at DATETIME,
+		archived_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT
+	)`)
+
 	testDB.Exec(`CREATE TABLE IF NOT EXISTS events (
 		id TEXT PRIMARY KEY,
 		club_id TEXT NOT NULL,
 		team_id TEXT,
+		season_id TEXT,
 		name TEXT,
 		description TEXT,
 		start_time DATETIME,
@@ -126,7 +157,8 @@ func setupTimelineTestContext(t *testing.T) *timelineTestContext {
 		recurrence_pattern TEXT,
 		recurrence_interval INTEGER DEFAULT 1,
 		recurrence_end DATETIME,
-		parent_event_id TEXT
+		parent_event_id TEXT,
+		venue_id TEXT
 	)`)
 
 	testDB.Exec(`CREATE TABLE IF NOT EXISTS news (
@@ -146,7 +178,56 @@ func setupTimelineTestContext(t *testing.T) *timelineTestContext {
 		user_id TEXT NOT NULL,
 		response TEXT,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		attended BOOLEAN,
+		previous_response TEXT,
+		response_changed_at DATETIME
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS team_members (
+		id TEXT PRIMARY KEY,
+		team_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT DEFAULT 'member',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS polls (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		team_id TEXT,
+		question TEXT NOT NULL,
+		description TEXT,
+		multiple_choice BOOLEAN DEFAULT FALSE,
+		anonymous BOOLEAN DEFAULT FALSE,
+		hide_results_until_closed BOOLEAN DEFAULT FALSE,
+		deadline DATETIME,
+		closed BOOLEAN DEFAULT FALSE,
+		closed_at DATETIME,
+		closed_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_by TEXT
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS club_role_permissions (
+		id TEXT PRIMARY KEY,
+		role_id TEXT NOT NULL,
+		permission TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+
+	testDB.Exec(`CREATE TABLE IF NOT EXISTS club_role_assignments (
+		id TEXT PRIMARY KEY,
+		club_id TEXT NOT NULL,
+		role_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_by TEXT
 	)`)
 
 	// Create test users
@@ -570,3 +651,76 @@ func TestGetTimelineCollection_WithContext(t *testing.T) {
 	// Should work with context
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+// TestGetTimelineCollection_IncludesPolls tests that polls appear in the timeline
+// and that team-restricted polls are hidden from members outside the team
+func TestGetTimelineCollection_IncludesPolls(t *testing.T) {
+	ctx := setupTimelineTestContext(t)
+
+	description := "Pick the colour of next season's kit"
+	poll := &models.Poll{
+		ID:          uuid.New().String(),
+		ClubID:      ctx.club1.ID,
+		Question:    "Kit colour",
+		Description: &description,
+		CreatedAt:   time.Now().Add(-10 * time.Minute),
+		CreatedBy:   ctx.user1.ID,
+		UpdatedAt:   time.Now().Add(-10 * time.Minute),
+		UpdatedBy:   ctx.user1.ID,
+	}
+	require.NoError(t, database.Db.Create(poll).Error)
+
+	teamID := uuid.New().String()
+	teamPoll := &models.Poll{
+		ID:        uuid.New().String(),
+		ClubID:    ctx.club2.ID,
+		TeamID:    &teamID,
+		Question:  "Team dinner venue",
+		CreatedAt: time.Now(),
+		CreatedBy: ctx.user2.ID,
+		UpdatedAt: time.Now(),
+		UpdatedBy: ctx.user2.ID,
+	}
+	require.NoError(t, database.Db.Create(teamPoll).Error)
+
+	t.Run("club poll is listed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/TimelineItems", nil)
+		req.Header.Set("Authorization", "Bearer "+ctx.token1)
+		w := httptest.NewRecorder()
+		ctx.handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Value []models.TimelineItem `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		var found *models.TimelineItem
+		for i := range response.Value {
+			if response.Value[i].Type == "poll" {
+				found = &response.Value[i]
+			}
+		}
+		require.NotNil(t, found)
+		assert.Equal(t, fmt.Sprintf("poll-%s", poll.ID), found.ID)
+		assert.Equal(t, poll.Question, found.Title)
+		assert.Equal(t, description, found.Content)
+	})
+
+	t.Run("team poll is hidden from non-team members", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/TimelineItems", nil)
+		req.Header.Set("Authorization", "Bearer "+ctx.token2)
+		w := httptest.NewRecorder()
+		ctx.handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Value []models.TimelineItem `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		for _, item := range response.Value {
+			assert.NotEqual(t, "poll", item.Type)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerVenueOperations registers the occupancy calendar of venues
+func (s *Service) registerVenueOperations() error {
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:      "GetOccupancy",
+		IsBound:   true,
+		EntitySet: "Venues",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "startDate", Type: reflect.TypeOf(time.Time{}), Required: true},
+			{Name: "endDate", Type: reflect.TypeOf(time.Time{}), Required: true},
+		},
+		ReturnType: reflect.TypeOf([]models.VenueBooking{}),
+		Handler:    s.getVenueOccupancyFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetOccupancy function for Venue: %w", err)
+	}
+
+	return nil
+}
+
+// getVenueOccupancyFunction returns the bookings of a venue in a time range, flagging conflicts
+// GET /api/v2/Venues('{venueId}')/GetOccupancy(startDate=2024-01-01T00:00:00Z,endDate=2024-01-31T23:59:59Z)
+func (s *Service) getVenueOccupancyFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	venue := ctx.(*models.Venue)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	club, err := models.GetClubByID(venue.ClubID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find club: %w", err)
+	}
+	if !club.IsMember(user) {
+		return nil, fmt.Errorf("forbidden: user is not a member of this club")
+	}
+
+	startDate, ok := params["startDate"].(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("invalid startDate parameter")
+	}
+	endDate, ok := params["endDate"].(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("invalid endDate parameter")
+	}
+	if !endDate.After(startDate) {
+		return nil, fmt.Errorf("endDate must be after startDate")
+	}
+	if endDate.Sub(startDate) > 366*24*time.Hour {
+		return nil, fmt.Errorf("the range must not exceed one year")
+	}
+
+	bookings, err := venue.GetOccupancy(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get venue occupancy: %w", err)
+	}
+
+	return bookings, nil
+}
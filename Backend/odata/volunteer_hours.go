@@ -0,0 +1,129 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/NLstn/civo/auth"
+	"github.com/NLstn/civo/models"
+	odata "github.com/nlstn/go-odata"
+)
+
+// registerVolunteerHourOperations registers the volunteer hour report and shift auto-assignment
+func (s *Service) registerVolunteerHourOperations() error {
+	if err := s.Service.RegisterFunction(odata.FunctionDefinition{
+		Name:      "GetVolunteerHours",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "underQuotaOnly", Type: reflect.TypeOf(false), Required: false},
+			{Name: "seasonId", Type: reflect.TypeOf(""), Required: false},
+		},
+		ReturnType: reflect.TypeOf([]models.VolunteerHours{}),
+		Handler:    s.getVolunteerHoursFunction,
+	}); err != nil {
+		return fmt.Errorf("failed to register GetVolunteerHours function for Club: %w", err)
+	}
+
+	if err := s.Service.RegisterAction(odata.ActionDefinition{
+		Name:      "AutoAssignShifts",
+		IsBound:   true,
+		EntitySet: "Clubs",
+		Parameters: []odata.ParameterDefinition{
+			{Name: "days", Type: reflect.TypeOf(int64(0)), Required: false},
+		},
+		ReturnType: reflect.TypeOf([]models.ShiftMember{}),
+		Handler:    s.autoAssignShiftsAction,
+	}); err != nil {
+		return fmt.Errorf("failed to register AutoAssignShifts action for Club: %w", err)
+	}
+
+	return nil
+}
+
+// getVolunteerHoursFunction returns the shift hours per member in the current quota period or the given season
+// Admins get the whole club, members only their own hours.
+// GET /api/v2/Clubs('{clubId}')/GetVolunteerHours(underQuotaOnly=true,seasonId='{seasonId}')
+func (s *Service) getVolunteerHoursFunction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) (interface{}, error) {
+	club := ctx.(*models.Club)
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("unauthorized: missing user id")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if !club.IsMember(user) {
+		return nil, fmt.Errorf("forbidden: user is not a member of this club")
+	}
+
+	if err := models.CheckFeatureEnabled(club.ID, "shifts"); err != nil {
+		return nil, err
+	}
+
+	underQuotaOnly, _ := params["underQuotaOnly"].(bool)
+	var season *models.Season
+	if seasonID, _ := params["seasonId"].(string); seasonID != "" {
+		var err error
+		if season, err = club.GetSeason(seasonID); err != nil {
+			return nil, err
+		}
+	}
+	report, err := club.GetVolunteerHours(underQuotaOnly, season)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volunteer hours: %w", err)
+	}
+
+	if models.HasPermission(club.ID, userID, models.PermissionShiftsManage) {
+		return report, nil
+	}
+
+	own := []models.VolunteerHours{}
+	for _, hours := range report {
+		if hours.UserID == userID {
+			own = append(own, hours)
+		}
+	}
+	return own, nil
+}
+
+// autoAssignShiftsAction handles the AutoAssignShifts action on Club entity
+// Fills upcoming shifts below their minimum headcount, balancing the load between members.
+// POST /api/v2/Clubs('{clubId}')/AutoAssignShifts
+func (s *Service) autoAssignShiftsAction(w http.ResponseWriter, r *http.Request, ctx interface{}, params map[string]interface{}) error {
+	club := ctx.(*models.Club)
+
+	userID, err := s.requirePermission(r, club.ID, models.PermissionShiftsManage)
+	if err != nil {
+		return err
+	}
+
+	if err := models.CheckFeatureEnabled(club.ID, "shifts"); err != nil {
+		return err
+	}
+
+	days := 14
+	switch v := params["days"].(type) {
+	case int64:
+		days = int(v)
+	case int:
+		days = v
+	case float64:
+		days = int(v)
+	}
+	if days < 1 || days > 365 {
+		return fmt.Errorf("days must be between 1 and 365")
+	}
+
+	assigned, err := club.AutoAssignShifts(days, userID)
+	if err != nil {
+		return fmt.Errorf("failed to assign shifts: %w", err)
+	}
+
+	return writeCollectionJSON(w, "ShiftMembers", assigned)
+}